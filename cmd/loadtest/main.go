@@ -0,0 +1,353 @@
+// Command loadtest drives a running server with simulated planning sessions:
+// each session has an owner and participants connected over WebSockets who
+// vote whenever voting starts. It reports how long broadcasts take to reach
+// clients and how fast votes are written.
+//
+//	go run ./cmd/loadtest -url http://localhost:8080 -sessions 10 -participants 8 -rounds 5
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var cards = []string{"1", "2", "3", "5", "8", "13"}
+
+// recorder collects latencies from every simulated client.
+type recorder struct {
+	mu         sync.Mutex
+	broadcasts []time.Duration // trigger request sent -> message received
+	votes      []time.Duration // vote request round trip
+	errors     int
+	sent       map[string]time.Time // trigger key -> when its request was sent
+}
+
+func (rec *recorder) markSent(key string) {
+	rec.mu.Lock()
+	rec.sent[key] = time.Now()
+	rec.mu.Unlock()
+}
+
+func (rec *recorder) markReceived(key string) {
+	now := time.Now()
+	rec.mu.Lock()
+	if sent, ok := rec.sent[key]; ok {
+		rec.broadcasts = append(rec.broadcasts, now.Sub(sent))
+	}
+	rec.mu.Unlock()
+}
+
+func (rec *recorder) vote(d time.Duration, err error) {
+	if err != nil {
+		rec.fail(err)
+		return
+	}
+	rec.mu.Lock()
+	rec.votes = append(rec.votes, d)
+	rec.mu.Unlock()
+}
+
+func (rec *recorder) fail(err error) {
+	log.Println(err)
+	rec.mu.Lock()
+	rec.errors++
+	rec.mu.Unlock()
+}
+
+// client is one simulated user with their own session cookie.
+type client struct {
+	base   *url.URL
+	http   *http.Client
+	userID string
+}
+
+func newClient(base *url.URL, username string) (*client, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &client{
+		base: base,
+		http: &http.Client{
+			Jar:     jar,
+			Timeout: 30 * time.Second,
+			// Redirects only lead back to pages we do not need
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+
+	if _, err := c.post("/set-username", url.Values{"username": {username}}); err != nil {
+		return nil, err
+	}
+	for _, cookie := range jar.Cookies(base) {
+		if cookie.Name == "poker_session" {
+			c.userID = cookie.Value
+		}
+	}
+	if c.userID == "" {
+		return nil, fmt.Errorf("no session cookie for %s", username)
+	}
+	return c, nil
+}
+
+func (c *client) post(path string, form url.Values) (*http.Response, error) {
+	resp, err := c.http.PostForm(c.base.String()+path, form)
+	if err != nil {
+		return nil, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("POST %s: %s", path, resp.Status)
+	}
+	return resp, nil
+}
+
+func (c *client) connect(sessionID string) (*websocket.Conn, error) {
+	wsURL := *c.base
+	wsURL.Scheme = strings.Replace(wsURL.Scheme, "http", "ws", 1)
+	wsURL.Path = "/session/" + sessionID + "/ws"
+
+	header := http.Header{}
+	for _, cookie := range c.http.Jar.Cookies(c.base) {
+		header.Add("Cookie", cookie.String())
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL.String(), header)
+	return conn, err
+}
+
+type message struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// listen reads messages until the connection closes, recording broadcast
+// latencies and handing each message to handle.
+func listen(conn *websocket.Conn, sessionID string, rec *recorder, handle func(message)) {
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+
+		switch msg.Type {
+		case "voting-started":
+			var ticket struct {
+				ID int `json:"id"`
+			}
+			json.Unmarshal(msg.Data, &ticket)
+			rec.markReceived(fmt.Sprintf("start:%s:%d", sessionID, ticket.ID))
+		case "vote-cast":
+			var vote struct {
+				UserID string `json:"user_id"`
+				Vote   struct {
+					TicketID int `json:"ticket_id"`
+				} `json:"vote"`
+			}
+			json.Unmarshal(msg.Data, &vote)
+			rec.markReceived(fmt.Sprintf("vote:%s:%d:%s", sessionID, vote.Vote.TicketID, vote.UserID))
+		}
+
+		handle(msg)
+	}
+}
+
+// runSession plays one planning session from start to finish.
+func runSession(base *url.URL, n, participants, rounds int, think time.Duration, rec *recorder) error {
+	owner, err := newClient(base, fmt.Sprintf("owner-%d", n))
+	if err != nil {
+		return err
+	}
+
+	resp, err := owner.post("/session/create", url.Values{"name": {fmt.Sprintf("Load test %d", n)}})
+	if err != nil {
+		return err
+	}
+	sessionID := strings.TrimPrefix(resp.Header.Get("HX-Redirect"), "/session/")
+	if sessionID == "" {
+		return fmt.Errorf("session %d: no session ID in response", n)
+	}
+
+	for i := 0; i < rounds; i++ {
+		if _, err := owner.post("/session/"+sessionID+"/tickets", url.Values{"title": {fmt.Sprintf("Ticket %d", i+1)}}); err != nil {
+			return err
+		}
+	}
+
+	var conns []*websocket.Conn
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	for p := 0; p < participants; p++ {
+		voter, err := newClient(base, fmt.Sprintf("voter-%d-%d", n, p))
+		if err != nil {
+			return err
+		}
+		if _, err := voter.post("/session/"+sessionID+"/join", nil); err != nil {
+			return err
+		}
+
+		conn, err := voter.connect(sessionID)
+		if err != nil {
+			return err
+		}
+		conns = append(conns, conn)
+
+		go listen(conn, sessionID, rec, func(msg message) {
+			if msg.Type != "voting-started" {
+				return
+			}
+			var ticket struct {
+				ID int `json:"id"`
+			}
+			json.Unmarshal(msg.Data, &ticket)
+
+			go func() {
+				time.Sleep(time.Duration(rand.Int63n(int64(think) + 1)))
+				rec.markSent(fmt.Sprintf("vote:%s:%d:%s", sessionID, ticket.ID, voter.userID))
+				start := time.Now()
+				_, err := voter.post("/session/"+sessionID+"/vote", url.Values{"vote": {cards[rand.Intn(len(cards))]}})
+				rec.vote(time.Since(start), err)
+			}()
+		})
+	}
+
+	// The owner learns each ticket's ID when it becomes current, and waits
+	// for every vote of a round before revealing
+	ticketChanged := make(chan int, 1)
+	votesCast := make(chan struct{}, participants)
+	conn, err := owner.connect(sessionID)
+	if err != nil {
+		return err
+	}
+	conns = append(conns, conn)
+	go listen(conn, sessionID, rec, func(msg message) {
+		switch msg.Type {
+		case "ticket-changed":
+			var ticket struct {
+				ID int `json:"id"`
+			}
+			json.Unmarshal(msg.Data, &ticket)
+			ticketChanged <- ticket.ID
+		case "vote-cast":
+			votesCast <- struct{}{}
+		}
+	})
+
+	for round := 0; round < rounds; round++ {
+		if _, err := owner.post("/session/"+sessionID+"/next-ticket", nil); err != nil {
+			return err
+		}
+
+		var ticketID int
+		select {
+		case ticketID = <-ticketChanged:
+		case <-time.After(30 * time.Second):
+			return fmt.Errorf("session %d round %d: ticket never changed", n, round+1)
+		}
+
+		rec.markSent(fmt.Sprintf("start:%s:%d", sessionID, ticketID))
+		if _, err := owner.post("/session/"+sessionID+"/start-voting", nil); err != nil {
+			return err
+		}
+
+		timeout := time.After(think + 30*time.Second)
+		for v := 0; v < participants; v++ {
+			select {
+			case <-votesCast:
+			case <-timeout:
+				return fmt.Errorf("session %d round %d: only %d of %d votes arrived", n, round+1, v, participants)
+			}
+		}
+
+		if _, err := owner.post("/session/"+sessionID+"/end-voting", nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
+
+func report(name string, durations []time.Duration) {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	fmt.Printf("%-10s n=%-6d p50=%-10v p90=%-10v p99=%-10v max=%v\n", name, len(durations),
+		percentile(durations, 0.50).Round(time.Microsecond),
+		percentile(durations, 0.90).Round(time.Microsecond),
+		percentile(durations, 0.99).Round(time.Microsecond),
+		percentile(durations, 1).Round(time.Microsecond))
+}
+
+func main() {
+	serverURL := flag.String("url", "http://localhost:8080", "server to load")
+	sessions := flag.Int("sessions", 10, "concurrent sessions")
+	participants := flag.Int("participants", 8, "voting participants per session")
+	rounds := flag.Int("rounds", 5, "voting rounds (tickets) per session")
+	think := flag.Duration("think", 2*time.Second, "longest a participant waits before voting")
+	flag.Parse()
+
+	base, err := url.Parse(*serverURL)
+	if err != nil {
+		log.Fatal("Invalid -url:", err)
+	}
+
+	rec := &recorder{sent: make(map[string]time.Time)}
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for n := 0; n < *sessions; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			if err := runSession(base, n, *participants, *rounds, *think, rec); err != nil {
+				rec.fail(err)
+			}
+		}(n)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	fmt.Printf("%d sessions x %d participants x %d rounds in %v\n", *sessions, *participants, *rounds, elapsed.Round(time.Millisecond))
+	report("broadcast", rec.broadcasts)
+	report("vote", rec.votes)
+	fmt.Printf("votes/s    %.1f\n", float64(len(rec.votes))/elapsed.Seconds())
+	fmt.Printf("errors     %d\n", rec.errors)
+
+	if rec.errors > 0 {
+		os.Exit(1)
+	}
+}
@@ -2,20 +2,33 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
+	_ "time/tzdata" // timezone preferences must validate without system zoneinfo
 
 	"poker-planning/internal/database"
+	"poker-planning/internal/deck"
 	"poker-planning/internal/handlers"
 	"poker-planning/internal/services"
+	"poker-planning/internal/stats"
 	"poker-planning/internal/utils"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -31,58 +44,443 @@ func main() {
 		dbPath = "poker.db"
 	}
 
+	restore := flag.String("restore", "", "restore the database from this backup file and exit")
+	exportPath := flag.String("export", "", "export all data to this JSON archive and exit")
+	importPath := flag.String("import", "", "import a JSON archive written by --export and exit")
+	onConflict := flag.String("on-conflict", "skip", "what --import does with existing users, organizations, projects and sessions: skip, new-id or fail")
+	demo := flag.Bool("demo", false, "seed a public demo session whose fake participants vote by themselves")
+	flag.Parse()
+
+	if *restore != "" {
+		if err := database.Restore(*restore, dbPath); err != nil {
+			log.Fatal("Failed to restore database:", err)
+		}
+		log.Printf("Restored %s from %s", dbPath, *restore)
+		return
+	}
+
 	db, err := database.NewDB(dbPath)
 	if err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
 	defer db.Close()
 
+	if *exportPath != "" {
+		if err := exportArchive(db, *exportPath); err != nil {
+			log.Fatal("Failed to export data:", err)
+		}
+		log.Printf("Exported %s to %s", dbPath, *exportPath)
+		return
+	}
+
+	if *importPath != "" {
+		policy, ok := services.ParseConflictPolicy(*onConflict)
+		if !ok {
+			log.Fatalf("Invalid --on-conflict: %q", *onConflict)
+		}
+		result, err := importArchive(db, *importPath, policy)
+		if err != nil {
+			log.Fatal("Failed to import data:", err)
+		}
+		log.Printf("Imported %d users, %d projects, %d sessions, %d tickets and %d votes from %s (%d existing records skipped)",
+			result.Users, result.Projects, result.Sessions, result.Tickets, result.Votes, *importPath, result.Skipped)
+		return
+	}
+
+	// Report errors and panics to Sentry when a DSN is configured
+	if dsn := os.Getenv("SENTRY_DSN"); dsn != "" {
+		if err := utils.InitErrorReporting(dsn, os.Getenv("SENTRY_ENVIRONMENT"), os.Getenv("SENTRY_RELEASE")); err != nil {
+			log.Fatal("Failed to set up error reporting:", err)
+		}
+		log.Println("Error reporting enabled")
+	}
+
 	userService := services.NewUserService(db.DB)
 	sessionService := services.NewSessionService(db.DB)
 	votingService := services.NewVotingService(db.DB)
 	ticketService := services.NewTicketService(db.DB)
-	wsService := services.NewWSService()
+	projectService := services.NewProjectService(db.DB)
+	organizationService := services.NewOrganizationService(db.DB)
+	teamService := services.NewTeamService(db.DB)
+	eventService := services.NewEventService(db.DB)
+	archiveService := services.NewArchiveService(db.DB)
+	// Web Push needs a VAPID key pair; without one nothing is pushed
+	notifyService := services.NewNotifyService(db.DB, services.VAPIDKeys{
+		PublicKey:  os.Getenv("VAPID_PUBLIC_KEY"),
+		PrivateKey: os.Getenv("VAPID_PRIVATE_KEY"),
+		Subject:    os.Getenv("VAPID_SUBJECT"),
+	})
+	wsService := services.NewWSService(userService)
+	// Bursts of the same broadcast, e.g. everyone voting at once, are sent
+	// as one per window so clients reload the session once
+	wsService.SetDebounce(time.Duration(getEnvInt("BROADCAST_DEBOUNCE_MS", 250)) * time.Millisecond)
 	go wsService.Run() // Start the WebSocket service
 
-	h := handlers.NewHandler(userService, sessionService, votingService, ticketService, wsService)
+	config := handlers.Config{
+		// Session size caps; 0 disables a limit
+		Limits: handlers.Limits{
+			MaxParticipants: getEnvInt("MAX_PARTICIPANTS", 50),
+			MaxTickets:      getEnvInt("MAX_TICKETS", 500),
+		},
+		AwayAfter:          time.Duration(getEnvInt("AWAY_AFTER_MINUTES", 5)) * time.Minute,
+		InstancePassphrase: os.Getenv("INSTANCE_PASSPHRASE"),
+		LinearAPIKey:       os.Getenv("LINEAR_API_KEY"),
+		TrelloAPIKey:       os.Getenv("TRELLO_API_KEY"),
+		TrelloToken:        os.Getenv("TRELLO_TOKEN"),
+		JiraWebhookSecret:  os.Getenv("JIRA_WEBHOOK_SECRET"),
+		SlackSigningSecret: os.Getenv("SLACK_SIGNING_SECRET"),
+		PublicURL:          os.Getenv("PUBLIC_URL"),
+		EmbedSecret:        os.Getenv("EMBED_SECRET"),
+		EmbedAncestors:     os.Getenv("EMBED_FRAME_ANCESTORS"),
+		ConnectTokenSecret: os.Getenv("WS_TOKEN_SECRET"),
+	}
+	if config.EmbedAncestors == "" {
+		config.EmbedAncestors = "*"
+	}
+	// Connection tokens live for a minute, so without a shared secret one
+	// per process does, as long as clients connect to the server they got
+	// their token from
+	if config.ConnectTokenSecret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatal("Failed to generate WS_TOKEN_SECRET:", err)
+		}
+		config.ConnectTokenSecret = hex.EncodeToString(secret)
+	}
+	// Special cards of sessions whose owners have not chosen their own
+	if value := os.Getenv("SPECIAL_CARDS"); value != "" {
+		cards, err := deck.ParseSpecialCards(value)
+		if err != nil {
+			log.Fatal("Invalid SPECIAL_CARDS:", err)
+		}
+		deck.SpecialCards = cards
+	}
+	// Percentiles computed for every ticket and offered as suggestion bases
+	if value := os.Getenv("VOTE_PERCENTILES"); value != "" {
+		percentiles, err := stats.ParsePercentiles(value)
+		if err != nil {
+			log.Fatal("Invalid VOTE_PERCENTILES:", err)
+		}
+		stats.Percentiles = percentiles
+	}
+	// Card styles of sessions whose owners have not chosen their own
+	if value := os.Getenv("CARD_STYLES"); value != "" {
+		styles, err := deck.ParseCardStyles(value)
+		if err != nil {
+			log.Fatal("Invalid CARD_STYLES:", err)
+		}
+		deck.CardStyles = styles
+	}
+	// Directory sign-in replaces the username screen
+	if ldapURL := os.Getenv("LDAP_URL"); ldapURL != "" {
+		groups, err := handlers.ParseLDAPGroupMappings(os.Getenv("LDAP_GROUP_MAPPINGS"))
+		if err != nil {
+			log.Fatal("Invalid LDAP_GROUP_MAPPINGS:", err)
+		}
+		config.LDAP, err = handlers.NewLDAPAuth(handlers.LDAPConfig{
+			URL:            ldapURL,
+			StartTLS:       os.Getenv("LDAP_START_TLS") == "true",
+			BindDN:         os.Getenv("LDAP_BIND_DN"),
+			BindPassword:   os.Getenv("LDAP_BIND_PASSWORD"),
+			BaseDN:         os.Getenv("LDAP_BASE_DN"),
+			UserFilter:     os.Getenv("LDAP_USER_FILTER"),
+			NameAttribute:  os.Getenv("LDAP_NAME_ATTRIBUTE"),
+			GroupAttribute: os.Getenv("LDAP_GROUP_ATTRIBUTE"),
+			Groups:         groups,
+		})
+		if err != nil {
+			log.Fatal("Invalid LDAP configuration:", err)
+		}
+	}
+
+	h := handlers.NewHandler(userService, sessionService, votingService, ticketService, projectService, organizationService, teamService, eventService, notifyService, archiveService, wsService, config)
+
+	// Background work stops as soon as shutdown starts
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	go h.BroadcastPresence(backgroundCtx, 5*time.Second)
+
+	if *demo {
+		sessionID, err := h.SeedDemo(backgroundCtx)
+		if err != nil {
+			log.Fatal("Failed to seed demo session:", err)
+		}
+		log.Printf("Demo session running at http://localhost:%s/session/%s", port, sessionID)
+		go h.RunDemo(backgroundCtx)
+	}
+
+	maintenance := database.MaintenanceConfig{
+		Interval:   time.Duration(getEnvInt("MAINTENANCE_INTERVAL_MINUTES", 60)) * time.Minute,
+		QuietStart: getEnvHour("MAINTENANCE_QUIET_START_HOUR", 3),
+		QuietEnd:   getEnvHour("MAINTENANCE_QUIET_END_HOUR", 5),
+	}
+	go db.RunMaintenance(backgroundCtx, maintenance)
+
+	backups := database.BackupConfig{
+		Interval: time.Duration(getEnvInt("BACKUP_INTERVAL_MINUTES", 60)) * time.Minute,
+		Dir:      os.Getenv("BACKUP_DIR"),
+		Keep:     getEnvInt("BACKUP_KEEP", 24),
+	}
+	if bucket := os.Getenv("BACKUP_S3_BUCKET"); bucket != "" {
+		backups.S3 = &database.S3Config{
+			Bucket:          bucket,
+			Prefix:          os.Getenv("BACKUP_S3_PREFIX"),
+			Region:          os.Getenv("AWS_REGION"),
+			Endpoint:        os.Getenv("BACKUP_S3_ENDPOINT"),
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if backups.S3.Region == "" {
+			backups.S3.Region = "us-east-1"
+		}
+	}
+	go db.RunBackups(backgroundCtx, backups)
+
+	// Access logs go to stdout unless ACCESS_LOG_FILE is set, in which case
+	// the file is rotated at ACCESS_LOG_MAX_SIZE_MB
+	accessLog := io.Writer(os.Stdout)
+	if path := os.Getenv("ACCESS_LOG_FILE"); path != "" {
+		file, err := utils.OpenRotatingFile(path, int64(getEnvInt("ACCESS_LOG_MAX_SIZE_MB", 100))<<20, getEnvInt("ACCESS_LOG_BACKUPS", 5))
+		if err != nil {
+			log.Fatal("Failed to open access log:", err)
+		}
+		defer file.Close()
+		accessLog = file
+	}
 
 	r := chi.NewRouter()
 
-	r.Use(middleware.Logger)
+	r.Use(middleware.RequestID)
+	r.Use(handlers.AccessLogger(slog.New(slog.NewJSONHandler(accessLog, nil))))
+	// Private instances: only these networks may connect at all, to the
+	// gRPC API as well
+	var allowedNetworks []*net.IPNet
+	if value := os.Getenv("ALLOWED_NETWORKS"); value != "" {
+		var err error
+		allowedNetworks, err = handlers.ParseAllowedNetworks(value)
+		if err != nil {
+			log.Fatal("Invalid ALLOWED_NETWORKS:", err)
+		}
+		r.Use(handlers.RequireAllowedNetwork(allowedNetworks))
+	}
+	// Instance-wide basic auth, from an htpasswd file or a single user
+	if path := os.Getenv("BASIC_AUTH_HTPASSWD"); path != "" {
+		auth, err := handlers.LoadHtpasswd(path)
+		if err != nil {
+			log.Fatal("Failed to load BASIC_AUTH_HTPASSWD:", err)
+		}
+		r.Use(auth.Require)
+	} else if username := os.Getenv("BASIC_AUTH_USER"); username != "" {
+		r.Use(handlers.NewBasicAuth(username, os.Getenv("BASIC_AUTH_PASSWORD")).Require)
+	}
 	r.Use(middleware.Recoverer)
 	r.Use(utils.RecoverFromPanic)
+	r.Use(handlers.SecurityHeaders(os.Getenv("CONTENT_SECURITY_POLICY")))
 	r.Use(middleware.Compress(5))
-	r.Use(middleware.Timeout(30 * time.Second)) // Add timeout middleware
-	r.Use(handlers.SessionMiddleware(userService))
+	// Streaming routes are exempt; they set their own timeouts below
+	r.Use(handlers.RequestTimeout(time.Duration(getEnvInt("REQUEST_TIMEOUT_SECONDS", 30)) * time.Second))
+	r.Use(h.RequireInstancePassphrase)
+	r.Use(handlers.LimitRequestBody)
+	r.Use(handlers.MethodOverride) // forms without JavaScript can only POST
+	// Directory users are worth impersonating, so they get opaque login
+	// tokens instead of their user ID, which every session shows
+	r.Use(handlers.SessionMiddleware(userService, config.LDAP != nil))
 
 	r.Get("/", h.Home)
+	r.Post("/unlock", h.Unlock)
 	r.Post("/set-username", h.SetUsername)
+	r.Get("/lobby", h.Lobby)
+	r.Put("/me/preferences", h.UpdatePreferences)
+	r.Get("/me/recent-emojis", h.GetRecentEmojis)
+	r.Post("/me/push-subscriptions", h.SubscribePush)
+	r.Delete("/me/push-subscriptions", h.UnsubscribePush)
+	r.Get("/sw.js", h.ServiceWorker)
 	
 	r.Route("/session", func(r chi.Router) {
 		r.Post("/create", h.CreateSession)
+		r.Post("/import", h.ImportSessionBundle)
 		r.Get("/{sessionID}", h.GetSession)
 		r.Get("/{sessionID}/partial", h.GetSessionPartial)
+		r.Get("/{sessionID}/m", h.MobileSession)
+		r.Get("/{sessionID}/m/state", h.GetMobileState)
 		r.Post("/{sessionID}/join", h.JoinSession)
+		r.Get("/{sessionID}/claim/{token}", h.ClaimSession)
+		r.Post("/{sessionID}/bots", h.AddBot)
+		r.Delete("/{sessionID}/bots/{botID}", h.RemoveBot)
+		r.Post("/{sessionID}/participants/{userID}/weight", h.SetParticipantWeight)
+		r.Post("/{sessionID}/participants/{userID}/stakeholder", h.SetParticipantStakeholder)
 		r.Post("/{sessionID}/tickets", h.CreateTicket)
+		r.Get("/{sessionID}/tickets", h.GetTicketsPage)
+		r.Post("/{sessionID}/import/{source}", h.ImportTickets)
+		r.Delete("/{sessionID}/tickets", h.DeleteTickets)
 		r.Delete("/{sessionID}/tickets/{ticketID}", h.DeleteTicket)
+		r.Post("/{sessionID}/tickets/{ticketID}/reopen", h.ReopenTicket)
+		r.Post("/{sessionID}/tickets/{ticketID}/duplicate", h.DuplicateTicket)
+		r.Post("/{sessionID}/tickets/{ticketID}/split", h.SplitTicket)
+		r.Post("/{sessionID}/tickets/{ticketID}/calibration", h.SetTicketCalibration)
+		r.Post("/{sessionID}/tickets/{ticketID}/prevoting", h.SetTicketPrevoting)
+		r.Post("/{sessionID}/tickets/{ticketID}/prevote", h.SubmitPrevote)
+		r.Post("/{sessionID}/display-unit", h.SetDisplayUnit)
+		r.Get("/{sessionID}/tickets/{ticketID}/histogram", h.GetVoteHistogram)
 		r.Post("/{sessionID}/start-voting", h.StartVoting)
 		r.Post("/{sessionID}/end-voting", h.EndVoting)
+		r.Post("/{sessionID}/nudge", h.NudgeVoters)
+		r.Get("/{sessionID}/agenda", h.GetAgenda)
+		r.Put("/{sessionID}/agenda", h.SetAgenda)
+		r.Delete("/{sessionID}/agenda", h.ClearAgenda)
+		r.Post("/{sessionID}/agenda/advance", h.AdvanceAgenda)
+		r.Post("/{sessionID}/references/{referenceID}", h.PinReference)
+		r.Delete("/{sessionID}/references/{referenceID}", h.UnpinReference)
 		r.Post("/{sessionID}/next-ticket", h.NextTicket)
 		r.Post("/{sessionID}/select-ticket/{ticketID}", h.SelectTicket)
+		r.Post("/{sessionID}/driver", h.HandOverDriver)
+		r.Delete("/{sessionID}/driver", h.ReclaimDriver)
+		r.Post("/{sessionID}/discussing/{ticketID}", h.DiscussTicket)
+		r.Delete("/{sessionID}/discussing", h.ClearDiscussingTicket)
+		r.Get("/{sessionID}/parking-lot", h.GetParkingLot)
+		r.Post("/{sessionID}/parking-lot", h.AddParkingLotItem)
+		r.Delete("/{sessionID}/parking-lot/{itemID}", h.DeleteParkingLotItem)
+		r.Get("/{sessionID}/parking-lot/export-csv", h.ExportParkingLotCSV)
 		r.Post("/{sessionID}/vote", h.SubmitVote)
+		r.Post("/{sessionID}/vote/repeat", h.RepeatVote)
+		r.Get("/{sessionID}/last-vote", h.GetLastVote)
+		r.Put("/{sessionID}/last-vote", h.SetLastVote)
+		r.Post("/{sessionID}/accept-estimate", h.AcceptEstimate)
+		r.Post("/{sessionID}/rounding-strategy", h.SetRoundingStrategy)
+		r.Put("/{sessionID}/special-cards", h.SetSpecialCards)
+		r.Put("/{sessionID}/card-styles", h.SetCardStyles)
 		r.Get("/{sessionID}/ws", h.WebSocketHandler)
+		r.Post("/{sessionID}/ws-token", h.IssueConnectToken)
+		r.With(middleware.Timeout(handlers.PollRequestTimeout)).Get("/{sessionID}/poll", h.PollSession)
+		r.Get("/{sessionID}/stats/live", h.GetLiveStats)
 		r.Post("/{sessionID}/leave", h.LeaveSession)
+		r.Patch("/{sessionID}", h.UpdateSessionSettings)
 		r.Delete("/{sessionID}", h.DeleteSession)
 		r.Post("/{sessionID}/review", h.ReviewSession)
 		r.Get("/{sessionID}/summary", h.GetSessionSummary)
 		r.Get("/{sessionID}/export-csv", h.ExportSessionCSV)
+		r.Get("/{sessionID}/export/bundle", h.ExportSessionBundle)
+		r.Post("/{sessionID}/feedback", h.SubmitFeedback)
+		r.Get("/{sessionID}/action-items", h.GetActionItems)
+		r.Post("/{sessionID}/action-items", h.AddActionItem)
+		r.Delete("/{sessionID}/action-items/{itemID}", h.DeleteActionItem)
+		r.Get("/{sessionID}/action-items/export-csv", h.ExportActionItemsCSV)
+		r.Get("/{sessionID}/notes", h.GetFacilitatorNotes)
+		r.Get("/{sessionID}/snapshots", h.GetSnapshots)
+		r.Post("/{sessionID}/snapshots", h.CreateSnapshot)
+		r.Post("/{sessionID}/snapshots/{snapshotID}/restore", h.RestoreSnapshot)
+		r.Delete("/{sessionID}/snapshots/{snapshotID}", h.DeleteSnapshot)
+		r.Put("/{sessionID}/notes", h.SetFacilitatorNote)
+		r.Post("/{sessionID}/project", h.SetSessionProject)
+		r.Post("/{sessionID}/carry-over", h.CarryOverSession)
+	})
+
+	r.Route("/org", func(r chi.Router) {
+		r.Post("/create", h.CreateOrganization)
+		r.Get("/join/{code}", h.JoinOrganization)
+		r.Get("/{orgID}", h.GetOrganization)
+		r.Post("/{orgID}/invite", h.RegenerateOrganizationInvite)
+		r.Post("/{orgID}/members/{userID}/role", h.SetOrganizationMemberRole)
+		r.Delete("/{orgID}/members/{userID}", h.RemoveOrganizationMember)
+		r.Post("/{orgID}/teams", h.CreateTeam)
+		r.Get("/{orgID}/teams/{teamID}", h.GetTeam)
+		r.Post("/{orgID}/teams/{teamID}", h.UpdateTeam)
+		r.Delete("/{orgID}/teams/{teamID}", h.DeleteTeam)
+		r.Post("/{orgID}/teams/{teamID}/members", h.AddTeamMember)
+		r.Delete("/{orgID}/teams/{teamID}/members/{userID}", h.RemoveTeamMember)
+		r.Post("/{orgID}/teams/{teamID}/sessions", h.CreateTeamSession)
+	})
+
+	r.Route("/team", func(r chi.Router) {
+		r.Get("/{teamID}/references", h.GetTeamReferences)
+		r.Post("/{teamID}/references", h.AddTeamReference)
+		r.Delete("/{teamID}/references/{referenceID}", h.DeleteTeamReference)
+		r.Get("/{teamID}/templates", h.GetTeamTemplates)
+		r.Post("/{teamID}/templates", h.AddTeamTemplate)
+		r.Delete("/{teamID}/templates/{templateID}", h.DeleteTeamTemplate)
+		r.Get("/{teamID}/accuracy", h.GetTeamAccuracy)
+		r.Post("/{teamID}/actuals", h.ImportTeamActuals)
+	})
+
+	r.Route("/project", func(r chi.Router) {
+		r.Post("/create", h.CreateProject)
+		r.Get("/{projectID}", h.GetProject)
+		r.Post("/{projectID}/sessions", h.CreateProjectSession)
 	})
 
 	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("static/"))))
 
+	// Tracker and chat webhooks, only with a secret to verify them
+	if config.JiraWebhookSecret != "" {
+		r.Post("/webhooks/jira", h.JiraWebhook)
+	}
+	if config.SlackSigningSecret != "" {
+		r.Post("/webhooks/slack", h.SlackCommand)
+	}
+
+	// Read-only session widgets for other sites to frame, only with a secret
+	// to sign their links
+	if config.EmbedSecret != "" {
+		r.With(handlers.AllowEmbedding(config.EmbedAncestors)).Get("/embed/session/{sessionID}", h.EmbedSession)
+	}
+
+	// API for dashboards and no-code tools, only with a token to guard it
+	apiToken := os.Getenv("API_TOKEN")
+	if apiToken != "" {
+		// Pages on other sites may call it from the browser only when their
+		// origins are allowed
+		var cors *handlers.CORSConfig
+		if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+			headers := os.Getenv("CORS_ALLOWED_HEADERS")
+			if headers == "" {
+				headers = "Authorization, Content-Type"
+			}
+			config, err := handlers.ParseCORSConfig(origins, headers, os.Getenv("CORS_ALLOW_CREDENTIALS") == "true")
+			if err != nil {
+				log.Fatal("Invalid CORS configuration:", err)
+			}
+			cors = &config
+		}
+
+		r.Route("/api/v1", func(r chi.Router) {
+			if cors != nil {
+				r.Use(handlers.CORS(*cors))
+			}
+			r.Use(handlers.RequireAPIToken(apiToken))
+			r.Get("/tickets", h.GetAPITickets)
+			r.Post("/actuals", h.ImportAPIActuals)
+			r.Get("/session/{sessionID}/events.ndjson", h.ExportSessionEvents)
+			r.Get("/session/{sessionID}/summary", h.GetAPISessionSummary)
+			r.Get("/events", h.GetRecentEvents)
+			r.Get("/events/types", h.ListEventTypes)
+			r.Get("/hooks", h.ListHooks)
+			r.Post("/hooks", h.CreateHook)
+			r.Delete("/hooks/{hookID}", h.DeleteHook)
+		})
+	}
+
+	// pprof and runtime stats, only with a token to guard them. Profiles are
+	// exempt from the request timeout, so ?seconds= can be longer than it
+	if token := os.Getenv("DEBUG_TOKEN"); token != "" {
+		r.Route("/debug", func(r chi.Router) {
+			r.Use(handlers.RequireDebugToken(token))
+			r.Get("/stats", handlers.GetRuntimeStats(db.DB, wsService))
+			r.Mount("/", middleware.Profiler())
+		})
+		log.Println("Debug endpoints enabled under /debug")
+	}
+
+	// Requests derive from requestCtx so that their queries can be cancelled
+	// if they are still running when shutdown gives up waiting for them
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	srv := &http.Server{
-		Addr:    ":" + port,
-		Handler: r,
+		Addr:        ":" + port,
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return requestCtx },
 	}
 
 	go func() {
@@ -92,18 +490,108 @@ func main() {
 		}
 	}()
 
+	// The gRPC API shares the API token and listens on its own port
+	var grpcServer *grpc.Server
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" && apiToken != "" {
+		listener, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			log.Fatal("Failed to listen for gRPC:", err)
+		}
+		grpcServer = h.NewGRPCServer(apiToken, allowedNetworks)
+		go func() {
+			log.Printf("gRPC server starting on :%s", grpcPort)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatal("gRPC server failed:", err)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopBackground()
+
+	// Event streams only end when their clients go away, so stop waiting for
+	// them along with the HTTP server
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			grpcServer.Stop()
+		}
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+		log.Println("Server forced to shutdown:", err)
+		cancelRequests()
 	}
 
+	utils.FlushErrorReports(5 * time.Second)
 	log.Println("Server exited")
-}
\ No newline at end of file
+}
+
+func getEnvInt(name string, defaultValue int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Fatalf("Invalid %s: %q", name, value)
+	}
+	return n
+}
+
+func getEnvHour(name string, defaultValue int) int {
+	hour := getEnvInt(name, defaultValue)
+	if hour > 23 {
+		log.Fatalf("Invalid %s: %d is not an hour of the day", name, hour)
+	}
+	return hour
+}
+
+func exportArchive(db *database.DB, path string) error {
+	archive, err := services.NewArchiveService(db.DB).Export(context.Background())
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(archive); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func importArchive(db *database.DB, path string, policy services.ConflictPolicy) (*services.ImportResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var archive services.Archive
+	if err := json.NewDecoder(file).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	return services.NewArchiveService(db.DB).ImportArchive(context.Background(), &archive, policy)
+}
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const backupPrefix = "poker-"
+
+// BackupConfig controls the periodic backups run by RunBackups. Backups are
+// written to Dir, uploaded to S3, or both.
+type BackupConfig struct {
+	Interval time.Duration
+	Dir      string    // local backup directory; empty keeps no local copies
+	Keep     int       // local backups to keep; 0 keeps them all
+	S3       *S3Config // nil disables uploads
+}
+
+// Enabled reports whether backups have somewhere to go.
+func (c BackupConfig) Enabled() bool {
+	return c.Interval > 0 && (c.Dir != "" || c.S3 != nil)
+}
+
+// RunBackups takes a backup every Interval until ctx is done. It blocks, so
+// run it in its own goroutine.
+func (db *DB) RunBackups(ctx context.Context, config BackupConfig) {
+	if !config.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		path, err := db.backupOnce(ctx, config)
+		if err != nil {
+			log.Printf("Database backup failed: %v", err)
+			continue
+		}
+		log.Printf("Database backed up to %s", path)
+	}
+}
+
+func (db *DB) backupOnce(ctx context.Context, config BackupConfig) (string, error) {
+	dir := config.Dir
+	if dir == "" {
+		dir = os.TempDir()
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	name := backupPrefix + time.Now().UTC().Format("20060102T150405Z") + ".db"
+	path := filepath.Join(dir, name)
+	if err := db.BackupTo(ctx, path); err != nil {
+		return "", err
+	}
+
+	if config.S3 != nil {
+		err := config.S3.Upload(ctx, name, path)
+		if config.Dir == "" {
+			os.Remove(path)
+		}
+		if err != nil {
+			return "", err
+		}
+		path = config.S3.URL(name)
+	}
+
+	if config.Dir != "" && config.Keep > 0 {
+		if err := pruneBackups(config.Dir, config.Keep); err != nil {
+			log.Printf("Failed to prune old backups: %v", err)
+		}
+	}
+
+	return path, nil
+}
+
+// BackupTo writes a consistent copy of the live database to path using
+// SQLite's online backup API, so the server keeps running while it copies.
+// The copy is written next to path and renamed into place when complete.
+func (db *DB) BackupTo(ctx context.Context, path string) error {
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath)
+
+	if err := copyDatabase(ctx, db.DB, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to move backup into place: %w", err)
+	}
+
+	return nil
+}
+
+// Restore replaces the database at dbPath with the contents of the backup at
+// backupPath. The server must not be running against dbPath.
+func Restore(backupPath, dbPath string) error {
+	if _, err := os.Stat(backupPath); err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+
+	src, err := sql.Open("sqlite3", "file:"+backupPath+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer src.Close()
+
+	return copyDatabase(context.Background(), src, dbPath)
+}
+
+// copyDatabase copies every page of src into the database at destPath,
+// creating it if needed.
+func copyDatabase(ctx context.Context, src *sql.DB, destPath string) error {
+	dest, err := sql.Open("sqlite3", destPath)
+	if err != nil {
+		return fmt.Errorf("failed to open backup destination: %w", err)
+	}
+	defer dest.Close()
+
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open backup destination: %w", err)
+	}
+	defer destConn.Close()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open backup source: %w", err)
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destDriver interface{}) error {
+		return srcConn.Raw(func(srcDriver interface{}) error {
+			backup, err := destDriver.(*sqlite3.SQLiteConn).Backup("main", srcDriver.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return fmt.Errorf("failed to start backup: %w", err)
+			}
+
+			// -1 copies all pages in one step
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return fmt.Errorf("failed to copy database: %w", err)
+			}
+
+			if err := backup.Finish(); err != nil {
+				return fmt.Errorf("failed to finish backup: %w", err)
+			}
+			return nil
+		})
+	})
+}
+
+// pruneBackups deletes all but the newest keep backups in dir. Backup names
+// embed their UTC timestamp, so they sort by age.
+func pruneBackups(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, ".db") {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups)
+
+	for len(backups) > keep {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
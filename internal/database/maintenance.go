@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// MaintenanceConfig controls the periodic maintenance run by RunMaintenance.
+type MaintenanceConfig struct {
+	Interval time.Duration // between WAL checkpoints; 0 disables maintenance
+	// Local hours [QuietStart, QuietEnd) during which VACUUM and the
+	// integrity check may run, at most once a day. The window may wrap past
+	// midnight.
+	QuietStart int
+	QuietEnd   int
+}
+
+// inQuietHours reports whether hour falls inside the quiet window.
+func (c MaintenanceConfig) inQuietHours(hour int) bool {
+	if c.QuietStart <= c.QuietEnd {
+		return hour >= c.QuietStart && hour < c.QuietEnd
+	}
+	return hour >= c.QuietStart || hour < c.QuietEnd
+}
+
+// RunMaintenance checkpoints the WAL and refreshes query planner statistics
+// every Interval, and once a day during quiet hours also vacuums the database
+// and logs an integrity check. It blocks until ctx is done, so run it in its
+// own goroutine.
+func (db *DB) RunMaintenance(ctx context.Context, config MaintenanceConfig) {
+	if config.Interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(config.Interval)
+	defer ticker.Stop()
+
+	var lastVacuum time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := db.Checkpoint(ctx); err != nil {
+			log.Printf("Database maintenance: %v", err)
+		}
+		if err := db.Optimize(ctx); err != nil {
+			log.Printf("Database maintenance: %v", err)
+		}
+
+		now := time.Now()
+		if !config.inQuietHours(now.Hour()) || now.Sub(lastVacuum) < 20*time.Hour {
+			continue
+		}
+		lastVacuum = now
+
+		if err := db.Vacuum(ctx); err != nil {
+			log.Printf("Database maintenance: %v", err)
+		}
+
+		problems, err := db.IntegrityCheck(ctx)
+		if err != nil {
+			log.Printf("Database maintenance: %v", err)
+		} else if len(problems) > 0 {
+			log.Printf("Database integrity check found %d problem(s): %s", len(problems), strings.Join(problems, "; "))
+		} else {
+			log.Println("Database integrity check: ok")
+		}
+	}
+}
+
+// Checkpoint copies the WAL back into the database file and truncates it, so
+// the WAL does not keep growing on long-lived instances.
+func (db *DB) Checkpoint(ctx context.Context) error {
+	var busy, logFrames, checkpointed int
+	err := db.QueryRowContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`).Scan(&busy, &logFrames, &checkpointed)
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint WAL: %w", err)
+	}
+	if busy != 0 {
+		return fmt.Errorf("failed to checkpoint WAL: database busy")
+	}
+	return nil
+}
+
+// Optimize lets SQLite refresh the statistics its query planner relies on.
+func (db *DB) Optimize(ctx context.Context) error {
+	if _, err := db.ExecContext(ctx, `PRAGMA optimize`); err != nil {
+		return fmt.Errorf("failed to optimize database: %w", err)
+	}
+	return nil
+}
+
+// Vacuum rebuilds the database file to reclaim space left by deleted rows.
+// It blocks writers while it runs.
+func (db *DB) Vacuum(ctx context.Context) error {
+	if _, err := db.ExecContext(ctx, `VACUUM`); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	return nil
+}
+
+// IntegrityCheck runs SQLite's integrity check and returns the problems it
+// reports, or none if the database is intact.
+func (db *DB) IntegrityCheck(ctx context.Context) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `PRAGMA integrity_check`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check integrity: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return nil, fmt.Errorf("failed to scan integrity check: %w", err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+
+	return problems, rows.Err()
+}
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE vote_rounds (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ticket_id INTEGER NOT NULL REFERENCES tickets(id) ON DELETE CASCADE,
+    round INTEGER NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id),
+    vote_value TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_vote_rounds_ticket ON vote_rounds(ticket_id, round);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_vote_rounds_ticket;
+DROP TABLE IF EXISTS vote_rounds;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tickets ADD COLUMN final_estimate_text TEXT;
+UPDATE tickets SET final_estimate_text = CAST(final_estimate AS TEXT) WHERE final_estimate IS NOT NULL;
+ALTER TABLE tickets DROP COLUMN final_estimate;
+ALTER TABLE tickets RENAME COLUMN final_estimate_text TO final_estimate;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tickets ADD COLUMN final_estimate_int INTEGER;
+UPDATE tickets SET final_estimate_int = CAST(final_estimate AS INTEGER)
+    WHERE final_estimate GLOB '[0-9]*' AND final_estimate NOT GLOB '*[^0-9]*';
+ALTER TABLE tickets DROP COLUMN final_estimate;
+ALTER TABLE tickets RENAME COLUMN final_estimate_int TO final_estimate;
+-- +goose StatementEnd
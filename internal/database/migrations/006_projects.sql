@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE projects (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    owner_id TEXT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE sessions ADD COLUMN project_id TEXT REFERENCES projects(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_projects_owner ON projects(owner_id);
+CREATE INDEX idx_sessions_project ON sessions(project_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sessions_project;
+DROP INDEX IF EXISTS idx_projects_owner;
+ALTER TABLE sessions DROP COLUMN project_id;
+DROP TABLE IF EXISTS projects;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN max_participants INTEGER;
+ALTER TABLE sessions ADD COLUMN max_tickets INTEGER;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN max_tickets;
+ALTER TABLE sessions DROP COLUMN max_participants;
+-- +goose StatementEnd
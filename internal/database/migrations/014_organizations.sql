@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE organizations (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    invite_code TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE organization_members (
+    organization_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT 'member',
+    joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+ALTER TABLE sessions ADD COLUMN organization_id TEXT REFERENCES organizations(id) ON DELETE CASCADE;
+ALTER TABLE projects ADD COLUMN organization_id TEXT REFERENCES organizations(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_organization_members_user ON organization_members(user_id);
+CREATE INDEX idx_sessions_organization ON sessions(organization_id);
+CREATE INDEX idx_projects_organization ON projects(organization_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_projects_organization;
+DROP INDEX IF EXISTS idx_sessions_organization;
+DROP INDEX IF EXISTS idx_organization_members_user;
+ALTER TABLE projects DROP COLUMN organization_id;
+ALTER TABLE sessions DROP COLUMN organization_id;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
+-- +goose StatementEnd
@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE teams (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    estimation_unit TEXT NOT NULL DEFAULT 'points',
+    voting_time_limit INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE team_members (
+    team_id TEXT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, user_id)
+);
+
+ALTER TABLE sessions ADD COLUMN team_id TEXT REFERENCES teams(id) ON DELETE SET NULL;
+ALTER TABLE sessions ADD COLUMN voting_time_limit INTEGER;
+ALTER TABLE sessions ADD COLUMN voting_started_at TIMESTAMP;
+
+CREATE INDEX idx_teams_organization ON teams(organization_id);
+CREATE INDEX idx_team_members_user ON team_members(user_id);
+CREATE INDEX idx_sessions_team ON sessions(team_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sessions_team;
+DROP INDEX IF EXISTS idx_team_members_user;
+DROP INDEX IF EXISTS idx_teams_organization;
+ALTER TABLE sessions DROP COLUMN voting_started_at;
+ALTER TABLE sessions DROP COLUMN voting_time_limit;
+ALTER TABLE sessions DROP COLUMN team_id;
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
+-- +goose StatementEnd
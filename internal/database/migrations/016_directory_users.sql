@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN ldap_dn TEXT;
+
+CREATE UNIQUE INDEX idx_users_ldap_dn ON users(ldap_dn);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_ldap_dn;
+ALTER TABLE users DROP COLUMN ldap_dn;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN vote_change_window INTEGER;
+ALTER TABLE tickets ADD COLUMN revealed_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tickets DROP COLUMN revealed_at;
+ALTER TABLE sessions DROP COLUMN vote_change_window;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tickets ADD COLUMN external_key TEXT;
+CREATE INDEX idx_tickets_external_key ON tickets(external_key);
+CREATE INDEX idx_tickets_title_nocase ON tickets(title COLLATE NOCASE);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_tickets_title_nocase;
+DROP INDEX IF EXISTS idx_tickets_external_key;
+ALTER TABLE tickets DROP COLUMN external_key;
+-- +goose StatementEnd
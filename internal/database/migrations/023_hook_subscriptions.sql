@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE hook_subscriptions (
+    id TEXT PRIMARY KEY,
+    event TEXT NOT NULL,
+    target_url TEXT NOT NULL,
+    session_id TEXT REFERENCES sessions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_hook_subscriptions_event ON hook_subscriptions(event);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_hook_subscriptions_event;
+DROP TABLE IF EXISTS hook_subscriptions;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE agenda_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    title TEXT NOT NULL,
+    planned_minutes INTEGER NOT NULL,
+    started_at TIMESTAMP,
+    ended_at TIMESTAMP
+);
+
+CREATE INDEX idx_agenda_items_session ON agenda_items(session_id, position);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_agenda_items_session;
+DROP TABLE IF EXISTS agenda_items;
+-- +goose StatementEnd
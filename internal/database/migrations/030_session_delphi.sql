@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions ADD COLUMN delphi_max_rounds INTEGER;
+ALTER TABLE sessions ADD COLUMN delphi_agreement INTEGER NOT NULL DEFAULT 75;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN delphi_agreement;
+ALTER TABLE sessions DROP COLUMN delphi_max_rounds;
+-- +goose StatementEnd
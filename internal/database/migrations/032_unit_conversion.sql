@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE teams ADD COLUMN days_per_point REAL;
+ALTER TABLE participants ADD COLUMN display_unit TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE participants DROP COLUMN display_unit;
+ALTER TABLE teams DROP COLUMN days_per_point;
+-- +goose StatementEnd
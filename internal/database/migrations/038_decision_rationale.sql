@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tickets ADD COLUMN decision_rationale TEXT NOT NULL DEFAULT '';
+ALTER TABLE tickets ADD COLUMN decision_assumptions TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tickets DROP COLUMN decision_assumptions;
+ALTER TABLE tickets DROP COLUMN decision_rationale;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- NULL uses the deployment's special cards; otherwise a JSON array of
+-- {value, label, counts}
+ALTER TABLE sessions ADD COLUMN special_cards TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN special_cards;
+-- +goose StatementEnd
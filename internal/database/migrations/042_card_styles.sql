@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- NULL uses the deployment's card styles; otherwise a JSON object of
+-- card -> {color, icon}
+ALTER TABLE sessions ADD COLUMN card_styles TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN card_styles;
+-- +goose StatementEnd
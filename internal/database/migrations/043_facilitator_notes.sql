@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE facilitator_notes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    ticket_id INTEGER REFERENCES tickets(id) ON DELETE CASCADE, -- NULL for the note on the session itself
+    text TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_facilitator_notes_target ON facilitator_notes(session_id, COALESCE(ticket_id, 0));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_facilitator_notes_target;
+DROP TABLE IF EXISTS facilitator_notes;
+-- +goose StatementEnd
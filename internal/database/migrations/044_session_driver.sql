@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The participant the owner handed voting control to; NULL while the owner
+-- drives the session
+ALTER TABLE sessions ADD COLUMN driver_id TEXT REFERENCES users(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN driver_id;
+-- +goose StatementEnd
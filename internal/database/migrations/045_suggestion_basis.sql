@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The statistic of the votes a suggested estimate starts from: median, mean
+-- or a percentile like p90
+ALTER TABLE sessions ADD COLUMN suggestion_basis TEXT NOT NULL DEFAULT 'median';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN suggestion_basis;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Restore points of a session: its tickets, votes and voting state as JSON
+CREATE TABLE session_snapshots (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    automatic BOOLEAN NOT NULL DEFAULT FALSE,
+    ticket_count INTEGER NOT NULL,
+    vote_count INTEGER NOT NULL,
+    state TEXT NOT NULL,
+    created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_session_snapshots_session ON session_snapshots(session_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_session_snapshots_session;
+DROP TABLE IF EXISTS session_snapshots;
+-- +goose StatementEnd
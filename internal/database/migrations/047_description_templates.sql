@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Each team's ticket description templates; sections is a JSON array of
+-- {title, required}
+CREATE TABLE team_description_templates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_id TEXT NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    sections TEXT NOT NULL,
+    created_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_team_description_templates_team ON team_description_templates(team_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_team_description_templates_team;
+DROP TABLE IF EXISTS team_description_templates;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Comma-separated ticket fields, like description or external_url, a ticket
+-- must have filled in before it can be voted on
+ALTER TABLE sessions ADD COLUMN required_fields TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN required_fields;
+-- +goose StatementEnd
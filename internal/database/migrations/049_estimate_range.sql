@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Suggested range around the final estimate, the middle half of the votes,
+-- as the cards at either end
+ALTER TABLE tickets ADD COLUMN estimate_low TEXT;
+ALTER TABLE tickets ADD COLUMN estimate_high TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tickets DROP COLUMN estimate_high;
+ALTER TABLE tickets DROP COLUMN estimate_low;
+-- +goose StatementEnd
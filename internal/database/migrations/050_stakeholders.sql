@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Interested stakeholders, as opposed to committed team members: their votes
+-- are advisory and left out of auto-reveal and the statistics
+ALTER TABLE participants ADD COLUMN is_stakeholder BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE participants DROP COLUMN is_stakeholder;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Comma-separated user IDs of the breakout group a ticket is being voted on
+-- by; empty when the whole session votes
+ALTER TABLE tickets ADD COLUMN breakout_voters TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tickets DROP COLUMN breakout_voters;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Sign-ins through the directory: the browser holds a random token, and only
+-- its SHA-256 hash is stored
+CREATE TABLE login_sessions (
+    token_hash TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL
+);
+CREATE INDEX idx_login_sessions_expires_at ON login_sessions(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE login_sessions;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Where an imported ticket came from, e.g. linear or jira, so tracker
+-- webhooks only touch their own tickets
+ALTER TABLE tickets ADD COLUMN external_source TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE tickets DROP COLUMN external_source;
+-- +goose StatementEnd
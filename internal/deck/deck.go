@@ -0,0 +1,227 @@
+package deck
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RoundingStrategy decides which card a value between two cards snaps to.
+type RoundingStrategy string
+
+const (
+	RoundNearest RoundingStrategy = "nearest"
+	RoundUp      RoundingStrategy = "up"
+	RoundDown    RoundingStrategy = "down"
+)
+
+const DefaultRoundingStrategy = RoundNearest
+
+var RoundingStrategies = []RoundingStrategy{RoundNearest, RoundUp, RoundDown}
+
+func ParseRoundingStrategy(value string) (RoundingStrategy, bool) {
+	for _, strategy := range RoundingStrategies {
+		if string(strategy) == value {
+			return strategy, true
+		}
+	}
+	return "", false
+}
+
+// NumericValue returns the numeric value of a card, or false for special
+// cards like ☕ and ?.
+func NumericValue(card string) (float64, bool) {
+	value, err := strconv.ParseFloat(card, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// FormatValue renders a numeric card value the way it appears on the card.
+func FormatValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// NumericValues returns the numeric cards of a deck in ascending order.
+func NumericValues(cards []string) []float64 {
+	var values []float64
+	for _, card := range cards {
+		if value, ok := NumericValue(card); ok {
+			values = append(values, value)
+		}
+	}
+	sort.Float64s(values)
+	return values
+}
+
+// Deck is the ordered list of cards a session votes with: numeric cards in
+// ascending order followed by the special cards.
+type Deck []string
+
+// SpecialCard is a card without a numeric value, such as ☕ for a break.
+// Votes on a card that does not count are left out when judging whether the
+// team agrees, like abstentions. A split card says the ticket is too big to
+// estimate and should be broken up.
+type SpecialCard struct {
+	Value  string `json:"value"`
+	Label  string `json:"label"`
+	Counts bool   `json:"counts"`          // a vote on it counts toward consensus
+	Split  bool   `json:"split,omitempty"` // winning the vote flags the ticket to be split
+}
+
+// Abstain is the card a participant plays to sit a ticket out. Every
+// session's deck ends with it. Unlike ?, which says the voter cannot tell,
+// an abstention never counts toward consensus and is reported apart from
+// the other votes.
+const Abstain = "abstain"
+
+// Infinity is the card for a ticket that cannot be estimated as scoped. It
+// has no numeric value, so it stays out of medians and means, but wherever
+// a ticket's statistics are shown, votes on it are flagged.
+const Infinity = "∞"
+
+// MaxSpecialCards is how many special cards a deck can have.
+const MaxSpecialCards = 8
+
+// SpecialCards are the special cards of sessions that have not chosen
+// their own. The deployment can replace them at startup.
+var SpecialCards = []SpecialCard{
+	{Value: "☕", Label: "Need a break"},
+	{Value: "?", Label: "Not sure"},
+	{Value: "✂", Label: "Too big, split it", Counts: true, Split: true},
+}
+
+// SuggestedSpecialCards are offered to owners choosing a session's special
+// cards, besides the defaults.
+var SuggestedSpecialCards = []SpecialCard{
+	{Value: Infinity, Label: "Cannot be estimated as scoped", Counts: true},
+}
+
+// ParseSpecialCards reads special cards from JSON, e.g.
+// [{"value":"∞","label":"Too big","counts":true}] or
+// [{"value":"✂","label":"Split it","counts":true,"split":true}], and checks
+// them.
+func ParseSpecialCards(value string) ([]SpecialCard, error) {
+	var cards []SpecialCard
+	if err := json.Unmarshal([]byte(value), &cards); err != nil {
+		return nil, fmt.Errorf("special cards must be a JSON array of {value, label, counts}: %w", err)
+	}
+	if err := ValidateSpecialCards(cards); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// ValidateSpecialCards checks that special cards are short, labelled,
+// distinct and not numbers, which would be mistaken for estimates.
+func ValidateSpecialCards(cards []SpecialCard) error {
+	if len(cards) > MaxSpecialCards {
+		return fmt.Errorf("a deck can have at most %d special cards", MaxSpecialCards)
+	}
+
+	seen := make(map[string]bool)
+	for _, card := range cards {
+		if length := utf8.RuneCountInString(card.Value); length < 1 || length > 8 || strings.TrimSpace(card.Value) != card.Value {
+			return fmt.Errorf("special card %q must be 1-8 characters without surrounding spaces", card.Value)
+		}
+		if _, ok := NumericValue(card.Value); ok {
+			return fmt.Errorf("special card %q must not be a number", card.Value)
+		}
+		if card.Value == Abstain {
+			return fmt.Errorf("special card %q is reserved for abstaining", card.Value)
+		}
+		if seen[card.Value] {
+			return fmt.Errorf("special card %q appears twice", card.Value)
+		}
+		seen[card.Value] = true
+		if length := utf8.RuneCountInString(card.Label); length > 40 {
+			return fmt.Errorf("the label of special card %q must be no more than 40 characters", card.Value)
+		}
+	}
+	return nil
+}
+
+// CountsTowardConsensus reports whether a vote on a card counts when judging
+// agreement. Numeric cards and cards that are not among the special ones
+// always count; abstentions never do.
+func CountsTowardConsensus(card string, special []SpecialCard) bool {
+	if _, ok := NumericValue(card); ok {
+		return true
+	}
+	if card == Abstain {
+		return false
+	}
+	for _, s := range special {
+		if s.Value == card {
+			return s.Counts
+		}
+	}
+	return true
+}
+
+// IsSplitCard reports whether a card is one of the special cards asking for
+// the ticket to be split.
+func IsSplitCard(card string, special []SpecialCard) bool {
+	for _, s := range special {
+		if s.Value == card {
+			return s.Split
+		}
+	}
+	return false
+}
+
+// IsValid reports whether the card is part of the deck.
+func (d Deck) IsValid(card string) bool {
+	return d.Order(card) >= 0
+}
+
+// Order returns the index of a card in the deck, or -1 if the deck does not
+// contain it.
+func (d Deck) Order(card string) int {
+	for i, c := range d {
+		if c == card {
+			return i
+		}
+	}
+	return -1
+}
+
+// Round snaps a value to a numeric card of the deck using the given strategy.
+// Values outside the deck range clamp to the smallest or largest card. Ties
+// under RoundNearest go to the larger card. It returns false when the deck
+// has no numeric cards.
+func Round(value float64, cards []string, strategy RoundingStrategy) (float64, bool) {
+	values := NumericValues(cards)
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	// Index of the first card >= value
+	i := sort.SearchFloat64s(values, value)
+	if i < len(values) && values[i] == value {
+		return value, true
+	}
+	if i == 0 {
+		return values[0], true
+	}
+	if i == len(values) {
+		return values[len(values)-1], true
+	}
+
+	lower, upper := values[i-1], values[i]
+	switch strategy {
+	case RoundUp:
+		return upper, true
+	case RoundDown:
+		return lower, true
+	default:
+		if value-lower < upper-value {
+			return lower, true
+		}
+		return upper, true
+	}
+}
@@ -0,0 +1,62 @@
+package deck
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// CardStyle is how a card is shown, so that pages and API clients present
+// the same cards. Both fields are optional.
+type CardStyle struct {
+	Color string `json:"color,omitempty"` // hex color, e.g. #f59e0b
+	Icon  string `json:"icon,omitempty"`  // emoji or short symbol shown with the value
+}
+
+// MaxCardStyles is how many cards can be styled.
+const MaxCardStyles = 32
+
+var colorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// CardStyles are the card styles of sessions that have not chosen their
+// own, by card. The deployment can replace them at startup.
+var CardStyles = map[string]CardStyle{}
+
+// ParseCardStyles reads card styles from JSON, e.g.
+// {"☕":{"color":"#92400e","icon":"🍵"},"13":{"color":"#dc2626"}}, and checks
+// them.
+func ParseCardStyles(value string) (map[string]CardStyle, error) {
+	var styles map[string]CardStyle
+	if err := json.Unmarshal([]byte(value), &styles); err != nil {
+		return nil, fmt.Errorf("card styles must be a JSON object of card -> {color, icon}: %w", err)
+	}
+	if err := ValidateCardStyles(styles); err != nil {
+		return nil, err
+	}
+	return styles, nil
+}
+
+// ValidateCardStyles checks that card styles use hex colors and short
+// icons. Styles of cards a deck does not have are allowed and unused.
+func ValidateCardStyles(styles map[string]CardStyle) error {
+	if len(styles) > MaxCardStyles {
+		return fmt.Errorf("at most %d cards can be styled", MaxCardStyles)
+	}
+
+	for card, style := range styles {
+		if length := utf8.RuneCountInString(card); length < 1 || length > 8 {
+			return fmt.Errorf("card %q must be 1-8 characters", card)
+		}
+		if style.Color == "" && style.Icon == "" {
+			return fmt.Errorf("card %q needs a color or an icon", card)
+		}
+		if style.Color != "" && !colorPattern.MatchString(style.Color) {
+			return fmt.Errorf("the color of card %q must be a hex color like #f59e0b", card)
+		}
+		if utf8.RuneCountInString(style.Icon) > 4 {
+			return fmt.Errorf("the icon of card %q must be no more than 4 characters", card)
+		}
+	}
+	return nil
+}
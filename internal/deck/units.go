@@ -0,0 +1,153 @@
+package deck
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Unit is what a session estimates in.
+type Unit string
+
+const (
+	UnitPoints Unit = "points"
+	UnitHours  Unit = "hours"
+	UnitDays   Unit = "days"
+)
+
+const DefaultUnit = UnitPoints
+
+var Units = []Unit{UnitPoints, UnitHours, UnitDays}
+
+var unitCards = map[Unit][]string{
+	UnitPoints: {"0", "1", "2", "3", "5", "8", "13", "21", "34", "55", "89", "144"},
+	UnitHours:  {"0.5", "1", "2", "4", "6", "8", "12", "16", "24", "32", "40"},
+	UnitDays:   {"0.5", "1", "1.5", "2", "3", "5", "8", "10", "15", "20"},
+}
+
+var unitSuffixes = map[Unit]string{
+	UnitPoints: "pts",
+	UnitHours:  "h",
+	UnitDays:   "d",
+}
+
+func ParseUnit(value string) (Unit, bool) {
+	for _, unit := range Units {
+		if string(unit) == value {
+			return unit, true
+		}
+	}
+	return "", false
+}
+
+// unitOrDefault maps unknown or empty units to the default so older sessions
+// keep working.
+func unitOrDefault(unit string) Unit {
+	if u, ok := ParseUnit(unit); ok {
+		return u
+	}
+	return DefaultUnit
+}
+
+// Cards returns the deck for a unit with the default special cards last.
+func Cards(unit string) Deck {
+	return WithSpecialCards(unit, SpecialCards)
+}
+
+// WithSpecialCards returns the deck for a unit with the given special cards
+// last.
+func WithSpecialCards(unit string, special []SpecialCard) Deck {
+	numeric := unitCards[unitOrDefault(unit)]
+	cards := make(Deck, len(numeric), len(numeric)+len(special))
+	copy(cards, numeric)
+	for _, card := range special {
+		cards = append(cards, card.Value)
+	}
+	return cards
+}
+
+// NumericCards returns only the numeric cards for a unit.
+func NumericCards(unit string) []string {
+	return unitCards[unitOrDefault(unit)]
+}
+
+// Suffix returns the short label shown after a value, e.g. "h" for hours.
+func Suffix(unit string) string {
+	return unitSuffixes[unitOrDefault(unit)]
+}
+
+// Format renders a statistic such as a median or mean with its unit.
+func Format(value float64, unit string) string {
+	return fmt.Sprintf("%.1f %s", value, Suffix(unit))
+}
+
+// FormatCard renders a card value with its unit, e.g. "0.5 d".
+func FormatCard(card string, unit string) string {
+	if _, ok := NumericValue(card); !ok {
+		return card
+	}
+	return card + " " + Suffix(unit)
+}
+
+// Conversion shows a deck in another unit at a fixed rate, for members of
+// teams moving between points and ideal days who still think in the other
+// unit. Votes cast on the converted cards count as the cards they were
+// converted from. The zero Conversion shows the deck as it is.
+type Conversion struct {
+	Unit Unit    // what the cards are shown in
+	Rate float64 // display units per unit of the deck
+}
+
+// NewConversion returns the conversion of a points deck into days, or of a
+// days deck into points, at daysPerPoint. It returns false for other units
+// and for a display unit that is the deck's own.
+func NewConversion(deckUnit, displayUnit string, daysPerPoint float64) (Conversion, bool) {
+	if daysPerPoint <= 0 {
+		return Conversion{}, false
+	}
+
+	switch {
+	case unitOrDefault(deckUnit) == UnitPoints && displayUnit == string(UnitDays):
+		return Conversion{Unit: UnitDays, Rate: daysPerPoint}, true
+	case unitOrDefault(deckUnit) == UnitDays && displayUnit == string(UnitPoints):
+		return Conversion{Unit: UnitPoints, Rate: 1 / daysPerPoint}, true
+	}
+	return Conversion{}, false
+}
+
+// Active reports whether the conversion changes the cards at all.
+func (c Conversion) Active() bool {
+	return c.Rate > 0
+}
+
+// Card shows a card in the display unit to three significant digits.
+// Special cards stay as they are.
+func (c Conversion) Card(card string) string {
+	value, ok := NumericValue(card)
+	if !ok || !c.Active() {
+		return card
+	}
+	converted, _ := strconv.ParseFloat(strconv.FormatFloat(value*c.Rate, 'g', 3, 64), 64)
+	return FormatValue(converted)
+}
+
+// Cards shows a deck in the display unit, in the same order.
+func (c Conversion) Cards(cards Deck) Deck {
+	converted := make(Deck, len(cards))
+	for i, card := range cards {
+		converted[i] = c.Card(card)
+	}
+	return converted
+}
+
+// Normalize returns the card of the deck that a vote on the converted cards
+// stands for. A vote that is not one of them comes back empty, which no
+// deck accepts, unless the conversion is the zero one.
+func (c Conversion) Normalize(vote string, cards Deck) string {
+	if i := c.Cards(cards).Order(vote); i >= 0 {
+		return cards[i]
+	}
+	if c.Active() {
+		return ""
+	}
+	return vote
+}
@@ -0,0 +1,108 @@
+// Package description handles ticket description templates: the sections a
+// team wants every ticket to describe, the text a new ticket starts with and
+// checking that the required sections were filled in.
+package description
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxSections is how many sections a template can have.
+	MaxSections = 10
+	// MaxSectionTitle is the longest a section title can be, in characters.
+	MaxSectionTitle = 50
+)
+
+// Section is a part of a description, e.g. "Acceptance Criteria". A
+// required one must have something written under it.
+type Section struct {
+	Title    string `json:"title"`
+	Required bool   `json:"required"`
+}
+
+// Validate checks that a template has sections and that their titles are
+// short, single lines and distinct.
+func Validate(sections []Section) error {
+	if len(sections) == 0 {
+		return fmt.Errorf("a template needs at least one section")
+	}
+	if len(sections) > MaxSections {
+		return fmt.Errorf("a template can have at most %d sections", MaxSections)
+	}
+
+	seen := make(map[string]bool)
+	for _, section := range sections {
+		length := utf8.RuneCountInString(section.Title)
+		if length < 1 || length > MaxSectionTitle || strings.TrimSpace(section.Title) != section.Title {
+			return fmt.Errorf("section %q must be 1-%d characters without surrounding spaces", section.Title, MaxSectionTitle)
+		}
+		if strings.ContainsAny(section.Title, ":\r\n") {
+			return fmt.Errorf("section %q must be a single line without a colon", section.Title)
+		}
+		key := strings.ToLower(section.Title)
+		if seen[key] {
+			return fmt.Errorf("section %q appears twice", section.Title)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// Prefill is the description a ticket written from the template starts
+// with: each section's title as a heading, with room to write under it.
+func Prefill(sections []Section) string {
+	headings := make([]string, len(sections))
+	for i, section := range sections {
+		headings[i] = section.Title + ":\n"
+	}
+	return strings.Join(headings, "\n")
+}
+
+// Missing returns the titles of the required sections that text leaves out
+// or leaves empty. A section starts at a line with its title, ignoring case,
+// Markdown heading marks and a trailing colon, and may start on that line
+// after the colon. It ends where the next section starts.
+func Missing(sections []Section, text string) []string {
+	content := make(map[int]string)
+	current := -1
+	for _, line := range strings.Split(text, "\n") {
+		if i, rest, ok := heading(sections, line); ok {
+			current = i
+			content[i] += rest
+			continue
+		}
+		if current >= 0 {
+			content[current] += strings.TrimSpace(line)
+		}
+	}
+
+	var missing []string
+	for i, section := range sections {
+		if section.Required && content[i] == "" {
+			missing = append(missing, section.Title)
+		}
+	}
+	return missing
+}
+
+// heading reports whether line starts one of the sections, and which, with
+// anything written after its colon.
+func heading(sections []Section, line string) (int, string, bool) {
+	line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+	for i, section := range sections {
+		if len(line) < len(section.Title) || !strings.EqualFold(line[:len(section.Title)], section.Title) {
+			continue
+		}
+		rest := strings.TrimSpace(line[len(section.Title):])
+		if rest == "" {
+			return i, "", true
+		}
+		if strings.HasPrefix(rest, ":") {
+			return i, strings.TrimSpace(rest[1:]), true
+		}
+	}
+	return 0, "", false
+}
@@ -0,0 +1,69 @@
+package description
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testSections = []Section{
+	{Title: "Context", Required: true},
+	{Title: "Acceptance Criteria", Required: true},
+	{Title: "Out of scope"},
+}
+
+func TestPrefill(t *testing.T) {
+	want := "Context:\n\nAcceptance Criteria:\n\nOut of scope:\n"
+	if got := Prefill(testSections); got != want {
+		t.Errorf("Prefill() = %q, want %q", got, want)
+	}
+	if got := Missing(testSections, Prefill(testSections)); !reflect.DeepEqual(got, []string{"Context", "Acceptance Criteria"}) {
+		t.Errorf("Missing(Prefill()) = %v, want both required sections", got)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", []string{"Context", "Acceptance Criteria"}},
+		{"filled in", "Context:\nUsers cannot reset their password\n\nAcceptance Criteria:\n- a reset link is emailed\n", nil},
+		{"on the heading line", "Context: users cannot reset\nacceptance criteria: link is emailed", nil},
+		{"markdown headings without colons", "## Context\nUsers\n### Acceptance Criteria\nEmail", nil},
+		{"optional section left empty", "Context: a\nAcceptance Criteria: b\nOut of scope:\n", nil},
+		{"one left empty", "Context:\n\nAcceptance Criteria:\nEmail\nOut of scope: SMS", []string{"Context"}},
+		{"only blank lines", "Context:\n  \n\t\nAcceptance Criteria: x", []string{"Context"}},
+		{"text before any section", "Users cannot reset\nAcceptance Criteria: x", []string{"Context"}},
+		{"title inside a sentence", "Context matters\nAcceptance Criteria: x", []string{"Context"}},
+		{"windows line endings", "Context:\r\nUsers\r\nAcceptance Criteria:\r\nEmail\r\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Missing(testSections, tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Missing() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(testSections); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+
+	invalid := [][]Section{
+		nil,
+		{{Title: ""}},
+		{{Title: " Context"}},
+		{{Title: "Context: why"}},
+		{{Title: "Context"}, {Title: "context"}},
+		make([]Section, MaxSections+1),
+	}
+	for _, sections := range invalid {
+		if err := Validate(sections); err == nil {
+			t.Errorf("Validate(%v) = nil, want an error", sections)
+		}
+	}
+}
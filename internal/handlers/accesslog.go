@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+type accessLogKey struct{}
+
+// accessLogEntry collects what inner middleware learns about a request, such
+// as who made it, for the access log line written once it completes.
+type accessLogEntry struct {
+	userID string
+}
+
+// AccessLogger writes one structured line per request with the user, the
+// session and the response size, so a session's history can be traced from
+// the logs. Install it before SessionMiddleware and after middleware.RequestID.
+func AccessLogger(logger *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			entry := &accessLogEntry{}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
+
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), accessLogKey{}, entry)))
+
+			status := ww.Status()
+			if status == 0 {
+				// WebSocket upgrades answer on the hijacked connection
+				status = http.StatusOK
+				if r.Header.Get("Upgrade") == "websocket" {
+					status = http.StatusSwitchingProtocols
+				}
+			}
+
+			// Routing has filled in the URL parameters by now
+			sessionID := ""
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				sessionID = rctx.URLParam("sessionID")
+			}
+
+			logger.LogAttrs(r.Context(), slog.LevelInfo, "request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("remote_addr", r.RemoteAddr),
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("user_id", entry.userID),
+				slog.String("session_id", sessionID),
+				slog.Bool("htmx", r.Header.Get("HX-Request") != ""),
+			)
+		})
+	}
+}
+
+// annotateAccessLog records the user behind a request in its access log line.
+func annotateAccessLog(ctx context.Context, userID string) {
+	if entry, ok := ctx.Value(accessLogKey{}).(*accessLogEntry); ok {
+		entry.userID = userID
+	}
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/importers"
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+)
+
+// The estimate-vs-actual chart is drawn in a viewBox of this size, with
+// room around the plot for the axis labels. team.html draws the axes to
+// match.
+const (
+	accuracyChartWidth   = 400
+	accuracyChartHeight  = 240
+	accuracyChartPadding = 32
+)
+
+// AccuracyChart is an estimate-vs-actual scatter of a team's tickets
+// estimated in one deck, with actuals in one unit.
+type AccuracyChart struct {
+	EstimationUnit string
+	ActualUnit     string
+	MaxEstimate    float64
+	MaxActual      float64
+	Points         []AccuracyChartPoint
+	Rows           []AccuracyRow
+}
+
+// AccuracyChartPoint is a ticket placed on the chart, X and Y in viewBox
+// coordinates.
+type AccuracyChartPoint struct {
+	services.AccuracyPoint
+	X, Y float64
+}
+
+// AccuracyRow sums up what the tickets given one estimate actually took.
+type AccuracyRow struct {
+	Estimate string
+	Tickets  int
+	Median   float64
+	Min      float64
+	Max      float64
+}
+
+// accuracyCharts groups a team's estimated tickets with actuals into one
+// chart per deck and actual unit. Tickets whose estimate is not a number,
+// like "?", cannot be placed and are left out.
+func accuracyCharts(points []services.AccuracyPoint) []AccuracyChart {
+	type chartKey struct{ estimationUnit, actualUnit string }
+	var keys []chartKey
+	groups := make(map[chartKey][]services.AccuracyPoint)
+	for _, point := range points {
+		if _, ok := deck.NumericValue(point.Estimate); !ok {
+			continue
+		}
+		key := chartKey{point.EstimationUnit, point.ActualUnit}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], point)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].estimationUnit != keys[j].estimationUnit {
+			return keys[i].estimationUnit < keys[j].estimationUnit
+		}
+		return keys[i].actualUnit < keys[j].actualUnit
+	})
+
+	var charts []AccuracyChart
+	for _, key := range keys {
+		chart := AccuracyChart{EstimationUnit: key.estimationUnit, ActualUnit: key.actualUnit}
+		actuals := make(map[string][]float64)
+		for _, point := range groups[key] {
+			estimate, _ := deck.NumericValue(point.Estimate)
+			chart.MaxEstimate = max(chart.MaxEstimate, estimate)
+			chart.MaxActual = max(chart.MaxActual, point.Actual)
+			actuals[point.Estimate] = append(actuals[point.Estimate], point.Actual)
+		}
+
+		plotWidth := float64(accuracyChartWidth - 2*accuracyChartPadding)
+		plotHeight := float64(accuracyChartHeight - 2*accuracyChartPadding)
+		for _, point := range groups[key] {
+			estimate, _ := deck.NumericValue(point.Estimate)
+			chartPoint := AccuracyChartPoint{AccuracyPoint: point, X: accuracyChartPadding, Y: accuracyChartHeight - accuracyChartPadding}
+			if chart.MaxEstimate > 0 {
+				chartPoint.X += estimate / chart.MaxEstimate * plotWidth
+			}
+			if chart.MaxActual > 0 {
+				chartPoint.Y -= point.Actual / chart.MaxActual * plotHeight
+			}
+			chart.Points = append(chart.Points, chartPoint)
+		}
+
+		for estimate, values := range actuals {
+			sort.Float64s(values)
+			row := AccuracyRow{Estimate: estimate, Tickets: len(values), Min: values[0], Max: values[len(values)-1]}
+			middle := len(values) / 2
+			row.Median = values[middle]
+			if len(values)%2 == 0 {
+				row.Median = (values[middle-1] + values[middle]) / 2
+			}
+			chart.Rows = append(chart.Rows, row)
+		}
+		sort.Slice(chart.Rows, func(i, j int) bool {
+			a, _ := deck.NumericValue(chart.Rows[i].Estimate)
+			b, _ := deck.NumericValue(chart.Rows[j].Estimate)
+			return a < b
+		})
+
+		charts = append(charts, chart)
+	}
+	return charts
+}
+
+// writeRecordedActuals answers an actuals import with how many tickets got
+// an actual and which rows matched no ticket.
+func writeRecordedActuals(w http.ResponseWriter, recorded int, unmatched []models.TicketActual) {
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"recorded":  recorded,
+		"unmatched": unmatched,
+	})
+}
+
+// GetTeamAccuracy returns the estimated tickets of a team's sessions that
+// have an actual, as estimate-vs-actual scatter data.
+func (h *Handler) GetTeamAccuracy(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	team, _, ok := h.getMemberTeam(w, r, user)
+	if !ok {
+		return
+	}
+
+	points, err := h.ticketService.GetTeamAccuracy(r.Context(), team.ID)
+	if err != nil {
+		utils.LogError("GetTeamAccuracy", err, utils.ReportContext{UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get actuals")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, points)
+}
+
+// ImportTeamActuals records what the team's tickets took from an uploaded
+// CSV or TSV export, matching only tickets of the team's sessions. Rows
+// without a unit column are in unit. Browsers without JavaScript go back
+// to the team page.
+func (h *Handler) ImportTeamActuals(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	team, canEdit, ok := h.getMemberTeam(w, r, user)
+	if !ok {
+		return
+	}
+	if !canEdit {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only team members can import actuals")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.WriteFormValidationError(w, r, utils.ValidationErrors{{Field: "file", Message: "Choose a CSV or TSV file of up to 5 MB"}})
+		return
+	}
+	defer file.Close()
+
+	var comma rune
+	if strings.HasSuffix(strings.ToLower(header.Filename), ".tsv") {
+		comma = '\t'
+	}
+	actuals, err := importers.ReadActuals(file, comma, r.FormValue("unit"))
+	if err != nil {
+		utils.WriteFormValidationError(w, r, utils.ValidationErrors{{Field: "file", Message: err.Error()}})
+		return
+	}
+
+	recorded, unmatched, err := h.ticketService.RecordActuals(r.Context(), team.ID, actuals)
+	if err != nil {
+		utils.LogError("ImportTeamActuals", err, utils.ReportContext{UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to record actuals")
+		return
+	}
+
+	if expectsPage(r) {
+		http.Redirect(w, r, "/org/"+team.OrganizationID+"/teams/"+team.ID, http.StatusSeeOther)
+		return
+	}
+	writeRecordedActuals(w, recorded, unmatched)
+}
+
+// ImportAPIActuals records what tickets took, e.g. their cycle time or
+// effort from the tracker, across all sessions. The body is JSON with an
+// actuals list, or a CSV or TSV export sent as text/csv or
+// text/tab-separated-values whose rows without a unit column are in the
+// unit query parameter.
+func (h *Handler) ImportAPIActuals(w http.ResponseWriter, r *http.Request) {
+	var actuals []models.TicketActual
+	switch contentType := r.Header.Get("Content-Type"); {
+	case strings.HasPrefix(contentType, "application/json"):
+		var body struct {
+			Actuals []models.TicketActual `json:"actuals"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); isTooLarge(err) {
+			writeTooLarge(w, r, maxImportFileSize)
+			return
+		} else if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+		if len(body.Actuals) > importers.MaxActuals {
+			utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("At most %d actuals can be imported at once", importers.MaxActuals))
+			return
+		}
+		for i, actual := range body.Actuals {
+			if err := importers.ValidateActual(actual); err != nil {
+				utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("actuals[%d]: %v", i, err))
+				return
+			}
+		}
+		actuals = body.Actuals
+
+	case strings.HasPrefix(contentType, "text/csv"), strings.HasPrefix(contentType, "text/tab-separated-values"):
+		var comma rune
+		if strings.HasPrefix(contentType, "text/tab-separated-values") {
+			comma = '\t'
+		}
+		var err error
+		actuals, err = importers.ReadActuals(r.Body, comma, r.URL.Query().Get("unit"))
+		if errors.Is(err, importers.ErrInvalidFile) {
+			utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if isTooLarge(err) {
+			writeTooLarge(w, r, maxImportFileSize)
+			return
+		}
+		if err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Could not read the body")
+			return
+		}
+
+	default:
+		utils.WriteJSONError(w, http.StatusUnsupportedMediaType, "Send application/json, text/csv or text/tab-separated-values")
+		return
+	}
+
+	recorded, unmatched, err := h.ticketService.RecordActuals(r.Context(), "", actuals)
+	if err != nil {
+		utils.LogError("ImportAPIActuals", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to record actuals")
+		return
+	}
+
+	writeRecordedActuals(w, recorded, unmatched)
+}
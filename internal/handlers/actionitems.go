@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// actionItems returns a session's action items, or nil if they cannot be
+// loaded.
+func (h *Handler) actionItems(ctx context.Context, sessionID string) []models.ActionItem {
+	items, err := h.sessionService.GetActionItems(ctx, sessionID)
+	if err != nil {
+		utils.LogError("actionItems", err, utils.ReportContext{SessionID: sessionID})
+		return nil
+	}
+	return items
+}
+
+// GetActionItems returns the session's action items as JSON, oldest first.
+func (h *Handler) GetActionItems(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	session := h.participantSession(w, r, "GetActionItems", user)
+	if session == nil {
+		return
+	}
+
+	items, err := h.sessionService.GetActionItems(r.Context(), session.ID)
+	if err != nil {
+		utils.LogError("GetActionItems", err, utils.ReportContext{SessionID: session.ID, UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get action items")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, items)
+}
+
+// AddActionItem records a follow-up task while the owner reviews the
+// session. The form has text and optionally assignee_id, one of the
+// participants. Each item is recorded as an action-item-added event, so hook
+// subscriptions can turn it into a Slack message or Jira issue.
+func (h *Handler) AddActionItem(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	session := h.participantSession(w, r, "AddActionItem", user)
+	if session == nil {
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can record action items")
+		return
+	}
+
+	item := models.ActionItem{
+		SessionID: session.ID,
+		Text:      utils.SanitizeInput(r.FormValue("text")),
+		CreatedBy: user.ID,
+	}
+	if assigneeID := r.FormValue("assignee_id"); assigneeID != "" {
+		item.AssigneeID = &assigneeID
+	}
+	if validationErrors := utils.ValidateActionItemText(item.Text); validationErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, validationErrors)
+		return
+	}
+
+	saved, err := h.sessionService.AddActionItem(r.Context(), item)
+	if err != nil {
+		writeServiceError(w, r, "AddActionItem", err, "Failed to add action item")
+		return
+	}
+	h.recordEvent(r.Context(), session.ID, services.EventActionItemAdded, 0, user.ID, map[string]interface{}{
+		"id":          saved.ID,
+		"text":        saved.Text,
+		"assignee_id": saved.AssigneeID,
+		"assignee":    saved.AssigneeName,
+	})
+
+	if expectsPage(r) {
+		http.Redirect(w, r, "/session/"+session.ID+"/summary", http.StatusSeeOther)
+		return
+	}
+	utils.WriteJSON(w, http.StatusCreated, saved)
+}
+
+// DeleteActionItem removes an action item (owner only).
+func (h *Handler) DeleteActionItem(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	itemID, err := strconv.Atoi(chi.URLParam(r, "itemID"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid action item ID")
+		return
+	}
+
+	session := h.participantSession(w, r, "DeleteActionItem", user)
+	if session == nil {
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can remove action items")
+		return
+	}
+
+	if err := h.sessionService.DeleteActionItem(r.Context(), session.ID, itemID); err != nil {
+		writeServiceError(w, r, "DeleteActionItem", err, "Failed to delete action item")
+		return
+	}
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+session.ID+"/summary")
+}
+
+// ExportActionItemsCSV downloads the session's action items, for tracking
+// them elsewhere.
+func (h *Handler) ExportActionItemsCSV(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	session := h.participantSession(w, r, "ExportActionItemsCSV", user)
+	if session == nil {
+		return
+	}
+
+	items, err := h.sessionService.GetActionItems(r.Context(), session.ID)
+	if err != nil {
+		utils.LogError("ExportActionItemsCSV", err, utils.ReportContext{SessionID: session.ID, UserID: user.ID})
+		http.Error(w, "Failed to get action items", http.StatusInternalServerError)
+		return
+	}
+
+	// Timestamps are ISO-8601 with the viewer's UTC offset
+	loc := viewerLocation(r, user)
+
+	filename := fmt.Sprintf("action-items-%s-%s.csv", session.ID, time.Now().In(loc).Format("2006-01-02"))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	header := []string{"Session Name", "Session ID", "Action Item", "Assignee", "Recorded At"}
+	if err := writer.Write(header); err != nil {
+		http.Error(w, "Failed to write CSV header", http.StatusInternalServerError)
+		return
+	}
+
+	for _, item := range items {
+		record := []string{
+			session.Name,
+			session.ID,
+			item.Text,
+			item.AssigneeName,
+			item.CreatedAt.In(loc).Format(time.RFC3339),
+		}
+		if err := writer.Write(record); err != nil {
+			http.Error(w, "Failed to write CSV record", http.StatusInternalServerError)
+			return
+		}
+	}
+}
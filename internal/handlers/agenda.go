@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	maxAgendaItems = 30
+	// maxAgendaMinutes is the longest an agenda item can be planned to
+	// take; longer sessions are split into items.
+	maxAgendaMinutes = 240
+)
+
+// AgendaSummary compares the time planned for an agenda with the time it
+// took, for the session summary.
+type AgendaSummary struct {
+	Items   []models.AgendaItem
+	Planned time.Duration
+	Elapsed time.Duration
+}
+
+// Overrun reports whether the agenda took longer than planned so far.
+func (a AgendaSummary) Overrun() bool {
+	return a.Elapsed > a.Planned
+}
+
+func newAgendaSummary(items []models.AgendaItem) *AgendaSummary {
+	if len(items) == 0 {
+		return nil
+	}
+
+	summary := &AgendaSummary{Items: items}
+	for _, item := range items {
+		summary.Planned += time.Duration(item.PlannedMinutes) * time.Minute
+		summary.Elapsed += item.Elapsed()
+	}
+	return summary
+}
+
+// formatDuration shows a duration as m:ss, or h:mm:ss from an hour on,
+// like the agenda's live timer.
+func formatDuration(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// agenda returns a session's agenda, or nil if it has none or it cannot be
+// loaded.
+func (h *Handler) agenda(ctx context.Context, sessionID string) []models.AgendaItem {
+	items, err := h.sessionService.GetAgenda(ctx, sessionID)
+	if err != nil {
+		utils.LogError("agenda", err, utils.ReportContext{SessionID: sessionID})
+		return nil
+	}
+	return items
+}
+
+// GetAgenda returns the session's agenda as JSON, in order, with when each
+// item started and ended.
+func (h *Handler) GetAgenda(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("GetAgenda", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteJSONError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	isParticipant := false
+	for _, participant := range session.Participants {
+		if participant.ID == user.ID {
+			isParticipant = true
+			break
+		}
+	}
+	if !isParticipant {
+		utils.WriteJSONError(w, http.StatusForbidden, "Not a session participant")
+		return
+	}
+
+	items, err := h.sessionService.GetAgenda(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("GetAgenda", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get agenda")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSON(w, http.StatusOK, items)
+}
+
+// SetAgenda replaces the session's agenda (owner only). The form repeats
+// kind, title and minutes once per item, in order; an item without a
+// title is called after its kind.
+func (h *Handler) SetAgenda(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("SetAgenda", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can plan the agenda")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+	kinds, titles, minutes := r.Form["kind"], r.Form["title"], r.Form["minutes"]
+	if len(titles) != len(kinds) || len(minutes) != len(kinds) {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Each agenda item needs a kind, title and minutes")
+		return
+	}
+	if len(kinds) > maxAgendaItems {
+		utils.WriteHTMLError(w, http.StatusBadRequest, fmt.Sprintf("An agenda can have at most %d items", maxAgendaItems))
+		return
+	}
+
+	items := make([]models.AgendaItem, 0, len(kinds))
+	for i := range kinds {
+		kind, ok := models.ParseAgendaKind(kinds[i])
+		if !ok {
+			utils.WriteHTMLError(w, http.StatusBadRequest, "Unknown agenda item kind")
+			return
+		}
+
+		title := utils.SanitizeInput(titles[i])
+		if validationErrors := utils.ValidateAgendaItemTitle(title); validationErrors.HasErrors() {
+			utils.WriteFormValidationError(w, r, validationErrors)
+			return
+		}
+		if title == "" {
+			title = kind.Label()
+		}
+
+		planned, err := strconv.Atoi(utils.SanitizeInput(minutes[i]))
+		if err != nil || planned < 1 || planned > maxAgendaMinutes {
+			utils.WriteHTMLError(w, http.StatusBadRequest, fmt.Sprintf("Agenda items take 1-%d minutes", maxAgendaMinutes))
+			return
+		}
+
+		items = append(items, models.AgendaItem{Kind: kind, Title: title, PlannedMinutes: planned})
+	}
+
+	saved, err := h.sessionService.SetAgenda(r.Context(), sessionID, items)
+	if err != nil {
+		writeServiceError(w, r, "SetAgenda", err, "Failed to save agenda")
+		return
+	}
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "agenda-updated",
+		Data: saved,
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
+
+// ClearAgenda deletes the session's agenda, so a new one can be planned
+// after it has started (owner only).
+func (h *Handler) ClearAgenda(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("ClearAgenda", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can plan the agenda")
+		return
+	}
+
+	if err := h.sessionService.ClearAgenda(r.Context(), sessionID); err != nil {
+		utils.LogError("ClearAgenda", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to clear agenda")
+		return
+	}
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "agenda-updated",
+		Data: []models.AgendaItem{},
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
+
+// AdvanceAgenda ends the current agenda item and starts the next one
+// (owner only). The first call starts the agenda, and the call after the
+// last item finishes it.
+func (h *Handler) AdvanceAgenda(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("AdvanceAgenda", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can move through the agenda")
+		return
+	}
+
+	items, current, err := h.sessionService.AdvanceAgenda(r.Context(), sessionID)
+	if err != nil {
+		writeServiceError(w, r, "AdvanceAgenda", err, "Failed to advance agenda")
+		return
+	}
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "agenda-advanced",
+		Data: map[string]interface{}{"current": current, "items": items},
+	})
+
+	data := map[string]interface{}{"finished": current == nil}
+	if current != nil {
+		data["position"] = current.Position
+		data["kind"] = current.Kind
+		data["title"] = current.Title
+		data["planned_minutes"] = current.PlannedMinutes
+	}
+	h.recordEvent(r.Context(), sessionID, services.EventAgendaAdvanced, 0, user.ID, data)
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
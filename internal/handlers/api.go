@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"poker-planning/internal/services"
+	"poker-planning/internal/stats"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	apiPageSize    = 100
+	maxAPIPageSize = 1000
+)
+
+// RequireAPIToken guards the /api/v1 endpoints with the configured token,
+// sent as "Authorization: Bearer <token>".
+func RequireAPIToken(token string) func(http.Handler) http.Handler {
+	return requireBearerToken(token)
+}
+
+// APITicket is a ticket as served to external dashboards.
+type APITicket struct {
+	ID             int              `json:"id"`
+	ExternalRef    *string          `json:"external_ref"`
+	Title          string           `json:"title"`
+	SessionID      string           `json:"session_id"`
+	SessionName    string           `json:"session_name"`
+	EstimationUnit string           `json:"estimation_unit"`
+	FinalEstimate  *string          `json:"final_estimate"`
+	EstimateLow    *string          `json:"estimate_low"`          // suggested range around the final estimate,
+	EstimateHigh   *string          `json:"estimate_high"`         // null unless it had numeric votes
+	Rationale      string           `json:"rationale,omitempty"`   // why the final estimate was chosen
+	Assumptions    string           `json:"assumptions,omitempty"` // what the final estimate assumes
+	Calibration    bool             `json:"calibration"`           // a reference story, not part of the backlog's estimates
+	NeedsSplit     bool             `json:"needs_split"`           // the team voted it too big to estimate
+	Split          bool             `json:"split"`                 // broken up into smaller tickets
+	CreatedAt      time.Time        `json:"created_at"`
+	RevealedAt     *time.Time       `json:"revealed_at"`
+	Rounds         []APIRound       `json:"rounds"`
+	Consensus      *stats.Consensus `json:"consensus"` // of the last round, null before anyone voted
+}
+
+// APIRound is one round of votes on a ticket, without who voted what.
+type APIRound struct {
+	Round        int             `json:"round"`
+	Distribution map[string]int  `json:"distribution"` // card -> number of votes
+	Consensus    stats.Consensus `json:"consensus"`
+}
+
+// apiTicket converts a ticket and its rounds of votes for the API.
+func (h *Handler) apiTicket(record services.TicketRecord) APITicket {
+	ticket := APITicket{
+		ID:             record.ID,
+		ExternalRef:    record.ExternalKey,
+		Title:          record.Title,
+		SessionID:      record.SessionID,
+		SessionName:    record.SessionName,
+		EstimationUnit: record.EstimationUnit,
+		FinalEstimate:  record.FinalEstimate,
+		EstimateLow:    record.EstimateLow,
+		EstimateHigh:   record.EstimateHigh,
+		Rationale:      record.Rationale,
+		Assumptions:    record.Assumptions,
+		Calibration:    record.IsCalibration,
+		NeedsSplit:     record.NeedsSplit,
+		Split:          record.IsSplit,
+		CreatedAt:      record.CreatedAt,
+		RevealedAt:     record.RevealedAt,
+		Rounds:         []APIRound{},
+	}
+
+	cards := stats.Deck{Special: record.SpecialCards}
+	for _, round := range record.Rounds {
+		distribution := make(map[string]int)
+		for _, vote := range round.Votes {
+			distribution[vote.VoteValue]++
+		}
+		ticket.Rounds = append(ticket.Rounds, APIRound{
+			Round:        round.Round,
+			Distribution: distribution,
+			Consensus:    cards.Consensus(round.Votes),
+		})
+	}
+	if n := len(ticket.Rounds); n > 0 {
+		ticket.Consensus = &ticket.Rounds[n-1].Consensus
+	}
+
+	return ticket
+}
+
+// apiPage reads the limit and offset query parameters.
+func apiPage(r *http.Request) (limit, offset int, err error) {
+	limit = apiPageSize
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAPIPageSize {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxAPIPageSize)
+		}
+	}
+	if value := r.URL.Query().Get("offset"); value != "" {
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative number")
+		}
+	}
+	return limit, offset, nil
+}
+
+// GetAPITickets lists tickets across all sessions with their estimate
+// history, vote distributions and consensus metrics, so dashboards can join
+// them against delivery data. external_ref selects the tickets with that
+// issue key.
+func (h *Handler) GetAPITickets(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := apiPage(r)
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	records, err := h.ticketService.FindTickets(r.Context(), services.TicketQuery{
+		ExternalKey: r.URL.Query().Get("external_ref"),
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		utils.LogError("GetAPITickets", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get tickets")
+		return
+	}
+
+	tickets := make([]APITicket, 0, len(records))
+	for _, record := range records {
+		tickets = append(tickets, h.apiTicket(record))
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"tickets": tickets,
+		"limit":   limit,
+		"offset":  offset,
+	})
+}
+
+// APISessionSummary is what the summary page of a session shows, for
+// reports built elsewhere. Calibration stories are listed but stay out of
+// the totals, overall statistics and participants' statistics.
+type APISessionSummary struct {
+	ID               string                  `json:"id"`
+	Name             string                  `json:"name"`
+	EstimationUnit   string                  `json:"estimation_unit"`
+	TotalVotes       int                     `json:"total_votes"`
+	EstimatedTickets int                     `json:"estimated_tickets"` // tickets with numeric votes
+	Overall          stats.TicketStats       `json:"overall"`
+	Tickets          []APISummaryTicket      `json:"tickets"`
+	Participants     []APISummaryParticipant `json:"participants"`
+}
+
+// APISummaryTicket is a ticket's votes and the statistics of them. Stats
+// and consensus are null before anyone voted, and the rollup is only there
+// when asked for or the session is large.
+type APISummaryTicket struct {
+	ID            int                `json:"id"`
+	ExternalRef   *string            `json:"external_ref"`
+	Title         string             `json:"title"`
+	FinalEstimate *string            `json:"final_estimate"`
+	EstimateLow   *string            `json:"estimate_low"`
+	EstimateHigh  *string            `json:"estimate_high"`
+	Calibration   bool               `json:"calibration"`
+	Votes         int                `json:"votes"`
+	Stats         *stats.TicketStats `json:"stats"`
+	Histogram     []stats.VoteCount  `json:"histogram"`
+	Rollup        []stats.VoteGroup  `json:"rollup,omitempty"`
+	Consensus     *stats.Consensus   `json:"consensus"`
+}
+
+// APISummaryParticipant is how a participant voted over the session.
+type APISummaryParticipant struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	stats.ParticipantStat
+}
+
+// GetAPISessionSummary returns a session's summary with the statistics the
+// summary page computes: per ticket, overall and per participant.
+func (h *Handler) GetAPISessionSummary(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("GetAPISessionSummary", err, utils.ReportContext{SessionID: sessionID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteJSONError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	rollup := wantsRollup(r, session)
+	summary := stats.Session(session)
+	response := APISessionSummary{
+		ID:               session.ID,
+		Name:             session.Name,
+		EstimationUnit:   session.EstimationUnit,
+		TotalVotes:       summary.TotalVotes,
+		EstimatedTickets: summary.EstimatedTickets,
+		Overall:          summary.Overall,
+		Tickets:          []APISummaryTicket{},
+		Participants:     []APISummaryParticipant{},
+	}
+
+	for _, ticket := range session.Tickets {
+		item := APISummaryTicket{
+			ID:            ticket.ID,
+			ExternalRef:   ticket.ExternalKey,
+			Title:         ticket.Title,
+			FinalEstimate: ticket.FinalEstimate,
+			EstimateLow:   ticket.EstimateLow,
+			EstimateHigh:  ticket.EstimateHigh,
+			Calibration:   ticket.IsCalibration,
+			Votes:         len(ticket.Votes),
+			Histogram:     []stats.VoteCount{},
+		}
+		if ticketStats, ok := summary.Tickets[ticket.ID]; ok {
+			consensus := stats.ForSession(session).Consensus(ticket.Votes)
+			item.Stats = &ticketStats
+			item.Histogram = summary.Histograms[ticket.ID]
+			item.Consensus = &consensus
+		}
+		if rollup {
+			item.Rollup = stats.ForSession(session).Rollup(ticket.Votes)
+		}
+		response.Tickets = append(response.Tickets, item)
+	}
+
+	for _, participant := range session.Participants {
+		item := APISummaryParticipant{UserID: participant.ID, Username: participant.Username}
+		if stat := summary.Participants[participant.ID]; stat != nil {
+			item.ParticipantStat = *stat
+		}
+		response.Participants = append(response.Participants, item)
+	}
+
+	utils.WriteJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BasicAuth guards the whole instance with HTTP basic auth. Browsers resend
+// the credentials with every request, WebSocket handshakes included, so
+// passwords that have been checked once are remembered rather than run
+// through bcrypt again.
+type BasicAuth struct {
+	users    map[string]string // username -> password hash
+	mu       sync.Mutex
+	verified map[[32]byte]bool // sha256 of "username:password" -> accepted
+}
+
+// NewBasicAuth allows a single username and password.
+func NewBasicAuth(username, password string) *BasicAuth {
+	sum := sha1.Sum([]byte(password))
+	return &BasicAuth{
+		users:    map[string]string{username: "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])},
+		verified: make(map[[32]byte]bool),
+	}
+}
+
+// LoadHtpasswd allows the users in an htpasswd file. Passwords must be
+// hashed with bcrypt (htpasswd -B) or SHA-1 (htpasswd -s).
+func LoadHtpasswd(path string) (*BasicAuth, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open htpasswd file: %w", err)
+	}
+	defer file.Close()
+
+	auth := &BasicAuth{users: make(map[string]string), verified: make(map[[32]byte]bool)}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		username, hash, ok := strings.Cut(entry, ":")
+		if !ok || username == "" {
+			return nil, fmt.Errorf("htpasswd line %d: expected username:hash", line)
+		}
+		if !strings.HasPrefix(hash, "$2") && !strings.HasPrefix(hash, "{SHA}") {
+			return nil, fmt.Errorf("htpasswd line %d: unsupported hash for %s, use bcrypt (htpasswd -B)", line, username)
+		}
+		auth.users[username] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read htpasswd file: %w", err)
+	}
+
+	if len(auth.users) == 0 {
+		return nil, fmt.Errorf("htpasswd file %s has no users", path)
+	}
+	return auth, nil
+}
+
+func (a *BasicAuth) check(username, password string) bool {
+	hash, ok := a.users[username]
+	if !ok {
+		return false
+	}
+
+	key := sha256.Sum256([]byte(username + ":" + password))
+	a.mu.Lock()
+	verified := a.verified[key]
+	a.mu.Unlock()
+	if verified {
+		return true
+	}
+
+	if strings.HasPrefix(hash, "{SHA}") {
+		sum := sha1.Sum([]byte(password))
+		ok = subtle.ConstantTimeCompare([]byte(hash[len("{SHA}"):]), []byte(base64.StdEncoding.EncodeToString(sum[:]))) == 1
+	} else {
+		ok = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	}
+
+	if ok {
+		a.mu.Lock()
+		a.verified[key] = true
+		a.mu.Unlock()
+	}
+	return ok
+}
+
+// Require asks for credentials on every route. The debug endpoints and the
+// API are left to their own bearer tokens, which use the same header, and
+// webhooks, embeds and WebSocket handshakes with a connection token to their
+// signatures.
+func (a *BasicAuth) Require(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/debug/") || strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, "/webhooks/") ||
+			strings.HasPrefix(r.URL.Path, "/embed/") || IsConnectTokenHandshake(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		username, password, ok := r.BasicAuth()
+		if !ok || !a.check(username, password) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="Sprint Planning Poker", charset="UTF-8"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
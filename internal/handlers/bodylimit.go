@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"poker-planning/internal/utils"
+)
+
+const (
+	// maxFormSize caps form and JSON bodies, far above what any form sends.
+	maxFormSize = 1 << 20
+	// maxUploadMemory is how much of an upload is held in memory; the rest
+	// of it goes to a temporary file.
+	maxUploadMemory = 1 << 20
+	// maxDescriptionSize is the longest ticket description that is checked
+	// like any other; longer ones, e.g. a whole file pasted by mistake, are
+	// refused outright.
+	maxDescriptionSize = 16 << 10
+)
+
+// isUploadRequest reports whether a request sends a file to import:
+// tickets, actuals or a session bundle. These may be larger than forms.
+func isUploadRequest(r *http.Request) bool {
+	return strings.Contains(r.URL.Path, "/import/") || strings.HasSuffix(r.URL.Path, "/actuals") || r.URL.Path == "/session/import"
+}
+
+// bodyLimit is the most a request may send.
+func bodyLimit(r *http.Request) int64 {
+	if isUploadRequest(r) {
+		return maxImportFileSize
+	}
+	return maxFormSize
+}
+
+// writeTooLarge answers 413, saying how much may be sent.
+func writeTooLarge(w http.ResponseWriter, r *http.Request, limit int64) {
+	what := "Forms"
+	if isUploadRequest(r) {
+		what = "Imported files"
+	}
+	message := fmt.Sprintf("The request is too large. %s can be up to %s", what, formatSize(limit))
+	if strings.HasPrefix(r.URL.Path, "/api/") || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		utils.WriteJSONError(w, http.StatusRequestEntityTooLarge, message)
+		return
+	}
+	utils.WriteHTMLError(w, http.StatusRequestEntityTooLarge, message)
+}
+
+func formatSize(size int64) string {
+	if size >= 1<<20 {
+		return fmt.Sprintf("%d MB", size>>20)
+	}
+	return fmt.Sprintf("%d KB", size>>10)
+}
+
+// LimitRequestBody caps request bodies: 5 MB for imports and 1 MB for
+// everything else. Bodies that say up front they are larger are refused
+// with 413 without reading them. Forms are parsed here, so one that turns
+// out too large is refused the same way instead of reaching its handler
+// with fields missing. Webhooks check their own bodies, whose signatures
+// need them unread.
+func LimitRequestBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Body == http.NoBody || strings.HasPrefix(r.URL.Path, "/webhooks/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limit := bodyLimit(r)
+		if r.ContentLength > limit {
+			writeTooLarge(w, r, limit)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+		var err error
+		switch contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); contentType {
+		case "application/x-www-form-urlencoded":
+			err = r.ParseForm()
+		case "multipart/form-data":
+			err = r.ParseMultipartForm(maxUploadMemory)
+		}
+		if isTooLarge(err) {
+			writeTooLarge(w, r, limit)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isTooLarge reports whether reading a body failed on its size limit.
+func isTooLarge(err error) bool {
+	var maxBytesError *http.MaxBytesError
+	return errors.As(err, &maxBytesError)
+}
+
+// descriptionTooLarge refuses a ticket description far longer than any
+// description may be with 413, before it is trimmed and validated.
+func descriptionTooLarge(w http.ResponseWriter, r *http.Request) bool {
+	if len(r.FormValue("description")) <= maxDescriptionSize {
+		return false
+	}
+	utils.WriteHTMLError(w, http.StatusRequestEntityTooLarge,
+		"The ticket description is too large. Descriptions can be up to 1000 characters; link to longer documents instead")
+	return true
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/stats"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultBotDelay = 3 * time.Second
+	maxBotDelay     = 60 * time.Second
+)
+
+// AddBot lets the session owner add a server-driven participant that votes
+// by itself whenever voting starts.
+func (h *Handler) AddBot(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only session owner can add bots", http.StatusForbidden)
+		return
+	}
+
+	name := utils.SanitizeInput(r.FormValue("name"))
+	if name == "" {
+		name = fmt.Sprintf("Bot %d", len(session.Participants))
+	}
+	fixedValue := utils.SanitizeInput(r.FormValue("fixed_value"))
+
+	var allErrors utils.ValidationErrors
+	for _, e := range utils.ValidateUsername(name) {
+		allErrors = append(allErrors, utils.ValidationError{Field: "name", Message: e.Message})
+	}
+
+	strategy, ok := models.ParseBotStrategy(r.FormValue("strategy"))
+	if !ok {
+		allErrors = append(allErrors, utils.ValidationError{Field: "strategy", Message: "Unknown bot strategy"})
+	}
+	if strategy == models.BotFixed {
+		for _, e := range utils.ValidateVoteValue(fixedValue, session.Deck()) {
+			allErrors = append(allErrors, utils.ValidationError{Field: "fixed_value", Message: e.Message})
+		}
+	} else {
+		fixedValue = ""
+	}
+
+	delay := defaultBotDelay
+	if value := r.FormValue("delay_seconds"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > maxBotDelay {
+			allErrors = append(allErrors, utils.ValidationError{
+				Field:   "delay_seconds",
+				Message: fmt.Sprintf("Delay must be between 0 and %d seconds", int(maxBotDelay.Seconds())),
+			})
+		}
+		delay = time.Duration(seconds) * time.Second
+	}
+
+	if allErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, allErrors)
+		return
+	}
+
+	if h.sessionIsFull(session, "") {
+		http.Error(w, fmt.Sprintf("Session is full (%d participants)", h.config.Limits.participantLimit(session)), http.StatusForbidden)
+		return
+	}
+
+	bot, err := h.sessionService.AddBot(r.Context(), sessionID, name, strategy, fixedValue, delay)
+	if err != nil {
+		writeServiceError(w, r, "AddBot", err, "Failed to add bot")
+		return
+	}
+
+	h.broadcastState(r.Context(), sessionID, models.SSEMessage{
+		Type: "user-joined",
+		Data: bot.User,
+	})
+
+	// A bot added mid-round votes in this round too
+	if session.IsVotingActive && session.CurrentTicketID != nil {
+		h.scheduleBotVote(*bot, *session.CurrentTicketID)
+	}
+
+	finishAction(w, r, http.StatusCreated, "/session/"+sessionID)
+}
+
+func (h *Handler) RemoveBot(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	botID := chi.URLParam(r, "botID")
+
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only session owner can remove bots", http.StatusForbidden)
+		return
+	}
+
+	var bot *models.User
+	for i := range session.Participants {
+		if session.Participants[i].ID == botID && session.Participants[i].IsBot {
+			bot = &session.Participants[i]
+			break
+		}
+	}
+	if bot == nil {
+		http.Error(w, "Bot not found", http.StatusNotFound)
+		return
+	}
+
+	if _, err := h.sessionService.RemoveBot(r.Context(), sessionID, botID); err != nil {
+		writeServiceError(w, r, "RemoveBot", err, "Failed to remove bot")
+		return
+	}
+
+	h.broadcastState(r.Context(), sessionID, models.SSEMessage{
+		Type: "user-left",
+		Data: bot,
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
+
+// scheduleBotVotes has every bot in the session vote on the ticket once its
+// delay has passed. Call it whenever voting starts.
+func (h *Handler) scheduleBotVotes(ctx context.Context, sessionID string, ticketID int) {
+	bots, err := h.sessionService.GetBots(ctx, sessionID)
+	if err != nil {
+		utils.LogError("scheduleBotVotes", err)
+		return
+	}
+
+	for _, bot := range bots {
+		h.scheduleBotVote(bot, ticketID)
+	}
+}
+
+// scheduleBotVote casts a bot's vote after its delay, with up to a second
+// of jitter so several bots do not all vote at once.
+func (h *Handler) scheduleBotVote(bot models.Bot, ticketID int) {
+	delay := bot.Delay + time.Duration(rand.Intn(1000))*time.Millisecond
+	time.AfterFunc(delay, func() {
+		h.botVote(context.Background(), bot, ticketID)
+	})
+}
+
+// botVote casts a bot's vote if voting on the ticket is still going on, the
+// bot may vote on it and it has not voted yet.
+func (h *Handler) botVote(ctx context.Context, bot models.Bot, ticketID int) {
+	session, err := h.sessionService.GetSessionWithoutTickets(ctx, bot.SessionID)
+	if err != nil {
+		utils.LogError("botVote", err)
+		return
+	}
+	if session == nil || !session.IsVotingActive || session.CurrentTicket == nil || session.CurrentTicket.ID != ticketID ||
+		!session.CurrentTicket.CanVote(bot.ID) {
+		return
+	}
+
+	var humanVotes []models.Vote
+	for _, vote := range session.CurrentTicket.Votes {
+		if vote.UserID == bot.ID {
+			return
+		}
+		if !isBotParticipant(session, vote.UserID) {
+			humanVotes = append(humanVotes, vote)
+		}
+	}
+
+	value := h.botVoteValue(session, bot, humanVotes)
+	vote, err := h.votingService.SubmitVote(ctx, session.ID, bot.ID, value)
+	if err != nil {
+		// The bot may have been removed, or voting ended, since the check above
+		utils.LogError("botVote", err)
+		return
+	}
+
+	h.broadcastVoteCast(ctx, session, bot.ID, vote)
+	h.recordEvent(ctx, session.ID, services.EventVoteCast, vote.TicketID, bot.ID, nil)
+
+	if session.AutoReveal {
+		session, err = h.sessionService.GetSessionWithoutTickets(ctx, bot.SessionID)
+		if err != nil {
+			utils.LogError("botVote", err)
+		} else if session != nil {
+			h.autoReveal(ctx, session)
+		}
+	}
+}
+
+// botVoteValue picks the card a bot plays. Mimicking the median falls back
+// to a random card while no person has voted yet.
+func (h *Handler) botVoteValue(session *models.Session, bot models.Bot, humanVotes []models.Vote) string {
+	switch bot.Strategy {
+	case models.BotFixed:
+		return bot.FixedValue
+	case models.BotMimicMedian:
+		if suggested := stats.ForSession(session).Suggested(humanVotes); suggested != nil {
+			return deck.FormatValue(*suggested)
+		}
+	}
+
+	cards := deck.NumericCards(session.EstimationUnit)
+	return cards[rand.Intn(len(cards))]
+}
+
+func isBotParticipant(session *models.Session, userID string) bool {
+	for _, participant := range session.Participants {
+		if participant.ID == userID {
+			return participant.IsBot
+		}
+	}
+	return false
+}
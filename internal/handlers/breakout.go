@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+)
+
+// parseBreakout reads the breakout group a ticket is opened for voting by,
+// the user IDs of participants who may vote, in the session's participant
+// order. No IDs means the whole session votes.
+func parseBreakout(session *models.Session, userIDs []string) ([]string, utils.ValidationErrors) {
+	chosen := make(map[string]bool)
+	for _, userID := range userIDs {
+		if userID != "" {
+			chosen[userID] = true
+		}
+	}
+	if len(chosen) == 0 {
+		return nil, nil
+	}
+
+	var breakout []string
+	for _, participant := range session.Participants {
+		if chosen[participant.ID] {
+			breakout = append(breakout, participant.ID)
+			delete(chosen, participant.ID)
+		}
+	}
+	if len(chosen) > 0 {
+		return nil, utils.ValidationErrors{{Field: "breakout", Message: "Breakout voters must be session participants"}}
+	}
+	return breakout, nil
+}
+
+// breakoutNames are the usernames of the ticket's breakout group, or nil
+// when the whole session votes on it.
+func breakoutNames(session *models.Session, ticket *models.Ticket) []string {
+	if ticket == nil || len(ticket.BreakoutVoters) == 0 {
+		return nil
+	}
+
+	var names []string
+	for _, participant := range session.Participants {
+		if ticket.CanVote(participant.ID) {
+			names = append(names, participant.Username)
+		}
+	}
+	return names
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ExportSessionBundle downloads a session as a self-contained JSON bundle
+// that ImportSessionBundle recreates on any instance, e.g. to move a backlog
+// prepared on staging to production (owner only).
+func (h *Handler) ExportSessionBundle(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("ExportSessionBundle", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only the session owner can export it", http.StatusForbidden)
+		return
+	}
+
+	bundle, err := h.archiveService.ExportSession(r.Context(), session.ID)
+	if err != nil {
+		utils.LogError("ExportSessionBundle", err, utils.ReportContext{SessionID: session.ID, UserID: user.ID})
+		http.Error(w, "Failed to export session", http.StatusInternalServerError)
+		return
+	}
+	if bundle == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	filename := fmt.Sprintf("planning-poker-%s-%s.json", session.ID, time.Now().In(viewerLocation(r, user)).Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		utils.LogError("ExportSessionBundle", err, utils.ReportContext{SessionID: session.ID, UserID: user.ID})
+	}
+}
+
+// ImportSessionBundle recreates a session from a bundle written by
+// ExportSessionBundle, as a new session owned by whoever imports it. The
+// bundle is uploaded as the form's `file`, or sent as the JSON body.
+// `organization_id` optionally adds the session to one of the user's
+// organizations.
+func (h *Handler) ImportSessionBundle(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); contentType != "application/json" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			utils.WriteFormValidationError(w, r, utils.ValidationErrors{{Field: "file", Message: "Choose a session bundle of up to 5 MB"}})
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	var bundle services.Archive
+	if err := json.NewDecoder(body).Decode(&bundle); err != nil {
+		if isTooLarge(err) {
+			writeTooLarge(w, r, maxImportFileSize)
+			return
+		}
+		utils.WriteFormValidationError(w, r, utils.ValidationErrors{{Field: "file", Message: services.ErrInvalidBundle.Message}})
+		return
+	}
+
+	orgID, orgErrors, err := h.formOrganization(r, user.ID)
+	if err != nil {
+		utils.LogError("ImportSessionBundle", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to check organization")
+		return
+	}
+	if orgErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, orgErrors)
+		return
+	}
+
+	if len(bundle.Sessions) == 1 {
+		if bundleErrors := validateBundle(&bundle); bundleErrors.HasErrors() {
+			utils.WriteFormValidationError(w, r, bundleErrors)
+			return
+		}
+
+		limit := h.config.Limits.ticketLimit(&models.Session{MaxTickets: bundle.Sessions[0].MaxTickets})
+		if limit > 0 && len(bundle.Tickets) > limit {
+			utils.WriteHTMLError(w, http.StatusConflict, fmt.Sprintf("This bundle has %d tickets, more than the limit of %d", len(bundle.Tickets), limit))
+			return
+		}
+	}
+
+	sessionID, result, err := h.archiveService.ImportSessionBundle(r.Context(), &bundle, user.ID, orgID)
+	if err != nil {
+		writeServiceError(w, r, "ImportSessionBundle", err, "Failed to import session")
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "" || expectsPage(r) {
+		redirectPage(w, r, "/session/"+sessionID)
+		return
+	}
+	utils.WriteJSON(w, http.StatusCreated, map[string]interface{}{
+		"session_id": sessionID,
+		"tickets":    result.Tickets,
+		"votes":      result.Votes,
+		"users":      result.Users,
+	})
+}
+
+// validateBundle checks what a bundle would put in the session the way the
+// forms that create it by hand do: names and ticket fields, and votes and
+// estimates against the session's deck.
+func validateBundle(bundle *services.Archive) utils.ValidationErrors {
+	session := bundle.Sessions[0]
+	var allErrors utils.ValidationErrors
+	add := func(prefix string, errors utils.ValidationErrors) {
+		for _, err := range errors {
+			allErrors = append(allErrors, utils.ValidationError{Field: "file", Message: prefix + err.Message})
+		}
+	}
+
+	add("", utils.ValidateSessionName(session.Name))
+	if _, ok := deck.ParseUnit(session.EstimationUnit); !ok {
+		add("", utils.ValidationErrors{{Message: "Invalid estimation unit"}})
+	}
+	for _, user := range bundle.Users {
+		add(fmt.Sprintf("User %q: ", user.Username), utils.ValidateUsername(user.Username))
+	}
+
+	cards := (&models.Session{EstimationUnit: session.EstimationUnit}).Deck()
+	for i, ticket := range bundle.Tickets {
+		prefix := fmt.Sprintf("Ticket %d: ", i+1)
+		add(prefix, utils.ValidateTicketTitle(ticket.Title))
+		add(prefix, utils.ValidateTicketDescription(ticket.Description))
+		add(prefix, utils.ValidateDecision(ticket.Rationale, ticket.Assumptions))
+		if ticket.FinalEstimate != nil {
+			add(prefix, utils.ValidateEstimate(*ticket.FinalEstimate, cards))
+		}
+		if ticket.EstimateLow != nil || ticket.EstimateHigh != nil {
+			if ticket.EstimateLow == nil || ticket.EstimateHigh == nil {
+				add(prefix, utils.ValidationErrors{{Message: "A range needs both ends"}})
+			} else {
+				add(prefix, utils.ValidateEstimateRange(*ticket.EstimateLow, *ticket.EstimateHigh, cards))
+			}
+		}
+	}
+
+	for _, votes := range [][]services.ArchiveVote{bundle.Votes, bundle.VoteRounds} {
+		for _, vote := range votes {
+			if !cards.IsValid(vote.VoteValue) {
+				add("", utils.ValidationErrors{{Message: fmt.Sprintf("%q is not a card of the session", vote.VoteValue)}})
+			}
+		}
+	}
+
+	return allErrors
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/stats"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CalibrationReference is the result of a calibration story, shown next to
+// the tickets after it so the team can size them against a known anchor.
+type CalibrationReference struct {
+	Ticket   models.Ticket
+	Estimate string // the final estimate, or else the suggested one, with its unit
+}
+
+// calibrationReferences returns the results of the session's calibration
+// stories that have been estimated, leaving out the current ticket while it
+// is the one being calibrated.
+func (h *Handler) calibrationReferences(ctx context.Context, session *models.Session) []CalibrationReference {
+	tickets, err := h.ticketService.GetCalibrationTickets(ctx, session.ID)
+	if err != nil {
+		utils.LogError("calibrationReferences", err, utils.ReportContext{SessionID: session.ID})
+		return nil
+	}
+
+	var references []CalibrationReference
+	for _, ticket := range tickets {
+		if session.CurrentTicket != nil && session.CurrentTicket.ID == ticket.ID {
+			continue
+		}
+
+		var estimate string
+		switch {
+		case ticket.FinalEstimate != nil:
+			estimate = deck.FormatCard(*ticket.FinalEstimate, session.EstimationUnit)
+		default:
+			suggested := stats.ForSession(session).Suggested(ticket.Votes)
+			if suggested == nil {
+				continue
+			}
+			estimate = deck.FormatCard(deck.FormatValue(*suggested), session.EstimationUnit)
+		}
+		references = append(references, CalibrationReference{Ticket: ticket, Estimate: estimate})
+	}
+	return references
+}
+
+// SetTicketCalibration marks a ticket as a calibration story, or back as an
+// ordinary one, with the form field calibration (owner only). Calibration
+// stories are estimated like any ticket but left out of the session's
+// statistics and velocity.
+func (h *Handler) SetTicketCalibration(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	ticketID, err := strconv.Atoi(chi.URLParam(r, "ticketID"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+
+	calibration, err := strconv.ParseBool(r.FormValue("calibration"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "calibration must be true or false")
+		return
+	}
+
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("SetTicketCalibration", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can mark calibration stories")
+		return
+	}
+
+	ticket, err := h.ticketService.GetTicketByID(r.Context(), ticketID)
+	if err != nil {
+		utils.LogError("SetTicketCalibration", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get ticket")
+		return
+	}
+	if ticket == nil || ticket.SessionID != sessionID {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Ticket not found")
+		return
+	}
+
+	if err := h.ticketService.SetCalibration(r.Context(), ticketID, calibration); err != nil {
+		writeServiceError(w, r, "SetTicketCalibration", err, "Failed to update ticket")
+		return
+	}
+	ticket.IsCalibration = calibration
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "ticket-updated",
+		Data: ticket,
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
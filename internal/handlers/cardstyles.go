@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SetCardStyles replaces how the session's cards are shown (owner only).
+// The form repeats card, color and icon for each card; cards with neither a
+// color nor an icon are left plain. reset=true goes back to the deployment's
+// styles.
+func (h *Handler) SetCardStyles(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("SetCardStyles", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can change how cards look")
+		return
+	}
+
+	var styles map[string]deck.CardStyle
+	if r.FormValue("reset") != "true" {
+		if err := r.ParseForm(); err != nil {
+			utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid form")
+			return
+		}
+
+		cards, colors, icons := r.Form["card"], r.Form["color"], r.Form["icon"]
+		styles = map[string]deck.CardStyle{}
+		for i, card := range cards {
+			var style deck.CardStyle
+			if i < len(colors) {
+				style.Color = utils.SanitizeInput(colors[i])
+			}
+			if i < len(icons) {
+				style.Icon = utils.SanitizeInput(icons[i])
+			}
+			if card == "" || style == (deck.CardStyle{}) {
+				continue
+			}
+			styles[card] = style
+		}
+		if err := deck.ValidateCardStyles(styles); err != nil {
+			utils.WriteFormValidationError(w, r, utils.ValidationErrors{{Field: "card_styles", Message: "Invalid card styles: " + err.Error()}})
+			return
+		}
+	}
+
+	if err := h.sessionService.SetCardStyles(r.Context(), sessionID, styles); err != nil {
+		utils.LogError("SetCardStyles", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		http.Error(w, "Failed to update card styles", http.StatusInternalServerError)
+		return
+	}
+	session.CustomCardStyles = styles
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "session-updated",
+		Data: session,
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
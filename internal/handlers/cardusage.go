@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+
+	"poker-planning/internal/deck"
+)
+
+const (
+	// uncertainShare is the share of votes, in percent, on a card without a
+	// number beyond which a team seems chronically unsure.
+	uncertainShare = 15
+	// anchorShare is the share of votes, in percent, on one numeric card
+	// beyond which a team seems to anchor on it.
+	anchorShare = 40
+)
+
+// CardUsage is how often a team played a card, with a hint when that looks
+// like an estimation anti-pattern.
+type CardUsage struct {
+	Card    string
+	Count   int
+	Percent int
+	Hint    string
+}
+
+// cardUsage orders a team's card counts by deck and points out cards played
+// suspiciously often. Cards the deck no longer has go last.
+func cardUsage(counts map[string]int, cards deck.Deck) []CardUsage {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	if total == 0 {
+		return nil
+	}
+
+	usage := make([]CardUsage, 0, len(counts))
+	for card, count := range counts {
+		u := CardUsage{Card: card, Count: count, Percent: count * 100 / total}
+		_, numeric := deck.NumericValue(card)
+		switch {
+		case card == deck.Abstain:
+		case !numeric && u.Percent >= uncertainShare:
+			u.Hint = fmt.Sprintf("Played in %d%% of votes: the team may often be unsure what tickets involve", u.Percent)
+		case numeric && u.Percent >= anchorShare:
+			u.Hint = fmt.Sprintf("Played in %d%% of votes: the team may be anchoring on it", u.Percent)
+		}
+		usage = append(usage, u)
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		pi, pj := cards.Order(usage[i].Card), cards.Order(usage[j].Card)
+		if (pi >= 0) != (pj >= 0) {
+			return pi >= 0
+		}
+		if pi < 0 {
+			return usage[i].Card < usage[j].Card
+		}
+		return pi < pj
+	})
+	return usage
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import "time"
+
+// Config holds deployment-level settings for the handlers.
+type Config struct {
+	Limits    Limits
+	AwayAfter time.Duration // idle time before a participant is marked away
+	// InstancePassphrase, when set, must be entered before anything else
+	InstancePassphrase string
+	// LDAP, when set, replaces the username screen with a directory sign-in
+	LDAP *LDAPAuth
+	// Tracker credentials for importing tickets; a tracker without them is
+	// not offered
+	LinearAPIKey string
+	TrelloAPIKey string
+	TrelloToken  string
+	// JiraWebhookSecret verifies the Jira webhooks that keep imported
+	// tickets in sync
+	JiraWebhookSecret string
+	// SlackSigningSecret verifies the /poker slash command
+	SlackSigningSecret string
+	// PublicURL is where participants reach the app, for links posted
+	// elsewhere; defaults to the host of the request
+	PublicURL string
+	// EmbedSecret signs the links to embeddable session widgets; without it
+	// sessions cannot be embedded
+	EmbedSecret string
+	// EmbedAncestors are the origins allowed to frame the widgets, e.g.
+	// "https://example.atlassian.net", or "*" for any site
+	EmbedAncestors string
+	// ConnectTokenSecret signs the tokens clients without cookies open the
+	// session WebSocket with
+	ConnectTokenSecret string
+}
+
+// ImportSources lists where tickets can be imported from. CSV and TSV files
+// need no setup, so they are always offered.
+func (c Config) ImportSources() []string {
+	sources := []string{"csv"}
+	if c.LinearAPIKey != "" {
+		sources = append(sources, "linear")
+	}
+	if c.TrelloAPIKey != "" && c.TrelloToken != "" {
+		sources = append(sources, "trello")
+	}
+	return sources
+}
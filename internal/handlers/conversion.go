@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// A team's conversion between points and ideal days must lie in this range.
+const (
+	minDaysPerPoint = 0.05
+	maxDaysPerPoint = 20
+)
+
+// parseDaysPerPoint reads how many ideal days a point stands for in a team.
+// An empty value removes the conversion.
+func parseDaysPerPoint(field, value string) (*float64, utils.ValidationErrors) {
+	if value == "" {
+		return nil, nil
+	}
+
+	days, err := strconv.ParseFloat(value, 64)
+	if err != nil || days < minDaysPerPoint || days > maxDaysPerPoint {
+		return nil, utils.ValidationErrors{{
+			Field:   field,
+			Message: fmt.Sprintf("Days per point must be between %s and %s", deck.FormatValue(minDaysPerPoint), deck.FormatValue(maxDaysPerPoint)),
+		}}
+	}
+
+	return &days, nil
+}
+
+// displayUnits returns the units a participant of a team session can see
+// the cards in: the session's own and, if the team converts between points
+// and days, the other one. It returns nil when there is no choice.
+func (h *Handler) displayUnits(ctx context.Context, session *models.Session) ([]deck.Unit, float64) {
+	if session.TeamID == nil {
+		return nil, 0
+	}
+
+	team, err := h.teamService.GetTeamByID(ctx, *session.TeamID)
+	if err != nil {
+		utils.LogError("displayUnits", err, utils.ReportContext{SessionID: session.ID})
+		return nil, 0
+	}
+	if team == nil || team.DaysPerPoint == nil {
+		return nil, 0
+	}
+
+	var units []deck.Unit
+	for _, unit := range deck.Units {
+		if _, ok := deck.NewConversion(session.EstimationUnit, string(unit), *team.DaysPerPoint); ok {
+			units = append(units, unit)
+		}
+	}
+	if len(units) == 0 {
+		return nil, 0
+	}
+	return append([]deck.Unit{deck.Unit(session.EstimationUnit)}, units...), *team.DaysPerPoint
+}
+
+// conversion returns how the user sees the session's cards, converted
+// into their display unit or as they are, and the units they can choose
+// from.
+func (h *Handler) conversion(ctx context.Context, session *models.Session, userID string) (deck.Conversion, []deck.Unit) {
+	units, daysPerPoint := h.displayUnits(ctx, session)
+	if units == nil {
+		return deck.Conversion{}, nil
+	}
+
+	unit, err := h.userService.DisplayUnit(ctx, session.ID, userID)
+	if err != nil {
+		utils.LogError("conversion", err, utils.ReportContext{SessionID: session.ID, UserID: userID})
+		return deck.Conversion{}, units
+	}
+
+	conversion, _ := deck.NewConversion(session.EstimationUnit, unit, daysPerPoint)
+	return conversion, units
+}
+
+// SetDisplayUnit chooses which unit the user sees the cards of a team
+// session in and votes with, from unit. The session's own unit, or an
+// empty one, shows the cards as they are; the other of points and days
+// converts them at the team's rate. Votes are always stored as the
+// session's cards.
+func (h *Handler) SetDisplayUnit(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("SetDisplayUnit", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	unit := r.FormValue("unit")
+	if unit == session.EstimationUnit {
+		unit = ""
+	}
+	if unit != "" {
+		units, _ := h.displayUnits(r.Context(), session)
+		allowed := false
+		for _, displayUnit := range units {
+			allowed = allowed || string(displayUnit) == unit
+		}
+		if !allowed {
+			utils.WriteHTMLError(w, http.StatusBadRequest, "The team has no conversion into this unit")
+			return
+		}
+	}
+
+	if err := h.userService.SetDisplayUnit(r.Context(), sessionID, user.ID, unit); err != nil {
+		writeServiceError(w, r, "SetDisplayUnit", err, "Failed to set display unit")
+		return
+	}
+
+	// Only the user's own view changes
+	w.Header().Set("HX-Refresh", "true")
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// corsMaxAge is how long, in seconds, browsers may cache a preflight.
+const corsMaxAge = 600
+
+// CORSConfig says which other sites' pages may call an API from the browser.
+type CORSConfig struct {
+	// AllowedOrigins are origins such as "https://app.example.com", or "*"
+	// for any site
+	AllowedOrigins []string
+	// AllowCredentials lets the pages send cookies and HTTP authentication
+	AllowCredentials bool
+	// AllowedHeaders are the request headers the pages may set
+	AllowedHeaders []string
+}
+
+// ParseCORSConfig reads a comma-separated list of origins and of headers.
+// Credentials cannot be allowed for any site, since that would let every
+// page on the web act as the signed-in user.
+func ParseCORSConfig(origins, headers string, credentials bool) (CORSConfig, error) {
+	config := CORSConfig{AllowCredentials: credentials}
+	for _, origin := range strings.Split(origins, ",") {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin == "" {
+			continue
+		}
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			return CORSConfig{}, fmt.Errorf("origin %q must start with http:// or https://", origin)
+		}
+		if origin == "*" && credentials {
+			return CORSConfig{}, fmt.Errorf("credentials cannot be allowed for any origin")
+		}
+		config.AllowedOrigins = append(config.AllowedOrigins, origin)
+	}
+	for _, header := range strings.Split(headers, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			config.AllowedHeaders = append(config.AllowedHeaders, http.CanonicalHeaderKey(header))
+		}
+	}
+	return config, nil
+}
+
+func (c CORSConfig) allows(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS lets the allowed origins call the routes it wraps from the browser.
+// It answers preflight requests itself, before any token check, as browsers
+// send them without credentials. Requests from other origins get no CORS
+// headers, so browsers keep their pages from reading the responses.
+func CORS(config CORSConfig) func(http.Handler) http.Handler {
+	headers := strings.Join(config.AllowedHeaders, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			header.Add("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			if origin == "" || !config.allows(origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header.Set("Access-Control-Allow-Origin", origin)
+			if config.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				header.Set("Access-Control-Allow-Methods", "GET, POST, DELETE")
+				if headers != "" {
+					header.Set("Access-Control-Allow-Headers", headers)
+				}
+				header.Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"net/http"
+	"runtime"
+	"strings"
+	"time"
+
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+)
+
+// RequireDebugToken only lets requests through that carry the configured
+// token as "Authorization: Bearer <token>". The debug endpoints expose
+// internals, so they are never mounted without one.
+func RequireDebugToken(token string) func(http.Handler) http.Handler {
+	return requireBearerToken(token)
+}
+
+// requireBearerToken rejects requests whose Authorization header does not
+// carry the token as a bearer token.
+func requireBearerToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RuntimeStats is a snapshot of what is usually behind a hang: goroutines
+// piling up, the WebSocket hub, or the database pool running dry.
+type RuntimeStats struct {
+	StartedAt   time.Time      `json:"started_at"`
+	Uptime      string         `json:"uptime"`
+	Goroutines  int            `json:"goroutines"`
+	HeapAlloc   uint64         `json:"heap_alloc_bytes"`
+	HeapObjects uint64         `json:"heap_objects"`
+	NumGC       uint32         `json:"num_gc"`
+	WSClients   int            `json:"ws_clients"`
+	WSSessions  map[string]int `json:"ws_sessions"` // session ID -> open connections
+	DBPool      sql.DBStats    `json:"db_pool"`
+}
+
+var startedAt = time.Now()
+
+// GetRuntimeStats serves RuntimeStats as JSON. Mount it behind
+// RequireDebugToken.
+func GetRuntimeStats(db *sql.DB, wsService *services.WSService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		sessions := wsService.ClientCounts()
+		clients := 0
+		for _, count := range sessions {
+			clients += count
+		}
+
+		utils.WriteJSON(w, http.StatusOK, RuntimeStats{
+			StartedAt:   startedAt,
+			Uptime:      time.Since(startedAt).Round(time.Second).String(),
+			Goroutines:  runtime.NumGoroutine(),
+			HeapAlloc:   mem.HeapAlloc,
+			HeapObjects: mem.HeapObjects,
+			NumGC:       mem.NumGC,
+			WSClients:   clients,
+			WSSessions:  sessions,
+			DBPool:      db.Stats(),
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/stats"
+	"poker-planning/internal/utils"
+)
+
+const (
+	minDelphiRounds    = 2
+	maxDelphiRounds    = 10
+	minDelphiAgreement = 50
+	// delphiPause is how long a Delphi round's results stay up before the
+	// next round starts by itself.
+	delphiPause = 20 * time.Second
+)
+
+// Phases a Delphi round can end in
+const (
+	delphiNextRound = "next-round" // another round starts after delphiPause
+	delphiConverged = "converged"  // enough votes agreed
+	delphiMaxRounds = "max-rounds" // the ticket had all its rounds
+)
+
+// DelphiResult is what a round of a Delphi session reveals: the aggregate
+// of its votes, without who voted what, and what happens next.
+type DelphiResult struct {
+	APIRound
+	MaxRounds   int        `json:"max_rounds"`
+	Agreement   int        `json:"agreement_needed"` // percent
+	Phase       string     `json:"phase"`
+	NextRoundAt *time.Time `json:"next_round_at"` // when the next round starts, for the next-round phase
+}
+
+// AgreementPercent is the share of the round's votes on its most played
+// card, in percent.
+func (d DelphiResult) AgreementPercent() int {
+	return int(math.Round(d.Consensus.Agreement * 100))
+}
+
+// parseDelphiMaxRounds reads how many rounds a ticket gets in Delphi mode.
+// An empty value turns Delphi mode off.
+func parseDelphiMaxRounds(field, value string) (*int, utils.ValidationErrors) {
+	if value == "" {
+		return nil, nil
+	}
+
+	rounds, err := strconv.Atoi(value)
+	if err != nil || rounds < minDelphiRounds || rounds > maxDelphiRounds {
+		return nil, utils.ValidationErrors{{
+			Field:   field,
+			Message: fmt.Sprintf("Delphi rounds must be between %d and %d", minDelphiRounds, maxDelphiRounds),
+		}}
+	}
+
+	return &rounds, nil
+}
+
+// parseDelphiAgreement reads the percentage of votes that must land on one
+// card for Delphi rounds to stop.
+func parseDelphiAgreement(field, value string) (int, utils.ValidationErrors) {
+	percent, err := strconv.Atoi(value)
+	if err != nil || percent < minDelphiAgreement || percent > 100 {
+		return 0, utils.ValidationErrors{{
+			Field:   field,
+			Message: fmt.Sprintf("Agreement must be between %d and 100 percent", minDelphiAgreement),
+		}}
+	}
+
+	return percent, nil
+}
+
+// delphiResult sums up the revealed round on a Delphi session's current
+// ticket, or returns nil outside Delphi mode, before the ticket's votes
+// are revealed, or if it cannot be loaded.
+func (h *Handler) delphiResult(ctx context.Context, session *models.Session, votes []models.Vote) *DelphiResult {
+	if !session.IsDelphi() || session.IsVotingActive || session.CurrentTicket == nil || session.CurrentTicket.RevealedAt == nil {
+		return nil
+	}
+
+	round, err := h.votingService.CurrentRound(ctx, session.CurrentTicket.ID)
+	if err != nil {
+		utils.LogError("delphiResult", err, utils.ReportContext{SessionID: session.ID})
+		return nil
+	}
+
+	distribution := make(map[string]int)
+	for _, vote := range votes {
+		distribution[vote.VoteValue]++
+	}
+	result := &DelphiResult{
+		APIRound:  APIRound{Round: round, Distribution: distribution, Consensus: stats.ForSession(session).Consensus(votes)},
+		MaxRounds: *session.DelphiMaxRounds,
+		Agreement: session.DelphiAgreement,
+	}
+
+	switch {
+	case len(votes) > 0 && result.AgreementPercent() >= session.DelphiAgreement:
+		result.Phase = delphiConverged
+	case round >= result.MaxRounds:
+		result.Phase = delphiMaxRounds
+	default:
+		result.Phase = delphiNextRound
+		next := session.CurrentTicket.RevealedAt.Add(delphiPause)
+		result.NextRoundAt = &next
+	}
+	return result
+}
+
+// scheduleDelphiRound starts the next round on a Delphi session's current
+// ticket once its results have been up for delphiPause, unless the session
+// has changed since.
+func (h *Handler) scheduleDelphiRound(session *models.Session) {
+	sessionID, ticketID, updatedAt := session.ID, *session.CurrentTicketID, session.UpdatedAt
+	time.AfterFunc(delphiPause, func() {
+		h.startDelphiRound(context.Background(), sessionID, ticketID, updatedAt)
+	})
+}
+
+// startDelphiRound archives the votes of the round that ended and opens the
+// next one, if the session is still showing that round's results.
+func (h *Handler) startDelphiRound(ctx context.Context, sessionID string, ticketID int, updatedAt time.Time) {
+	session, err := h.sessionService.GetSessionWithoutTickets(ctx, sessionID)
+	if err != nil {
+		utils.LogError("startDelphiRound", err, utils.ReportContext{SessionID: sessionID})
+		return
+	}
+	if session == nil || !session.IsDelphi() || session.IsVotingActive || session.CurrentTicket == nil ||
+		session.CurrentTicket.ID != ticketID || !session.UpdatedAt.Equal(updatedAt) {
+		return
+	}
+
+	// Losing a race to the owner means they moved on themselves, and a
+	// refusal that the ticket now lacks required fields leaves it to them
+	err = h.votingService.StartVoting(ctx, session, ticketID, true, session.CurrentTicket.BreakoutVoters)
+	if errors.Is(err, services.ErrSessionModified) || errors.Is(err, services.ErrValidation) {
+		return
+	}
+	if err != nil {
+		utils.LogError("startDelphiRound", err, utils.ReportContext{SessionID: sessionID})
+		return
+	}
+	session.CurrentTicket.Votes = nil
+
+	round, err := h.votingService.CurrentRound(ctx, ticketID)
+	if err != nil {
+		utils.LogError("startDelphiRound", err, utils.ReportContext{SessionID: sessionID})
+	}
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "voting-started",
+		Data: session.CurrentTicket,
+	})
+	h.recordEvent(ctx, sessionID, services.EventVotingStarted, ticketID, "", map[string]interface{}{"revote": true, "delphi_round": round})
+	h.scheduleBotVotes(ctx, sessionID, ticketID)
+	h.scheduleVotingTimeout(session)
+	h.pushVotingStarted(session, session.CurrentTicket, "")
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/stats"
+	"poker-planning/internal/utils"
+)
+
+// HistogramBar is how many votes a card got, for clients that draw the
+// histogram themselves.
+type HistogramBar struct {
+	Card    string `json:"card"`
+	Count   int    `json:"count"`
+	Percent int    `json:"percent"`
+}
+
+// sessionPhase is where voting on the current ticket is: idle, voting or
+// revealed.
+func sessionPhase(session *models.Session) string {
+	switch {
+	case session.CurrentTicket == nil:
+		return "idle"
+	case session.IsVotingActive:
+		return "voting"
+	case len(session.CurrentTicket.Votes) > 0:
+		return "revealed"
+	}
+	return "idle"
+}
+
+// votedIDs are the participants with a vote on the current ticket.
+func votedIDs(session *models.Session) []string {
+	voted := []string{}
+	if session.CurrentTicket != nil {
+		for _, vote := range session.CurrentTicket.Votes {
+			voted = append(voted, vote.UserID)
+		}
+	}
+	return voted
+}
+
+// stateHash fingerprints the parts of a session that change while it is
+// played, so that clients which apply broadcasts as deltas can tell when
+// they drifted and reload. It is the FNV-1a (32-bit, hex) hash of
+// "ticket|phase|voters|participants": the current ticket's ID or empty,
+// the phase, and the user IDs of who voted and of the participants, each
+// sorted and comma-separated.
+func stateHash(session *models.Session) string {
+	ticket := ""
+	if session.CurrentTicket != nil {
+		ticket = strconv.Itoa(session.CurrentTicket.ID)
+	}
+
+	voters := votedIDs(session)
+	sort.Strings(voters)
+	participants := make([]string, 0, len(session.Participants))
+	for _, participant := range session.Participants {
+		participants = append(participants, participant.ID)
+	}
+	sort.Strings(participants)
+
+	hash := fnv.New32a()
+	fmt.Fprintf(hash, "%s|%s|%s|%s", ticket, sessionPhase(session), strings.Join(voters, ","), strings.Join(participants, ","))
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
+
+// voteHistogram counts revealed votes by card, in deck order.
+func (h *Handler) voteHistogram(votes []models.Vote, cards stats.Deck) []HistogramBar {
+	bars := []HistogramBar{}
+	for _, count := range cards.Histogram(votes) {
+		bars = append(bars, HistogramBar{Card: count.Value, Count: count.Count, Percent: count.Percentage})
+	}
+	return bars
+}
+
+// broadcastState broadcasts a change to a session, stamped with the state
+// hash of the session after it. A session that cannot be loaded is
+// broadcast without one, which tells clients to reload.
+func (h *Handler) broadcastState(ctx context.Context, sessionID string, message models.SSEMessage) {
+	session, err := h.sessionService.GetSessionWithoutTickets(ctx, sessionID)
+	if err != nil {
+		utils.LogError("broadcastState "+message.Type, err, utils.ReportContext{SessionID: sessionID})
+	} else if session != nil {
+		message.StateHash = stateHash(session)
+	}
+	h.wsService.Broadcast(sessionID, message)
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/stats"
+	"poker-planning/internal/utils"
+)
+
+// demoTickets seed the demo session. Size is the card most bots lean
+// towards, so reveals show a believable spread around it.
+var demoTickets = []struct {
+	title       string
+	description string
+	size        string
+}{
+	{"Login page", "Username and password form with validation and error states", "3"},
+	{"Password reset email", "Send a one-time reset link that expires after an hour", "5"},
+	{"Dark mode", "Respect the system setting and allow overriding it per user", "3"},
+	{"CSV export of reports", "Export the monthly report table, including filters", "2"},
+	{"Migrate billing to new provider", "Move subscriptions and invoices without double charging anyone", "13"},
+	{"Search autocomplete", "Suggest matching projects while typing in the search box", "8"},
+	{"Audit log", "Record who changed what in the admin area", "5"},
+	{"Fix typo on pricing page", "", "1"},
+}
+
+var demoParticipants = []string{"Ada", "Grace", "Linus", "Margaret", "Ken"}
+
+const demoFacilitator = "Demo Facilitator"
+
+// demo is the state of the running demo session.
+type demo struct {
+	sessionID     string
+	facilitatorID string
+	bots          map[string]bool
+	sizes         map[int]string // ticket ID -> size bots lean towards
+}
+
+// SeedDemo creates a public demo session with sample tickets and fake
+// participants, owned by a fake facilitator, and returns its ID. Call it
+// before serving requests, then run RunDemo to bring it to life.
+func (h *Handler) SeedDemo(ctx context.Context) (string, error) {
+	facilitator, err := h.userService.CreateUser(ctx, demoFacilitator)
+	if err != nil {
+		return "", err
+	}
+
+	session, err := h.sessionService.CreateSession(ctx, "Demo: Sprint Planning", facilitator.ID, string(deck.DefaultUnit), nil)
+	if err != nil {
+		return "", err
+	}
+
+	session.IsPublic = true
+	if err := h.sessionService.UpdateSessionSettings(ctx, session); err != nil {
+		return "", err
+	}
+
+	d := &demo{
+		sessionID:     session.ID,
+		facilitatorID: facilitator.ID,
+		bots:          make(map[string]bool),
+		sizes:         make(map[int]string),
+	}
+
+	for _, name := range demoParticipants {
+		// Registered as bots so they show as present, but RunDemo casts
+		// their votes itself since nobody starts voting through the API
+		bot, err := h.sessionService.AddBot(ctx, session.ID, name, models.BotRandom, "", 0)
+		if err != nil {
+			return "", err
+		}
+		d.bots[bot.ID] = true
+	}
+
+	for _, seed := range demoTickets {
+		ticket, err := h.ticketService.CreateTicket(ctx, session.ID, seed.title, seed.description, "")
+		if err != nil {
+			return "", err
+		}
+		d.sizes[ticket.ID] = seed.size
+	}
+
+	h.demo = d
+	return session.ID, nil
+}
+
+// RunDemo plays the facilitator and the bots of the demo session: it starts
+// voting on each ticket, lets the bots' votes trickle in, reveals, accepts
+// the suggested estimate and moves on, starting over once every ticket is
+// estimated. Visitors can join and vote along. It blocks until ctx is done,
+// so run it in its own goroutine.
+func (h *Handler) RunDemo(ctx context.Context) {
+	if h.demo == nil {
+		return
+	}
+
+	for {
+		delay := h.demoStep(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// demoStep takes the next action in the demo session and returns how long
+// to wait before the one after it.
+func (h *Handler) demoStep(ctx context.Context) time.Duration {
+	d := h.demo
+
+	session, err := h.sessionService.GetSessionByID(ctx, d.sessionID)
+	if err != nil {
+		utils.LogError("RunDemo", err)
+		return 10 * time.Second
+	}
+	if session == nil {
+		// Someone deleted it; nothing left to drive
+		return time.Hour
+	}
+
+	ticket := session.CurrentTicket
+	switch {
+	case ticket == nil || ticket.FinalEstimate != nil:
+		h.demoNextTicket(ctx, session)
+		return 3 * time.Second
+
+	case session.IsVotingActive:
+		voted := make(map[string]bool)
+		for _, vote := range ticket.Votes {
+			voted[vote.UserID] = true
+		}
+
+		var pending []string
+		for botID := range d.bots {
+			if !voted[botID] {
+				pending = append(pending, botID)
+			}
+		}
+
+		if len(pending) == 0 {
+			h.demoReveal(ctx, session)
+			return 8 * time.Second
+		}
+
+		h.demoVote(ctx, session, pending[rand.Intn(len(pending))])
+		return time.Second + time.Duration(rand.Intn(3000))*time.Millisecond
+
+	case len(ticket.Votes) == 0:
+		if err := h.votingService.StartVoting(ctx, session, ticket.ID, false, nil); err != nil {
+			utils.LogError("RunDemo", err)
+			return 5 * time.Second
+		}
+		h.wsService.Broadcast(session.ID, models.SSEMessage{
+			Type: "voting-started",
+			Data: ticket,
+		})
+		return 2 * time.Second
+
+	default:
+		estimate := "?"
+		if suggested := stats.ForSession(session).Suggested(ticket.Votes); suggested != nil {
+			estimate = deck.FormatValue(*suggested)
+		}
+		low, high := rangeCards(stats.VoteRange(ticket.Votes))
+		if err := h.ticketService.SetFinalEstimate(ctx, ticket.ID, estimate, low, high, "", ""); err != nil {
+			utils.LogError("RunDemo", err)
+			return 5 * time.Second
+		}
+		ticket.FinalEstimate = &estimate
+		ticket.EstimateLow, ticket.EstimateHigh = low, high
+		h.wsService.Broadcast(session.ID, models.SSEMessage{
+			Type: "ticket-updated",
+			Data: ticket,
+		})
+		return 3 * time.Second
+	}
+}
+
+// demoNextTicket selects the first ticket without an estimate, clearing
+// every estimate and vote first if there is none left.
+func (h *Handler) demoNextTicket(ctx context.Context, session *models.Session) {
+	var next *models.Ticket
+	for i := range session.Tickets {
+		if session.Tickets[i].FinalEstimate == nil {
+			next = &session.Tickets[i]
+			break
+		}
+	}
+
+	if next == nil && len(session.Tickets) > 0 {
+		for i := range session.Tickets {
+			ticket := &session.Tickets[i]
+			if err := h.ticketService.ClearFinalEstimate(ctx, ticket.ID); err != nil {
+				utils.LogError("RunDemo", err)
+				return
+			}
+			if err := h.votingService.ClearVotesForTicket(ctx, ticket.ID); err != nil {
+				utils.LogError("RunDemo", err)
+				return
+			}
+			ticket.FinalEstimate = nil
+			ticket.EstimateLow, ticket.EstimateHigh = nil, nil
+			ticket.Votes = nil
+		}
+		next = &session.Tickets[0]
+	}
+
+	if next == nil {
+		return
+	}
+
+	session.CurrentTicketID = &next.ID
+	session.IsVotingActive = false
+	if err := h.sessionService.UpdateSession(ctx, session); err != nil {
+		utils.LogError("RunDemo", err)
+		return
+	}
+
+	h.wsService.Broadcast(session.ID, models.SSEMessage{
+		Type: "ticket-changed",
+		Data: next,
+	})
+}
+
+// demoVote casts a bot's vote: usually the ticket's size, sometimes a
+// neighbouring card, and now and then a question mark.
+func (h *Handler) demoVote(ctx context.Context, session *models.Session, botID string) {
+	cards := deck.NumericCards(session.EstimationUnit)
+	value := "?"
+	if size, ok := h.demo.sizes[session.CurrentTicket.ID]; ok && rand.Intn(10) > 0 {
+		value = size
+		index := deck.Deck(cards).Order(size)
+		if roll := rand.Intn(10); index >= 0 && roll < 3 {
+			index += roll%2*2 - 1 // one card either way
+			if index >= 0 && index < len(cards) {
+				value = cards[index]
+			}
+		}
+	}
+
+	vote, err := h.votingService.SubmitVote(ctx, session.ID, botID, value)
+	if err != nil {
+		utils.LogError("RunDemo", err)
+		return
+	}
+
+	h.wsService.Touch(session.ID, botID)
+	h.wsService.Broadcast(session.ID, models.SSEMessage{
+		Type: "vote-cast",
+		Data: map[string]interface{}{
+			"user_id": botID,
+			"vote":    vote,
+		},
+	})
+}
+
+func (h *Handler) demoReveal(ctx context.Context, session *models.Session) {
+	if err := h.votingService.EndVoting(ctx, session); err != nil {
+		utils.LogError("RunDemo", err)
+		return
+	}
+
+	votes, err := h.votingService.GetVotesForTicket(ctx, session.CurrentTicket.ID)
+	if err != nil {
+		utils.LogError("RunDemo", err)
+		return
+	}
+
+	h.wsService.Broadcast(session.ID, models.SSEMessage{
+		Type: "voting-ended",
+		Data: map[string]interface{}{
+			"ticket":            session.CurrentTicket,
+			"votes":             votes,
+			"vote_change_until": session.VoteChangeDeadline(),
+		},
+	})
+	h.promptDiscussion(session.ID, session.CurrentTicket.ID, votes)
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"poker-planning/internal/description"
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// templateRows is how many section rows the form for a new template shows.
+const templateRows = 6
+
+// sessionTemplates returns the description templates of a team session's
+// team, for its owner to write tickets from.
+func (h *Handler) sessionTemplates(ctx context.Context, session *models.Session, userID string) []models.DescriptionTemplate {
+	if session.TeamID == nil || session.OwnerID != userID {
+		return nil
+	}
+
+	templates, err := h.teamService.GetDescriptionTemplates(ctx, *session.TeamID)
+	if err != nil {
+		utils.LogError("sessionTemplates", err, utils.ReportContext{SessionID: session.ID, UserID: userID})
+		return nil
+	}
+	return templates
+}
+
+// checkTemplate checks a new ticket's description against the team template
+// it was written from, the form's template_id, if any.
+func (h *Handler) checkTemplate(r *http.Request, session *models.Session, text string) (utils.ValidationErrors, error) {
+	value := r.FormValue("template_id")
+	if value == "" {
+		return nil, nil
+	}
+
+	invalid := utils.ValidationErrors{{Field: "template_id", Message: "Choose one of the team's templates"}}
+	templateID, err := strconv.Atoi(value)
+	if err != nil || session.TeamID == nil {
+		return invalid, nil
+	}
+	template, err := h.teamService.GetDescriptionTemplate(r.Context(), *session.TeamID, templateID)
+	if err != nil {
+		return nil, err
+	}
+	if template == nil {
+		return invalid, nil
+	}
+
+	if missing := description.Missing(template.Sections, text); len(missing) > 0 {
+		return utils.ValidationErrors{{
+			Field:   "description",
+			Message: "Fill in the " + strings.Join(missing, ", ") + " section(s) of the " + template.Name + " template",
+		}}, nil
+	}
+	return nil, nil
+}
+
+// GetTeamTemplates returns a team's description templates as JSON.
+func (h *Handler) GetTeamTemplates(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	team, _, ok := h.getMemberTeam(w, r, user)
+	if !ok {
+		return
+	}
+
+	templates, err := h.teamService.GetDescriptionTemplates(r.Context(), team.ID)
+	if err != nil {
+		utils.LogError("GetTeamTemplates", err, utils.ReportContext{UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get description templates")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, templates)
+}
+
+// AddTeamTemplate adds a description template to a team. The form has its
+// template_name, repeats section for each section title, in order, and
+// lists the indexes of the required sections as required. Sections with an
+// empty title are skipped.
+func (h *Handler) AddTeamTemplate(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	team, canEdit, ok := h.getMemberTeam(w, r, user)
+	if !ok {
+		return
+	}
+	if !canEdit {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only team members can add description templates")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid form")
+		return
+	}
+	name := utils.SanitizeInput(r.FormValue("template_name"))
+	required := formIndexes(r, "required")
+
+	var sections []description.Section
+	for i, title := range r.Form["section"] {
+		section := description.Section{Title: utils.SanitizeInput(title), Required: required[i]}
+		if section.Title != "" {
+			sections = append(sections, section)
+		}
+	}
+
+	validationErrors := utils.ValidateTemplateName(name)
+	if err := description.Validate(sections); err != nil {
+		validationErrors = append(validationErrors, utils.ValidationError{Field: "section", Message: "Invalid sections: " + err.Error()})
+	}
+	if validationErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, validationErrors)
+		return
+	}
+
+	template, err := h.teamService.AddDescriptionTemplate(r.Context(), team.ID, name, sections, user.ID)
+	if err != nil {
+		writeServiceError(w, r, "AddTeamTemplate", err, "Failed to add description template")
+		return
+	}
+
+	if expectsPage(r) {
+		http.Redirect(w, r, "/org/"+team.OrganizationID+"/teams/"+team.ID, http.StatusSeeOther)
+		return
+	}
+	utils.WriteJSON(w, http.StatusCreated, template)
+}
+
+// DeleteTeamTemplate removes one of a team's description templates.
+func (h *Handler) DeleteTeamTemplate(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	team, canEdit, ok := h.getMemberTeam(w, r, user)
+	if !ok {
+		return
+	}
+	if !canEdit {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only team members can remove description templates")
+		return
+	}
+
+	templateID, err := strconv.Atoi(chi.URLParam(r, "templateID"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid description template ID")
+		return
+	}
+
+	if err := h.teamService.DeleteDescriptionTemplate(r.Context(), team.ID, templateID); err != nil {
+		writeServiceError(w, r, "DeleteTeamTemplate", err, "Failed to delete description template")
+		return
+	}
+
+	finishAction(w, r, http.StatusNoContent, "/org/"+team.OrganizationID+"/teams/"+team.ID)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DiscussTicket highlights a ticket for discussion without making it the
+// voting ticket, so the team can read ahead while a vote wraps up (owner
+// only).
+func (h *Handler) DiscussTicket(w http.ResponseWriter, r *http.Request) {
+	ticketID, err := strconv.Atoi(chi.URLParam(r, "ticketID"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+	h.setDiscussingTicket(w, r, &ticketID)
+}
+
+// ClearDiscussingTicket removes the discussion highlight (owner only).
+func (h *Handler) ClearDiscussingTicket(w http.ResponseWriter, r *http.Request) {
+	h.setDiscussingTicket(w, r, nil)
+}
+
+func (h *Handler) setDiscussingTicket(w http.ResponseWriter, r *http.Request, ticketID *int) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("setDiscussingTicket", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can pick the ticket to discuss")
+		return
+	}
+
+	if err := h.sessionService.SetDiscussingTicket(r.Context(), sessionID, ticketID); err != nil {
+		writeServiceError(w, r, "setDiscussingTicket", err, "Failed to set discussing ticket")
+		return
+	}
+
+	var ticket *models.Ticket
+	if ticketID != nil {
+		ticket, err = h.ticketService.GetTicketByID(r.Context(), *ticketID)
+		if err != nil {
+			utils.LogError("setDiscussingTicket", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		}
+	}
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "discussing-changed",
+		Data: map[string]interface{}{"ticket_id": ticketID, "ticket": ticket},
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
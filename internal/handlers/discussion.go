@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+)
+
+// DiscussionPrompt names the voters at either end of a revealed round so
+// the facilitator can ask the outliers to explain their estimates first.
+type DiscussionPrompt struct {
+	TicketID int           `json:"ticket_id"`
+	Lowest   OutlierVoters `json:"lowest"`
+	Highest  OutlierVoters `json:"highest"`
+}
+
+// OutlierVoters are everyone who played the lowest or the highest card.
+type OutlierVoters struct {
+	Value     string   `json:"value"`
+	UserIDs   []string `json:"user_ids"`
+	Usernames []string `json:"usernames"`
+}
+
+func (o *OutlierVoters) add(vote models.Vote) {
+	o.Value = vote.VoteValue
+	o.UserIDs = append(o.UserIDs, vote.UserID)
+	if vote.User != nil {
+		o.Usernames = append(o.Usernames, vote.User.Username)
+	}
+}
+
+// discussionPrompt finds the lowest and highest numeric voters on a ticket,
+// leaving out advisory votes. It returns nil when the numeric votes agree,
+// since there is nobody to ask.
+func discussionPrompt(ticketID int, votes []models.Vote) *DiscussionPrompt {
+	var low, high float64
+	found := false
+	for _, vote := range votes {
+		value, ok := deck.NumericValue(vote.VoteValue)
+		if !ok || vote.Advisory {
+			continue
+		}
+		if !found || value < low {
+			low = value
+		}
+		if !found || value > high {
+			high = value
+		}
+		found = true
+	}
+	if !found || low == high {
+		return nil
+	}
+
+	prompt := &DiscussionPrompt{TicketID: ticketID}
+	for _, vote := range votes {
+		value, ok := deck.NumericValue(vote.VoteValue)
+		switch {
+		case !ok, vote.Advisory:
+		case value == low:
+			prompt.Lowest.add(vote)
+		case value == high:
+			prompt.Highest.add(vote)
+		}
+	}
+	return prompt
+}
+
+// promptDiscussion tells the session who voted lowest and highest once the
+// votes on a ticket are revealed. Call it after broadcasting voting-ended.
+func (h *Handler) promptDiscussion(sessionID string, ticketID int, votes []models.Vote) {
+	prompt := discussionPrompt(ticketID, votes)
+	if prompt == nil {
+		return
+	}
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "discussion-prompt",
+		Data: prompt,
+	})
+}
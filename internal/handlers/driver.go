@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// HandOverDriver lets the owner hand voting control (starting and ending
+// votes and picking the ticket) to a participant, e.g. while they present
+// (owner only). Handing it to the owner takes it back.
+func (h *Handler) HandOverDriver(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("HandOverDriver", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can hand over control")
+		return
+	}
+
+	driverID := r.FormValue("user_id")
+	if driverID == "" || driverID == session.OwnerID {
+		h.setDriver(w, r, session, user, nil)
+		return
+	}
+
+	var driver *models.User
+	for i := range session.Participants {
+		if session.Participants[i].ID == driverID {
+			driver = &session.Participants[i]
+			break
+		}
+	}
+	if driver == nil || driver.IsBot {
+		utils.WriteFormValidationError(w, r, utils.ValidationErrors{
+			{Field: "user_id", Message: "Control can only be handed to a participant of the session"},
+		})
+		return
+	}
+
+	h.setDriver(w, r, session, user, driver)
+}
+
+// ReclaimDriver gives voting control back to the owner. The owner can take
+// it back at any time and the driver can hand it back.
+func (h *Handler) ReclaimDriver(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("ReclaimDriver", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+	if !session.CanDrive(user.ID) {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner or the driver can give control back")
+		return
+	}
+
+	h.setDriver(w, r, session, user, nil)
+}
+
+// setDriver saves who drives the session, nil for the owner, and announces
+// it to everyone.
+func (h *Handler) setDriver(w http.ResponseWriter, r *http.Request, session *models.Session, user *models.User, driver *models.User) {
+	var driverID *string
+	if driver != nil {
+		driverID = &driver.ID
+	}
+	if err := h.sessionService.SetDriver(r.Context(), session.ID, driverID); err != nil {
+		writeServiceError(w, r, "setDriver", err, "Failed to hand over control")
+		return
+	}
+
+	data := map[string]interface{}{"driver_id": driverID, "driver": nil, "by": user.Username}
+	if driver != nil {
+		data["driver"] = driver.Username
+	}
+	h.wsService.Broadcast(session.ID, models.SSEMessage{
+		Type: "driver-changed",
+		Data: data,
+	})
+	h.recordEvent(r.Context(), session.ID, services.EventDriverChanged, 0, user.ID, map[string]interface{}{"driver_id": driverID})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+session.ID)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/stats"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// embedRefreshSeconds is how often an embedded widget polls for changes.
+// Third-party pages do not send the session cookie, so widgets cannot use
+// the session WebSocket.
+const embedRefreshSeconds = 5
+
+// EmbedView is what the embeddable widget shows: the current ticket and,
+// once revealed, its results. Who voted what stays out of it.
+type EmbedView struct {
+	Session        *models.Session
+	Ticket         *models.Ticket
+	Token          string
+	Voted          int // votes cast on the ticket so far
+	Participants   int
+	Histogram      []stats.VoteCount
+	Stats          stats.TicketStats
+	RefreshSeconds int
+}
+
+// embedToken lets whoever holds it watch a session from another site.
+func (h *Handler) embedToken(sessionID string) string {
+	mac := hmac.New(sha256.New, []byte(h.config.EmbedSecret))
+	mac.Write([]byte("embed:" + sessionID))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// embedURL is the signed widget link for a session.
+func (h *Handler) embedURL(r *http.Request, sessionID string) string {
+	return h.publicURL(r) + "/embed/session/" + sessionID + "?token=" + url.QueryEscape(h.embedToken(sessionID))
+}
+
+// EmbedSession serves a session's live estimation widget for framing in
+// wikis such as Confluence or Notion. The signed token stands in for
+// signing in, so the widget is read-only. HTMX polls return just the
+// results.
+func (h *Handler) EmbedSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+	token := r.URL.Query().Get("token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.embedToken(sessionID))) != 1 {
+		http.Error(w, "Invalid embed link", http.StatusForbidden)
+		return
+	}
+
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("EmbedSession", err, utils.ReportContext{SessionID: sessionID})
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	view := EmbedView{
+		Session:        session,
+		Ticket:         session.CurrentTicket,
+		Token:          token,
+		Participants:   len(session.Participants),
+		RefreshSeconds: embedRefreshSeconds,
+	}
+	if ticket := session.CurrentTicket; ticket != nil {
+		view.Voted = len(ticket.Votes)
+		if !session.IsVotingActive && len(ticket.Votes) > 0 {
+			view.Histogram = stats.ForSession(session).Histogram(ticket.Votes)
+			view.Stats = stats.ForSession(session).Ticket(ticket.Votes)
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if r.Header.Get("HX-Request") != "" {
+		h.executeTemplate(w, "embed-results", view)
+		return
+	}
+	h.executeTemplate(w, "embed", view)
+}
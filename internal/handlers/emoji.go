@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"poker-planning/internal/utils"
+)
+
+// GetRecentEmojis renders the current user's recently sent emojis as quick
+// picks for the reaction picker.
+func (h *Handler) GetRecentEmojis(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	emojis, err := h.userService.GetRecentEmojis(r.Context(), user.ID)
+	if err != nil {
+		utils.LogError("GetRecentEmojis", err)
+		http.Error(w, "Failed to get recent emojis", http.StatusInternalServerError)
+		return
+	}
+
+	h.executeTemplate(w, "recent-emojis", PageData{RecentEmojis: emojis})
+}
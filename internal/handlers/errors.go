@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+)
+
+// writeServiceError responds to an error returned by a service. Refusals
+// (services.Error) get the status for their kind and their own message;
+// anything else is logged under operation and reported as a 500 with
+// fallback as the message.
+func writeServiceError(w http.ResponseWriter, r *http.Request, operation string, err error, fallback string) {
+	var serviceErr *services.Error
+	if !errors.As(err, &serviceErr) {
+		utils.LogRequestError(r, operation, err)
+		http.Error(w, fallback, http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, services.ErrConflict):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrValidation):
+		status = http.StatusBadRequest
+	}
+
+	http.Error(w, serviceErr.Message, status)
+}
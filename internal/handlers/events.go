@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/stats"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// recordEvent appends to the session's event log and sends the event to any
+// hooks subscribed to it. The log is for analytics, so a failure is logged
+// and does not fail the request.
+func (h *Handler) recordEvent(ctx context.Context, sessionID, eventType string, ticketID int, userID string, data interface{}) {
+	event, err := h.eventService.RecordEvent(ctx, sessionID, eventType, ticketID, userID, data)
+	if err != nil {
+		utils.LogError("recordEvent", err, utils.ReportContext{SessionID: sessionID, UserID: userID})
+		return
+	}
+	h.deliverHooks(ctx, event)
+}
+
+// recordReveal logs the votes on a ticket as they stood when they were
+// revealed. cause is "owner", "auto" or "time-limit"; userID is the owner
+// who revealed them, if any. A Delphi session never tells who voted what, so
+// its votes are logged without voters, in random order.
+func (h *Handler) recordReveal(ctx context.Context, session *models.Session, ticketID int, userID, cause string, votes []models.Vote) {
+	type revealedVote struct {
+		UserID   string  `json:"user_id,omitempty"`
+		Value    string  `json:"value"`
+		Weight   float64 `json:"weight"`
+		Advisory bool    `json:"advisory,omitempty"`
+	}
+
+	anonymous := session.IsDelphi()
+	revealed := make([]revealedVote, 0, len(votes))
+	for _, vote := range votes {
+		voter := vote.UserID
+		if anonymous {
+			voter = ""
+		}
+		revealed = append(revealed, revealedVote{UserID: voter, Value: vote.VoteValue, Weight: stats.Weight(vote), Advisory: vote.Advisory})
+	}
+	if anonymous {
+		rand.Shuffle(len(revealed), func(i, j int) { revealed[i], revealed[j] = revealed[j], revealed[i] })
+	}
+
+	ticketStats := stats.ForSession(session).Ticket(votes)
+	data := map[string]interface{}{
+		"cause":       cause,
+		"votes":       revealed,
+		"abstentions": ticketStats.Abstentions,
+	}
+	if ticketStats.HasValues {
+		data["median"] = ticketStats.Median
+		data["mean"] = ticketStats.Mean
+	}
+
+	h.recordEvent(ctx, session.ID, services.EventVotesRevealed, ticketID, userID, data)
+}
+
+// ExportSessionEvents streams a session's event log as newline-delimited
+// JSON, one event per line, oldest first. after skips the events up to and
+// including that ID, so exports can be picked up where the last one ended.
+func (h *Handler) ExportSessionEvents(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+
+	var after int64
+	if value := r.URL.Query().Get("after"); value != "" {
+		var err error
+		after, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || after < 0 {
+			utils.WriteJSONError(w, http.StatusBadRequest, "after must be an event ID")
+			return
+		}
+	}
+
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("ExportSessionEvents", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteJSONError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	written := 0
+
+	err = h.eventService.EachEvent(r.Context(), sessionID, after, func(event models.SessionEvent) error {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+		// Flush in batches so large logs stream instead of buffering
+		if written++; written%100 == 0 && flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		utils.LogError("ExportSessionEvents", err)
+		// Once lines have gone out the status is sent and the stream just ends
+		if written == 0 {
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to export events")
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"poker-planning/internal/utils"
+)
+
+// SubmitFeedback takes a participant's 1-5 rating of the session and an
+// optional comment, asked for on the summary page once the session is
+// reviewed. Feedback is only shown aggregated, never who gave it.
+func (h *Handler) SubmitFeedback(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	session := h.participantSession(w, r, "SubmitFeedback", user)
+	if session == nil {
+		return
+	}
+
+	// A missing or malformed rating is 0, which fails validation
+	rating, _ := strconv.Atoi(r.FormValue("rating"))
+	comment := utils.SanitizeInput(r.FormValue("comment"))
+	if validationErrors := utils.ValidateFeedback(rating, comment); validationErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, validationErrors)
+		return
+	}
+
+	if err := h.sessionService.SubmitFeedback(r.Context(), session.ID, user.ID, rating, comment); err != nil {
+		writeServiceError(w, r, "SubmitFeedback", err, "Failed to save feedback")
+		return
+	}
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+session.ID+"/summary")
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// expectsPage reports whether a request is a plain form submission from a
+// browser without JavaScript, which needs a page back. HTMX and scripts
+// update the page themselves and only need the status.
+func expectsPage(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "" && strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// finishAction answers a successful action with status, or by sending a
+// browser without JavaScript to the page showing the result.
+func finishAction(w http.ResponseWriter, r *http.Request, status int, page string) {
+	if expectsPage(r) {
+		http.Redirect(w, r, page, http.StatusSeeOther)
+		return
+	}
+	w.WriteHeader(status)
+}
+
+// redirectPage sends HTMX to another page with HX-Redirect and everything else
+// with a plain redirect.
+func redirectPage(w http.ResponseWriter, r *http.Request, page string) {
+	if r.Header.Get("HX-Request") != "" {
+		w.Header().Set("HX-Redirect", page)
+		return
+	}
+	http.Redirect(w, r, page, http.StatusSeeOther)
+}
+
+// localPath returns path if it is a page on this site, and otherwise the
+// home page, so redirects taken from forms cannot send users elsewhere.
+// Browsers read a backslash as a slash, so paths with one are refused too.
+func localPath(path string) string {
+	u, err := url.Parse(path)
+	if err != nil || u.Scheme != "" || u.Host != "" || !strings.HasPrefix(path, "/") ||
+		strings.HasPrefix(path, "//") || strings.Contains(path, "\\") {
+		return "/"
+	}
+	return path
+}
+
+// MethodOverride lets HTML forms, which can only POST, reach the DELETE,
+// PATCH and PUT routes by naming the method in a "_method" field. Only
+// urlencoded bodies are read.
+func MethodOverride(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+			switch method := strings.ToUpper(r.PostFormValue("_method")); method {
+			case http.MethodDelete, http.MethodPatch, http.MethodPut:
+				r.Method = method
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
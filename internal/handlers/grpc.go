@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/pokerpb"
+	"poker-planning/internal/services"
+	"poker-planning/internal/stats"
+	"poker-planning/internal/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcEventPollInterval is how often StreamEvents checks the event log for
+// new events.
+const grpcEventPollInterval = time.Second
+
+// NewGRPCServer serves the PlanningPoker gRPC API from proto/poker/v1, guarded
+// by the same token as /api/v1, sent as "authorization: Bearer <token>"
+// metadata. Like the HTTP server it only takes calls from networks, unless
+// that is nil.
+func (h *Handler) NewGRPCServer(token string, networks []*net.IPNet) *grpc.Server {
+	check := func(ctx context.Context) error {
+		if networks != nil {
+			if p, ok := peer.FromContext(ctx); !ok || !allowedAddr(networks, p.Addr.String()) {
+				return status.Error(codes.PermissionDenied, "network not allowed")
+			}
+		}
+		return checkGRPCToken(ctx, token)
+	}
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := check(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := check(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	)
+	pokerpb.RegisterPlanningPokerServer(server, &grpcServer{h: h})
+	return server
+}
+
+func checkGRPCToken(ctx context.Context, token string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var given string
+	if values := md.Get("authorization"); len(values) > 0 {
+		given = strings.TrimPrefix(values[0], "Bearer ")
+	}
+	if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		return status.Error(codes.Unauthenticated, "invalid API token")
+	}
+	return nil
+}
+
+type grpcServer struct {
+	pokerpb.UnimplementedPlanningPokerServer
+	h *Handler
+}
+
+// getSession loads a session without its tickets, as a NotFound error when
+// it does not exist.
+func (s *grpcServer) getSession(ctx context.Context, method, sessionID string) (*models.Session, error) {
+	session, err := s.h.sessionService.GetSessionWithoutTickets(ctx, sessionID)
+	if err != nil {
+		utils.LogError(method, err, utils.ReportContext{SessionID: sessionID})
+		return nil, status.Error(codes.Internal, "failed to get session")
+	}
+	if session == nil {
+		return nil, status.Error(codes.NotFound, "session not found")
+	}
+	return session, nil
+}
+
+func (s *grpcServer) GetSession(ctx context.Context, req *pokerpb.GetSessionRequest) (*pokerpb.Session, error) {
+	session, err := s.getSession(ctx, "GRPC.GetSession", req.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &pokerpb.Session{
+		Id:             session.ID,
+		Name:           session.Name,
+		OwnerId:        session.OwnerID,
+		EstimationUnit: session.EstimationUnit,
+		IsVotingActive: session.IsVotingActive,
+		CreatedAt:      timestamppb.New(session.CreatedAt),
+	}
+	if session.CurrentTicketID != nil {
+		id := int32(*session.CurrentTicketID)
+		result.CurrentTicketId = &id
+	}
+	for _, participant := range session.Participants {
+		result.Participants = append(result.Participants, &pokerpb.Participant{
+			Id:       participant.ID,
+			Username: participant.Username,
+			IsBot:    participant.IsBot,
+			Weight:   participant.Weight,
+		})
+	}
+	return result, nil
+}
+
+func (s *grpcServer) ListTickets(ctx context.Context, req *pokerpb.ListTicketsRequest) (*pokerpb.ListTicketsResponse, error) {
+	if _, err := s.getSession(ctx, "GRPC.ListTickets", req.GetSessionId()); err != nil {
+		return nil, err
+	}
+
+	tickets, err := s.h.ticketService.GetTicketsForSession(ctx, req.GetSessionId())
+	if err != nil {
+		utils.LogError("GRPC.ListTickets", err, utils.ReportContext{SessionID: req.GetSessionId()})
+		return nil, status.Error(codes.Internal, "failed to get tickets")
+	}
+
+	result := &pokerpb.ListTicketsResponse{}
+	for i := range tickets {
+		result.Tickets = append(result.Tickets, grpcTicket(&tickets[i]))
+	}
+	return result, nil
+}
+
+// CreateTicket validates and limits tickets like the session page does, and
+// tells the session's clients about the new ticket.
+func (s *grpcServer) CreateTicket(ctx context.Context, req *pokerpb.CreateTicketRequest) (*pokerpb.Ticket, error) {
+	session, err := s.getSession(ctx, "GRPC.CreateTicket", req.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+
+	title := utils.SanitizeInput(req.GetTitle())
+	description := utils.SanitizeInput(req.GetDescription())
+	externalKey := utils.SanitizeInput(req.GetExternalKey())
+
+	var allErrors utils.ValidationErrors
+	allErrors = append(allErrors, utils.ValidateTicketTitle(title)...)
+	allErrors = append(allErrors, utils.ValidateTicketDescription(description)...)
+	allErrors = append(allErrors, utils.ValidateTicketKey(externalKey)...)
+	if allErrors.HasErrors() {
+		return nil, status.Error(codes.InvalidArgument, allErrors.Error())
+	}
+
+	if limit := s.h.config.Limits.ticketLimit(session); limit > 0 {
+		count, err := s.h.ticketService.CountTickets(ctx, session.ID, services.TicketFilterAll)
+		if err != nil {
+			utils.LogError("GRPC.CreateTicket", err, utils.ReportContext{SessionID: session.ID})
+			return nil, status.Error(codes.Internal, "failed to count tickets")
+		}
+		if count >= limit {
+			return nil, status.Error(codes.FailedPrecondition, fmt.Sprintf("this session has reached its limit of %d tickets", limit))
+		}
+	}
+
+	ticket, err := s.h.ticketService.CreateTicket(ctx, session.ID, title, description, externalKey)
+	if err != nil {
+		utils.LogError("GRPC.CreateTicket", err, utils.ReportContext{SessionID: session.ID})
+		return nil, status.Error(codes.Internal, "failed to create ticket")
+	}
+
+	s.h.wsService.Broadcast(session.ID, models.SSEMessage{
+		Type: "ticket-created",
+		Data: ticket,
+	})
+	s.h.recordEvent(ctx, session.ID, services.EventTicketCreated, ticket.ID, "", map[string]interface{}{
+		"title":        ticket.Title,
+		"external_key": ticket.ExternalKey,
+		"source":       "grpc",
+	})
+
+	return grpcTicket(ticket), nil
+}
+
+// ListVotes refuses while the ticket is being voted on, so integrations
+// cannot see votes before the team does.
+func (s *grpcServer) ListVotes(ctx context.Context, req *pokerpb.ListVotesRequest) (*pokerpb.ListVotesResponse, error) {
+	ticket, err := s.h.ticketService.GetTicketByID(ctx, int(req.GetTicketId()))
+	if err != nil {
+		utils.LogError("GRPC.ListVotes", err)
+		return nil, status.Error(codes.Internal, "failed to get ticket")
+	}
+	if ticket == nil {
+		return nil, status.Error(codes.NotFound, "ticket not found")
+	}
+
+	session, err := s.getSession(ctx, "GRPC.ListVotes", ticket.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.IsVotingActive && session.CurrentTicketID != nil && *session.CurrentTicketID == ticket.ID {
+		return nil, status.Error(codes.FailedPrecondition, "votes are hidden until they are revealed")
+	}
+
+	votes, err := s.h.votingService.GetVotesForTicket(ctx, ticket.ID)
+	if err != nil {
+		utils.LogError("GRPC.ListVotes", err, utils.ReportContext{SessionID: session.ID})
+		return nil, status.Error(codes.Internal, "failed to get votes")
+	}
+
+	result := &pokerpb.ListVotesResponse{}
+	for _, vote := range votes {
+		var username string
+		if vote.User != nil {
+			username = vote.User.Username
+		}
+		result.Votes = append(result.Votes, &pokerpb.Vote{
+			UserId:    vote.UserID,
+			Username:  username,
+			Value:     vote.VoteValue,
+			Weight:    stats.Weight(vote),
+			CreatedAt: timestamppb.New(vote.CreatedAt),
+		})
+	}
+	return result, nil
+}
+
+// StreamEvents sends the session's event log and then polls it for new
+// events until the client goes away.
+func (s *grpcServer) StreamEvents(req *pokerpb.StreamEventsRequest, stream pokerpb.PlanningPoker_StreamEventsServer) error {
+	ctx := stream.Context()
+	if _, err := s.getSession(ctx, "GRPC.StreamEvents", req.GetSessionId()); err != nil {
+		return err
+	}
+
+	after := req.GetAfterId()
+	ticker := time.NewTicker(grpcEventPollInterval)
+	defer ticker.Stop()
+
+	for {
+		err := s.h.eventService.EachEvent(ctx, req.GetSessionId(), after, func(event models.SessionEvent) error {
+			if err := stream.Send(grpcEvent(event)); err != nil {
+				return err
+			}
+			after = event.ID
+			return nil
+		})
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		if err != nil {
+			utils.LogError("GRPC.StreamEvents", err, utils.ReportContext{SessionID: req.GetSessionId()})
+			return status.Error(codes.Internal, "failed to stream events")
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func grpcTicket(ticket *models.Ticket) *pokerpb.Ticket {
+	result := &pokerpb.Ticket{
+		Id:            int32(ticket.ID),
+		SessionId:     ticket.SessionID,
+		Title:         ticket.Title,
+		Description:   ticket.Description,
+		ExternalKey:   ticket.ExternalKey,
+		ExternalUrl:   ticket.ExternalURL,
+		FinalEstimate: ticket.FinalEstimate,
+		Position:      int32(ticket.Position),
+		CreatedAt:     timestamppb.New(ticket.CreatedAt),
+	}
+	if ticket.RevealedAt != nil {
+		result.RevealedAt = timestamppb.New(*ticket.RevealedAt)
+	}
+	return result
+}
+
+func grpcEvent(event models.SessionEvent) *pokerpb.SessionEvent {
+	result := &pokerpb.SessionEvent{
+		Id:        event.ID,
+		SessionId: event.SessionID,
+		Type:      event.Type,
+		UserId:    event.UserID,
+		DataJson:  string(event.Data),
+		CreatedAt: timestamppb.New(event.CreatedAt),
+	}
+	if event.TicketID != nil {
+		id := int32(*event.TicketID)
+		result.TicketId = &id
+	}
+	return result
+}
@@ -5,35 +5,65 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
-	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"poker-planning/internal/deck"
 	"poker-planning/internal/models"
 	"poker-planning/internal/services"
+	"poker-planning/internal/stats"
 	"poker-planning/internal/utils"
 
 	"github.com/go-chi/chi/v5"
 )
 
 type Handler struct {
-	userService    *services.UserService
-	sessionService *services.SessionService
-	votingService  *services.VotingService
-	ticketService  *services.TicketService
-	wsService      *services.WSService
-	templates      *template.Template
+	userService         *services.UserService
+	sessionService      *services.SessionService
+	votingService       *services.VotingService
+	ticketService       *services.TicketService
+	projectService      *services.ProjectService
+	organizationService *services.OrganizationService
+	teamService         *services.TeamService
+	eventService        *services.EventService
+	notifyService       *services.NotifyService
+	archiveService      *services.ArchiveService
+	wsService           *services.WSService
+	config              Config
+	templates           *template.Template
+	demo                *demo // set by SeedDemo
 }
 
-func NewHandler(userService *services.UserService, sessionService *services.SessionService, votingService *services.VotingService, ticketService *services.TicketService, wsService *services.WSService) *Handler {
-	templates := template.Must(template.ParseGlob("templates/*.html"))
+func NewHandler(userService *services.UserService, sessionService *services.SessionService, votingService *services.VotingService, ticketService *services.TicketService, projectService *services.ProjectService, organizationService *services.OrganizationService, teamService *services.TeamService, eventService *services.EventService, notifyService *services.NotifyService, archiveService *services.ArchiveService, wsService *services.WSService, config Config) *Handler {
+	templates := template.Must(template.New("").Funcs(template.FuncMap{
+		"formatEstimate": deck.Format,
+		"formatCard":     deck.FormatCard,
+		"formatValue":    deck.FormatValue,
+		"isAbstain":      func(card string) bool { return card == deck.Abstain },
+		"localTime":      localTime,
+		"weightOptions":  weightOptions,
+		"advisoryVotes":  stats.Advisory,
+		"formatDuration": formatDuration,
+		// Directory users sign in with a password and keep their directory name
+		"directoryLogin": func() bool { return config.LDAP != nil },
+		"importSources":  config.ImportSources,
+	}).ParseGlob("templates/*.html"))
 	
 	return &Handler{
-		userService:    userService,
-		sessionService: sessionService,
-		votingService:  votingService,
-		ticketService:  ticketService,
-		wsService:      wsService,
-		templates:      templates,
+		userService:         userService,
+		sessionService:      sessionService,
+		votingService:       votingService,
+		ticketService:       ticketService,
+		projectService:      projectService,
+		organizationService: organizationService,
+		teamService:         teamService,
+		eventService:        eventService,
+		notifyService:       notifyService,
+		archiveService:      archiveService,
+		wsService:           wsService,
+		config:              config,
+		templates:           templates,
 	}
 }
 
@@ -44,68 +74,171 @@ type PageData struct {
 	Session         *models.Session
 	SessionName     string
 	VotingCards     []string
+	Conversion      deck.Conversion // how the viewer sees the cards; the zero value shows them as they are
+	DisplayUnits    []deck.Unit     // units the viewer can choose to see the cards in, if any
 	UserVote        *models.Vote
-	VoteHistogram   []VoteCount
+	LastVote        *string // the viewer's last vote in the session, for "same as last time"
+	Agenda          []models.AgendaItem
+	AgendaKinds     []models.AgendaKind
+	ParkingLot      []models.ParkingLotItem
+	ParkingLotKinds []models.ParkingLotKind
+	Snapshots       []models.SessionSnapshot // restore points, for the owner only
+	ActionItems     []models.ActionItem
+	Feedback        *models.FeedbackSummary // participants' ratings of the session, nil until someone rates it
+	GaveFeedback    bool                    // the viewer has rated the session
+	Calibration     []CalibrationReference // results of calibration stories, to size tickets against
+	PinnedReferences []models.ReferenceStory // team reference stories pinned next to the current ticket
+	TeamReferences   []models.ReferenceStory // the team's library, for the owner to pin from
+	DescriptionTemplates []models.DescriptionTemplate // the team's ticket description templates; in a session, for the owner only
+	TemplateRows         []int                        // section rows of the form for a new description template
+	VoteHistogram   []stats.VoteCount
 	CurrentTicketIndex int
+	SuggestedEstimate  float64 // current ticket's votes snapped to a card from the session's basis statistic
+	HasSuggestion      bool
+	WeightedSuggestion bool // some votes behind the suggestion count more than others
+	SuggestedRange     *stats.Range // middle half of the current ticket's votes, accepted with the estimate
+	SuggestionBasis    stats.Basis
+	DiscussionPrompt   *DiscussionPrompt // lowest and highest voters after reveal, nil on consensus
+	Breakout           []string          // usernames of the current ticket's breakout group, nil when everyone votes
+	Delphi             *DelphiResult     // the revealed round's aggregate in Delphi mode
+	Prevote            *APIRound         // the current ticket's pre-votes, aggregated
+	Prevotes           map[int]services.PrevoteStatus // ticket ID -> pre-votes so far and the viewer's own
+	RoundingStrategies []deck.RoundingStrategy
+	SuggestionBases    []stats.Basis
+	TicketFields       []models.TicketField // fields the owner can require before voting
+	SpecialCardRows    []SpecialCardRow // the owner's form for the session's special cards
+	BotStrategies      []models.BotStrategy
+	EstimationUnits    []deck.Unit
+	Limits             Limits // deployment-wide session caps
+	RecentEmojis       []models.RecentEmoji
+	Presence           PresenceSummary
+	OnlineUsers        map[string]bool // participant ID -> connected
+	AwayUsers          map[string]bool // participant ID -> idle
 	TicketAverages  map[int]float64 // ticket ID -> median (backward compatibility)
+	TicketHistory   map[int][]models.TicketEstimate // ticket ID -> estimates in earlier sessions
+	// Backlog paging: Session.Tickets holds one page when these are set
+	TicketCount      int
+	HasMoreTickets   bool
+	NextTicketOffset int
 	// Summary page data
 	TotalVotes       int
+	AgendaSummary    *AgendaSummary // planned vs. actual time, nil without an agenda
 	EstimatedTickets int
 	OverallAverage   float64 // overall median (backward compatibility)
-	OverallStats     TicketStats // overall median, mean, mode
-	TicketVoteGroups map[int][]VoteCount // ticket ID -> vote groups
-	ParticipantStats map[string]*ParticipantStat // user ID -> stats
-	TicketStats      map[int]TicketStats // ticket ID -> full statistics
+	OverallStats     stats.TicketStats // overall median, mean, mode
+	TicketVoteGroups map[int][]stats.VoteCount // ticket ID -> vote groups
+	ParticipantStats map[string]*stats.ParticipantStat // user ID -> stats
+	TicketStats      map[int]stats.TicketStats // ticket ID -> full statistics
+	// Project page data
+	Project           *models.Project
+	Projects          []models.Project
+	SessionVelocities []SessionVelocity
+	ProjectVelocity   []UnitVelocity // one entry per estimation unit
+	// Lobby page data
+	LobbySessions []LobbySession
+	// Organization page data
+	Organization     *models.Organization
+	OrganizationRole models.OrganizationRole // the viewer's role
+	Organizations    []models.Organization   // the viewer's organizations, for pickers
+	Teams            []models.Team
+	// Team page data, velocity is in SessionVelocities and ProjectVelocity
+	Team           *models.Team
+	IsTeamMember   bool
+	AccuracyCharts []AccuracyChart // estimates against imported actuals
+	FeedbackTrend  []models.FeedbackSummary // ratings of the team's sessions, oldest first
+	CardUsage      []CardUsage              // how often the team plays each card, in deck order
+	FacilitatorNotes *models.FacilitatorNotes // the owner's private notes; never set on pages participants see
+	ActualUnits    []string
+	// EmbedURL is the signed widget link offered to the session owner, empty
+	// when embedding is off
+	EmbedURL string
+	// Passphrase and home page data
+	RedirectTo string
+	// PushKey is the VAPID public key browsers subscribe to push
+	// notifications with, empty when push is off
+	PushKey string
+	// Location is the viewer's timezone, for localTime
+	Location *time.Location
 }
 
-type ParticipantStat struct {
-	VoteCount  int
-	MedianVote float64
-}
-
-type TicketStats struct {
-	Median    float64
-	Mean      float64
-	Mode      string
-	HasValues bool // indicates if there are numeric votes
-}
+// ticketPageSize is how many backlog tickets the session page renders up
+// front; the rest are lazy-loaded from GetTicketsPage.
+const ticketPageSize = 50
 
-type VoteCount struct {
-	Value      string
-	Count      int
-	Percentage int
-}
+const maxTicketPageSize = 200
 
 func (h *Handler) Home(w http.ResponseWriter, r *http.Request) {
 	user := GetUserFromContext(r.Context())
 	
+	var projects []models.Project
+	var organizations []models.Organization
+	if user != nil {
+		var err error
+		projects, err = h.projectService.GetProjectsForOwner(r.Context(), user.ID)
+		if err != nil {
+			utils.LogError("Home", err)
+		}
+		organizations, err = h.organizationService.GetOrganizationsForUser(r.Context(), user.ID)
+		if err != nil {
+			utils.LogError("Home", err)
+		}
+	}
+
 	data := PageData{
-		Title:    "Home",
-		Template: "home",
-		User:     user,
+		Title:           "Home",
+		Template:        "home",
+		User:            user,
+		EstimationUnits: deck.Units,
+		Projects:        projects,
+		Organizations:   organizations,
+		RedirectTo:      r.URL.Query().Get("redirect_to"),
+		PushKey:         h.notifyService.PublicKey(),
 	}
 	
 	h.executeTemplate(w, "base.html", data)
 }
 
 func (h *Handler) SetUsername(w http.ResponseWriter, r *http.Request) {
+	if h.config.LDAP != nil {
+		h.signInWithDirectory(w, r)
+		return
+	}
+
 	username := utils.SanitizeInput(r.FormValue("username"))
 	
 	if validationErrors := utils.ValidateUsername(username); validationErrors.HasErrors() {
-		utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
+		utils.WriteFormValidationError(w, r, validationErrors)
 		return
 	}
 
-	user, err := h.userService.CreateUser(username)
+	user, err := h.userService.CreateUser(r.Context(), username)
 	if err != nil {
 		utils.LogError("SetUsername", err)
 		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to create user account")
 		return
 	}
 
+	h.signIn(w, r, user)
+}
+
+// signIn gives the browser the user's session cookie and sends it back to
+// the page it signed in from. With directory sign-in the cookie holds a
+// login token rather than the user ID; see SessionMiddleware.
+func (h *Handler) signIn(w http.ResponseWriter, r *http.Request, user *models.User) {
+	value := user.ID
+	if h.config.LDAP != nil {
+		token, err := h.userService.CreateLoginSession(r.Context(), user.ID)
+		if err != nil {
+			utils.LogError("signIn", err, utils.ReportContext{UserID: user.ID})
+			utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to sign in")
+			return
+		}
+		value = token
+	}
+
 	http.SetCookie(w, &http.Cookie{
 		Name:     SessionCookieName,
-		Value:    user.ID,
+		Value:    value,
 		MaxAge:   6 * 3600, // 6 hours
 		Path:     "/",
 		HttpOnly: true,
@@ -121,6 +254,12 @@ func (h *Handler) SetUsername(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	
+	// Plain form posts are redirected by the server, so only to this site
+	if r.Header.Get("HX-Request") == "" {
+		http.Redirect(w, r, localPath(redirectTo), http.StatusSeeOther)
+		return
+	}
+	
 	if redirectTo != "" && redirectTo != "/" {
 		w.Header().Set("HX-Redirect", redirectTo)
 	} else {
@@ -137,19 +276,38 @@ func (h *Handler) CreateSession(w http.ResponseWriter, r *http.Request) {
 
 	name := utils.SanitizeInput(r.FormValue("name"))
 	
-	if validationErrors := utils.ValidateSessionName(name); validationErrors.HasErrors() {
-		utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
+	validationErrors := utils.ValidateSessionName(name)
+
+	estimationUnit := string(deck.DefaultUnit)
+	if unitStr := r.FormValue("estimation_unit"); unitStr != "" {
+		unit, ok := deck.ParseUnit(unitStr)
+		if !ok {
+			validationErrors = append(validationErrors, utils.ValidationError{Field: "estimation_unit", Message: "Invalid estimation unit"})
+		}
+		estimationUnit = string(unit)
+	}
+
+	orgID, orgErrors, err := h.formOrganization(r, user.ID)
+	if err != nil {
+		utils.LogError("CreateSession", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to check organization")
+		return
+	}
+	validationErrors = append(validationErrors, orgErrors...)
+
+	if validationErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, validationErrors)
 		return
 	}
 
-	session, err := h.sessionService.CreateSession(name, user.ID)
+	session, err := h.sessionService.CreateSession(r.Context(), name, user.ID, estimationUnit, orgID)
 	if err != nil {
 		utils.LogError("CreateSession", err)
 		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to create planning session")
 		return
 	}
 
-	w.Header().Set("HX-Redirect", "/session/"+session.ID)
+	redirectPage(w, r, "/session/"+session.ID)
 }
 
 func (h *Handler) GetSessionPartial(w http.ResponseWriter, r *http.Request) {
@@ -160,7 +318,7 @@ func (h *Handler) GetSessionPartial(w http.ResponseWriter, r *http.Request) {
 	}
 
 	sessionID := chi.URLParam(r, "sessionID")
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, ticketCount, err := h.sessionService.GetSessionWithTicketPage(r.Context(), sessionID, 0, ticketPageSize)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -185,26 +343,28 @@ func (h *Handler) GetSessionPartial(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var userVote *models.Vote
-	var voteHistogram []VoteCount
+	var voteHistogram []stats.VoteCount
 	var currentTicketIndex int
+	var suggestedEstimate float64
+	var hasSuggestion bool
+	var weightedSuggestion bool
+	var suggestionBasis stats.Basis
+	var suggestedRange *stats.Range
+	var prompt *DiscussionPrompt
+	var delphi *DelphiResult
 	
-	// Calculate medians for all tickets
+	// Calculate medians for the loaded page of tickets
 	ticketAverages := make(map[int]float64)
 	for _, ticket := range session.Tickets {
 		if len(ticket.Votes) > 0 {
-			if median := h.calculateVoteMedian(ticket.Votes); median != nil {
+			if median := stats.Median(ticket.Votes); median != nil {
 				ticketAverages[ticket.ID] = *median
 			}
 		}
 	}
 
 	if session.CurrentTicket != nil {
-		for i, ticket := range session.Tickets {
-			if ticket.ID == session.CurrentTicket.ID {
-				currentTicketIndex = i + 1
-				break
-			}
-		}
+		currentTicketIndex = h.currentTicketIndex(r.Context(), session)
 
 		for _, vote := range session.CurrentTicket.Votes {
 			if vote.UserID == user.ID {
@@ -214,21 +374,76 @@ func (h *Handler) GetSessionPartial(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if !session.IsVotingActive {
-			voteHistogram = h.calculateVoteHistogram(session.CurrentTicket.Votes)
+			cards := stats.ForSession(session)
+			voteHistogram = cards.Histogram(session.CurrentTicket.Votes)
+			if ticketStats := cards.Ticket(session.CurrentTicket.Votes); ticketStats.HasValues {
+				suggestedEstimate = ticketStats.Suggested
+				hasSuggestion = true
+				weightedSuggestion = ticketStats.Weighted
+				suggestionBasis = ticketStats.Basis
+				suggestedRange = ticketStats.Range
+			}
+			// Delphi rounds never tell who voted what
+			if session.IsDelphi() {
+				delphi = h.delphiResult(r.Context(), session, session.CurrentTicket.Votes)
+			} else {
+				prompt = discussionPrompt(session.CurrentTicket.ID, session.CurrentTicket.Votes)
+			}
 		}
 	}
 
+	presence := h.presenceSummary(session)
+
 	data := PageData{
 		Title:              session.Name,
 		Template:           "session",
 		User:               user,
 		Session:            session,
 		SessionName:        session.Name,
-		VotingCards:        models.AllVotingCards(),
 		UserVote:           userVote,
 		VoteHistogram:      voteHistogram,
 		CurrentTicketIndex: currentTicketIndex,
+		SuggestedEstimate:  suggestedEstimate,
+		HasSuggestion:      hasSuggestion,
+		WeightedSuggestion: weightedSuggestion,
+		SuggestedRange:     suggestedRange,
+		SuggestionBasis:    suggestionBasis,
+		DiscussionPrompt:   prompt,
+		Breakout:           breakoutNames(session, session.CurrentTicket),
+		Delphi:             delphi,
+		RoundingStrategies: deck.RoundingStrategies,
+		SuggestionBases:    stats.Bases(),
+		TicketFields:       models.TicketFields,
+		SpecialCardRows:    specialCardRows(session),
+		BotStrategies:      models.BotStrategies,
+		EstimationUnits:    deck.Units,
+		Limits:             h.config.Limits,
 		TicketAverages:     ticketAverages,
+		TicketHistory:      h.ticketHistory(r.Context(), session, user),
+		LastVote:           h.lastVote(r.Context(), session.ID, user.ID),
+		Agenda:             h.agenda(r.Context(), session.ID),
+		AgendaKinds:        models.AgendaKinds,
+		ParkingLot:         h.parkingLot(r.Context(), session.ID),
+		ParkingLotKinds:    models.ParkingLotKinds,
+		Snapshots:          h.snapshots(r.Context(), session, user),
+		Location:           viewerLocation(r, user),
+		Calibration:        h.calibrationReferences(r.Context(), session),
+		Prevote:            h.prevoteResult(r.Context(), session),
+		Prevotes:           h.prevoteStatus(r.Context(), session.ID, user.ID),
+		Presence:           presence,
+		OnlineUsers:        onlineUsers(presence, user.ID),
+		AwayUsers:          awayUsers(presence, user.ID),
+		TicketCount:        ticketCount,
+		HasMoreTickets:     len(session.Tickets) < ticketCount,
+		NextTicketOffset:   len(session.Tickets),
+	}
+	data.PinnedReferences, data.TeamReferences = h.sessionReferences(r.Context(), session, user.ID)
+	data.DescriptionTemplates = h.sessionTemplates(r.Context(), session, user.ID)
+	data.Conversion, data.DisplayUnits = h.conversion(r.Context(), session, user.ID)
+	data.VotingCards = data.Conversion.Cards(session.Deck())
+	if data.LastVote != nil {
+		lastVote := data.Conversion.Card(*data.LastVote)
+		data.LastVote = &lastVote
 	}
 
 	// Return only the session content, not the full page
@@ -245,7 +460,7 @@ func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	sessionID := chi.URLParam(r, "sessionID")
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, _, err := h.sessionService.GetSessionWithTicketPage(r.Context(), sessionID, 0, ticketPageSize)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -255,7 +470,21 @@ func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userJoined, err := h.sessionService.JoinSession(sessionID, user.ID)
+	// Sessions of an organization do not exist for outsiders
+	if ok, err := h.canSeeOrganization(r.Context(), session.OrganizationID, user.ID); err != nil {
+		http.Error(w, "Failed to check organization", http.StatusInternalServerError)
+		return
+	} else if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if h.sessionIsFull(session, user.ID) {
+		http.Error(w, fmt.Sprintf("Session is full (%d participants)", h.config.Limits.participantLimit(session)), http.StatusForbidden)
+		return
+	}
+
+	userJoined, err := h.sessionService.JoinSession(r.Context(), sessionID, user.ID)
 	if err != nil {
 		http.Error(w, "Failed to join session", http.StatusInternalServerError)
 		return
@@ -263,39 +492,41 @@ func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
 
 	// Only broadcast if user actually joined (wasn't already a participant)
 	if userJoined {
-		h.wsService.Broadcast(sessionID, models.SSEMessage{
+		h.broadcastState(r.Context(), sessionID, models.SSEMessage{
 			Type: "user-joined",
 			Data: user,
 		})
 	}
 
-	session, err = h.sessionService.GetSessionByID(sessionID)
+	session, ticketCount, err := h.sessionService.GetSessionWithTicketPage(r.Context(), sessionID, 0, ticketPageSize)
 	if err != nil {
 		http.Error(w, "Failed to refresh session", http.StatusInternalServerError)
 		return
 	}
 
 	var userVote *models.Vote
-	var voteHistogram []VoteCount
+	var voteHistogram []stats.VoteCount
 	var currentTicketIndex int
+	var suggestedEstimate float64
+	var hasSuggestion bool
+	var weightedSuggestion bool
+	var suggestionBasis stats.Basis
+	var suggestedRange *stats.Range
+	var prompt *DiscussionPrompt
+	var delphi *DelphiResult
 	
-	// Calculate medians for all tickets
+	// Calculate medians for the loaded page of tickets
 	ticketAverages := make(map[int]float64)
 	for _, ticket := range session.Tickets {
 		if len(ticket.Votes) > 0 {
-			if median := h.calculateVoteMedian(ticket.Votes); median != nil {
+			if median := stats.Median(ticket.Votes); median != nil {
 				ticketAverages[ticket.ID] = *median
 			}
 		}
 	}
 
 	if session.CurrentTicket != nil {
-		for i, ticket := range session.Tickets {
-			if ticket.ID == session.CurrentTicket.ID {
-				currentTicketIndex = i + 1
-				break
-			}
-		}
+		currentTicketIndex = h.currentTicketIndex(r.Context(), session)
 
 		for _, vote := range session.CurrentTicket.Votes {
 			if vote.UserID == user.ID {
@@ -305,21 +536,85 @@ func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if !session.IsVotingActive {
-			voteHistogram = h.calculateVoteHistogram(session.CurrentTicket.Votes)
+			cards := stats.ForSession(session)
+			voteHistogram = cards.Histogram(session.CurrentTicket.Votes)
+			if ticketStats := cards.Ticket(session.CurrentTicket.Votes); ticketStats.HasValues {
+				suggestedEstimate = ticketStats.Suggested
+				hasSuggestion = true
+				weightedSuggestion = ticketStats.Weighted
+				suggestionBasis = ticketStats.Basis
+				suggestedRange = ticketStats.Range
+			}
+			// Delphi rounds never tell who voted what
+			if session.IsDelphi() {
+				delphi = h.delphiResult(r.Context(), session, session.CurrentTicket.Votes)
+			} else {
+				prompt = discussionPrompt(session.CurrentTicket.ID, session.CurrentTicket.Votes)
+			}
 		}
 	}
 
+	recentEmojis, err := h.userService.GetRecentEmojis(r.Context(), user.ID)
+	if err != nil {
+		utils.LogError("GetSession", err)
+	}
+
+	presence := h.presenceSummary(session)
+
 	data := PageData{
 		Title:              session.Name,
 		Template:           "session",
 		User:               user,
 		Session:            session,
 		SessionName:        session.Name,
-		VotingCards:        models.AllVotingCards(),
 		UserVote:           userVote,
 		VoteHistogram:      voteHistogram,
 		CurrentTicketIndex: currentTicketIndex,
+		SuggestedEstimate:  suggestedEstimate,
+		HasSuggestion:      hasSuggestion,
+		WeightedSuggestion: weightedSuggestion,
+		SuggestedRange:     suggestedRange,
+		SuggestionBasis:    suggestionBasis,
+		DiscussionPrompt:   prompt,
+		Breakout:           breakoutNames(session, session.CurrentTicket),
+		Delphi:             delphi,
+		RoundingStrategies: deck.RoundingStrategies,
+		SuggestionBases:    stats.Bases(),
+		TicketFields:       models.TicketFields,
+		SpecialCardRows:    specialCardRows(session),
+		BotStrategies:      models.BotStrategies,
+		EstimationUnits:    deck.Units,
+		Limits:             h.config.Limits,
 		TicketAverages:     ticketAverages,
+		TicketHistory:      h.ticketHistory(r.Context(), session, user),
+		LastVote:           h.lastVote(r.Context(), session.ID, user.ID),
+		Agenda:             h.agenda(r.Context(), session.ID),
+		AgendaKinds:        models.AgendaKinds,
+		ParkingLot:         h.parkingLot(r.Context(), session.ID),
+		ParkingLotKinds:    models.ParkingLotKinds,
+		Snapshots:          h.snapshots(r.Context(), session, user),
+		Location:           viewerLocation(r, user),
+		Calibration:        h.calibrationReferences(r.Context(), session),
+		Prevote:            h.prevoteResult(r.Context(), session),
+		Prevotes:           h.prevoteStatus(r.Context(), session.ID, user.ID),
+		RecentEmojis:       recentEmojis,
+		Presence:           presence,
+		OnlineUsers:        onlineUsers(presence, user.ID),
+		AwayUsers:          awayUsers(presence, user.ID),
+		TicketCount:        ticketCount,
+		HasMoreTickets:     len(session.Tickets) < ticketCount,
+		NextTicketOffset:   len(session.Tickets),
+	}
+	data.PinnedReferences, data.TeamReferences = h.sessionReferences(r.Context(), session, user.ID)
+	data.DescriptionTemplates = h.sessionTemplates(r.Context(), session, user.ID)
+	data.Conversion, data.DisplayUnits = h.conversion(r.Context(), session, user.ID)
+	data.VotingCards = data.Conversion.Cards(session.Deck())
+	if data.LastVote != nil {
+		lastVote := data.Conversion.Card(*data.LastVote)
+		data.LastVote = &lastVote
+	}
+	if h.config.EmbedSecret != "" && session.OwnerID == user.ID {
+		data.EmbedURL = h.embedURL(r, session.ID)
 	}
 
 	h.executeTemplate(w, "base.html", data)
@@ -333,8 +628,31 @@ func (h *Handler) JoinSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	// Sessions of an organization do not exist for outsiders
+	if ok, err := h.canSeeOrganization(r.Context(), session.OrganizationID, user.ID); err != nil {
+		http.Error(w, "Failed to check organization", http.StatusInternalServerError)
+		return
+	} else if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if h.sessionIsFull(session, user.ID) {
+		http.Error(w, fmt.Sprintf("Session is full (%d participants)", h.config.Limits.participantLimit(session)), http.StatusForbidden)
+		return
+	}
 	
-	userJoined, err := h.sessionService.JoinSession(sessionID, user.ID)
+	userJoined, err := h.sessionService.JoinSession(r.Context(), sessionID, user.ID)
 	if err != nil {
 		http.Error(w, "Failed to join session", http.StatusInternalServerError)
 		return
@@ -342,7 +660,7 @@ func (h *Handler) JoinSession(w http.ResponseWriter, r *http.Request) {
 
 	// Only broadcast if user actually joined (wasn't already a participant)
 	if userJoined {
-		h.wsService.Broadcast(sessionID, models.SSEMessage{
+		h.broadcastState(r.Context(), sessionID, models.SSEMessage{
 			Type: "user-joined",
 			Data: user,
 		})
@@ -360,18 +678,19 @@ func (h *Handler) LeaveSession(w http.ResponseWriter, r *http.Request) {
 
 	sessionID := chi.URLParam(r, "sessionID")
 	
-	err := h.sessionService.LeaveSession(sessionID, user.ID)
+	err := h.sessionService.LeaveSession(r.Context(), sessionID, user.ID)
 	if err != nil {
 		http.Error(w, "Failed to leave session", http.StatusInternalServerError)
 		return
 	}
+	h.wsService.Forget(sessionID, user.ID)
 
-	h.wsService.Broadcast(sessionID, models.SSEMessage{
+	h.broadcastState(r.Context(), sessionID, models.SSEMessage{
 		Type: "user-left",
 		Data: user,
 	})
 
-	w.WriteHeader(http.StatusNoContent)
+	finishAction(w, r, http.StatusNoContent, "/")
 }
 
 func (h *Handler) DeleteSession(w http.ResponseWriter, r *http.Request) {
@@ -382,7 +701,7 @@ func (h *Handler) DeleteSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	sessionID := chi.URLParam(r, "sessionID")
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -406,187 +725,192 @@ func (h *Handler) DeleteSession(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 
-	err = h.sessionService.DeleteSession(sessionID)
+	err = h.sessionService.DeleteSession(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to delete session", http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	finishAction(w, r, http.StatusNoContent, "/")
 }
 
-func (h *Handler) calculateVoteHistogram(votes []models.Vote) []VoteCount {
-	voteCounts := make(map[string]int)
-	total := len(votes)
+// UpdateSessionSettings changes a session's name, deck, rounding strategy,
+// limits or voting time limit. Only the fields present in the form are
+// changed.
+func (h *Handler) UpdateSessionSettings(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 
-	for _, vote := range votes {
-		voteCounts[vote.VoteValue]++
+	if err := r.ParseForm(); err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid form data")
+		return
 	}
 
-	var histogram []VoteCount
-	// Only include vote values that actually received votes
-	for voteValue, count := range voteCounts {
-		if count > 0 {
-			percentage := 0
-			if total > 0 {
-				percentage = (count * 100) / total
-			}
-			
-			histogram = append(histogram, VoteCount{
-				Value:      voteValue,
-				Count:      count,
-				Percentage: percentage,
-			})
-		}
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
 	}
 
-	return histogram
-}
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only session owner can change session settings", http.StatusForbidden)
+		return
+	}
 
-func (h *Handler) calculateVoteMedian(votes []models.Vote) *float64 {
-	if len(votes) == 0 {
-		return nil
+	var allErrors utils.ValidationErrors
+
+	if _, ok := r.PostForm["name"]; ok {
+		name := utils.SanitizeInput(r.PostForm.Get("name"))
+		allErrors = append(allErrors, utils.ValidateSessionName(name)...)
+		session.Name = name
 	}
-	
-	var numericVotes []float64
-	
-	for _, vote := range votes {
-		// Only include numeric votes in median calculation
-		// Skip special cards like ☕ and ?
-		switch vote.VoteValue {
-		case "0", "1", "2", "3", "5", "8", "13", "21", "34", "55", "89", "144":
-			if val := parseVoteValue(vote.VoteValue); val >= 0 {
-				numericVotes = append(numericVotes, float64(val))
-			}
+
+	if _, ok := r.PostForm["rounding_strategy"]; ok {
+		strategy, valid := deck.ParseRoundingStrategy(r.PostForm.Get("rounding_strategy"))
+		if !valid {
+			allErrors = append(allErrors, utils.ValidationError{Field: "rounding_strategy", Message: "Invalid rounding strategy"})
 		}
+		session.RoundingStrategy = string(strategy)
 	}
-	
-	if len(numericVotes) == 0 {
-		return nil
-	}
-	
-	// Sort the votes to calculate median
-	sort.Float64s(numericVotes)
-	
-	var median float64
-	n := len(numericVotes)
-	
-	if n%2 == 0 {
-		// Even number of votes: take the left middle value (lower index)
-		median = numericVotes[n/2-1]
-	} else {
-		// Odd number of votes: middle value
-		median = numericVotes[n/2]
-	}
-	
-	return &median
-}
 
-func (h *Handler) calculateTicketStats(votes []models.Vote) TicketStats {
-	if len(votes) == 0 {
-		return TicketStats{
-			Median:    0,
-			Mean:      0,
-			Mode:      "N/A",
-			HasValues: false,
+	if _, ok := r.PostForm["suggestion_basis"]; ok {
+		basis, valid := stats.ParseBasis(r.PostForm.Get("suggestion_basis"))
+		if !valid {
+			allErrors = append(allErrors, utils.ValidationError{Field: "suggestion_basis", Message: "Suggestions can be based on the median, the mean or a percentile from p1 to p99"})
 		}
+		session.SuggestionBasis = string(basis)
 	}
 
-	// Separate numeric and non-numeric votes
-	var numericVotes []float64
-	voteFrequency := make(map[string]int)
-	
-	for _, vote := range votes {
-		voteFrequency[vote.VoteValue]++
-		
-		// Check if vote is numeric for median/mean calculation
-		switch vote.VoteValue {
-		case "0", "1", "2", "3", "5", "8", "13", "21", "34", "55", "89", "144":
-			if val := parseVoteValue(vote.VoteValue); val >= 0 {
-				numericVotes = append(numericVotes, float64(val))
-			}
+	unitChanged := false
+	if _, ok := r.PostForm["estimation_unit"]; ok {
+		unit, valid := deck.ParseUnit(r.PostForm.Get("estimation_unit"))
+		if !valid {
+			allErrors = append(allErrors, utils.ValidationError{Field: "estimation_unit", Message: "Invalid estimation unit"})
 		}
+		unitChanged = string(unit) != session.EstimationUnit
+		session.EstimationUnit = string(unit)
 	}
 
-	stats := TicketStats{HasValues: len(numericVotes) > 0}
+	if checked, ok := formCheckbox(r, "is_public"); ok {
+		session.IsPublic = checked
+	}
 
-	// Calculate median (only for numeric votes)
-	if len(numericVotes) > 0 {
-		sort.Float64s(numericVotes)
-		n := len(numericVotes)
-		if n%2 == 0 {
-			// Even number: take the left middle value (lower index)
-			stats.Median = numericVotes[n/2-1]
-		} else {
-			// Odd number: take the middle value
-			stats.Median = numericVotes[n/2]
-		}
+	if checked, ok := formCheckbox(r, "auto_reveal"); ok {
+		session.AutoReveal = checked
 	}
 
-	// Calculate mean (only for numeric votes)
-	if len(numericVotes) > 0 {
-		var sum float64
-		for _, vote := range numericVotes {
-			sum += vote
-		}
-		stats.Mean = sum / float64(len(numericVotes))
+	if checked, ok := formCheckbox(r, "auto_reveal_ignores_away"); ok {
+		session.AutoRevealIgnoresAway = checked
 	}
 
-	// Calculate mode (for all votes, including non-numeric)
-	maxCount := 0
-	var modes []string
-	
-	for value, count := range voteFrequency {
-		if count > maxCount {
-			maxCount = count
-			modes = []string{value}
-		} else if count == maxCount {
-			modes = append(modes, value)
-		}
+	if values, ok := r.PostForm["required_fields"]; ok {
+		fields, fieldErrors := parseRequiredFields("required_fields", values)
+		allErrors = append(allErrors, fieldErrors...)
+		session.RequiredFields = fields
 	}
-	
-	if len(modes) == 1 {
-		stats.Mode = modes[0]
-	} else if len(modes) == len(voteFrequency) {
-		// All values appear equally - no mode
-		stats.Mode = "None"
-	} else {
-		// Multiple modes
-		stats.Mode = fmt.Sprintf("Multiple: %v", modes)
+
+	if _, ok := r.PostForm["max_participants"]; ok {
+		limit, fieldErrors := parseSessionLimit("max_participants", r.PostForm.Get("max_participants"), h.config.Limits.MaxParticipants)
+		allErrors = append(allErrors, fieldErrors...)
+		session.MaxParticipants = limit
+	}
+
+	if _, ok := r.PostForm["max_tickets"]; ok {
+		limit, fieldErrors := parseSessionLimit("max_tickets", r.PostForm.Get("max_tickets"), h.config.Limits.MaxTickets)
+		allErrors = append(allErrors, fieldErrors...)
+		session.MaxTickets = limit
+	}
+
+	if _, ok := r.PostForm["voting_time_limit"]; ok {
+		limit, fieldErrors := parseVotingTimeLimit("voting_time_limit", r.PostForm.Get("voting_time_limit"))
+		allErrors = append(allErrors, fieldErrors...)
+		session.VotingTimeLimit = limit
+	}
+
+	if _, ok := r.PostForm["vote_change_window"]; ok {
+		window, fieldErrors := parseVoteChangeWindow("vote_change_window", r.PostForm.Get("vote_change_window"))
+		allErrors = append(allErrors, fieldErrors...)
+		session.VoteChangeWindow = window
 	}
 
-	return stats
+	if _, ok := r.PostForm["delphi_max_rounds"]; ok {
+		rounds, fieldErrors := parseDelphiMaxRounds("delphi_max_rounds", r.PostForm.Get("delphi_max_rounds"))
+		allErrors = append(allErrors, fieldErrors...)
+		session.DelphiMaxRounds = rounds
+	}
+
+	if _, ok := r.PostForm["delphi_agreement"]; ok {
+		agreement, fieldErrors := parseDelphiAgreement("delphi_agreement", r.PostForm.Get("delphi_agreement"))
+		allErrors = append(allErrors, fieldErrors...)
+		session.DelphiAgreement = agreement
+	}
+
+	if allErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, allErrors.Error())
+		return
+	}
+
+	// Votes already cast would not be on the new deck
+	if unitChanged && session.IsVotingActive {
+		utils.WriteHTMLError(w, http.StatusConflict, "Cannot change the deck while voting is in progress")
+		return
+	}
+
+	err = h.sessionService.UpdateSessionSettings(r.Context(), session)
+	if err != nil {
+		utils.LogError("UpdateSessionSettings", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to update session settings")
+		return
+	}
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "session-updated",
+		Data: session,
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
+
+// parseRequiredFields reads the ticket fields a session requires before
+// voting. The settings form sends an empty value ahead of the checkboxes so
+// that unchecking all of them clears the policy.
+func parseRequiredFields(field string, values []string) ([]models.TicketField, utils.ValidationErrors) {
+	fields := []models.TicketField{}
+	seen := make(map[models.TicketField]bool)
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		ticketField, ok := models.ParseTicketField(value)
+		if !ok {
+			return nil, utils.ValidationErrors{{Field: field, Message: "Required fields can be description, external_url or external_key"}}
+		}
+		if !seen[ticketField] {
+			seen[ticketField] = true
+			fields = append(fields, ticketField)
+		}
+	}
+	return fields, nil
 }
 
-func parseVoteValue(voteValue string) int {
-	switch voteValue {
-	case "0":
-		return 0
-	case "1":
-		return 1
-	case "2":
-		return 2
-	case "3":
-		return 3
-	case "5":
-		return 5
-	case "8":
-		return 8
-	case "13":
-		return 13
-	case "21":
-		return 21
-	case "34":
-		return 34
-	case "55":
-		return 55
-	case "89":
-		return 89
-	case "144":
-		return 144
-	default:
-		return -1 // Invalid/special vote
+// formCheckbox reads a boolean form field. The settings form sends a hidden
+// "false" ahead of each checkbox, so the field is true if any value is.
+func formCheckbox(r *http.Request, field string) (checked bool, ok bool) {
+	values, ok := r.PostForm[field]
+	for _, value := range values {
+		if value == "true" {
+			checked = true
+		}
 	}
+	return checked, ok
 }
 
 func (h *Handler) ReviewSession(w http.ResponseWriter, r *http.Request) {
@@ -597,7 +921,7 @@ func (h *Handler) ReviewSession(w http.ResponseWriter, r *http.Request) {
 	}
 
 	sessionID := chi.URLParam(r, "sessionID")
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -622,7 +946,59 @@ func (h *Handler) ReviewSession(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 
-	w.WriteHeader(http.StatusNoContent)
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID+"/summary")
+}
+
+// CarryOverSession creates a follow-up session holding the tickets that were
+// not estimated, for when refinement doesn't finish the backlog in one go.
+func (h *Handler) CarryOverSession(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only session owner can carry over tickets", http.StatusForbidden)
+		return
+	}
+
+	name := utils.SanitizeInput(r.FormValue("name"))
+	if name == "" {
+		name = session.Name + " (continued)"
+		if len(name) > 100 {
+			name = session.Name
+		}
+	}
+
+	if validationErrors := utils.ValidateSessionName(name); validationErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
+		return
+	}
+
+	followUp, _, err := h.sessionService.CreateFollowUpSession(r.Context(), session, name)
+	if err != nil {
+		utils.LogError("CarryOverSession", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to carry over tickets")
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "" {
+		w.Header().Set("HX-Redirect", "/session/"+followUp.ID)
+		return
+	}
+	http.Redirect(w, r, "/session/"+followUp.ID, http.StatusSeeOther)
 }
 
 func (h *Handler) GetSessionSummary(w http.ResponseWriter, r *http.Request) {
@@ -634,7 +1010,7 @@ func (h *Handler) GetSessionSummary(w http.ResponseWriter, r *http.Request) {
 	}
 
 	sessionID := chi.URLParam(r, "sessionID")
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -658,64 +1034,27 @@ func (h *Handler) GetSessionSummary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Calculate summary statistics
-	totalVotes := 0
-	estimatedTickets := 0
-	var allVotes []models.Vote
-	ticketAverages := make(map[int]float64)
-	ticketVoteGroups := make(map[int][]VoteCount)
-	ticketStats := make(map[int]TicketStats)
+	summary := stats.Session(session)
 
-	for _, ticket := range session.Tickets {
-		if len(ticket.Votes) > 0 {
-			totalVotes += len(ticket.Votes)
-			allVotes = append(allVotes, ticket.Votes...)
-			
-			// Calculate full statistics
-			stats := h.calculateTicketStats(ticket.Votes)
-			ticketStats[ticket.ID] = stats
-			
-			// Maintain backward compatibility with median as "average"
-			if stats.HasValues {
-				ticketAverages[ticket.ID] = stats.Median
-				estimatedTickets++
-			}
-			
-			ticketVoteGroups[ticket.ID] = h.calculateVoteHistogram(ticket.Votes)
+	// The median stands in for the average where pages still show one
+	ticketAverages := make(map[int]float64)
+	for ticketID, ticketStats := range summary.Tickets {
+		if ticketStats.HasValues {
+			ticketAverages[ticketID] = ticketStats.Median
 		}
 	}
-
-	// Calculate overall statistics
 	var overallAverage float64
-	var overallStats TicketStats
-	if len(allVotes) > 0 {
-		overallStats = h.calculateTicketStats(allVotes)
-		if overallStats.HasValues {
-			overallAverage = overallStats.Median
-		}
+	if summary.Overall.HasValues {
+		overallAverage = summary.Overall.Median
 	}
 
-	// Calculate participant statistics
-	participantStats := make(map[string]*ParticipantStat)
-	for _, participant := range session.Participants {
-		var participantVotes []models.Vote
-		for _, ticket := range session.Tickets {
-			for _, vote := range ticket.Votes {
-				if vote.UserID == participant.ID {
-					participantVotes = append(participantVotes, vote)
-				}
-			}
-		}
-		
-		stat := &ParticipantStat{
-			VoteCount: len(participantVotes),
-		}
-		
-		if median := h.calculateVoteMedian(participantVotes); median != nil {
-			stat.MedianVote = *median
-		}
-		
-		participantStats[participant.ID] = stat
+	feedback, err := h.sessionService.GetFeedbackSummary(r.Context(), session.ID)
+	if err != nil {
+		utils.LogError("GetSessionSummary", err, utils.ReportContext{SessionID: session.ID, UserID: user.ID})
+	}
+	gaveFeedback, err := h.sessionService.HasFeedback(r.Context(), session.ID, user.ID)
+	if err != nil {
+		utils.LogError("GetSessionSummary", err, utils.ReportContext{SessionID: session.ID, UserID: user.ID})
 	}
 
 	data := PageData{
@@ -725,13 +1064,19 @@ func (h *Handler) GetSessionSummary(w http.ResponseWriter, r *http.Request) {
 		Session:          session,
 		SessionName:      session.Name,
 		TicketAverages:   ticketAverages,
-		TotalVotes:       totalVotes,
-		EstimatedTickets: estimatedTickets,
+		TotalVotes:       summary.TotalVotes,
+		EstimatedTickets: summary.EstimatedTickets,
 		OverallAverage:   overallAverage,
-		TicketVoteGroups: ticketVoteGroups,
-		ParticipantStats: participantStats,
-		TicketStats:      ticketStats,
-		OverallStats:     overallStats,
+		TicketVoteGroups: summary.Histograms,
+		ParticipantStats: summary.Participants,
+		TicketStats:      summary.Tickets,
+		OverallStats:     summary.Overall,
+		AgendaSummary:    newAgendaSummary(h.agenda(r.Context(), session.ID)),
+		ParkingLot:       h.parkingLot(r.Context(), session.ID),
+		ActionItems:      h.actionItems(r.Context(), session.ID),
+		Feedback:         feedback,
+		GaveFeedback:     gaveFeedback,
+		Location:         viewerLocation(r, user),
 	}
 
 	h.executeTemplate(w, "base.html", data)
@@ -745,7 +1090,7 @@ func (h *Handler) ExportSessionCSV(w http.ResponseWriter, r *http.Request) {
 	}
 
 	sessionID := chi.URLParam(r, "sessionID")
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -770,16 +1115,28 @@ func (h *Handler) ExportSessionCSV(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Calculate statistics for CSV
-	ticketStats := make(map[int]TicketStats)
+	ticketStats := make(map[int]stats.TicketStats)
 	for _, ticket := range session.Tickets {
 		if len(ticket.Votes) > 0 {
-			stats := h.calculateTicketStats(ticket.Votes)
-			ticketStats[ticket.ID] = stats
+			ticketStats[ticket.ID] = stats.ForSession(session).Ticket(ticket.Votes)
 		}
 	}
 
+	// Each ticket's votes rolled up into low, medium and high groups read
+	// better than a column per card in large sessions
+	rollup := wantsRollup(r, session)
+	ticketRollups := make(map[int][]string)
+	if rollup {
+		for _, ticket := range session.Tickets {
+			ticketRollups[ticket.ID] = rollupColumns(stats.ForSession(session).Rollup(ticket.Votes))
+		}
+	}
+
+	// Timestamps are ISO-8601 with the viewer's UTC offset
+	loc := viewerLocation(r, user)
+
 	// Set CSV headers
-	filename := fmt.Sprintf("planning-poker-%s-%s.csv", sessionID, time.Now().Format("2006-01-02"))
+	filename := fmt.Sprintf("planning-poker-%s-%s.csv", sessionID, time.Now().In(loc).Format("2006-01-02"))
 	w.Header().Set("Content-Type", "text/csv")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
 
@@ -787,8 +1144,31 @@ func (h *Handler) ExportSessionCSV(w http.ResponseWriter, r *http.Request) {
 	writer := csv.NewWriter(w)
 	defer writer.Flush()
 
+	// The owner's export adds their private notes; everyone else's leaves
+	// them out
+	var notes *models.FacilitatorNotes
+	if session.OwnerID == user.ID {
+		if notes = h.facilitatorNotes(r, session); notes == nil {
+			http.Error(w, "Failed to get notes", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	// Write header
-	header := []string{"Session Name", "Session ID", "Ticket Title", "Ticket Description", "Participant", "Vote Value", "Ticket Median", "Ticket Mean", "Ticket Mode"}
+	header := []string{"Session Name", "Session ID", "Ticket Title", "Ticket Description", "Participant", "Vote Value", "Ticket Median", "Ticket Mean", "Ticket Mode", "Ticket Abstentions", "Ticket Infinite Votes", "Estimation Unit", "Ticket Created At", "Voted At", "Vote Weight", "Weighted Stats", "Calibration", "Final Estimate", "Rationale", "Assumptions", "Estimate Low", "Estimate High"}
+	if rollup {
+		// One column per group, naming the cards of each band
+		for _, group := range stats.ForSession(session).Rollup(nil) {
+			column := fmt.Sprintf("Ticket %s Votes", strings.ToUpper(group.Group[:1])+group.Group[1:])
+			if group.From != "" {
+				column += fmt.Sprintf(" (%s-%s)", group.From, group.To)
+			}
+			header = append(header, column)
+		}
+	}
+	if notes != nil {
+		header = append(header, "Session Notes", "Ticket Notes")
+	}
 	if err := writer.Write(header); err != nil {
 		http.Error(w, "Failed to write CSV header", http.StatusInternalServerError)
 		return
@@ -796,7 +1176,14 @@ func (h *Handler) ExportSessionCSV(w http.ResponseWriter, r *http.Request) {
 
 	// Write data
 	for _, ticket := range session.Tickets {
-		stats := ticketStats[ticket.ID]
+		voteStats := ticketStats[ticket.ID]
+		var finalEstimate, estimateLow, estimateHigh string
+		if ticket.FinalEstimate != nil {
+			finalEstimate = *ticket.FinalEstimate
+		}
+		if ticket.EstimateLow != nil && ticket.EstimateHigh != nil {
+			estimateLow, estimateHigh = *ticket.EstimateLow, *ticket.EstimateHigh
+		}
 		
 		if len(ticket.Votes) > 0 {
 			for _, vote := range ticket.Votes {
@@ -812,9 +1199,28 @@ func (h *Handler) ExportSessionCSV(w http.ResponseWriter, r *http.Request) {
 					ticket.Description,
 					username,
 					vote.VoteValue,
-					formatFloat(stats.Median, stats.HasValues),
-					formatFloat(stats.Mean, stats.HasValues),
-					stats.Mode,
+					formatFloat(voteStats.Median, voteStats.HasValues),
+					formatFloat(voteStats.Mean, voteStats.HasValues),
+					voteStats.Mode,
+					strconv.Itoa(voteStats.Abstentions),
+					strconv.Itoa(voteStats.Infinite),
+					session.EstimationUnit,
+					ticket.CreatedAt.In(loc).Format(time.RFC3339),
+					vote.CreatedAt.In(loc).Format(time.RFC3339),
+					deck.FormatValue(stats.Weight(vote)),
+					strconv.FormatBool(voteStats.Weighted),
+					strconv.FormatBool(ticket.IsCalibration),
+					finalEstimate,
+					ticket.Rationale,
+					ticket.Assumptions,
+					estimateLow,
+					estimateHigh,
+				}
+				if rollup {
+					record = append(record, ticketRollups[ticket.ID]...)
+				}
+				if notes != nil {
+					record = append(record, notes.Session, notes.Tickets[ticket.ID])
 				}
 				if err := writer.Write(record); err != nil {
 					http.Error(w, "Failed to write CSV record", http.StatusInternalServerError)
@@ -833,6 +1239,25 @@ func (h *Handler) ExportSessionCSV(w http.ResponseWriter, r *http.Request) {
 				"N/A",
 				"N/A",
 				"N/A",
+				"0",
+				"0",
+				session.EstimationUnit,
+				ticket.CreatedAt.In(loc).Format(time.RFC3339),
+				"",
+				"",
+				"false",
+				strconv.FormatBool(ticket.IsCalibration),
+				finalEstimate,
+				ticket.Rationale,
+				ticket.Assumptions,
+				estimateLow,
+				estimateHigh,
+			}
+			if rollup {
+				record = append(record, ticketRollups[ticket.ID]...)
+			}
+			if notes != nil {
+				record = append(record, notes.Session, notes.Tickets[ticket.ID])
 			}
 			if err := writer.Write(record); err != nil {
 				http.Error(w, "Failed to write CSV record", http.StatusInternalServerError)
@@ -849,6 +1274,24 @@ func formatFloat(val float64, hasValues bool) string {
 	return fmt.Sprintf("%.1f", val)
 }
 
+// wantsRollup reports whether an export rolls votes up into groups: by
+// default when the session is large, or as ?rollup=true or false asks.
+func wantsRollup(r *http.Request, session *models.Session) bool {
+	if value := r.URL.Query().Get("rollup"); value != "" {
+		return value == "true"
+	}
+	return len(session.Participants) >= stats.LargeSession
+}
+
+// rollupColumns renders a rollup's groups as CSV columns, e.g. "12 (40%)".
+func rollupColumns(rollup []stats.VoteGroup) []string {
+	var columns []string
+	for _, group := range rollup {
+		columns = append(columns, fmt.Sprintf("%d (%d%%)", group.Count, group.Percentage))
+	}
+	return columns
+}
+
 func (h *Handler) executeTemplate(w http.ResponseWriter, tmplName string, data interface{}) {
 	err := h.templates.ExecuteTemplate(w, tmplName, data)
 	if err != nil {
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	hookTimeout       = 10 * time.Second
+	maxHookURLLength  = 2000
+	sampleEventsLimit = 3
+	maxSampleEvents   = 100
+)
+
+// deliverHooks POSTs an event to every URL subscribed to it. Deliveries run
+// in the background and are not retried; a hook that answers 410 Gone is
+// unsubscribed, as no-code platforms expect.
+func (h *Handler) deliverHooks(ctx context.Context, event *models.SessionEvent) {
+	hooks, err := h.eventService.HooksFor(ctx, event.Type, event.SessionID)
+	if err != nil {
+		utils.LogError("deliverHooks", err, utils.ReportContext{SessionID: event.SessionID})
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		utils.LogError("deliverHooks", err, utils.ReportContext{SessionID: event.SessionID})
+		return
+	}
+
+	for _, hook := range hooks {
+		go h.deliverHook(hook, event.Type, body)
+	}
+}
+
+func (h *Handler) deliverHook(hook models.HookSubscription, eventType string, body []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.TargetURL, bytes.NewReader(body))
+	if err != nil {
+		utils.LogError("deliverHook", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Hook-ID", hook.ID)
+	req.Header.Set("X-Hook-Event", eventType)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		utils.LogError("deliverHook", fmt.Errorf("hook %s: %w", hook.ID, err))
+		return
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusGone:
+		if err := h.eventService.DeleteHook(ctx, hook.ID); err != nil && !errors.Is(err, services.ErrHookNotFound) {
+			utils.LogError("deliverHook", err)
+		}
+	case resp.StatusCode/100 != 2:
+		utils.LogError("deliverHook", fmt.Errorf("hook %s returned %s", hook.ID, resp.Status))
+	}
+}
+
+// hookRequest is the body of a subscribe request, sent as JSON or a form.
+type hookRequest struct {
+	Event     string `json:"event"`
+	TargetURL string `json:"target_url"`
+	SessionID string `json:"session_id"`
+}
+
+// ListHooks lists the hook subscriptions.
+func (h *Handler) ListHooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.eventService.ListHooks(r.Context())
+	if err != nil {
+		utils.LogError("ListHooks", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get hooks")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"hooks": hooks})
+}
+
+// CreateHook subscribes a URL to an event type, optionally in one session.
+// The response's id is what no-code platforms send back to unsubscribe.
+func (h *Handler) CreateHook(w http.ResponseWriter, r *http.Request) {
+	var req hookRequest
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Invalid JSON body")
+			return
+		}
+	} else {
+		req = hookRequest{Event: r.FormValue("event"), TargetURL: r.FormValue("target_url"), SessionID: r.FormValue("session_id")}
+	}
+
+	if !services.IsEventType(req.Event) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "event must be one of: "+strings.Join(services.EventTypes, ", "))
+		return
+	}
+
+	target, err := url.Parse(req.TargetURL)
+	if err != nil || (target.Scheme != "https" && target.Scheme != "http") || target.Host == "" || len(req.TargetURL) > maxHookURLLength {
+		utils.WriteJSONError(w, http.StatusBadRequest, "target_url must be an http or https URL")
+		return
+	}
+
+	if req.SessionID != "" {
+		session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), req.SessionID)
+		if err != nil {
+			utils.LogError("CreateHook", err)
+			utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get session")
+			return
+		}
+		if session == nil {
+			utils.WriteJSONError(w, http.StatusBadRequest, "Session not found")
+			return
+		}
+	}
+
+	hook, err := h.eventService.CreateHook(r.Context(), req.Event, req.TargetURL, req.SessionID)
+	if err != nil {
+		utils.LogError("CreateHook", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to create hook")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, hook)
+}
+
+// DeleteHook unsubscribes a hook.
+func (h *Handler) DeleteHook(w http.ResponseWriter, r *http.Request) {
+	err := h.eventService.DeleteHook(r.Context(), chi.URLParam(r, "hookID"))
+	if errors.Is(err, services.ErrHookNotFound) {
+		utils.WriteJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		utils.LogError("DeleteHook", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to delete hook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListEventTypes lists the event types hooks can subscribe to.
+func (h *Handler) ListEventTypes(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{"events": services.EventTypes})
+}
+
+// GetRecentEvents returns the latest events of a type, newest first, shaped
+// like hook deliveries. No-code platforms use them as samples while a
+// hook is set up, and can poll it instead of subscribing.
+func (h *Handler) GetRecentEvents(w http.ResponseWriter, r *http.Request) {
+	eventType := r.URL.Query().Get("type")
+	if !services.IsEventType(eventType) {
+		utils.WriteJSONError(w, http.StatusBadRequest, "type must be one of: "+strings.Join(services.EventTypes, ", "))
+		return
+	}
+
+	limit := sampleEventsLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxSampleEvents {
+			utils.WriteJSONError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxSampleEvents))
+			return
+		}
+	}
+
+	events, err := h.eventService.RecentEvents(r.Context(), eventType, limit)
+	if err != nil {
+		utils.LogError("GetRecentEvents", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get events")
+		return
+	}
+
+	// A bare array, as Zapier and Make expect from a polling trigger
+	utils.WriteJSON(w, http.StatusOK, events)
+}
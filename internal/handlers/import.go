@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"poker-planning/internal/importers"
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	maxImportedTitle       = 200  // runes, as for tickets added by hand
+	maxImportedDescription = 1000 // bytes
+	maxImportFileSize      = 5 << 20
+)
+
+// importerFor builds the importer for a tracker from the import form.
+// Supporting another tracker means adding its case here.
+func (h *Handler) importerFor(source string, r *http.Request) (importers.Importer, utils.ValidationErrors, bool) {
+	var fieldErrors utils.ValidationErrors
+	required := func(field, label string) string {
+		value := utils.SanitizeInput(r.FormValue(field))
+		if value == "" {
+			fieldErrors = append(fieldErrors, utils.ValidationError{Field: field, Message: label + " is required"})
+		}
+		return value
+	}
+
+	switch {
+	case source == "csv":
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			fieldErrors = append(fieldErrors, utils.ValidationError{Field: "file", Message: "Choose a CSV or TSV file of up to 5 MB"})
+			return nil, fieldErrors, true
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			fieldErrors = append(fieldErrors, utils.ValidationError{Field: "file", Message: "Could not read the file"})
+			return nil, fieldErrors, true
+		}
+
+		importer := &importers.CSV{Reader: bytes.NewReader(data)}
+		if strings.HasSuffix(strings.ToLower(header.Filename), ".tsv") {
+			importer.Comma = '\t'
+		}
+		if value := utils.SanitizeInput(r.FormValue("jira_site")); value != "" {
+			site, ok := importers.JiraSite(value)
+			if !ok {
+				fieldErrors = append(fieldErrors, utils.ValidationError{Field: "jira_site", Message: "Jira site must be a URL such as https://example.atlassian.net"})
+			}
+			importer.JiraSite = site
+		}
+		return importer, fieldErrors, true
+
+	case source == "linear" && h.config.LinearAPIKey != "":
+		team := required("team", "Team key")
+		cycle := 0
+		if value := utils.SanitizeInput(r.FormValue("cycle")); value != "" {
+			var err error
+			cycle, err = strconv.Atoi(value)
+			if err != nil || cycle < 1 {
+				fieldErrors = append(fieldErrors, utils.ValidationError{Field: "cycle", Message: "Cycle must be a positive number"})
+			}
+		}
+		return &importers.Linear{APIKey: h.config.LinearAPIKey, Team: team, Cycle: cycle}, fieldErrors, true
+
+	case source == "trello" && h.config.TrelloAPIKey != "" && h.config.TrelloToken != "":
+		board := required("board", "Board")
+		list := required("list", "List")
+		return &importers.Trello{APIKey: h.config.TrelloAPIKey, Token: h.config.TrelloToken, Board: board, List: list}, fieldErrors, true
+	}
+
+	return nil, nil, false
+}
+
+// ImportTickets lets the owner pull tickets from an issue tracker, or from a
+// CSV or TSV file, into the session. Each ticket keeps its issue key and,
+// from a tracker, a link back to it.
+func (h *Handler) ImportTickets(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	source := chi.URLParam(r, "source")
+
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only session owner can import tickets", http.StatusForbidden)
+		return
+	}
+
+	importer, fieldErrors, ok := h.importerFor(source, r)
+	if !ok {
+		http.Error(w, "Unknown or unconfigured import source", http.StatusNotFound)
+		return
+	}
+	if fieldErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, fieldErrors)
+		return
+	}
+
+	tickets, err := importer.Import(r.Context())
+	if errors.Is(err, importers.ErrInvalidFile) {
+		utils.WriteFormValidationError(w, r, utils.ValidationErrors{{Field: "file", Message: err.Error()}})
+		return
+	}
+	if err != nil {
+		utils.LogError("ImportTickets", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusBadGateway, fmt.Sprintf("Could not import from %s: %v", source, err))
+		return
+	}
+
+	// A Jira export links its tickets to the site, so they are recorded as
+	// from Jira for the Jira webhook to find
+	if csv, ok := importer.(*importers.CSV); ok && csv.JiraSite != "" {
+		source = "jira"
+	}
+
+	h.addImportedTickets(w, r, session, user, source, tickets)
+}
+
+// addImportedTickets adds tickets read by an importer to the end of the
+// backlog. Tickets whose key is already in the session are skipped, so the
+// same cycle or list can be imported again to pick up what is new.
+func (h *Handler) addImportedTickets(w http.ResponseWriter, r *http.Request, session *models.Session, user *models.User, source string, tickets []models.Ticket) {
+	seen := make(map[string]bool)
+	for _, ticket := range session.Tickets {
+		if ticket.ExternalKey != nil {
+			seen[*ticket.ExternalKey] = true
+		}
+	}
+
+	var fresh []models.Ticket
+	skipped := 0
+	for _, ticket := range tickets {
+		ticket = cleanImportedTicket(ticket)
+		if ticket.Title == "" {
+			continue
+		}
+		if ticket.ExternalKey != nil {
+			if seen[*ticket.ExternalKey] {
+				skipped++
+				continue
+			}
+			seen[*ticket.ExternalKey] = true
+		}
+		fresh = append(fresh, ticket)
+	}
+
+	if len(fresh) == 0 {
+		utils.WriteHTMLError(w, http.StatusUnprocessableEntity, fmt.Sprintf("Nothing new to import (%d tickets were already imported)", skipped))
+		return
+	}
+
+	if !h.checkTicketLimit(w, r, session, len(fresh)) {
+		return
+	}
+
+	h.restorePoint(r.Context(), session.ID, "Before importing from "+source, user)
+
+	created, err := h.ticketService.ImportTickets(r.Context(), session.ID, source, fresh)
+	if err != nil {
+		writeServiceError(w, r, "ImportTickets", err, "Failed to import tickets")
+		return
+	}
+
+	h.wsService.Broadcast(session.ID, models.SSEMessage{
+		Type: "tickets-imported",
+		Data: map[string]interface{}{
+			"source":  source,
+			"tickets": created,
+		},
+	})
+	for _, ticket := range created {
+		h.recordEvent(r.Context(), session.ID, services.EventTicketCreated, ticket.ID, user.ID, map[string]interface{}{
+			"title":        ticket.Title,
+			"external_key": ticket.ExternalKey,
+			"source":       source,
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, "Imported %d tickets, skipped %d that were already imported", len(created), skipped)
+}
+
+// cleanImportedTicket fits a ticket from a tracker into the limits of
+// tickets added by hand, dropping a key or link that would not be valid.
+func cleanImportedTicket(ticket models.Ticket) models.Ticket {
+	title := strings.Join(strings.Fields(ticket.Title), " ")
+	if runes := []rune(title); len(runes) > maxImportedTitle {
+		title = string(runes[:maxImportedTitle-1]) + "…"
+	}
+	ticket.Title = title
+
+	description := strings.TrimSpace(ticket.Description)
+	if len(description) > maxImportedDescription {
+		cut := maxImportedDescription - len("…")
+		for cut > 0 && !utf8.RuneStart(description[cut]) {
+			cut--
+		}
+		description = description[:cut] + "…"
+	}
+	ticket.Description = description
+
+	if ticket.ExternalKey != nil && utils.ValidateTicketKey(*ticket.ExternalKey).HasErrors() {
+		ticket.ExternalKey = nil
+	}
+	if ticket.ExternalURL != nil && !strings.HasPrefix(*ticket.ExternalURL, "https://") && !strings.HasPrefix(*ticket.ExternalURL, "http://") {
+		ticket.ExternalURL = nil
+	}
+
+	return ticket
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"poker-planning/internal/utils"
+)
+
+const InstanceCookieName = "poker_instance"
+
+// ParseAllowedNetworks parses a comma-separated list of CIDR ranges. A bare
+// IP address allows just that address.
+func ParseAllowedNetworks(value string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range %q", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// RequireAllowedNetwork rejects requests whose connection does not come from
+// one of the networks. Behind a reverse proxy this is the proxy's address.
+func RequireAllowedNetwork(networks []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !allowedAddr(networks, r.RemoteAddr) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allowedAddr reports whether a connection's remote address, host and port,
+// is in one of the networks.
+func allowedAddr(networks []*net.IPNet, addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	ip := net.ParseIP(host)
+	for _, network := range networks {
+		if ip != nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// instanceToken is what the instance cookie holds once the passphrase has
+// been entered. It changes with the passphrase, which locks everyone out.
+func (h *Handler) instanceToken() string {
+	mac := hmac.New(sha256.New, []byte(h.config.InstancePassphrase))
+	mac.Write([]byte("poker-planning instance"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (h *Handler) instanceUnlocked(r *http.Request) bool {
+	cookie, err := r.Cookie(InstanceCookieName)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(h.instanceToken())) == 1
+}
+
+// RequireInstancePassphrase keeps a private instance behind its shared
+// passphrase. Pages show the passphrase screen until it has been entered;
+// everything else is refused. Static files, the token-guarded debug and
+// API endpoints, the signed webhooks, the signed embeds and WebSocket
+// handshakes with a connection token stay reachable.
+func (h *Handler) RequireInstancePassphrase(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.config.InstancePassphrase == "" || h.instanceUnlocked(r) ||
+			r.URL.Path == "/unlock" || strings.HasPrefix(r.URL.Path, "/static/") || strings.HasPrefix(r.URL.Path, "/debug/") ||
+			strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, "/webhooks/") ||
+			strings.HasPrefix(r.URL.Path, "/embed/") || IsConnectTokenHandshake(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method != http.MethodGet || r.Header.Get("HX-Request") != "" {
+			http.Error(w, "Instance passphrase required", http.StatusUnauthorized)
+			return
+		}
+
+		data := PageData{
+			Title:      "Private Instance",
+			Template:   "unlock",
+			RedirectTo: r.URL.RequestURI(),
+		}
+
+		w.WriteHeader(http.StatusUnauthorized)
+		h.executeTemplate(w, "base.html", data)
+	})
+}
+
+// Unlock checks the instance passphrase and remembers it in a cookie.
+func (h *Handler) Unlock(w http.ResponseWriter, r *http.Request) {
+	given := r.FormValue("passphrase")
+	if subtle.ConstantTimeCompare([]byte(given), []byte(h.config.InstancePassphrase)) != 1 {
+		// Slow down guessing
+		time.Sleep(time.Second)
+		utils.WriteFormValidationError(w, r, utils.ValidationErrors{{Field: "passphrase", Message: "Incorrect passphrase"}})
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     InstanceCookieName,
+		Value:    h.instanceToken(),
+		MaxAge:   30 * 24 * 3600, // 30 days
+		Path:     "/",
+		HttpOnly: true,
+		// Lax, so following a link from chat or e-mail into the instance
+		// does not ask for the passphrase again
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	// Only go back to a page on this site
+	redirectPage(w, r, localPath(r.FormValue("redirect_to")))
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"poker-planning/internal/importers"
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+)
+
+const maxWebhookBodySize = 1 << 20
+
+// jiraWebhook is the part of a Jira issue webhook that the sync reads.
+type jiraWebhook struct {
+	WebhookEvent string `json:"webhookEvent"`
+	Issue        struct {
+		Key    string `json:"key"`
+		Self   string `json:"self"` // REST link, which gives the site
+		Fields struct {
+			Summary string `json:"summary"`
+			Status  struct {
+				StatusCategory struct {
+					Key string `json:"key"` // "new", "indeterminate" or "done"
+				} `json:"statusCategory"`
+			} `json:"status"`
+		} `json:"fields"`
+	} `json:"issue"`
+}
+
+// validJiraWebhook checks that a webhook came from Jira: either it is signed
+// with the secret in X-Hub-Signature, as Jira Cloud does for webhooks with a
+// secret, or the secret is in the URL, for Jira versions that cannot sign.
+func validJiraWebhook(secret string, r *http.Request, body []byte) bool {
+	if given := r.URL.Query().Get("secret"); given != "" {
+		return subtle.ConstantTimeCompare([]byte(given), []byte(secret)) == 1
+	}
+
+	signature, ok := strings.CutPrefix(r.Header.Get("X-Hub-Signature"), "sha256=")
+	if !ok {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal([]byte(signature), []byte(hex.EncodeToString(mac.Sum(nil))))
+}
+
+// JiraWebhook keeps imported tickets in step with their Jira issues until
+// they are estimated: a new summary renames the ticket, and an issue that
+// is closed or deleted flags it so the session can skip it.
+func (h *Handler) JiraWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		utils.WriteJSONError(w, http.StatusRequestEntityTooLarge, "Webhook body too large")
+		return
+	}
+
+	if !validJiraWebhook(h.config.JiraWebhookSecret, r, body) {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Invalid webhook signature")
+		return
+	}
+
+	var event jiraWebhook
+	if err := json.Unmarshal(body, &event); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Expected a Jira webhook event")
+		return
+	}
+
+	var title string
+	var closed bool
+	switch event.WebhookEvent {
+	case "jira:issue_updated":
+		title = cleanImportedTicket(models.Ticket{Title: event.Issue.Fields.Summary}).Title
+		closed = event.Issue.Fields.Status.StatusCategory.Key == "done"
+	case "jira:issue_deleted":
+		closed = true
+	default:
+		// Other events may share the webhook; there is nothing to sync
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	site, ok := importers.JiraSiteOfIssue(event.Issue.Self)
+	if event.Issue.Key == "" || !ok {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Expected a Jira issue event")
+		return
+	}
+
+	issueURL := importers.JiraIssueURL(site, event.Issue.Key)
+	tickets, err := h.ticketService.SyncExternalIssue(r.Context(), event.Issue.Key, issueURL, title, closed)
+	if err != nil {
+		utils.LogError("JiraWebhook", err)
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to sync tickets")
+		return
+	}
+
+	for _, ticket := range tickets {
+		h.wsService.Broadcast(ticket.SessionID, models.SSEMessage{
+			Type: "ticket-synced",
+			Data: map[string]interface{}{
+				"source": "jira",
+				"ticket": ticket,
+				"closed": ticket.ExternalClosedAt != nil,
+			},
+		})
+		h.recordEvent(r.Context(), ticket.SessionID, services.EventTicketUpdated, ticket.ID, "", map[string]interface{}{
+			"source": "jira",
+			"title":  ticket.Title,
+			"closed": ticket.ExternalClosedAt != nil,
+		})
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// lastVote returns what a user last voted in a session, or nil if they have
+// not voted or it cannot be loaded.
+func (h *Handler) lastVote(ctx context.Context, sessionID, userID string) *string {
+	lastVote, err := h.userService.LastVote(ctx, sessionID, userID)
+	if err != nil {
+		utils.LogError("lastVote", err, utils.ReportContext{SessionID: sessionID, UserID: userID})
+		return nil
+	}
+	if lastVote == nil {
+		return nil
+	}
+	return lastVote.Vote
+}
+
+// GetLastVote returns what the current user last voted in the session and
+// the session's cards, for quick re-votes and voting by number keys.
+func (h *Handler) GetLastVote(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	lastVote, err := h.userService.LastVote(r.Context(), sessionID, user.ID)
+	if err != nil {
+		utils.LogError("GetLastVote", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get last vote")
+		return
+	}
+	if lastVote == nil {
+		utils.WriteJSONError(w, http.StatusForbidden, "Not a session participant")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSON(w, http.StatusOK, lastVote)
+}
+
+// SetLastVote replaces the current user's last vote in the session, which
+// the next repeated vote casts.
+func (h *Handler) SetLastVote(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("SetLastVote", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteJSONError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	vote := utils.SanitizeInput(r.FormValue("vote"))
+	if validationErrors := utils.ValidateVoteValue(vote, session.Deck()); validationErrors.HasErrors() {
+		utils.WriteJSONError(w, http.StatusBadRequest, validationErrors.Error())
+		return
+	}
+
+	if err := h.userService.SetLastVote(r.Context(), sessionID, user.ID, vote); err != nil {
+		writeServiceError(w, r, "SetLastVote", err, "Failed to set last vote")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RepeatVote casts the current user's last vote in the session again on the
+// current ticket: "same as last time".
+func (h *Handler) RepeatVote(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	lastVote, err := h.userService.LastVote(r.Context(), sessionID, user.ID)
+	if err != nil {
+		utils.LogError("RepeatVote", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get last vote")
+		return
+	}
+	if lastVote == nil {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Not a session participant")
+		return
+	}
+	if lastVote.Vote == nil {
+		utils.WriteHTMLError(w, http.StatusConflict, "You have not voted in this session yet")
+		return
+	}
+
+	h.submitVote(w, r, sessionID, user, *lastVote.Vote, false)
+}
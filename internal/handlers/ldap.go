@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ErrInvalidCredentials is returned when the directory does not know the
+// user or rejects their password.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// LDAPConfig describes how to reach the directory and find users in it.
+type LDAPConfig struct {
+	URL            string // ldap:// or ldaps://
+	StartTLS       bool   // upgrade an ldap:// connection before binding
+	BindDN         string // account used to search for users; empty binds anonymously
+	BindPassword   string
+	BaseDN         string
+	UserFilter     string // %s is replaced by the escaped login name
+	NameAttribute  string // shown as the username, e.g. displayName
+	GroupAttribute string // lists the user's groups, e.g. memberOf
+	Groups         []LDAPGroupMapping
+	Timeout        time.Duration
+}
+
+// LDAPGroupMapping grants the members of a directory group a role in an
+// organization and, optionally, a place on one of its teams.
+type LDAPGroupMapping struct {
+	Group          *ldap.DN
+	OrganizationID string
+	TeamID         string
+	Role           models.OrganizationRole
+}
+
+// ParseLDAPGroupMappings parses semicolon-separated mappings of the form
+// "group DN => orgID[/teamID][:admin]".
+func ParseLDAPGroupMappings(value string) ([]LDAPGroupMapping, error) {
+	var mappings []LDAPGroupMapping
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		groupDN, target, ok := strings.Cut(entry, "=>")
+		if !ok {
+			return nil, fmt.Errorf("mapping %q: expected group DN => organization ID", entry)
+		}
+
+		group, err := ldap.ParseDN(strings.TrimSpace(groupDN))
+		if err != nil {
+			return nil, fmt.Errorf("mapping %q: invalid group DN: %w", entry, err)
+		}
+
+		mapping := LDAPGroupMapping{Group: group, Role: models.RoleMember}
+		target = strings.TrimSpace(target)
+		if rest, role, ok := strings.Cut(target, ":"); ok {
+			if mapping.Role, ok = models.ParseOrganizationRole(role); !ok {
+				return nil, fmt.Errorf("mapping %q: unknown role %q", entry, role)
+			}
+			target = rest
+		}
+		mapping.OrganizationID, mapping.TeamID, _ = strings.Cut(target, "/")
+		if mapping.OrganizationID == "" {
+			return nil, fmt.Errorf("mapping %q: missing organization ID", entry)
+		}
+
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// LDAPIdentity is what the directory says about a user who signed in.
+type LDAPIdentity struct {
+	DN     string
+	Name   string
+	Groups []*ldap.DN
+}
+
+// LDAPAuth signs users in against an LDAP or Active Directory server.
+type LDAPAuth struct {
+	config LDAPConfig
+}
+
+// NewLDAPAuth checks the config and fills in defaults that suit OpenLDAP.
+// Active Directory needs a UserFilter such as (sAMAccountName=%s).
+func NewLDAPAuth(config LDAPConfig) (*LDAPAuth, error) {
+	if _, err := url.Parse(config.URL); err != nil {
+		return nil, fmt.Errorf("invalid LDAP URL: %w", err)
+	}
+	if config.BaseDN == "" {
+		return nil, errors.New("LDAP base DN is required")
+	}
+	if config.UserFilter == "" {
+		config.UserFilter = "(uid=%s)"
+	}
+	if !strings.Contains(config.UserFilter, "%s") {
+		return nil, fmt.Errorf("LDAP user filter %q must contain %%s", config.UserFilter)
+	}
+	if config.NameAttribute == "" {
+		config.NameAttribute = "cn"
+	}
+	if config.GroupAttribute == "" {
+		config.GroupAttribute = "memberOf"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &LDAPAuth{config: config}, nil
+}
+
+// Groups is the list of group mappings the directory manages.
+func (a *LDAPAuth) Groups() []LDAPGroupMapping {
+	return a.config.Groups
+}
+
+func (a *LDAPAuth) dial() (*ldap.Conn, error) {
+	addr, err := url.Parse(a.config.URL)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{ServerName: addr.Hostname()}
+
+	conn, err := ldap.DialURL(a.config.URL, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	conn.SetTimeout(a.config.Timeout)
+
+	if a.config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// Authenticate looks the user up with the search account, then binds as
+// them to check their password.
+func (a *LDAPAuth) Authenticate(username, password string) (*LDAPIdentity, error) {
+	// An empty password is an unauthenticated bind, which servers accept
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if a.config.BindDN != "" {
+		err = conn.Bind(a.config.BindDN, a.config.BindPassword)
+	} else {
+		err = conn.UnauthenticatedBind("")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind search account: %w", err)
+	}
+
+	filter := strings.ReplaceAll(a.config.UserFilter, "%s", ldap.EscapeFilter(username))
+	search := ldap.NewSearchRequest(
+		a.config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(a.config.Timeout.Seconds()), false,
+		filter, []string{a.config.NameAttribute, a.config.GroupAttribute}, nil,
+	)
+	result, err := conn.Search(search)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("failed to search for user: %w", err)
+	}
+	// Unknown and ambiguous logins are refused alike
+	if result == nil || len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to bind as user: %w", err)
+	}
+
+	identity := &LDAPIdentity{DN: entry.DN, Name: entry.GetAttributeValue(a.config.NameAttribute)}
+	for _, value := range entry.GetAttributeValues(a.config.GroupAttribute) {
+		group, err := ldap.ParseDN(value)
+		if err != nil {
+			continue
+		}
+		identity.Groups = append(identity.Groups, group)
+	}
+	return identity, nil
+}
+
+// inGroup reports whether the identity is a member of the group.
+func (i *LDAPIdentity) inGroup(group *ldap.DN) bool {
+	for _, g := range i.Groups {
+		if g.EqualFold(group) {
+			return true
+		}
+	}
+	return false
+}
+
+// syncDirectoryMemberships makes the user's memberships in the organizations
+// and teams named by the group mappings match their directory groups. Those
+// organizations are managed by the directory: members who are in none of
+// their groups are removed on sign-in, and where a group grants admin, only
+// its members stay admins. Failures are logged so sign-in still succeeds.
+func (h *Handler) syncDirectoryMemberships(ctx context.Context, userID string, identity *LDAPIdentity) {
+	roles := make(map[string]models.OrganizationRole) // org ID -> granted role, "" if none
+	adminGroups := make(map[string]bool)              // orgs whose admins come from the directory
+	teams := make(map[string]bool)                    // team ID -> granted
+	teamOrgs := make(map[string]string)               // team ID -> org ID
+
+	for _, mapping := range h.config.LDAP.Groups() {
+		granted := identity.inGroup(mapping.Group)
+		if _, ok := roles[mapping.OrganizationID]; !ok {
+			roles[mapping.OrganizationID] = ""
+		}
+		if mapping.Role == models.RoleAdmin {
+			adminGroups[mapping.OrganizationID] = true
+		}
+		if granted && roles[mapping.OrganizationID] != models.RoleAdmin {
+			roles[mapping.OrganizationID] = mapping.Role
+		}
+		if mapping.TeamID != "" {
+			teams[mapping.TeamID] = teams[mapping.TeamID] || granted
+			teamOrgs[mapping.TeamID] = mapping.OrganizationID
+		}
+	}
+
+	for orgID, role := range roles {
+		current, err := h.organizationService.GetMemberRole(ctx, orgID, userID)
+		if err != nil {
+			utils.LogError("syncDirectoryMemberships", err)
+			continue
+		}
+
+		switch {
+		case role == "" && current != "":
+			err = h.organizationService.RemoveMember(ctx, orgID, userID)
+		case role == "":
+		case current == "":
+			_, err = h.organizationService.AddMember(ctx, orgID, userID, role)
+		case role == models.RoleAdmin && current != models.RoleAdmin,
+			role == models.RoleMember && current == models.RoleAdmin && adminGroups[orgID]:
+			err = h.organizationService.SetMemberRole(ctx, orgID, userID, role)
+		}
+		if err != nil {
+			utils.LogError("syncDirectoryMemberships", fmt.Errorf("organization %s: %w", orgID, err))
+		}
+	}
+
+	for teamID, granted := range teams {
+		var err error
+		if !granted {
+			err = h.teamService.RemoveTeamMember(ctx, teamID, userID)
+			if errors.Is(err, services.ErrMemberNotFound) {
+				err = nil
+			}
+		} else if roles[teamOrgs[teamID]] != "" {
+			_, err = h.teamService.AddTeamMember(ctx, teamID, userID)
+		}
+		if err != nil {
+			utils.LogError("syncDirectoryMemberships", fmt.Errorf("team %s: %w", teamID, err))
+		}
+	}
+}
+
+// signInWithDirectory replaces the username screen when LDAP is configured:
+// the user signs in with their directory login and password, and their
+// organization and team memberships are brought in line with their groups.
+func (h *Handler) signInWithDirectory(w http.ResponseWriter, r *http.Request) {
+	login := strings.TrimSpace(r.FormValue("username"))
+	identity, err := h.config.LDAP.Authenticate(login, r.FormValue("password"))
+	if errors.Is(err, ErrInvalidCredentials) {
+		// Slow down guessing
+		time.Sleep(time.Second)
+		utils.WriteFormValidationError(w, r, utils.ValidationErrors{{Field: "password", Message: "Incorrect username or password"}})
+		return
+	}
+	if err != nil {
+		utils.LogError("signInWithDirectory", err)
+		utils.WriteHTMLError(w, http.StatusBadGateway, "The directory is unavailable, try again later")
+		return
+	}
+
+	user, err := h.userService.GetOrCreateDirectoryUser(r.Context(), identity.DN, directoryUsername(identity.Name, login))
+	if err != nil {
+		utils.LogError("signInWithDirectory", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to sign in")
+		return
+	}
+
+	h.syncDirectoryMemberships(r.Context(), user.ID, identity)
+	h.signIn(w, r, user)
+}
+
+// directoryUsername turns the directory's name for a user into a valid
+// username, falling back to their login when the name is empty.
+func directoryUsername(candidates ...string) string {
+	for _, candidate := range candidates {
+		name := strings.Map(func(r rune) rune {
+			if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_') {
+				return r
+			}
+			return ' '
+		}, candidate)
+		name = strings.Join(strings.Fields(name), " ")
+		if len(name) > 50 {
+			name = strings.TrimSpace(name[:50])
+		}
+		if !utils.ValidateUsername(name).HasErrors() {
+			return name
+		}
+	}
+	return "User"
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+)
+
+// Limits caps how large a session may grow. Zero means unlimited. A session
+// can lower these with its own overrides but never raise them.
+type Limits struct {
+	MaxParticipants int
+	MaxTickets      int
+}
+
+func effectiveLimit(deploymentLimit int, override *int) int {
+	if override != nil && (deploymentLimit == 0 || *override < deploymentLimit) {
+		return *override
+	}
+	return deploymentLimit
+}
+
+func (l Limits) participantLimit(session *models.Session) int {
+	return effectiveLimit(l.MaxParticipants, session.MaxParticipants)
+}
+
+func (l Limits) ticketLimit(session *models.Session) int {
+	return effectiveLimit(l.MaxTickets, session.MaxTickets)
+}
+
+// parseSessionLimit parses a per-session limit override. An empty value clears
+// the override so the deployment default applies again.
+func parseSessionLimit(field, value string, deploymentLimit int) (*int, utils.ValidationErrors) {
+	if value == "" {
+		return nil, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		return nil, utils.ValidationErrors{{Field: field, Message: "Limit must be a positive number"}}
+	}
+
+	if deploymentLimit > 0 && limit > deploymentLimit {
+		return nil, utils.ValidationErrors{{Field: field, Message: fmt.Sprintf("Limit cannot exceed %d", deploymentLimit)}}
+	}
+
+	return &limit, nil
+}
+
+// sessionIsFull reports whether userID would be turned away from the session.
+// Existing participants can always come back.
+func (h *Handler) sessionIsFull(session *models.Session, userID string) bool {
+	limit := h.config.Limits.participantLimit(session)
+	if limit == 0 {
+		return false
+	}
+
+	for _, participant := range session.Participants {
+		if participant.ID == userID {
+			return false
+		}
+	}
+
+	return len(session.Participants) >= limit
+}
+
+// checkTicketLimit writes a 409 and returns false if adding more tickets to
+// the session would exceed its ticket limit.
+func (h *Handler) checkTicketLimit(w http.ResponseWriter, r *http.Request, session *models.Session, adding int) bool {
+	limit := h.config.Limits.ticketLimit(session)
+	if limit == 0 {
+		return true
+	}
+
+	count, err := h.ticketService.CountTickets(r.Context(), session.ID, services.TicketFilterAll)
+	if err != nil {
+		utils.LogError("CountTickets", err)
+		http.Error(w, "Failed to count tickets", http.StatusInternalServerError)
+		return false
+	}
+
+	if count+adding > limit {
+		utils.WriteHTMLError(w, http.StatusConflict, fmt.Sprintf("This session has reached its limit of %d tickets", limit))
+		return false
+	}
+
+	return true
+}
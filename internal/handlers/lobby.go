@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+)
+
+// lobbySize caps how many public sessions the lobby lists.
+const lobbySize = 100
+
+type LobbySession struct {
+	Session          models.Session
+	OwnerName        string
+	ParticipantCount int
+	IsFull           bool
+}
+
+func (h *Handler) Lobby(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/?redirect_to="+r.URL.Path, http.StatusSeeOther)
+		return
+	}
+
+	sessions, err := h.sessionService.GetPublicSessions(r.Context(), user.ID, lobbySize)
+	if err != nil {
+		utils.LogError("Lobby", err)
+		http.Error(w, "Failed to get public sessions", http.StatusInternalServerError)
+		return
+	}
+
+	lobby := make([]LobbySession, 0, len(sessions))
+	for i := range sessions {
+		lobby = append(lobby, h.lobbySession(&sessions[i], user.ID))
+	}
+
+	data := PageData{
+		Title:         "Lobby",
+		Template:      "lobby",
+		User:          user,
+		LobbySessions: lobby,
+	}
+
+	h.executeTemplate(w, "base.html", data)
+}
+
+// lobbySession describes a listed session as seen by userID.
+func (h *Handler) lobbySession(session *models.Session, userID string) LobbySession {
+	entry := LobbySession{
+		Session:          *session,
+		ParticipantCount: len(session.Participants),
+		IsFull:           h.sessionIsFull(session, userID),
+	}
+	for _, participant := range session.Participants {
+		if participant.ID == session.OwnerID {
+			entry.OwnerName = participant.Username
+			break
+		}
+	}
+	return entry
+}
@@ -13,7 +13,10 @@ const (
 	SessionCookieName = "poker_session"
 )
 
-func SessionMiddleware(userService *services.UserService) func(http.Handler) http.Handler {
+// SessionMiddleware loads the user signed in with the session cookie. With
+// loginTokens the cookie holds an opaque login token, as for directory
+// sign-ins; otherwise it holds the user ID.
+func SessionMiddleware(userService *services.UserService, loginTokens bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			cookie, err := r.Cookie(SessionCookieName)
@@ -22,7 +25,12 @@ func SessionMiddleware(userService *services.UserService) func(http.Handler) htt
 				return
 			}
 
-			user, err := userService.GetUserByID(cookie.Value)
+			var user *models.User
+			if loginTokens {
+				user, err = userService.GetUserByLoginSession(r.Context(), cookie.Value)
+			} else {
+				user, err = userService.GetUserByID(r.Context(), cookie.Value)
+			}
 			if err != nil {
 				http.SetCookie(w, &http.Cookie{
 					Name:     SessionCookieName,
@@ -49,11 +57,12 @@ func SessionMiddleware(userService *services.UserService) func(http.Handler) htt
 				return
 			}
 
-			userService.UpdateLastSeen(user.ID)
+			userService.UpdateLastSeen(r.Context(), user.ID)
+			annotateAccessLog(r.Context(), user.ID)
 
 			http.SetCookie(w, &http.Cookie{
 				Name:     SessionCookieName,
-				Value:    user.ID,
+				Value:    cookie.Value,
 				MaxAge:   6 * 3600, // 6 hours
 				Path:     "/",
 				HttpOnly: true,
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/stats"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// MobileState is the compact session state for phones: the current ticket,
+// the cards and the viewer's own vote. The backlog, the other participants
+// and their votes stay out of it, so it stays small on slow connections.
+type MobileState struct {
+	SessionID      string                    `json:"session_id"`
+	SessionName    string                    `json:"session_name"`
+	Unit           string                    `json:"unit"`
+	Phase          string                    `json:"phase"` // idle, voting or revealed, as in state-snapshot
+	Ticket         *MobileTicket             `json:"ticket"`
+	Cards          []string                  `json:"cards"`
+	CardStyles     map[string]deck.CardStyle `json:"card_styles"` // by card; cards without one have no style
+	MyVote         *string                   `json:"my_vote"`
+	Voted          int                       `json:"voted"`
+	Participants   int                       `json:"participants"` // who may vote: the breakout group, if the ticket has one
+	VotesLocked    bool                      `json:"votes_locked"`
+	CanVote        bool                      `json:"can_vote"` // false outside the current ticket's breakout group
+	VotingDeadline *time.Time                `json:"voting_deadline,omitempty"`
+	Results        []MobileCount             `json:"results,omitempty"` // once revealed, in card order
+	Median         *float64                  `json:"median,omitempty"`
+}
+
+// MobileTicket is the part of the current ticket a phone needs to vote on it.
+type MobileTicket struct {
+	ID            int     `json:"id"`
+	Title         string  `json:"title"`
+	ExternalKey   *string `json:"external_key,omitempty"`
+	FinalEstimate *string `json:"final_estimate,omitempty"`
+}
+
+// MobileCount is how many votes a card got.
+type MobileCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// CardStyle is how a card is shown.
+func (s MobileState) CardStyle(card string) deck.CardStyle {
+	return s.CardStyles[card]
+}
+
+// IsMyVote reports whether the viewer voted card on the current ticket.
+func (s MobileState) IsMyVote(card string) bool {
+	return s.MyVote != nil && *s.MyVote == card
+}
+
+// mobileState builds the compact state of a session loaded without its
+// tickets, as seen by userID.
+func (h *Handler) mobileState(session *models.Session, userID string) MobileState {
+	state := MobileState{
+		SessionID:    session.ID,
+		SessionName:  session.Name,
+		Unit:         session.EstimationUnit,
+		Phase:        "idle",
+		Cards:        session.Deck(),
+		CardStyles:   session.CardStyles(),
+		Participants: len(session.Participants),
+		VotesLocked:  session.VotesLocked(),
+		CanVote:      true,
+	}
+
+	ticket := session.CurrentTicket
+	if ticket == nil {
+		return state
+	}
+
+	state.Ticket = &MobileTicket{
+		ID:            ticket.ID,
+		Title:         ticket.Title,
+		ExternalKey:   ticket.ExternalKey,
+		FinalEstimate: ticket.FinalEstimate,
+	}
+	state.Voted = len(ticket.Votes)
+	if len(ticket.BreakoutVoters) > 0 {
+		state.Participants = len(ticket.BreakoutVoters)
+		state.CanVote = ticket.CanVote(userID)
+	}
+	for _, vote := range ticket.Votes {
+		if vote.UserID == userID {
+			value := vote.VoteValue
+			state.MyVote = &value
+			break
+		}
+	}
+
+	switch {
+	case session.IsVotingActive:
+		state.Phase = "voting"
+		state.VotingDeadline = session.VotingDeadline()
+	case len(ticket.Votes) > 0:
+		state.Phase = "revealed"
+		cards := stats.ForSession(session)
+		for _, count := range cards.Histogram(ticket.Votes) {
+			if count.Count > 0 {
+				state.Results = append(state.Results, MobileCount{Value: count.Value, Count: count.Count})
+			}
+		}
+		if ticketStats := cards.Ticket(ticket.Votes); ticketStats.HasValues {
+			median := ticketStats.Median
+			state.Median = &median
+		}
+	}
+	return state
+}
+
+// MobileSession serves the lightweight voting page for phones, joining the
+// session like the full page does. HTMX refreshes get just the state.
+func (h *Handler) MobileSession(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/?redirect_to="+r.URL.Path, http.StatusSeeOther)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("MobileSession", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	// Sessions of an organization do not exist for outsiders
+	if ok, err := h.canSeeOrganization(r.Context(), session.OrganizationID, user.ID); err != nil {
+		http.Error(w, "Failed to check organization", http.StatusInternalServerError)
+		return
+	} else if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if h.sessionIsFull(session, user.ID) {
+		http.Error(w, fmt.Sprintf("Session is full (%d participants)", h.config.Limits.participantLimit(session)), http.StatusForbidden)
+		return
+	}
+
+	userJoined, err := h.sessionService.JoinSession(r.Context(), sessionID, user.ID)
+	if err != nil {
+		http.Error(w, "Failed to join session", http.StatusInternalServerError)
+		return
+	}
+	if userJoined {
+		h.broadcastState(r.Context(), sessionID, models.SSEMessage{
+			Type: "user-joined",
+			Data: user,
+		})
+		session, err = h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+		if err != nil || session == nil {
+			http.Error(w, "Failed to refresh session", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	state := h.mobileState(session, user.ID)
+	w.Header().Set("Cache-Control", "no-store")
+	if r.Header.Get("HX-Request") != "" {
+		h.executeTemplate(w, "mobile-state", state)
+		return
+	}
+	h.executeTemplate(w, "mobile", state)
+}
+
+// GetMobileState returns the compact session state as JSON for the mobile
+// page and other small clients.
+func (h *Handler) GetMobileState(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("GetMobileState", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteJSONError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	isParticipant := false
+	for _, participant := range session.Participants {
+		if participant.ID == user.ID {
+			isParticipant = true
+			break
+		}
+	}
+	if !isParticipant {
+		utils.WriteJSONError(w, http.StatusForbidden, "Not a session participant")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSON(w, http.StatusOK, h.mobileState(session, user.ID))
+}
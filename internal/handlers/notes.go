@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// facilitatorNotes returns the owner's private notes on a session, or nil if
+// they cannot be loaded. Only call it for the session owner.
+func (h *Handler) facilitatorNotes(r *http.Request, session *models.Session) *models.FacilitatorNotes {
+	notes, err := h.sessionService.GetFacilitatorNotes(r.Context(), session.ID)
+	if err != nil {
+		utils.LogError("facilitatorNotes", err, utils.ReportContext{SessionID: session.ID, UserID: session.OwnerID})
+		return nil
+	}
+	return notes
+}
+
+// GetFacilitatorNotes shows the owner's private notes on the session and
+// each of its tickets. The notes have their own page, rather than a part of
+// the session page, so nothing rendered for participants can carry them.
+func (h *Handler) GetFacilitatorNotes(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/?redirect_to="+r.URL.Path, http.StatusSeeOther)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("GetFacilitatorNotes", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only the session owner can see facilitator notes", http.StatusForbidden)
+		return
+	}
+
+	notes := h.facilitatorNotes(r, session)
+	if notes == nil {
+		http.Error(w, "Failed to get notes", http.StatusInternalServerError)
+		return
+	}
+
+	data := PageData{
+		Title:            "Notes - " + session.Name,
+		Template:         "notes",
+		User:             user,
+		Session:          session,
+		FacilitatorNotes: notes,
+	}
+
+	h.executeTemplate(w, "base.html", data)
+}
+
+// SetFacilitatorNote saves the owner's private note on the session, or on
+// one of its tickets when the form has ticket_id. Saving an empty note
+// removes it. Nothing is broadcast, so participants never learn of it.
+func (h *Handler) SetFacilitatorNote(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	session := h.participantSession(w, r, "SetFacilitatorNote", user)
+	if session == nil {
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can write facilitator notes")
+		return
+	}
+
+	var ticketID *int
+	if value := r.FormValue("ticket_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid ticket ID")
+			return
+		}
+		ticketID = &id
+	}
+
+	text := utils.SanitizeInput(r.FormValue("text"))
+	if validationErrors := utils.ValidateFacilitatorNote(text); validationErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, validationErrors)
+		return
+	}
+
+	if err := h.sessionService.SetFacilitatorNote(r.Context(), session.ID, ticketID, text); err != nil {
+		writeServiceError(w, r, "SetFacilitatorNote", err, "Failed to save note")
+		return
+	}
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+session.ID+"/notes")
+}
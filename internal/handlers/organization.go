@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// canSeeOrganization reports whether a user may see something that belongs
+// to an organization. Sessions outside any organization stay open to anyone
+// with the link.
+func (h *Handler) canSeeOrganization(ctx context.Context, orgID *string, userID string) (bool, error) {
+	if orgID == nil {
+		return true, nil
+	}
+
+	role, err := h.organizationService.GetMemberRole(ctx, *orgID, userID)
+	if err != nil {
+		return false, err
+	}
+	return role != "", nil
+}
+
+// sameOrganization compares organization IDs, where nil means none.
+func sameOrganization(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// formOrganization reads the organization a new session or project goes
+// into from the organization_id form field. Users can only create things in
+// organizations they belong to.
+func (h *Handler) formOrganization(r *http.Request, userID string) (*string, utils.ValidationErrors, error) {
+	orgID := r.FormValue("organization_id")
+	if orgID == "" {
+		return nil, nil, nil
+	}
+
+	role, err := h.organizationService.GetMemberRole(r.Context(), orgID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if role == "" {
+		return nil, utils.ValidationErrors{{Field: "organization_id", Message: "You are not a member of this organization"}}, nil
+	}
+	return &orgID, nil, nil
+}
+
+func (h *Handler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	name := utils.SanitizeInput(r.FormValue("name"))
+	if validationErrors := utils.ValidateOrganizationName(name); validationErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
+		return
+	}
+
+	org, err := h.organizationService.CreateOrganization(r.Context(), name, user.ID)
+	if err != nil {
+		utils.LogError("CreateOrganization", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to create organization")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/org/"+org.ID)
+}
+
+// GetOrganization shows an organization and its teams to its members.
+// Admins also see every session in it and manage the members and teams;
+// others see its public sessions.
+func (h *Handler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/?redirect_to="+r.URL.Path, http.StatusSeeOther)
+		return
+	}
+
+	org, role, ok := h.getMemberOrganization(w, r, user)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.GetOrganizationSessions(r.Context(), org.ID)
+	if err != nil {
+		utils.LogError("GetOrganization", err)
+		http.Error(w, "Failed to get organization sessions", http.StatusInternalServerError)
+		return
+	}
+
+	teams, err := h.teamService.GetTeamsForOrganization(r.Context(), org.ID)
+	if err != nil {
+		utils.LogError("GetOrganization", err)
+		http.Error(w, "Failed to get organization teams", http.StatusInternalServerError)
+		return
+	}
+
+	var listed []LobbySession
+	for i := range sessions {
+		session := &sessions[i]
+		if role != models.RoleAdmin && !session.IsPublic {
+			continue
+		}
+		listed = append(listed, h.lobbySession(session, user.ID))
+	}
+
+	data := PageData{
+		Title:            org.Name,
+		Template:         "organization",
+		User:             user,
+		Organization:     org,
+		OrganizationRole: role,
+		LobbySessions:    listed,
+		Teams:            teams,
+		EstimationUnits:  deck.Units,
+		Location:         viewerLocation(r, user),
+	}
+
+	h.executeTemplate(w, "base.html", data)
+}
+
+// JoinOrganization adds the user to the organization an invite link is for.
+func (h *Handler) JoinOrganization(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/?redirect_to="+r.URL.Path, http.StatusSeeOther)
+		return
+	}
+
+	org, err := h.organizationService.GetOrganizationByInviteCode(r.Context(), chi.URLParam(r, "code"))
+	if err != nil {
+		http.Error(w, "Failed to get organization", http.StatusInternalServerError)
+		return
+	}
+	if org == nil {
+		http.Error(w, "Invite link is invalid or has been replaced", http.StatusNotFound)
+		return
+	}
+
+	if _, err := h.organizationService.AddMember(r.Context(), org.ID, user.ID, models.RoleMember); err != nil {
+		utils.LogError("JoinOrganization", err)
+		http.Error(w, "Failed to join organization", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/org/"+org.ID, http.StatusSeeOther)
+}
+
+// SetOrganizationMemberRole lets an admin promote or demote a member.
+func (h *Handler) SetOrganizationMemberRole(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	org, ok := h.getAdminOrganization(w, r, user, "change roles")
+	if !ok {
+		return
+	}
+
+	role, ok := models.ParseOrganizationRole(r.FormValue("role"))
+	if !ok {
+		http.Error(w, "Invalid role, expected admin or member", http.StatusBadRequest)
+		return
+	}
+
+	err := h.organizationService.SetMemberRole(r.Context(), org.ID, chi.URLParam(r, "userID"), role)
+	if err != nil {
+		writeServiceError(w, r, "SetOrganizationMemberRole", err, "Failed to change role")
+		return
+	}
+
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveOrganizationMember lets an admin remove a member, or a member leave.
+func (h *Handler) RemoveOrganizationMember(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	memberID := chi.URLParam(r, "userID")
+
+	var org *models.Organization
+	var ok bool
+	if memberID == user.ID {
+		org, _, ok = h.getMemberOrganization(w, r, user)
+	} else {
+		org, ok = h.getAdminOrganization(w, r, user, "remove members")
+	}
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.RemoveMember(r.Context(), org.ID, memberID); err != nil {
+		writeServiceError(w, r, "RemoveOrganizationMember", err, "Failed to remove member")
+		return
+	}
+
+	if memberID == user.ID {
+		w.Header().Set("HX-Redirect", "/")
+	} else {
+		w.Header().Set("HX-Refresh", "true")
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RegenerateOrganizationInvite replaces the invite link of an organization,
+// for when the old one has leaked.
+func (h *Handler) RegenerateOrganizationInvite(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	org, ok := h.getAdminOrganization(w, r, user, "replace the invite link")
+	if !ok {
+		return
+	}
+
+	if _, err := h.organizationService.RegenerateInviteCode(r.Context(), org.ID); err != nil {
+		utils.LogError("RegenerateOrganizationInvite", err)
+		http.Error(w, "Failed to replace invite link", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getMemberOrganization loads the organization in the URL along with the
+// user's role, writing an error response if it does not exist or the user
+// is not a member. Non-members get a 404 so organizations stay unlisted.
+func (h *Handler) getMemberOrganization(w http.ResponseWriter, r *http.Request, user *models.User) (*models.Organization, models.OrganizationRole, bool) {
+	orgID := chi.URLParam(r, "orgID")
+
+	org, err := h.organizationService.GetOrganizationByID(r.Context(), orgID)
+	if err != nil {
+		http.Error(w, "Failed to get organization", http.StatusInternalServerError)
+		return nil, "", false
+	}
+
+	var role models.OrganizationRole
+	if org != nil {
+		for _, member := range org.Members {
+			if member.ID == user.ID {
+				role = member.Role
+				break
+			}
+		}
+	}
+
+	if role == "" {
+		http.Error(w, "Organization not found", http.StatusNotFound)
+		return nil, "", false
+	}
+	return org, role, true
+}
+
+// getAdminOrganization is getMemberOrganization for actions only admins may
+// take.
+func (h *Handler) getAdminOrganization(w http.ResponseWriter, r *http.Request, user *models.User, action string) (*models.Organization, bool) {
+	org, role, ok := h.getMemberOrganization(w, r, user)
+	if !ok {
+		return nil, false
+	}
+
+	if role != models.RoleAdmin {
+		http.Error(w, "Only organization admins can "+action, http.StatusForbidden)
+		return nil, false
+	}
+	return org, true
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// parkingLot returns a session's parking lot, or nil if it cannot be
+// loaded.
+func (h *Handler) parkingLot(ctx context.Context, sessionID string) []models.ParkingLotItem {
+	items, err := h.sessionService.GetParkingLot(ctx, sessionID)
+	if err != nil {
+		utils.LogError("parkingLot", err, utils.ReportContext{SessionID: sessionID})
+		return nil
+	}
+	return items
+}
+
+// broadcastParkingLot sends everyone in the session its parking lot as it
+// is now.
+func (h *Handler) broadcastParkingLot(ctx context.Context, sessionID string) {
+	items, err := h.sessionService.GetParkingLot(ctx, sessionID)
+	if err != nil {
+		utils.LogError("broadcastParkingLot", err, utils.ReportContext{SessionID: sessionID})
+		return
+	}
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "parking-lot-updated",
+		Data: items,
+	})
+}
+
+// participantSession loads a session for one of its participants, writing
+// the error response and returning nil otherwise.
+func (h *Handler) participantSession(w http.ResponseWriter, r *http.Request, operation string, user *models.User) *models.Session {
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError(operation, err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return nil
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return nil
+	}
+
+	for _, participant := range session.Participants {
+		if participant.ID == user.ID {
+			return session
+		}
+	}
+	http.Error(w, "Not a session participant", http.StatusForbidden)
+	return nil
+}
+
+// GetParkingLot returns the session's parking lot as JSON, oldest first.
+func (h *Handler) GetParkingLot(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	session := h.participantSession(w, r, "GetParkingLot", user)
+	if session == nil {
+		return
+	}
+
+	items, err := h.sessionService.GetParkingLot(r.Context(), session.ID)
+	if err != nil {
+		utils.LogError("GetParkingLot", err, utils.ReportContext{SessionID: session.ID, UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get parking lot")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, items)
+}
+
+// AddParkingLotItem parks a question or risk from any participant, so it
+// can be followed up after the session instead of holding up the vote.
+// The form has text, kind (question by default) and optionally ticket_id.
+func (h *Handler) AddParkingLotItem(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	item := models.ParkingLotItem{
+		SessionID: sessionID,
+		UserID:    user.ID,
+		Username:  user.Username,
+		Kind:      models.ParkingLotQuestion,
+		Text:      utils.SanitizeInput(r.FormValue("text")),
+	}
+
+	if value := r.FormValue("kind"); value != "" {
+		kind, ok := models.ParseParkingLotKind(value)
+		if !ok {
+			utils.WriteHTMLError(w, http.StatusBadRequest, "Parking lot items are questions or risks")
+			return
+		}
+		item.Kind = kind
+	}
+	if value := r.FormValue("ticket_id"); value != "" {
+		ticketID, err := strconv.Atoi(value)
+		if err != nil {
+			utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid ticket ID")
+			return
+		}
+		item.TicketID = &ticketID
+	}
+	if validationErrors := utils.ValidateParkingLotText(item.Text); validationErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, validationErrors)
+		return
+	}
+
+	saved, err := h.sessionService.AddParkingLotItem(r.Context(), item)
+	if err != nil {
+		writeServiceError(w, r, "AddParkingLotItem", err, "Failed to add parking lot item")
+		return
+	}
+	h.broadcastParkingLot(r.Context(), sessionID)
+
+	if expectsPage(r) {
+		http.Redirect(w, r, "/session/"+sessionID, http.StatusSeeOther)
+		return
+	}
+	utils.WriteJSON(w, http.StatusCreated, saved)
+}
+
+// DeleteParkingLotItem takes an item out of the parking lot. Whoever raised
+// it and the session owner can.
+func (h *Handler) DeleteParkingLotItem(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	itemID, err := strconv.Atoi(chi.URLParam(r, "itemID"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid parking lot item ID")
+		return
+	}
+
+	session := h.participantSession(w, r, "DeleteParkingLotItem", user)
+	if session == nil {
+		return
+	}
+
+	err = h.sessionService.DeleteParkingLotItem(r.Context(), session.ID, itemID, user.ID, session.OwnerID == user.ID)
+	if err != nil {
+		writeServiceError(w, r, "DeleteParkingLotItem", err, "Failed to delete parking lot item")
+		return
+	}
+	h.broadcastParkingLot(r.Context(), session.ID)
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+session.ID)
+}
+
+// ExportParkingLotCSV downloads the session's parking lot, for following
+// the items up in a tracker.
+func (h *Handler) ExportParkingLotCSV(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	session := h.participantSession(w, r, "ExportParkingLotCSV", user)
+	if session == nil {
+		return
+	}
+
+	items, err := h.sessionService.GetParkingLot(r.Context(), session.ID)
+	if err != nil {
+		utils.LogError("ExportParkingLotCSV", err, utils.ReportContext{SessionID: session.ID, UserID: user.ID})
+		http.Error(w, "Failed to get parking lot", http.StatusInternalServerError)
+		return
+	}
+
+	// Timestamps are ISO-8601 with the viewer's UTC offset
+	loc := viewerLocation(r, user)
+
+	filename := fmt.Sprintf("parking-lot-%s-%s.csv", session.ID, time.Now().In(loc).Format("2006-01-02"))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	header := []string{"Session Name", "Session ID", "Kind", "Text", "Ticket Title", "Raised By", "Raised At"}
+	if err := writer.Write(header); err != nil {
+		http.Error(w, "Failed to write CSV header", http.StatusInternalServerError)
+		return
+	}
+
+	for _, item := range items {
+		record := []string{
+			session.Name,
+			session.ID,
+			item.Kind.Label(),
+			item.Text,
+			item.TicketTitle,
+			item.Username,
+			item.CreatedAt.In(loc).Format(time.RFC3339),
+		}
+		if err := writer.Write(record); err != nil {
+			http.Error(w, "Failed to write CSV record", http.StatusInternalServerError)
+			return
+		}
+	}
+}
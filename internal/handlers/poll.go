@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// pollTimeout is how long a poll waits for events before answering
+	// empty. It stays under the idle timeouts of common proxies.
+	pollTimeout = 25 * time.Second
+	// pollInterval is how often a waiting poll checks the event log.
+	pollInterval = time.Second
+	// pollBatchSize caps the events in one response.
+	pollBatchSize = 100
+	// pollErrorRetry is how long clients should back off after a failed poll.
+	pollErrorRetry = 5 * time.Second
+)
+
+// PollResponse is a batch of session events for long-polling clients. Seq is
+// the ID of the last event the client has seen, to send back as since.
+// RetryAfterMS is how long to wait before polling again: 0 while events are
+// flowing, longer after a poll that timed out empty.
+type PollResponse struct {
+	Events       []models.SessionEvent `json:"events"`
+	Seq          int64                 `json:"seq"`
+	More         bool                  `json:"more"` // more events are waiting, poll again straight away
+	RetryAfterMS int                   `json:"retry_after_ms"`
+}
+
+// PollSession is the long-polling fallback for clients behind proxies that
+// block WebSockets. It answers as soon as the session's event log has events
+// after since, or empty after pollTimeout. Without since it answers at once
+// with the current seq to start from.
+func (h *Handler) PollSession(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+
+	var since int64
+	value := r.URL.Query().Get("since")
+	if value != "" {
+		var err error
+		since, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || since < 0 {
+			utils.WriteJSONError(w, http.StatusBadRequest, "since must be an event sequence number")
+			return
+		}
+	}
+
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("PollSession", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		writePollError(w)
+		return
+	}
+	if session == nil {
+		// Gone rather than not found: the session ended, so stop polling
+		utils.WriteJSONError(w, http.StatusGone, "Session has ended")
+		return
+	}
+
+	isParticipant := false
+	for _, participant := range session.Participants {
+		if participant.ID == user.ID {
+			isParticipant = true
+			break
+		}
+	}
+	if !isParticipant {
+		utils.WriteJSONError(w, http.StatusForbidden, "Not a session participant")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	if value == "" {
+		seq, err := h.eventService.LatestEventID(r.Context(), sessionID)
+		if err != nil {
+			utils.LogError("PollSession", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+			writePollError(w)
+			return
+		}
+		utils.WriteJSON(w, http.StatusOK, PollResponse{Events: []models.SessionEvent{}, Seq: seq})
+		return
+	}
+
+	timeout := time.NewTimer(pollTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		events, err := h.eventService.EventsSince(r.Context(), sessionID, since, pollBatchSize+1)
+		if err != nil {
+			if r.Context().Err() != nil {
+				return
+			}
+			utils.LogError("PollSession", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+			writePollError(w)
+			return
+		}
+
+		if len(events) > 0 {
+			more := len(events) > pollBatchSize
+			if more {
+				events = events[:pollBatchSize]
+			}
+			redactEvents(session, events)
+			utils.WriteJSON(w, http.StatusOK, PollResponse{
+				Events: events,
+				Seq:    events[len(events)-1].ID,
+				More:   more,
+			})
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-timeout.C:
+			// Nothing happened; a short pause keeps idle clients from
+			// reconnecting in a tight loop through proxies that cut polls short
+			utils.WriteJSON(w, http.StatusOK, PollResponse{
+				Events:       events,
+				Seq:          since,
+				RetryAfterMS: int(pollInterval / time.Millisecond),
+			})
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// redactEvents hides from the events participants poll what they must not
+// see: vote and pre-vote values before the reveal, and who voted what in a
+// Delphi session. Neither is logged any more, but older events carry them.
+func redactEvents(session *models.Session, events []models.SessionEvent) {
+	for i := range events {
+		switch events[i].Type {
+		case services.EventVoteCast, services.EventPrevoteCast:
+			events[i].Data = json.RawMessage("{}")
+		case services.EventVotesRevealed:
+			if session.IsDelphi() {
+				events[i].Data = anonymousReveal(events[i].Data)
+			}
+		}
+	}
+}
+
+// anonymousReveal drops the voters from a votes-revealed event's data. Data
+// it cannot read is dropped altogether.
+func anonymousReveal(data json.RawMessage) json.RawMessage {
+	var fields map[string]json.RawMessage
+	var votes []map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil || json.Unmarshal(fields["votes"], &votes) != nil {
+		return json.RawMessage("{}")
+	}
+
+	for _, vote := range votes {
+		delete(vote, "user_id")
+	}
+	encoded, err := json.Marshal(votes)
+	if err != nil {
+		return json.RawMessage("{}")
+	}
+	fields["votes"] = encoded
+	if data, err = json.Marshal(fields); err != nil {
+		return json.RawMessage("{}")
+	}
+	return data
+}
+
+// writePollError tells the client to back off before polling again.
+func writePollError(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(pollErrorRetry/time.Second)))
+	utils.WriteJSONError(w, http.StatusServiceUnavailable, "Failed to get events, try again later")
+}
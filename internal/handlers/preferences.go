@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+)
+
+// UpdatePreferences replaces the current user's preferences. Checkbox fields
+// that are absent from the form are stored as false.
+func (h *Handler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	prefs := models.UserPreferences{
+		PreferredUnit:       r.PostForm.Get("preferred_unit"),
+		AutoReady:           r.PostForm.Get("auto_ready") == "true",
+		ReducedMotion:       r.PostForm.Get("reduced_motion") == "true",
+		Timezone:            utils.SanitizeInput(r.PostForm.Get("timezone")),
+		NotifyVotingStarted: r.PostForm.Get("notify_voting_started") == "true",
+		NotifyVotesRevealed: r.PostForm.Get("notify_votes_revealed") == "true",
+		NotificationLevel:   r.PostForm.Get("notification_level"),
+		DoNotDisturb:        r.PostForm.Get("do_not_disturb") == "true",
+	}
+
+	var allErrors utils.ValidationErrors
+	if prefs.PreferredUnit != "" {
+		if _, ok := deck.ParseUnit(prefs.PreferredUnit); !ok {
+			allErrors = append(allErrors, utils.ValidationError{Field: "preferred_unit", Message: "Invalid estimation unit"})
+		}
+	}
+	if prefs.NotificationLevel != "" && !services.IsNotificationLevel(prefs.NotificationLevel) {
+		allErrors = append(allErrors, utils.ValidationError{Field: "notification_level", Message: "Notification level must be silent, subtle or chime"})
+	}
+	allErrors = append(allErrors, utils.ValidateTimezone(prefs.Timezone)...)
+
+	if allErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, allErrors.Error())
+		return
+	}
+
+	err := h.userService.UpdatePreferences(r.Context(), user.ID, prefs)
+	if err != nil {
+		utils.LogError("UpdatePreferences", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to save preferences")
+		return
+	}
+	h.wsService.SetNotificationLevel(user.ID, prefs.NotificationLevel)
+
+	w.WriteHeader(http.StatusNoContent)
+}
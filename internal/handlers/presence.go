@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"time"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// PresenceSummary describes who is actually connected to a session. The
+// owner facilitates, so a connected owner counts as an observer rather than
+// a voter, as does anyone connected who is no longer a participant. Away
+// participants have been idle for longer than AwayAfter.
+type PresenceSummary struct {
+	ConnectedVoters          int      `json:"connected_voters"`
+	Observers                int      `json:"observers"`
+	Disconnected             int      `json:"disconnected"`
+	Away                     int      `json:"away"`
+	Connections              int      `json:"connections"`
+	OnlineUserIDs            []string `json:"online_user_ids"`
+	AwayUserIDs              []string `json:"away_user_ids"`
+	DisconnectedParticipants []string `json:"disconnected_participants"` // usernames
+}
+
+// isAway reports whether a participant has shown no activity (WebSocket
+// messages or votes) for longer than the configured AwayAfter. A zero
+// AwayAfter disables away detection. Bots are never away, and neither is
+// anyone without recorded activity, such as after a restart or once
+// disconnected: they may still be there.
+func (h *Handler) isAway(sessionID string, participant models.User) bool {
+	if h.config.AwayAfter == 0 || participant.IsBot {
+		return false
+	}
+
+	lastActive, ok := h.wsService.LastActive(sessionID, participant.ID)
+	return ok && time.Since(lastActive) > h.config.AwayAfter
+}
+
+func (h *Handler) presenceSummary(session *models.Session) PresenceSummary {
+	connected := h.wsService.ConnectedUsers(session.ID)
+	summary := PresenceSummary{
+		Connections:              h.wsService.GetClientCount(session.ID),
+		OnlineUserIDs:            []string{},
+		AwayUserIDs:              []string{},
+		DisconnectedParticipants: []string{},
+	}
+
+	participants := make(map[string]bool)
+	for _, participant := range session.Participants {
+		participants[participant.ID] = true
+
+		if h.isAway(session.ID, participant) {
+			summary.Away++
+			summary.AwayUserIDs = append(summary.AwayUserIDs, participant.ID)
+		}
+
+		// Bots are driven by the server, so they are always there
+		if !connected[participant.ID] && !participant.IsBot {
+			summary.Disconnected++
+			summary.DisconnectedParticipants = append(summary.DisconnectedParticipants, participant.Username)
+			continue
+		}
+
+		summary.OnlineUserIDs = append(summary.OnlineUserIDs, participant.ID)
+		if participant.ID == session.OwnerID {
+			summary.Observers++
+		} else {
+			summary.ConnectedVoters++
+		}
+	}
+
+	for userID := range connected {
+		if !participants[userID] {
+			summary.Observers++
+		}
+	}
+
+	return summary
+}
+
+// onlineUsers marks which participants show as online. The viewer counts as
+// online even before their own connection is established.
+func onlineUsers(summary PresenceSummary, viewerID string) map[string]bool {
+	online := map[string]bool{viewerID: true}
+	for _, userID := range summary.OnlineUserIDs {
+		online[userID] = true
+	}
+	return online
+}
+
+// awayUsers marks which participants show as away. The viewer is loading the
+// page, so never counts as away themselves.
+func awayUsers(summary PresenceSummary, viewerID string) map[string]bool {
+	away := make(map[string]bool)
+	for _, userID := range summary.AwayUserIDs {
+		if userID != viewerID {
+			away[userID] = true
+		}
+	}
+	return away
+}
+
+func (h *Handler) GetLiveStats(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	isParticipant := false
+	for _, participant := range session.Participants {
+		if participant.ID == user.ID {
+			isParticipant = true
+			break
+		}
+	}
+
+	if !isParticipant {
+		http.Error(w, "Not a session participant", http.StatusForbidden)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, h.presenceSummary(session))
+}
+
+// BroadcastPresence sends a presence-summary to every session with open
+// connections whenever its summary has changed since the last tick. It
+// blocks until ctx is done, so run it in its own goroutine.
+func (h *Handler) BroadcastPresence(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := make(map[string]PresenceSummary)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		active := make(map[string]bool)
+		for _, sessionID := range h.wsService.ActiveSessions() {
+			active[sessionID] = true
+
+			session, err := h.sessionService.GetSessionWithoutTickets(ctx, sessionID)
+			if err != nil {
+				utils.LogError("BroadcastPresence", err)
+				continue
+			}
+			if session == nil {
+				continue
+			}
+
+			// Someone going away may leave only votes from people still here
+			h.autoReveal(ctx, session)
+
+			summary := h.presenceSummary(session)
+			previous, ok := last[sessionID]
+			if ok && reflect.DeepEqual(previous, summary) {
+				continue
+			}
+			last[sessionID] = summary
+
+			if ok {
+				h.broadcastStatusChanges(sessionID, previous.AwayUserIDs, summary.AwayUserIDs)
+			}
+
+			h.wsService.Broadcast(sessionID, models.SSEMessage{
+				Type: "presence-summary",
+				Data: summary,
+			})
+		}
+
+		for sessionID := range last {
+			if !active[sessionID] {
+				delete(last, sessionID)
+			}
+		}
+	}
+}
+
+// broadcastStatusChanges sends a participant-status message for everyone who
+// went away or came back between two presence summaries.
+func (h *Handler) broadcastStatusChanges(sessionID string, previousAway, currentAway []string) {
+	wasAway := make(map[string]bool)
+	for _, userID := range previousAway {
+		wasAway[userID] = true
+	}
+
+	isAway := make(map[string]bool)
+	for _, userID := range currentAway {
+		isAway[userID] = true
+		if !wasAway[userID] {
+			h.broadcastStatus(sessionID, userID, "away")
+		}
+	}
+
+	for userID := range wasAway {
+		if !isAway[userID] {
+			h.broadcastStatus(sessionID, userID, "active")
+		}
+	}
+}
+
+func (h *Handler) broadcastStatus(sessionID, userID, status string) {
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "participant-status",
+		Data: map[string]interface{}{
+			"user_id": userID,
+			"status":  status,
+		},
+	})
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/stats"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// prevoteResult sums up the pre-votes on the session's current ticket, so
+// the team can settle uncontroversial tickets without a live round. It
+// returns nil if there is no current ticket, nobody pre-voted, or the
+// pre-votes cannot be loaded.
+func (h *Handler) prevoteResult(ctx context.Context, session *models.Session) *APIRound {
+	if session.CurrentTicket == nil {
+		return nil
+	}
+
+	votes, err := h.votingService.GetPrevotes(ctx, session.CurrentTicket.ID)
+	if err != nil {
+		utils.LogError("prevoteResult", err, utils.ReportContext{SessionID: session.ID})
+		return nil
+	}
+	if len(votes) == 0 {
+		return nil
+	}
+
+	distribution := make(map[string]int)
+	for _, vote := range votes {
+		distribution[vote.VoteValue]++
+	}
+	return &APIRound{Round: services.PrevoteRound, Distribution: distribution, Consensus: stats.ForSession(session).Consensus(votes)}
+}
+
+// prevoteStatus returns how many pre-votes each ticket of the session has
+// and what the user pre-voted, or nil if it cannot be loaded.
+func (h *Handler) prevoteStatus(ctx context.Context, sessionID, userID string) map[int]services.PrevoteStatus {
+	status, err := h.votingService.GetPrevoteStatus(ctx, sessionID, userID)
+	if err != nil {
+		utils.LogError("prevoteStatus", err, utils.ReportContext{SessionID: sessionID, UserID: userID})
+		return nil
+	}
+	return status
+}
+
+// SetTicketPrevoting opens a ticket for silent pre-votes ahead of the live
+// session, or closes it, with the form field open (owner only). Pre-voting
+// closes by itself when voting on the ticket starts.
+func (h *Handler) SetTicketPrevoting(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	ticketID, err := strconv.Atoi(chi.URLParam(r, "ticketID"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+
+	open, err := strconv.ParseBool(r.FormValue("open"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "open must be true or false")
+		return
+	}
+
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("SetTicketPrevoting", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can open tickets for pre-votes")
+		return
+	}
+
+	ticket, err := h.ticketService.GetTicketByID(r.Context(), ticketID)
+	if err != nil {
+		utils.LogError("SetTicketPrevoting", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get ticket")
+		return
+	}
+	if ticket == nil || ticket.SessionID != sessionID {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Ticket not found")
+		return
+	}
+	if open && ticket.FinalEstimate != nil {
+		utils.WriteHTMLError(w, http.StatusConflict, "The ticket has already been estimated")
+		return
+	}
+
+	if err := h.ticketService.SetPrevoting(r.Context(), ticketID, open); err != nil {
+		writeServiceError(w, r, "SetTicketPrevoting", err, "Failed to update ticket")
+		return
+	}
+	ticket.PrevoteOpen = open
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "ticket-updated",
+		Data: ticket,
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
+
+// SubmitPrevote records the user's pre-vote on a ticket open for
+// pre-voting, replacing their earlier one. Pre-votes stay silent: the
+// session only hears how many there are.
+func (h *Handler) SubmitPrevote(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	ticketID, err := strconv.Atoi(chi.URLParam(r, "ticketID"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid ticket ID")
+		return
+	}
+
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("SubmitPrevote", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	conversion, _ := h.conversion(r.Context(), session, user.ID)
+	voteValue := conversion.Normalize(r.FormValue("vote"), session.Deck())
+	if validationErrors := utils.ValidateVoteValue(voteValue, session.Deck()); validationErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
+		return
+	}
+
+	count, err := h.votingService.SubmitPrevote(r.Context(), sessionID, ticketID, user.ID, voteValue)
+	if err != nil {
+		writeServiceError(w, r, "SubmitPrevote", err, "Failed to submit pre-vote")
+		return
+	}
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "prevote-cast",
+		Data: map[string]interface{}{"ticket_id": ticketID, "prevotes": count},
+	})
+	// Pre-votes are silent, so the log only records that one was cast
+	h.recordEvent(r.Context(), sessionID, services.EventPrevoteCast, ticketID, user.ID, nil)
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SessionVelocity is the sum of numeric final estimates in a session.
+type SessionVelocity struct {
+	Session            models.Session
+	EstimatedTickets   int
+	UnestimatedTickets int
+	Velocity           float64
+}
+
+// UnitVelocity aggregates session velocity for sessions sharing a unit, since
+// points and hours can't be added together.
+type UnitVelocity struct {
+	Unit     string
+	Total    float64
+	Sessions int
+	Average  float64
+}
+
+func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	name := utils.SanitizeInput(r.FormValue("name"))
+
+	if validationErrors := utils.ValidateProjectName(name); validationErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
+		return
+	}
+
+	orgID, orgErrors, err := h.formOrganization(r, user.ID)
+	if err != nil {
+		utils.LogError("CreateProject", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to check organization")
+		return
+	}
+	if orgErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, orgErrors.Error())
+		return
+	}
+
+	project, err := h.projectService.CreateProject(r.Context(), name, user.ID, orgID)
+	if err != nil {
+		utils.LogError("CreateProject", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to create project")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/project/"+project.ID)
+}
+
+func (h *Handler) GetProject(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		redirectURL := "/?redirect_to=" + r.URL.Path
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectID")
+	project, err := h.projectService.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		http.Error(w, "Failed to get project", http.StatusInternalServerError)
+		return
+	}
+	if project == nil {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+
+	if ok, err := h.canSeeProject(r.Context(), project, user.ID); err != nil {
+		utils.LogError("GetProject", err, utils.ReportContext{UserID: user.ID})
+		http.Error(w, "Failed to check project access", http.StatusInternalServerError)
+		return
+	} else if !ok {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+
+	sessionVelocities, projectVelocity := calculateProjectVelocity(project.Sessions)
+
+	data := PageData{
+		Title:             project.Name,
+		Template:          "project",
+		User:              user,
+		Project:           project,
+		SessionVelocities: sessionVelocities,
+		ProjectVelocity:   projectVelocity,
+		EstimationUnits:   deck.Units,
+		Location:          viewerLocation(r, user),
+	}
+
+	h.executeTemplate(w, "base.html", data)
+}
+
+// canSeeProject reports whether a user may see a project: its owner, the
+// members of its organization, or, outside any organization, anyone taking
+// part in one of its sessions.
+func (h *Handler) canSeeProject(ctx context.Context, project *models.Project, userID string) (bool, error) {
+	if project.OwnerID == userID {
+		return true, nil
+	}
+	if project.OrganizationID != nil {
+		return h.canSeeOrganization(ctx, project.OrganizationID, userID)
+	}
+	return h.projectService.IsProjectParticipant(ctx, project.ID, userID)
+}
+
+// CreateProjectSession starts a new session in a project, optionally as a
+// follow-up carrying over the unestimated tickets of an earlier session.
+func (h *Handler) CreateProjectSession(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectID")
+	project, err := h.projectService.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		http.Error(w, "Failed to get project", http.StatusInternalServerError)
+		return
+	}
+	if project == nil {
+		http.Error(w, "Project not found", http.StatusNotFound)
+		return
+	}
+
+	if project.OwnerID != user.ID {
+		http.Error(w, "Only project owner can create sessions", http.StatusForbidden)
+		return
+	}
+
+	name := utils.SanitizeInput(r.FormValue("name"))
+	if validationErrors := utils.ValidateSessionName(name); validationErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
+		return
+	}
+
+	estimationUnit := string(deck.DefaultUnit)
+	if unitStr := r.FormValue("estimation_unit"); unitStr != "" {
+		unit, ok := deck.ParseUnit(unitStr)
+		if !ok {
+			utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid estimation unit")
+			return
+		}
+		estimationUnit = string(unit)
+	}
+
+	// Carrying over starts a follow-up of a session in this project
+	if previousID := r.FormValue("carry_over_from"); previousID != "" {
+		inProject := false
+		for _, session := range project.Sessions {
+			if session.ID == previousID {
+				inProject = true
+				break
+			}
+		}
+		if !inProject {
+			http.Error(w, "Session does not belong to this project", http.StatusBadRequest)
+			return
+		}
+
+		previous, err := h.sessionService.GetSessionByID(r.Context(), previousID)
+		if err != nil {
+			http.Error(w, "Failed to get session", http.StatusInternalServerError)
+			return
+		}
+		if previous == nil {
+			http.Error(w, "Session not found", http.StatusNotFound)
+			return
+		}
+
+		session, _, err := h.sessionService.CreateFollowUpSession(r.Context(), previous, name)
+		if err != nil {
+			utils.LogError("CreateProjectSession", err)
+			utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to carry over tickets")
+			return
+		}
+
+		w.Header().Set("HX-Redirect", "/session/"+session.ID)
+		return
+	}
+
+	// Sessions of an organization's project belong to the organization too
+	session, err := h.sessionService.CreateProjectSession(r.Context(), project, name, user.ID, estimationUnit)
+	if err != nil {
+		utils.LogError("CreateProjectSession", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to create planning session")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/session/"+session.ID)
+}
+
+// SetSessionProject moves a session into one of the owner's projects, or out
+// of any project when project_id is empty.
+func (h *Handler) SetSessionProject(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only session owner can change the project", http.StatusForbidden)
+		return
+	}
+
+	var projectID *string
+	if id := r.FormValue("project_id"); id != "" {
+		project, err := h.projectService.GetProjectByID(r.Context(), id)
+		if err != nil {
+			http.Error(w, "Failed to get project", http.StatusInternalServerError)
+			return
+		}
+		if project == nil {
+			http.Error(w, "Project not found", http.StatusNotFound)
+			return
+		}
+		if project.OwnerID != user.ID {
+			http.Error(w, "Only project owner can add sessions", http.StatusForbidden)
+			return
+		}
+		if !sameOrganization(project.OrganizationID, session.OrganizationID) {
+			http.Error(w, "Session and project must be in the same organization", http.StatusBadRequest)
+			return
+		}
+		projectID = &project.ID
+	}
+
+	err = h.sessionService.SetSessionProject(r.Context(), sessionID, projectID)
+	if err != nil {
+		http.Error(w, "Failed to update session project", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func calculateProjectVelocity(sessions []models.Session) ([]SessionVelocity, []UnitVelocity) {
+	var sessionVelocities []SessionVelocity
+	unitTotals := make(map[string]*UnitVelocity)
+
+	for _, session := range sessions {
+		sv := SessionVelocity{Session: session}
+		for _, ticket := range session.Tickets {
+			if ticket.IsCalibration {
+				continue
+			}
+			if ticket.FinalEstimate == nil {
+				sv.UnestimatedTickets++
+				continue
+			}
+			sv.EstimatedTickets++
+			if value, ok := deck.NumericValue(*ticket.FinalEstimate); ok {
+				sv.Velocity += value
+			}
+		}
+		sessionVelocities = append(sessionVelocities, sv)
+
+		uv, ok := unitTotals[session.EstimationUnit]
+		if !ok {
+			uv = &UnitVelocity{Unit: session.EstimationUnit}
+			unitTotals[session.EstimationUnit] = uv
+		}
+		uv.Total += sv.Velocity
+		uv.Sessions++
+	}
+
+	var projectVelocity []UnitVelocity
+	for _, uv := range unitTotals {
+		uv.Average = uv.Total / float64(uv.Sessions)
+		projectVelocity = append(projectVelocity, *uv)
+	}
+	sort.Slice(projectVelocity, func(i, j int) bool {
+		return projectVelocity[i].Unit < projectVelocity[j].Unit
+	})
+
+	return sessionVelocities, projectVelocity
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// pushTimeout bounds sending one round of push notifications.
+	pushTimeout = 30 * time.Second
+	// maxPushEndpointLength keeps junk out of the subscriptions table; real
+	// endpoints are a few hundred characters.
+	maxPushEndpointLength = 2000
+)
+
+// pushSubscriptionRequest is a browser PushSubscription as serialized by
+// its toJSON method.
+type pushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// SubscribePush saves the current browser's push subscription, opting the
+// user in to push notifications on it.
+func (h *Handler) SubscribePush(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	if !h.notifyService.Enabled() {
+		utils.WriteJSONError(w, http.StatusNotFound, "Push notifications are not configured")
+		return
+	}
+
+	var req pushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid push subscription")
+		return
+	}
+	if !strings.HasPrefix(req.Endpoint, "https://") || len(req.Endpoint) > maxPushEndpointLength || req.Keys.P256dh == "" || req.Keys.Auth == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid push subscription")
+		return
+	}
+
+	err := h.notifyService.Subscribe(r.Context(), user.ID, req.Endpoint, req.Keys.P256dh, req.Keys.Auth)
+	switch {
+	case errors.Is(err, services.ErrInvalidPushEndpoint):
+		utils.WriteJSONError(w, http.StatusBadRequest, services.ErrInvalidPushEndpoint.Message)
+		return
+	case errors.Is(err, services.ErrPushEndpointTaken):
+		utils.WriteJSONError(w, http.StatusConflict, services.ErrPushEndpointTaken.Message)
+		return
+	case err != nil:
+		utils.LogError("SubscribePush", err, utils.ReportContext{UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to save push subscription")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnsubscribePush forgets one of the current user's push subscriptions.
+func (h *Handler) UnsubscribePush(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req pushSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Endpoint == "" {
+		utils.WriteJSONError(w, http.StatusBadRequest, "Invalid push subscription")
+		return
+	}
+
+	if err := h.notifyService.Unsubscribe(r.Context(), user.ID, req.Endpoint); err != nil {
+		utils.LogError("UnsubscribePush", err, utils.ReportContext{UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to delete push subscription")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServiceWorker serves the service worker that shows push notifications.
+// It is served from the root, as a worker only controls pages below its own
+// path.
+func (h *Handler) ServiceWorker(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeFile(w, r, "static/js/sw.js")
+}
+
+// NudgeVoters reminds the participants who have not voted yet that the
+// team is waiting for them. The session owner facilitates and is not
+// nudged, nor are bots, participants outside the ticket's breakout group or
+// participants in do not disturb. The response names who was nudged and who
+// was left alone.
+func (h *Handler) NudgeVoters(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("NudgeVoters", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can nudge voters")
+		return
+	}
+	if !session.IsVotingActive || session.CurrentTicket == nil {
+		utils.WriteHTMLError(w, http.StatusConflict, "Voting is not in progress")
+		return
+	}
+
+	doNotDisturb, err := h.userService.DoNotDisturb(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("NudgeVoters", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get participants")
+		return
+	}
+
+	voted := make(map[string]bool)
+	for _, vote := range session.CurrentTicket.Votes {
+		voted[vote.UserID] = true
+	}
+
+	var waiting []string
+	nudged, skipped := []string{}, []string{}
+	for _, participant := range session.Participants {
+		if participant.IsBot || participant.ID == session.OwnerID || voted[participant.ID] || !session.CurrentTicket.CanVote(participant.ID) {
+			continue
+		}
+		if doNotDisturb[participant.ID] {
+			skipped = append(skipped, participant.Username)
+			continue
+		}
+		waiting = append(waiting, participant.ID)
+		nudged = append(nudged, participant.Username)
+		h.wsService.SendToUser(sessionID, participant.ID, models.SSEMessage{
+			Type: "nudge",
+			Data: map[string]string{"from": user.Username, "ticket": session.CurrentTicket.Title},
+		})
+	}
+
+	h.pushInBackground(session, waiting, models.PushNotification{
+		Title: "Your vote is needed",
+		Body:  user.Username + " is waiting for your vote on " + session.CurrentTicket.Title,
+		Tag:   "nudge-" + session.ID,
+	})
+
+	if expectsPage(r) {
+		http.Redirect(w, r, "/session/"+sessionID, http.StatusSeeOther)
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, map[string][]string{
+		"nudged":         nudged,
+		"do_not_disturb": skipped,
+	})
+}
+
+// pushVotingStarted tells participants whose session tab is not in front of
+// them that voting has started, if they may vote on the ticket. actorID
+// started it and knows already.
+func (h *Handler) pushVotingStarted(session *models.Session, ticket *models.Ticket, actorID string) {
+	var userIDs []string
+	for _, participant := range session.Participants {
+		if !participant.IsBot && participant.ID != actorID && ticket.CanVote(participant.ID) {
+			userIDs = append(userIDs, participant.ID)
+		}
+	}
+
+	h.pushInBackground(session, userIDs, models.PushNotification{
+		Title: "Voting has started",
+		Body:  ticket.Title,
+		Tag:   "voting-" + session.ID,
+	})
+}
+
+// pushInBackground sends a notification linking to the session to those of
+// userIDs without the session in front of them: users looking at it get
+// the WebSocket message instead, and users in do not disturb get nothing.
+// Sending runs in the background.
+func (h *Handler) pushInBackground(session *models.Session, userIDs []string, notification models.PushNotification) {
+	if !h.notifyService.Enabled() {
+		return
+	}
+
+	doNotDisturb, err := h.userService.DoNotDisturb(context.Background(), session.ID)
+	if err != nil {
+		utils.LogError("pushInBackground", err, utils.ReportContext{SessionID: session.ID})
+		return
+	}
+
+	var recipients []string
+	for _, userID := range userIDs {
+		if !doNotDisturb[userID] && h.wsService.InBackground(session.ID, userID) {
+			recipients = append(recipients, userID)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	notification.URL = "/session/" + session.ID
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+		defer cancel()
+
+		for _, userID := range recipients {
+			if err := h.notifyService.Notify(ctx, userID, notification); err != nil {
+				utils.LogError("pushInBackground", err, utils.ReportContext{SessionID: session.ID, UserID: userID})
+			}
+		}
+	}()
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// sessionReferences returns the reference stories pinned in a team session
+// and, for its owner, the team's library to pin more from.
+func (h *Handler) sessionReferences(ctx context.Context, session *models.Session, userID string) ([]models.ReferenceStory, []models.ReferenceStory) {
+	if session.TeamID == nil {
+		return nil, nil
+	}
+
+	pinned, err := h.teamService.GetPinnedReferences(ctx, session.ID)
+	if err != nil {
+		utils.LogError("sessionReferences", err, utils.ReportContext{SessionID: session.ID, UserID: userID})
+	}
+	if session.OwnerID != userID {
+		return pinned, nil
+	}
+
+	library, err := h.teamService.GetReferences(ctx, *session.TeamID)
+	if err != nil {
+		utils.LogError("sessionReferences", err, utils.ReportContext{SessionID: session.ID, UserID: userID})
+	}
+	return pinned, library
+}
+
+// GetTeamReferences returns a team's reference story library as JSON.
+func (h *Handler) GetTeamReferences(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	team, _, ok := h.getMemberTeam(w, r, user)
+	if !ok {
+		return
+	}
+
+	references, err := h.teamService.GetReferences(r.Context(), team.ID)
+	if err != nil {
+		utils.LogError("GetTeamReferences", err, utils.ReportContext{UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get reference stories")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, references)
+}
+
+// AddTeamReference adds a story to a team's library, either from title and
+// points or from ticket_id, an estimated ticket of one of the team's
+// sessions. Browsers without JavaScript go back to redirect_to.
+func (h *Handler) AddTeamReference(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	team, canEdit, ok := h.getMemberTeam(w, r, user)
+	if !ok {
+		return
+	}
+	if !canEdit {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only team members can add reference stories")
+		return
+	}
+
+	title := utils.SanitizeInput(r.FormValue("title"))
+	points := utils.SanitizeInput(r.FormValue("points"))
+	var ticketID *int
+
+	if value := r.FormValue("ticket_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid ticket ID")
+			return
+		}
+		ticket, err := h.ticketService.GetTicketByID(r.Context(), id)
+		if err != nil {
+			utils.LogError("AddTeamReference", err, utils.ReportContext{UserID: user.ID})
+			utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get ticket")
+			return
+		}
+		if ticket == nil {
+			utils.WriteHTMLError(w, http.StatusNotFound, "Ticket not found")
+			return
+		}
+		session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), ticket.SessionID)
+		if err != nil {
+			utils.LogError("AddTeamReference", err, utils.ReportContext{SessionID: ticket.SessionID, UserID: user.ID})
+			utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+			return
+		}
+		if session == nil || session.TeamID == nil || *session.TeamID != team.ID {
+			utils.WriteHTMLError(w, http.StatusBadRequest, "The ticket is not from one of the team's sessions")
+			return
+		}
+		if ticket.FinalEstimate == nil {
+			utils.WriteHTMLError(w, http.StatusConflict, "The ticket has not been estimated yet")
+			return
+		}
+
+		ticketID = &ticket.ID
+		if title == "" {
+			title = ticket.Title
+		}
+		points = *ticket.FinalEstimate
+	}
+
+	validationErrors := utils.ValidateTicketTitle(title)
+	if !deck.Cards(team.EstimationUnit).IsValid(points) {
+		validationErrors = append(validationErrors, utils.ValidationError{
+			Field:   "points",
+			Message: "Points must be one of the team's cards",
+		})
+	}
+	if validationErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, validationErrors)
+		return
+	}
+
+	reference, err := h.teamService.AddReference(r.Context(), team.ID, title, points, ticketID, user.ID)
+	if err != nil {
+		utils.LogError("AddTeamReference", err, utils.ReportContext{UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to add reference story")
+		return
+	}
+
+	if expectsPage(r) {
+		http.Redirect(w, r, localPath(r.FormValue("redirect_to")), http.StatusSeeOther)
+		return
+	}
+	utils.WriteJSON(w, http.StatusCreated, reference)
+}
+
+// DeleteTeamReference removes a story from a team's library.
+func (h *Handler) DeleteTeamReference(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	team, canEdit, ok := h.getMemberTeam(w, r, user)
+	if !ok {
+		return
+	}
+	if !canEdit {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only team members can remove reference stories")
+		return
+	}
+
+	referenceID, err := strconv.Atoi(chi.URLParam(r, "referenceID"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid reference story ID")
+		return
+	}
+
+	if err := h.teamService.DeleteReference(r.Context(), team.ID, referenceID); err != nil {
+		writeServiceError(w, r, "DeleteTeamReference", err, "Failed to delete reference story")
+		return
+	}
+
+	finishAction(w, r, http.StatusNoContent, "/org/"+team.OrganizationID+"/teams/"+team.ID)
+}
+
+// PinReference pins a story from the team's library in a team session, next
+// to the current ticket (owner only).
+func (h *Handler) PinReference(w http.ResponseWriter, r *http.Request) {
+	h.setReferencePinned(w, r, true)
+}
+
+// UnpinReference takes a pinned reference story off a session (owner only).
+func (h *Handler) UnpinReference(w http.ResponseWriter, r *http.Request) {
+	h.setReferencePinned(w, r, false)
+}
+
+func (h *Handler) setReferencePinned(w http.ResponseWriter, r *http.Request, pinned bool) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	referenceID, err := strconv.Atoi(chi.URLParam(r, "referenceID"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid reference story ID")
+		return
+	}
+
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("setReferencePinned", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can pin reference stories")
+		return
+	}
+	if session.TeamID == nil {
+		utils.WriteHTMLError(w, http.StatusConflict, "Only team sessions have reference stories")
+		return
+	}
+
+	if pinned {
+		err = h.teamService.PinReference(r.Context(), sessionID, *session.TeamID, referenceID)
+	} else {
+		err = h.teamService.UnpinReference(r.Context(), sessionID, referenceID)
+	}
+	if err != nil {
+		writeServiceError(w, r, "setReferencePinned", err, "Failed to pin reference story")
+		return
+	}
+
+	references, err := h.teamService.GetPinnedReferences(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("setReferencePinned", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+	} else {
+		h.wsService.Broadcast(sessionID, models.SSEMessage{
+			Type: "references-updated",
+			Data: references,
+		})
+	}
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
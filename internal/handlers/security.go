@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DefaultContentSecurityPolicy allows what the templates load: HTMX and
+// Tailwind from their CDNs, the Material Icons font, and inline scripts and
+// hx-on handlers, which HTMX compiles with eval. 'self' in connect-src also
+// covers the session WebSocket on the same host.
+const DefaultContentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' 'unsafe-inline' 'unsafe-eval' https://unpkg.com https://cdn.tailwindcss.com; " +
+	"style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; " +
+	"font-src 'self' https://fonts.gstatic.com; " +
+	"img-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"base-uri 'self'; " +
+	"form-action 'self'; " +
+	"frame-ancestors 'none'"
+
+// SecurityHeaders sets the browser security headers on every response. An
+// empty csp uses DefaultContentSecurityPolicy.
+func SecurityHeaders(csp string) func(http.Handler) http.Handler {
+	if csp == "" {
+		csp = DefaultContentSecurityPolicy
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			header.Set("Content-Security-Policy", csp)
+			header.Set("X-Frame-Options", "DENY")
+			header.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+			header.Set("X-Content-Type-Options", "nosniff")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AllowEmbedding lets the pages it wraps be framed by the given origins,
+// e.g. "https://wiki.example.com" or "*" for any site. Use it on the
+// embeddable views only; everything else stays unframeable.
+func AllowEmbedding(ancestors string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			// X-Frame-Options cannot name origins; frame-ancestors takes over
+			header.Del("X-Frame-Options")
+			if csp := header.Get("Content-Security-Policy"); csp != "" {
+				header.Set("Content-Security-Policy", setCSPDirective(csp, "frame-ancestors", ancestors))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setCSPDirective replaces a directive in a policy, adding it if missing.
+func setCSPDirective(csp, name, value string) string {
+	var directives []string
+	for _, directive := range strings.Split(csp, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) > 0 && !strings.EqualFold(fields[0], name) {
+			directives = append(directives, strings.Join(fields, " "))
+		}
+	}
+
+	directives = append(directives, name+" "+value)
+	return strings.Join(directives, "; ")
+}
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/stats"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	slackSignatureMaxAge = 5 * time.Minute
+	slackDefaultName     = "Planning poker"
+	slackResultsTickets  = 20 // estimated tickets listed in results
+)
+
+const slackUsage = "Usage:\n" +
+	"• `/poker new [name]` starts a session and posts its join link here\n" +
+	"• `/poker results <session link>` posts the estimates so far and the last revealed votes"
+
+// slackMessage is a slash command reply, or a message sent to its
+// response_url.
+type slackMessage struct {
+	ResponseType string `json:"response_type"` // "in_channel" or "ephemeral"
+	Text         string `json:"text"`
+}
+
+// validSlackRequest checks Slack's signature over the timestamp and body,
+// refusing old timestamps so a captured request cannot be replayed.
+func validSlackRequest(secret string, r *http.Request, body []byte, now time.Time) bool {
+	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > slackSignatureMaxAge || age < -slackSignatureMaxAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(r.Header.Get("X-Slack-Signature")), []byte(expected))
+}
+
+// claimToken lets whoever holds it take over a session from its current
+// owner. It is tied to that owner, so it stops working once used.
+func (h *Handler) claimToken(sessionID, ownerID string) string {
+	mac := hmac.New(sha256.New, []byte(h.config.SlackSigningSecret))
+	mac.Write([]byte("claim:" + sessionID + ":" + ownerID))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// publicURL is the address participants reach the app at, from PUBLIC_URL
+// or else the request.
+func (h *Handler) publicURL(r *http.Request) string {
+	if h.config.PublicURL != "" {
+		return strings.TrimSuffix(h.config.PublicURL, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// SlackCommand answers the /poker slash command.
+func (h *Handler) SlackCommand(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if !validSlackRequest(h.config.SlackSigningSecret, r, body, time.Now()) {
+		http.Error(w, "Invalid request signature", http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	subcommand, argument, _ := strings.Cut(strings.TrimSpace(form.Get("text")), " ")
+	argument = strings.TrimSpace(argument)
+
+	var reply slackMessage
+	switch strings.ToLower(subcommand) {
+	case "new", "start":
+		reply = h.slackNewSession(r, form, argument)
+	case "results":
+		reply = h.slackResults(r, argument)
+	default:
+		reply = slackMessage{ResponseType: "ephemeral", Text: slackUsage}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, reply)
+}
+
+// slackNewSession creates a session owned by a stand-in for the Slack user
+// and posts its join link to the channel. The Slack user is sent a private
+// link that makes them the owner in their browser.
+func (h *Handler) slackNewSession(r *http.Request, form url.Values, name string) slackMessage {
+	if name == "" {
+		name = slackDefaultName
+	}
+	if fieldErrors := utils.ValidateSessionName(name); fieldErrors.HasErrors() {
+		return slackMessage{ResponseType: "ephemeral", Text: fieldErrors.Error()}
+	}
+
+	username := form.Get("user_name")
+	if utils.ValidateUsername(username).HasErrors() {
+		username = "Slack user"
+	}
+
+	owner, err := h.userService.CreateUser(r.Context(), username)
+	if err != nil {
+		utils.LogError("SlackCommand", err)
+		return slackMessage{ResponseType: "ephemeral", Text: "Failed to create the session, try again"}
+	}
+
+	session, err := h.sessionService.CreateSession(r.Context(), name, owner.ID, string(deck.DefaultUnit), nil)
+	if err != nil {
+		utils.LogError("SlackCommand", err)
+		return slackMessage{ResponseType: "ephemeral", Text: "Failed to create the session, try again"}
+	}
+
+	base := h.publicURL(r)
+	claimURL := base + "/session/" + session.ID + "/claim/" + h.claimToken(session.ID, owner.ID)
+	h.postSlackResponse(form.Get("response_url"), slackMessage{
+		ResponseType: "ephemeral",
+		Text:         fmt.Sprintf("Open <%s|this link> to run *%s* as its facilitator. Don't share it.", claimURL, name),
+	})
+
+	return slackMessage{
+		ResponseType: "in_channel",
+		Text:         fmt.Sprintf("<@%s> started planning poker: *<%s/session/%s|%s>*", form.Get("user_id"), base, session.ID, name),
+	}
+}
+
+// slackResults summarises a session: the tickets estimated so far and the
+// votes on the current ticket if they have been revealed.
+func (h *Handler) slackResults(r *http.Request, link string) slackMessage {
+	// Accept the join link as well as the bare session ID
+	sessionID := strings.Trim(link, "<>")
+	if i := strings.Index(sessionID, "/session/"); i >= 0 {
+		sessionID = sessionID[i+len("/session/"):]
+	}
+	sessionID, _, _ = strings.Cut(sessionID, "/")
+	sessionID, _, _ = strings.Cut(sessionID, "|")
+	if sessionID == "" {
+		return slackMessage{ResponseType: "ephemeral", Text: slackUsage}
+	}
+
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("SlackCommand", err)
+		return slackMessage{ResponseType: "ephemeral", Text: "Failed to get the session, try again"}
+	}
+	if session == nil || session.OrganizationID != nil {
+		// Organization sessions are private to their members
+		return slackMessage{ResponseType: "ephemeral", Text: "Session not found"}
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "*%s*\n", session.Name)
+
+	estimated := 0
+	for _, ticket := range session.Tickets {
+		if ticket.FinalEstimate == nil {
+			continue
+		}
+		if estimated++; estimated <= slackResultsTickets {
+			fmt.Fprintf(&text, "• %s: *%s*", slackTicketName(ticket), deck.FormatCard(*ticket.FinalEstimate, session.EstimationUnit))
+			if estimateRange := ticket.EstimateRange(session.EstimationUnit); estimateRange != "" {
+				fmt.Fprintf(&text, " (%s)", estimateRange)
+			}
+			text.WriteString("\n")
+		}
+	}
+	if estimated > slackResultsTickets {
+		fmt.Fprintf(&text, "…and %d more\n", estimated-slackResultsTickets)
+	}
+	fmt.Fprintf(&text, "%d of %d tickets estimated\n", estimated, len(session.Tickets))
+
+	if ticket := session.CurrentTicket; ticket != nil && !session.IsVotingActive && ticket.RevealedAt != nil && len(ticket.Votes) > 0 {
+		counts := make(map[string]int)
+		for _, vote := range ticket.Votes {
+			counts[vote.VoteValue]++
+		}
+		cards := make([]string, 0, len(counts))
+		for card := range counts {
+			cards = append(cards, card)
+		}
+		sort.Slice(cards, func(i, j int) bool {
+			if counts[cards[i]] != counts[cards[j]] {
+				return counts[cards[i]] > counts[cards[j]]
+			}
+			return cards[i] < cards[j]
+		})
+
+		parts := make([]string, 0, len(cards))
+		for _, card := range cards {
+			parts = append(parts, fmt.Sprintf("%s ×%d", deck.FormatCard(card, session.EstimationUnit), counts[card]))
+		}
+		fmt.Fprintf(&text, "\nRevealed on %s: %s", slackTicketName(*ticket), strings.Join(parts, ", "))
+		if ticketStats := stats.ForSession(session).Ticket(ticket.Votes); ticketStats.HasValues {
+			fmt.Fprintf(&text, " (median %s, mean %s)", deck.Format(ticketStats.Median, session.EstimationUnit), deck.Format(ticketStats.Mean, session.EstimationUnit))
+		}
+	}
+
+	return slackMessage{ResponseType: "in_channel", Text: text.String()}
+}
+
+func slackTicketName(ticket models.Ticket) string {
+	if ticket.ExternalKey != nil {
+		return *ticket.ExternalKey + " " + ticket.Title
+	}
+	return ticket.Title
+}
+
+// postSlackResponse sends a follow-up message to a slash command's
+// response_url in the background. Only Slack's own hooks are accepted, so
+// the command cannot be used to make the server call elsewhere.
+func (h *Handler) postSlackResponse(responseURL string, message slackMessage) {
+	parsed, err := url.Parse(responseURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host != "hooks.slack.com" {
+		return
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+		if err != nil {
+			utils.LogError("postSlackResponse", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			utils.LogError("postSlackResponse", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			utils.LogError("postSlackResponse", fmt.Errorf("Slack returned %s", resp.Status))
+		}
+	}()
+}
+
+// ClaimSession makes the signed-in user the owner of a session started from
+// Slack, using the private link sent to whoever started it.
+func (h *Handler) ClaimSession(w http.ResponseWriter, r *http.Request) {
+	if h.config.SlackSigningSecret == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/?redirect_to="+r.URL.Path, http.StatusSeeOther)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if session.OwnerID == user.ID {
+		http.Redirect(w, r, "/session/"+sessionID, http.StatusSeeOther)
+		return
+	}
+
+	token := chi.URLParam(r, "token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(h.claimToken(sessionID, session.OwnerID))) != 1 {
+		http.Error(w, "This link has already been used", http.StatusForbidden)
+		return
+	}
+
+	transferred, err := h.sessionService.TransferOwnership(r.Context(), sessionID, session.OwnerID, user.ID)
+	if err != nil {
+		utils.LogError("ClaimSession", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		http.Error(w, "Failed to claim session", http.StatusInternalServerError)
+		return
+	}
+	if !transferred {
+		http.Error(w, "This link has already been used", http.StatusForbidden)
+		return
+	}
+
+	if session, err = h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID); err == nil && session != nil {
+		h.wsService.Broadcast(sessionID, models.SSEMessage{
+			Type: "session-updated",
+			Data: session,
+		})
+	}
+
+	http.Redirect(w, r, "/session/"+sessionID, http.StatusSeeOther)
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// snapshots returns a session's restore points for its owner, or nil for
+// anyone else or if they cannot be loaded.
+func (h *Handler) snapshots(ctx context.Context, session *models.Session, user *models.User) []models.SessionSnapshot {
+	if session.OwnerID != user.ID {
+		return nil
+	}
+	snapshots, err := h.sessionService.GetSnapshots(ctx, session.ID)
+	if err != nil {
+		utils.LogError("snapshots", err, utils.ReportContext{SessionID: session.ID, UserID: user.ID})
+		return nil
+	}
+	return snapshots
+}
+
+// restorePoint saves an automatic restore point before a bulk change, so
+// the owner can roll it back. Failing to save one is logged and does not
+// stop the change.
+func (h *Handler) restorePoint(ctx context.Context, sessionID, name string, user *models.User) {
+	if _, err := h.sessionService.CreateSnapshot(ctx, sessionID, name, user.ID, true); err != nil {
+		utils.LogError("restorePoint", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+	}
+}
+
+// ownedSession loads a session for its owner, writing the error response
+// and returning nil otherwise.
+func (h *Handler) ownedSession(w http.ResponseWriter, r *http.Request, operation string, user *models.User) *models.Session {
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError(operation, err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return nil
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return nil
+	}
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only the session owner can manage restore points", http.StatusForbidden)
+		return nil
+	}
+	return session
+}
+
+// GetSnapshots returns the session's restore points as JSON, newest first
+// (owner only).
+func (h *Handler) GetSnapshots(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	session := h.ownedSession(w, r, "GetSnapshots", user)
+	if session == nil {
+		return
+	}
+
+	snapshots, err := h.sessionService.GetSnapshots(r.Context(), session.ID)
+	if err != nil {
+		utils.LogError("GetSnapshots", err, utils.ReportContext{SessionID: session.ID, UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get restore points")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, snapshots)
+}
+
+// CreateSnapshot saves a named restore point of the session's tickets,
+// votes and voting state, e.g. before trying something risky mid-meeting.
+func (h *Handler) CreateSnapshot(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	session := h.ownedSession(w, r, "CreateSnapshot", user)
+	if session == nil {
+		return
+	}
+
+	name := utils.SanitizeInput(r.FormValue("name"))
+	if validationErrors := utils.ValidateSnapshotName(name); validationErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, validationErrors)
+		return
+	}
+
+	snapshot, err := h.sessionService.CreateSnapshot(r.Context(), session.ID, name, user.ID, false)
+	if err != nil {
+		writeServiceError(w, r, "CreateSnapshot", err, "Failed to save restore point")
+		return
+	}
+
+	if expectsPage(r) {
+		http.Redirect(w, r, "/session/"+session.ID, http.StatusSeeOther)
+		return
+	}
+	utils.WriteJSON(w, http.StatusCreated, snapshot)
+}
+
+// RestoreSnapshot rolls the session back to one of its restore points, e.g.
+// after deleting the wrong tickets or a botched import. The session as it
+// was is saved as a restore point first, and everyone's page reloads.
+func (h *Handler) RestoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	snapshotID, err := strconv.Atoi(chi.URLParam(r, "snapshotID"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid restore point ID")
+		return
+	}
+
+	session := h.ownedSession(w, r, "RestoreSnapshot", user)
+	if session == nil {
+		return
+	}
+
+	snapshot, err := h.sessionService.RestoreSnapshot(r.Context(), session.ID, snapshotID, user.ID)
+	if err != nil {
+		writeServiceError(w, r, "RestoreSnapshot", err, "Failed to restore the session")
+		return
+	}
+
+	data := map[string]interface{}{
+		"snapshot_id": snapshot.ID,
+		"name":        snapshot.Name,
+		"created_at":  snapshot.CreatedAt,
+	}
+	h.wsService.Broadcast(session.ID, models.SSEMessage{Type: "session-restored", Data: data})
+	h.recordEvent(r.Context(), session.ID, services.EventSessionRestored, 0, user.ID, data)
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+session.ID)
+}
+
+// DeleteSnapshot removes one of the session's restore points.
+func (h *Handler) DeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	snapshotID, err := strconv.Atoi(chi.URLParam(r, "snapshotID"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid restore point ID")
+		return
+	}
+
+	session := h.ownedSession(w, r, "DeleteSnapshot", user)
+	if session == nil {
+		return
+	}
+
+	if err := h.sessionService.DeleteSnapshot(r.Context(), session.ID, snapshotID); err != nil {
+		writeServiceError(w, r, "DeleteSnapshot", err, "Failed to delete restore point")
+		return
+	}
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+session.ID)
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SpecialCardRow is a row of the owner's special cards form. Blank rows
+// suggest a card the deck does not have yet.
+type SpecialCardRow struct {
+	deck.SpecialCard
+	Suggestion *deck.SpecialCard
+}
+
+// specialCardRows lists the session's special cards, then blank rows for
+// adding more, up to the most a deck can have.
+func specialCardRows(session *models.Session) []SpecialCardRow {
+	var rows []SpecialCardRow
+	for _, card := range session.SpecialCards() {
+		rows = append(rows, SpecialCardRow{SpecialCard: card})
+	}
+
+	cards := session.Deck()
+	suggestions := append(append([]deck.SpecialCard{}, deck.SpecialCards...), deck.SuggestedSpecialCards...)
+	for _, suggestion := range suggestions {
+		if len(rows) >= deck.MaxSpecialCards {
+			return rows
+		}
+		if !cards.IsValid(suggestion.Value) {
+			suggestion := suggestion
+			rows = append(rows, SpecialCardRow{Suggestion: &suggestion})
+		}
+	}
+	if len(rows) < deck.MaxSpecialCards {
+		rows = append(rows, SpecialCardRow{})
+	}
+	return rows
+}
+
+// SetSpecialCards replaces the session's special cards (owner only). The
+// form repeats value and label for each card, in order, and lists the
+// indexes of the cards that count toward consensus as counts and of those
+// asking for the ticket to be split as split. Cards with an
+// empty value are skipped; reset=true goes back to the deployment's cards.
+// Votes already cast are kept.
+func (h *Handler) SetSpecialCards(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("SetSpecialCards", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if session.OwnerID != user.ID {
+		utils.WriteHTMLError(w, http.StatusForbidden, "Only the session owner can change the special cards")
+		return
+	}
+
+	var cards []deck.SpecialCard
+	if r.FormValue("reset") != "true" {
+		if err := r.ParseForm(); err != nil {
+			utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid form")
+			return
+		}
+		counts, split := formIndexes(r, "counts"), formIndexes(r, "split")
+
+		values, labels := r.Form["value"], r.Form["label"]
+		cards = []deck.SpecialCard{}
+		for i, value := range values {
+			card := deck.SpecialCard{Value: utils.SanitizeInput(value), Counts: counts[i], Split: split[i]}
+			if card.Value == "" {
+				continue
+			}
+			if i < len(labels) {
+				card.Label = utils.SanitizeInput(labels[i])
+			}
+			cards = append(cards, card)
+		}
+		if err := deck.ValidateSpecialCards(cards); err != nil {
+			utils.WriteFormValidationError(w, r, utils.ValidationErrors{{Field: "special_cards", Message: "Invalid special cards: " + err.Error()}})
+			return
+		}
+	}
+
+	if err := h.sessionService.SetSpecialCards(r.Context(), sessionID, cards); err != nil {
+		utils.LogError("SetSpecialCards", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		http.Error(w, "Failed to update special cards", http.StatusInternalServerError)
+		return
+	}
+	session.CustomSpecialCards = cards
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "session-updated",
+		Data: session,
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
+
+// formIndexes reads a repeated form field of row indexes, such as the
+// checked rows of a form, into a set.
+func formIndexes(r *http.Request, field string) map[int]bool {
+	indexes := make(map[int]bool)
+	for _, value := range r.Form[field] {
+		if i, err := strconv.Atoi(value); err == nil {
+			indexes[i] = true
+		}
+	}
+	return indexes
+}
@@ -1,22 +1,31 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 
+	"poker-planning/internal/models"
+	"poker-planning/internal/stats"
+
 	"github.com/go-chi/chi/v5"
 )
 
 func (h *Handler) WebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+
+	// Clients without cookies connect with a token; a handshake that has one
+	// is authenticated by it alone, as it may have skipped the instance gates
 	user := GetUserFromContext(r.Context())
+	if token := r.URL.Query().Get("token"); token != "" {
+		user = h.connectTokenUser(r, sessionID, token)
+	}
 	if user == nil {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	sessionID := chi.URLParam(r, "sessionID")
-	
 	// Verify session exists and user is a participant
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -40,6 +49,60 @@ func (h *Handler) WebSocketHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Handle WebSocket connection
-	h.wsService.HandleWebSocket(w, r, sessionID, user.ID)
-}
\ No newline at end of file
+	// Handle WebSocket connection, starting it with the session's current
+	// state in case the page the client has is already out of date
+	h.wsService.HandleWebSocket(w, r, sessionID, user.ID, func() (models.SSEMessage, error) {
+		session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+		if err != nil {
+			return models.SSEMessage{}, err
+		}
+		if session == nil {
+			return models.SSEMessage{}, fmt.Errorf("session %s not found", sessionID)
+		}
+		return h.stateSnapshot(session), nil
+	})
+}
+
+// stateSnapshot describes where a session is: the current ticket, the voting
+// phase, the cards and how to show them, who has voted and, once revealed,
+// the votes and their statistics.
+// Vote values are left out while voting is still going on.
+func (h *Handler) stateSnapshot(session *models.Session) models.SSEMessage {
+	data := map[string]interface{}{
+		"current_ticket": nil,
+		"participants":   session.Participants,
+		"cards":          session.Deck(),
+		"card_styles":    session.CardStyles(),
+	}
+
+	phase := sessionPhase(session)
+	if ticket := session.CurrentTicket; ticket != nil {
+		summary := *ticket
+		summary.Votes = nil
+		data["current_ticket"] = summary
+
+		switch phase {
+		case "voting":
+			if deadline := session.VotingDeadline(); deadline != nil {
+				data["voting_deadline"] = deadline
+			}
+		case "revealed":
+			data["votes"] = ticket.Votes
+			data["vote_change_until"] = session.VoteChangeDeadline()
+			if ticketStats := stats.ForSession(session).Ticket(ticket.Votes); ticketStats.HasValues {
+				data["stats"] = map[string]interface{}{
+					"median":      ticketStats.Median,
+					"mean":        ticketStats.Mean,
+					"mode":        ticketStats.Mode,
+					"weighted":    ticketStats.Weighted,
+					"abstentions": ticketStats.Abstentions,
+					"infinite":    ticketStats.Infinite,
+				}
+			}
+		}
+	}
+
+	data["phase"] = phase
+	data["voted"] = votedIDs(session)
+	return models.SSEMessage{Type: "state-snapshot", Data: data, StateHash: stateHash(session)}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SetParticipantStakeholder lets the owner mark a participant as an
+// interested stakeholder rather than a committed team member, or back. A
+// stakeholder's votes are advisory: they are shown apart from the others,
+// left out of the statistics and never waited for by auto-reveal.
+func (h *Handler) SetParticipantStakeholder(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	participantID := chi.URLParam(r, "userID")
+
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only session owner can change participant roles", http.StatusForbidden)
+		return
+	}
+
+	var participant *models.User
+	for i := range session.Participants {
+		if session.Participants[i].ID == participantID {
+			participant = &session.Participants[i]
+			break
+		}
+	}
+	if participant == nil {
+		http.Error(w, "Participant not found", http.StatusNotFound)
+		return
+	}
+
+	stakeholder, err := strconv.ParseBool(r.FormValue("stakeholder"))
+	if err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "stakeholder must be true or false")
+		return
+	}
+
+	if err := h.sessionService.SetParticipantStakeholder(r.Context(), sessionID, participantID, stakeholder); err != nil {
+		writeServiceError(w, r, "SetParticipantStakeholder", err, "Failed to change participant role")
+		return
+	}
+	participant.IsStakeholder = stakeholder
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "session-updated",
+		Data: session,
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"net/http"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// parseTeamDefaults reads the session defaults of a team form into team.
+func parseTeamDefaults(r *http.Request, team *models.Team) utils.ValidationErrors {
+	name := utils.SanitizeInput(r.FormValue("name"))
+	allErrors := utils.ValidateTeamName(name)
+	team.Name = name
+
+	team.EstimationUnit = string(deck.DefaultUnit)
+	if unitStr := r.FormValue("estimation_unit"); unitStr != "" {
+		unit, ok := deck.ParseUnit(unitStr)
+		if !ok {
+			allErrors = append(allErrors, utils.ValidationError{Field: "estimation_unit", Message: "Invalid estimation unit"})
+		}
+		team.EstimationUnit = string(unit)
+	}
+
+	limit, fieldErrors := parseVotingTimeLimit("voting_time_limit", r.FormValue("voting_time_limit"))
+	allErrors = append(allErrors, fieldErrors...)
+	team.VotingTimeLimit = limit
+
+	daysPerPoint, fieldErrors := parseDaysPerPoint("days_per_point", r.FormValue("days_per_point"))
+	allErrors = append(allErrors, fieldErrors...)
+	team.DaysPerPoint = daysPerPoint
+
+	return allErrors
+}
+
+// CreateTeam lets an organization admin set up a team.
+func (h *Handler) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	org, ok := h.getAdminOrganization(w, r, user, "create teams")
+	if !ok {
+		return
+	}
+
+	var team models.Team
+	if validationErrors := parseTeamDefaults(r, &team); validationErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, validationErrors)
+		return
+	}
+
+	created, err := h.teamService.CreateTeam(r.Context(), org.ID, team.Name, team.EstimationUnit, team.VotingTimeLimit, team.DaysPerPoint)
+	if err != nil {
+		utils.LogError("CreateTeam", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to create team")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/org/"+org.ID+"/teams/"+created.ID)
+}
+
+// GetTeam shows a team to the members of its organization: its members and
+// defaults, the sessions it has held with their velocity and ratings, how
+// often it plays each card, and how its estimates compare with what the
+// tickets actually took.
+func (h *Handler) GetTeam(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Redirect(w, r, "/?redirect_to="+r.URL.Path, http.StatusSeeOther)
+		return
+	}
+
+	org, role, ok := h.getMemberOrganization(w, r, user)
+	if !ok {
+		return
+	}
+
+	team, ok := h.getOrganizationTeam(w, r, org)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.teamService.GetTeamSessions(r.Context(), team.ID)
+	if err != nil {
+		utils.LogError("GetTeam", err)
+		http.Error(w, "Failed to get team sessions", http.StatusInternalServerError)
+		return
+	}
+	sessionVelocities, teamVelocity := calculateProjectVelocity(sessions)
+
+	references, err := h.teamService.GetReferences(r.Context(), team.ID)
+	if err != nil {
+		utils.LogError("GetTeam", err)
+		http.Error(w, "Failed to get reference stories", http.StatusInternalServerError)
+		return
+	}
+
+	accuracy, err := h.ticketService.GetTeamAccuracy(r.Context(), team.ID)
+	if err != nil {
+		utils.LogError("GetTeam", err)
+		http.Error(w, "Failed to get actuals", http.StatusInternalServerError)
+		return
+	}
+
+	feedbackTrend, err := h.teamService.GetFeedbackTrend(r.Context(), team.ID)
+	if err != nil {
+		utils.LogError("GetTeam", err)
+		http.Error(w, "Failed to get session feedback", http.StatusInternalServerError)
+		return
+	}
+
+	usage, err := h.teamService.GetCardUsage(r.Context(), team.ID)
+	if err != nil {
+		utils.LogError("GetTeam", err)
+		http.Error(w, "Failed to get card usage", http.StatusInternalServerError)
+		return
+	}
+
+	templates, err := h.teamService.GetDescriptionTemplates(r.Context(), team.ID)
+	if err != nil {
+		utils.LogError("GetTeam", err)
+		http.Error(w, "Failed to get description templates", http.StatusInternalServerError)
+		return
+	}
+
+	data := PageData{
+		Title:                team.Name,
+		Template:             "team",
+		User:                 user,
+		Organization:         org,
+		OrganizationRole:     role,
+		Team:                 team,
+		IsTeamMember:         isTeamMember(team, user.ID),
+		SessionVelocities:    sessionVelocities,
+		ProjectVelocity:      teamVelocity,
+		EstimationUnits:      deck.Units,
+		TeamReferences:       references,
+		DescriptionTemplates: templates,
+		TemplateRows:         make([]int, templateRows),
+		VotingCards:          deck.Cards(team.EstimationUnit),
+		AccuracyCharts:       accuracyCharts(accuracy),
+		FeedbackTrend:        feedbackTrend,
+		CardUsage:            cardUsage(usage, deck.Cards(team.EstimationUnit)),
+		ActualUnits:          models.ActualUnits,
+		Location:             viewerLocation(r, user),
+	}
+
+	h.executeTemplate(w, "base.html", data)
+}
+
+// UpdateTeam lets an organization admin rename a team or change the
+// defaults its future sessions start with.
+func (h *Handler) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	org, ok := h.getAdminOrganization(w, r, user, "change teams")
+	if !ok {
+		return
+	}
+
+	team, ok := h.getOrganizationTeam(w, r, org)
+	if !ok {
+		return
+	}
+
+	if validationErrors := parseTeamDefaults(r, team); validationErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, validationErrors)
+		return
+	}
+
+	if err := h.teamService.UpdateTeam(r.Context(), team); err != nil {
+		utils.LogError("UpdateTeam", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to update team")
+		return
+	}
+
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteTeam lets an organization admin remove a team. Its sessions stay in
+// the organization.
+func (h *Handler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	org, ok := h.getAdminOrganization(w, r, user, "delete teams")
+	if !ok {
+		return
+	}
+
+	team, ok := h.getOrganizationTeam(w, r, org)
+	if !ok {
+		return
+	}
+
+	if err := h.teamService.DeleteTeam(r.Context(), team.ID); err != nil {
+		utils.LogError("DeleteTeam", err)
+		http.Error(w, "Failed to delete team", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/org/"+org.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AddTeamMember lets an organization admin put one of its members on a
+// team's standing participant list.
+func (h *Handler) AddTeamMember(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	org, ok := h.getAdminOrganization(w, r, user, "change team members")
+	if !ok {
+		return
+	}
+
+	team, ok := h.getOrganizationTeam(w, r, org)
+	if !ok {
+		return
+	}
+
+	memberID := r.FormValue("user_id")
+	inOrganization := false
+	for _, member := range org.Members {
+		if member.ID == memberID {
+			inOrganization = true
+			break
+		}
+	}
+	if !inOrganization {
+		http.Error(w, "Only organization members can join its teams", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.teamService.AddTeamMember(r.Context(), team.ID, memberID); err != nil {
+		utils.LogError("AddTeamMember", err)
+		http.Error(w, "Failed to add team member", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveTeamMember lets an organization admin take someone off a team.
+func (h *Handler) RemoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	org, ok := h.getAdminOrganization(w, r, user, "change team members")
+	if !ok {
+		return
+	}
+
+	team, ok := h.getOrganizationTeam(w, r, org)
+	if !ok {
+		return
+	}
+
+	if err := h.teamService.RemoveTeamMember(r.Context(), team.ID, chi.URLParam(r, "userID")); err != nil {
+		writeServiceError(w, r, "RemoveTeamMember", err, "Failed to remove team member")
+		return
+	}
+
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateTeamSession starts a session with the team's defaults and its
+// members already invited. Team members and organization admins can start
+// one.
+func (h *Handler) CreateTeamSession(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	org, role, ok := h.getMemberOrganization(w, r, user)
+	if !ok {
+		return
+	}
+
+	team, ok := h.getOrganizationTeam(w, r, org)
+	if !ok {
+		return
+	}
+
+	if role != models.RoleAdmin && !isTeamMember(team, user.ID) {
+		http.Error(w, "Only team members can start team sessions", http.StatusForbidden)
+		return
+	}
+
+	name := utils.SanitizeInput(r.FormValue("name"))
+	if validationErrors := utils.ValidateSessionName(name); validationErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
+		return
+	}
+
+	session, err := h.sessionService.CreateTeamSession(r.Context(), team, name, user.ID)
+	if err != nil {
+		utils.LogError("CreateTeamSession", err)
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to create team session")
+		return
+	}
+
+	w.Header().Set("HX-Redirect", "/session/"+session.ID)
+}
+
+// getOrganizationTeam loads the team in the URL, writing a 404 if it does
+// not exist or belongs to another organization.
+func (h *Handler) getOrganizationTeam(w http.ResponseWriter, r *http.Request, org *models.Organization) (*models.Team, bool) {
+	team, err := h.teamService.GetTeamByID(r.Context(), chi.URLParam(r, "teamID"))
+	if err != nil {
+		http.Error(w, "Failed to get team", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if team == nil || team.OrganizationID != org.ID {
+		http.Error(w, "Team not found", http.StatusNotFound)
+		return nil, false
+	}
+	return team, true
+}
+
+// getMemberTeam loads the team in the URL of the /team routes, and whether
+// the user can change its reference stories and actuals. Its organization's
+// members can read them; team members and organization admins can change
+// them. Anyone else gets a 404.
+func (h *Handler) getMemberTeam(w http.ResponseWriter, r *http.Request, user *models.User) (*models.Team, bool, bool) {
+	team, err := h.teamService.GetTeamByID(r.Context(), chi.URLParam(r, "teamID"))
+	if err != nil {
+		utils.LogError("getMemberTeam", err, utils.ReportContext{UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to get team")
+		return nil, false, false
+	}
+	if team == nil {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Team not found")
+		return nil, false, false
+	}
+
+	role, err := h.organizationService.GetMemberRole(r.Context(), team.OrganizationID, user.ID)
+	if err != nil {
+		utils.LogError("getMemberTeam", err, utils.ReportContext{UserID: user.ID})
+		utils.WriteHTMLError(w, http.StatusInternalServerError, "Failed to check organization")
+		return nil, false, false
+	}
+	if role == "" {
+		utils.WriteHTMLError(w, http.StatusNotFound, "Team not found")
+		return nil, false, false
+	}
+
+	return team, role == models.RoleAdmin || isTeamMember(team, user.ID), true
+}
+
+func isTeamMember(team *models.Team, userID string) bool {
+	for _, member := range team.Members {
+		if member.ID == userID {
+			return true
+		}
+	}
+	return false
+}
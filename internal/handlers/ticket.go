@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/stats"
 	"poker-planning/internal/utils"
 
 	"github.com/go-chi/chi/v5"
@@ -19,7 +24,7 @@ func (h *Handler) CreateTicket(w http.ResponseWriter, r *http.Request) {
 
 	sessionID := chi.URLParam(r, "sessionID")
 	
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -34,28 +39,56 @@ func (h *Handler) CreateTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if descriptionTooLarge(w, r) {
+		return
+	}
 	title := utils.SanitizeInput(r.FormValue("title"))
 	description := utils.SanitizeInput(r.FormValue("description"))
+	externalKey := utils.SanitizeInput(r.FormValue("external_key"))
 
 	var allErrors utils.ValidationErrors
 	allErrors = append(allErrors, utils.ValidateTicketTitle(title)...)
 	allErrors = append(allErrors, utils.ValidateTicketDescription(description)...)
+	allErrors = append(allErrors, utils.ValidateTicketKey(externalKey)...)
+	templateErrors, err := h.checkTemplate(r, session, description)
+	if err != nil {
+		utils.LogError("CreateTicket", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		http.Error(w, "Failed to get description template", http.StatusInternalServerError)
+		return
+	}
+	allErrors = append(allErrors, templateErrors...)
 	
 	if allErrors.HasErrors() {
-		utils.WriteHTMLError(w, http.StatusBadRequest, allErrors.Error())
+		utils.WriteFormValidationError(w, r, allErrors)
 		return
 	}
 
-	ticket, err := h.ticketService.CreateTicket(sessionID, title, description)
+	if !h.checkTicketLimit(w, r, session, 1) {
+		return
+	}
+
+	ticket, err := h.ticketService.CreateTicket(r.Context(), sessionID, title, description, externalKey)
 	if err != nil {
 		http.Error(w, "Failed to create ticket", http.StatusInternalServerError)
 		return
 	}
 
+	if r.FormValue("calibration") != "" {
+		if err := h.ticketService.SetCalibration(r.Context(), ticket.ID, true); err != nil {
+			utils.LogError("CreateTicket", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		} else {
+			ticket.IsCalibration = true
+		}
+	}
+
 	h.wsService.Broadcast(sessionID, models.SSEMessage{
 		Type: "ticket-created",
 		Data: ticket,
 	})
+	h.recordEvent(r.Context(), sessionID, services.EventTicketCreated, ticket.ID, user.ID, map[string]interface{}{
+		"title":        ticket.Title,
+		"external_key": ticket.ExternalKey,
+	})
 
 	// Return success response for HTMX, redirect for regular requests
 	if r.Header.Get("HX-Request") != "" {
@@ -66,6 +99,115 @@ func (h *Handler) CreateTicket(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetTicketsPage renders the next page of the session backlog for the
+// lazy-loading ticket list.
+func (h *Handler) GetTicketsPage(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	offset := 0
+	var err error
+	if value := r.URL.Query().Get("offset"); value != "" {
+		offset, err = strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := ticketPageSize
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxTicketPageSize {
+			http.Error(w, fmt.Sprintf("Limit must be between 1 and %d", maxTicketPageSize), http.StatusBadRequest)
+			return
+		}
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, ticketCount, err := h.sessionService.GetSessionWithTicketPage(r.Context(), sessionID, offset, limit)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	isParticipant := false
+	for _, participant := range session.Participants {
+		if participant.ID == user.ID {
+			isParticipant = true
+			break
+		}
+	}
+
+	if !isParticipant {
+		http.Error(w, "Not a session participant", http.StatusForbidden)
+		return
+	}
+
+	ticketAverages := make(map[int]float64)
+	for _, ticket := range session.Tickets {
+		if len(ticket.Votes) > 0 {
+			if median := stats.Median(ticket.Votes); median != nil {
+				ticketAverages[ticket.ID] = *median
+			}
+		}
+	}
+
+	nextOffset := offset + len(session.Tickets)
+	data := PageData{
+		User:             user,
+		Session:          session,
+		TicketAverages:   ticketAverages,
+		TicketHistory:    h.ticketHistory(r.Context(), session, user),
+		Prevotes:         h.prevoteStatus(r.Context(), sessionID, user.ID),
+		TicketCount:      ticketCount,
+		HasMoreTickets:   nextOffset < ticketCount,
+		NextTicketOffset: nextOffset,
+	}
+
+	data.Conversion, _ = h.conversion(r.Context(), session, user.ID)
+	data.VotingCards = data.Conversion.Cards(session.Deck())
+
+	h.executeTemplate(w, "ticket-items", data)
+}
+
+// currentTicketIndex is the 1-based place of the current ticket in the
+// backlog. It is found in the loaded page when possible; a ticket beyond
+// the page is counted in the database.
+func (h *Handler) currentTicketIndex(ctx context.Context, session *models.Session) int {
+	for i, ticket := range session.Tickets {
+		if ticket.ID == session.CurrentTicket.ID {
+			return i + 1
+		}
+	}
+
+	index, err := h.sessionService.TicketIndex(ctx, session.ID, session.CurrentTicket)
+	if err != nil {
+		utils.LogError("currentTicketIndex", err)
+		return 0
+	}
+	return index
+}
+
+// ticketHistory looks up how the loaded tickets were estimated in the
+// user's earlier sessions. The history is only informative, so a failed
+// lookup is logged and the page renders without it.
+func (h *Handler) ticketHistory(ctx context.Context, session *models.Session, user *models.User) map[int][]models.TicketEstimate {
+	history, err := h.ticketService.GetEstimationHistory(ctx, session.ID, user.ID, session.Tickets)
+	if err != nil {
+		utils.LogError("ticketHistory", err)
+		return nil
+	}
+	return history
+}
+
 func (h *Handler) DeleteTicket(w http.ResponseWriter, r *http.Request) {
 	user := GetUserFromContext(r.Context())
 	if user == nil {
@@ -82,7 +224,7 @@ func (h *Handler) DeleteTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -98,7 +240,7 @@ func (h *Handler) DeleteTicket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get ticket before deletion for broadcast
-	ticket, err := h.ticketService.GetTicketByID(ticketID)
+	ticket, err := h.ticketService.GetTicketByID(r.Context(), ticketID)
 	if err != nil {
 		http.Error(w, "Failed to get ticket", http.StatusInternalServerError)
 		return
@@ -117,16 +259,16 @@ func (h *Handler) DeleteTicket(w http.ResponseWriter, r *http.Request) {
 	if session.CurrentTicketID != nil && *session.CurrentTicketID == ticketID {
 		session.CurrentTicketID = nil
 		session.IsVotingActive = false
-		err = h.sessionService.UpdateSession(session)
+		err = h.sessionService.UpdateSession(r.Context(), session)
 		if err != nil {
 			http.Error(w, "Failed to update session", http.StatusInternalServerError)
 			return
 		}
 	}
 
-	err = h.ticketService.DeleteTicket(ticketID)
+	err = h.ticketService.DeleteTicket(r.Context(), ticketID)
 	if err != nil {
-		http.Error(w, "Failed to delete ticket", http.StatusInternalServerError)
+		writeServiceError(w, r, "DeleteTicket", err, "Failed to delete ticket")
 		return
 	}
 
@@ -136,10 +278,219 @@ func (h *Handler) DeleteTicket(w http.ResponseWriter, r *http.Request) {
 			"ticket_id": ticketID,
 		},
 	})
+	h.recordEvent(r.Context(), sessionID, services.EventTicketDeleted, ticketID, user.ID, nil)
 
 	http.Redirect(w, r, "/session/"+sessionID, http.StatusSeeOther)
 }
 
+// DeleteTickets clears tickets in bulk. The request must carry confirm=true;
+// without it nothing is deleted and the response says how many tickets would be.
+func (h *Handler) DeleteTickets(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+
+	filter, ok := services.ParseTicketFilter(r.FormValue("filter"))
+	if !ok {
+		http.Error(w, "Invalid filter, expected all, unestimated or estimated", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only session owner can delete tickets", http.StatusForbidden)
+		return
+	}
+
+	if r.FormValue("confirm") != "true" {
+		count, err := h.ticketService.CountTickets(r.Context(), sessionID, filter)
+		if err != nil {
+			http.Error(w, "Failed to count tickets", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteHTMLError(w, http.StatusConflict, fmt.Sprintf("This will delete %d %s tickets. Resend with confirm=true to proceed.", count, filter))
+		return
+	}
+
+	h.restorePoint(r.Context(), sessionID, fmt.Sprintf("Before deleting %s tickets", filter), user)
+
+	deleted, err := h.ticketService.DeleteTickets(r.Context(), sessionID, filter)
+	if err != nil {
+		utils.LogError("DeleteTickets", err)
+		http.Error(w, "Failed to delete tickets", http.StatusInternalServerError)
+		return
+	}
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "tickets-deleted",
+		Data: map[string]interface{}{
+			"filter": filter,
+			"count":  deleted,
+		},
+	})
+	h.recordEvent(r.Context(), sessionID, services.EventTicketsDeleted, 0, user.ID, map[string]interface{}{
+		"filter": filter,
+		"count":  deleted,
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
+
+const maxSplitTickets = 10
+
+// getOwnedTicket loads a session ticket for an owner-only ticket action,
+// writing the error response and returning nil if anything doesn't check out.
+func (h *Handler) getOwnedTicket(w http.ResponseWriter, r *http.Request, action string) (*models.Session, *models.Ticket) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, nil
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	ticketID, err := strconv.Atoi(chi.URLParam(r, "ticketID"))
+	if err != nil {
+		http.Error(w, "Invalid ticket ID", http.StatusBadRequest)
+		return nil, nil
+	}
+
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return nil, nil
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return nil, nil
+	}
+
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only session owner can "+action, http.StatusForbidden)
+		return nil, nil
+	}
+
+	ticket, err := h.ticketService.GetTicketByID(r.Context(), ticketID)
+	if err != nil {
+		http.Error(w, "Failed to get ticket", http.StatusInternalServerError)
+		return nil, nil
+	}
+	if ticket == nil {
+		http.Error(w, "Ticket not found", http.StatusNotFound)
+		return nil, nil
+	}
+
+	if ticket.SessionID != sessionID {
+		http.Error(w, "Ticket does not belong to this session", http.StatusBadRequest)
+		return nil, nil
+	}
+
+	return session, ticket
+}
+
+func (h *Handler) DuplicateTicket(w http.ResponseWriter, r *http.Request) {
+	session, ticket := h.getOwnedTicket(w, r, "duplicate tickets")
+	if ticket == nil {
+		return
+	}
+
+	if !h.checkTicketLimit(w, r, session, 1) {
+		return
+	}
+
+	duplicate, err := h.ticketService.DuplicateTicket(r.Context(), ticket.ID)
+	if err != nil {
+		writeServiceError(w, r, "DuplicateTicket", err, "Failed to duplicate ticket")
+		return
+	}
+
+	h.wsService.Broadcast(session.ID, models.SSEMessage{
+		Type: "ticket-created",
+		Data: duplicate,
+	})
+	h.recordEvent(r.Context(), session.ID, services.EventTicketCreated, duplicate.ID, session.OwnerID, map[string]interface{}{
+		"title":        duplicate.Title,
+		"duplicate_of": ticket.ID,
+	})
+
+	finishAction(w, r, http.StatusCreated, "/session/"+session.ID)
+}
+
+// SplitTicket breaks a ticket into child tickets. Titles come one per line in
+// the "titles" field.
+func (h *Handler) SplitTicket(w http.ResponseWriter, r *http.Request) {
+	session, ticket := h.getOwnedTicket(w, r, "split tickets")
+	if ticket == nil {
+		return
+	}
+
+	if ticket.IsSplit {
+		http.Error(w, "Ticket has already been split", http.StatusConflict)
+		return
+	}
+
+	var titles []string
+	var allErrors utils.ValidationErrors
+	for _, line := range strings.Split(r.FormValue("titles"), "\n") {
+		title := utils.SanitizeInput(line)
+		if title == "" {
+			continue
+		}
+		allErrors = append(allErrors, utils.ValidateTicketTitle(title)...)
+		titles = append(titles, title)
+	}
+
+	if len(titles) < 2 || len(titles) > maxSplitTickets {
+		allErrors = append(allErrors, utils.ValidationError{
+			Field:   "titles",
+			Message: fmt.Sprintf("Enter between 2 and %d ticket titles, one per line", maxSplitTickets),
+		})
+	}
+
+	if allErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, allErrors.Error())
+		return
+	}
+
+	if !h.checkTicketLimit(w, r, session, len(titles)) {
+		return
+	}
+
+	children, err := h.ticketService.SplitTicket(r.Context(), ticket.ID, titles)
+	if err != nil {
+		writeServiceError(w, r, "SplitTicket", err, "Failed to split ticket")
+		return
+	}
+
+	ticket.IsSplit = true
+	h.wsService.Broadcast(session.ID, models.SSEMessage{
+		Type: "ticket-updated",
+		Data: map[string]interface{}{
+			"ticket":   ticket,
+			"children": children,
+		},
+	})
+	childIDs := make([]int, len(children))
+	for i, child := range children {
+		childIDs[i] = child.ID
+	}
+	h.recordEvent(r.Context(), session.ID, services.EventTicketSplit, ticket.ID, session.OwnerID, map[string][]int{"children": childIDs})
+
+	finishAction(w, r, http.StatusCreated, "/session/"+session.ID)
+}
+
 func (h *Handler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 	user := GetUserFromContext(r.Context())
 	if user == nil {
@@ -156,7 +507,7 @@ func (h *Handler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -171,7 +522,7 @@ func (h *Handler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ticket, err := h.ticketService.GetTicketByID(ticketID)
+	ticket, err := h.ticketService.GetTicketByID(r.Context(), ticketID)
 	if err != nil {
 		http.Error(w, "Failed to get ticket", http.StatusInternalServerError)
 		return
@@ -187,24 +538,42 @@ func (h *Handler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update ticket fields
-	title := r.FormValue("title")
-	description := r.FormValue("description")
-	
+	if descriptionTooLarge(w, r) {
+		return
+	}
+	title := utils.SanitizeInput(r.FormValue("title"))
+	description := utils.SanitizeInput(r.FormValue("description"))
+
+	var allErrors utils.ValidationErrors
 	if title != "" {
+		allErrors = append(allErrors, utils.ValidateTicketTitle(title)...)
 		ticket.Title = title
 	}
+	allErrors = append(allErrors, utils.ValidateTicketDescription(description)...)
 	ticket.Description = description
 
 	// Handle final estimate if provided
-	estimateStr := r.FormValue("final_estimate")
-	if estimateStr != "" {
-		estimate, err := strconv.Atoi(estimateStr)
-		if err == nil {
-			ticket.FinalEstimate = &estimate
-		}
+	estimate := utils.SanitizeInput(r.FormValue("final_estimate"))
+	if estimate != "" {
+		allErrors = append(allErrors, utils.ValidateEstimate(estimate, session.Deck())...)
+		ticket.FinalEstimate = &estimate
+	}
+
+	// The decision behind the final estimate is only changed when sent
+	if _, ok := r.Form["rationale"]; ok {
+		ticket.Rationale = utils.SanitizeInput(r.FormValue("rationale"))
+	}
+	if _, ok := r.Form["assumptions"]; ok {
+		ticket.Assumptions = utils.SanitizeInput(r.FormValue("assumptions"))
+	}
+	allErrors = append(allErrors, utils.ValidateDecision(ticket.Rationale, ticket.Assumptions)...)
+
+	if allErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, allErrors)
+		return
 	}
 
-	err = h.ticketService.UpdateTicket(ticket)
+	err = h.ticketService.UpdateTicket(r.Context(), ticket)
 	if err != nil {
 		http.Error(w, "Failed to update ticket", http.StatusInternalServerError)
 		return
@@ -214,6 +583,12 @@ func (h *Handler) UpdateTicket(w http.ResponseWriter, r *http.Request) {
 		Type: "ticket-updated",
 		Data: ticket,
 	})
+	h.recordEvent(r.Context(), sessionID, services.EventTicketUpdated, ticket.ID, user.ID, map[string]interface{}{
+		"title":          ticket.Title,
+		"final_estimate": ticket.FinalEstimate,
+		"rationale":      ticket.Rationale,
+		"assumptions":    ticket.Assumptions,
+	})
 
 	http.Redirect(w, r, "/session/"+sessionID, http.StatusSeeOther)
 }
\ No newline at end of file
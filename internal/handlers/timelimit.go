@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/utils"
+)
+
+const (
+	minVotingTimeLimit  = 10 * time.Second
+	maxVotingTimeLimit  = time.Hour
+	maxVoteChangeWindow = 24 * time.Hour
+)
+
+// parseVotingTimeLimit reads a voting time limit in seconds. An empty value
+// means no limit.
+func parseVotingTimeLimit(field, value string) (*int, utils.ValidationErrors) {
+	if value == "" {
+		return nil, nil
+	}
+
+	seconds, err := strconv.Atoi(value)
+	limit := time.Duration(seconds) * time.Second
+	if err != nil || limit < minVotingTimeLimit || limit > maxVotingTimeLimit {
+		return nil, utils.ValidationErrors{{
+			Field:   field,
+			Message: fmt.Sprintf("Time limit must be between %d and %d seconds", int(minVotingTimeLimit.Seconds()), int(maxVotingTimeLimit.Seconds())),
+		}}
+	}
+
+	return &seconds, nil
+}
+
+// parseVoteChangeWindow reads how many seconds votes may be changed after
+// they are revealed. An empty value allows changes any time and 0 locks
+// votes on reveal.
+func parseVoteChangeWindow(field, value string) (*int, utils.ValidationErrors) {
+	if value == "" {
+		return nil, nil
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > maxVoteChangeWindow {
+		return nil, utils.ValidationErrors{{
+			Field:   field,
+			Message: fmt.Sprintf("Vote change window must be between 0 and %d seconds", int(maxVoteChangeWindow.Seconds())),
+		}}
+	}
+
+	return &seconds, nil
+}
+
+// scheduleVotingTimeout ends the round that just started once the session's
+// time limit runs out. Call it whenever voting starts.
+func (h *Handler) scheduleVotingTimeout(session *models.Session) {
+	deadline := session.VotingDeadline()
+	if deadline == nil {
+		return
+	}
+
+	sessionID, startedAt := session.ID, *session.VotingStartedAt
+	time.AfterFunc(time.Until(*deadline), func() {
+		h.endTimedOutVoting(context.Background(), sessionID, startedAt)
+	})
+}
+
+// endTimedOutVoting reveals the votes of a round whose time is up, unless
+// that round already ended or a new one has started since.
+func (h *Handler) endTimedOutVoting(ctx context.Context, sessionID string, startedAt time.Time) {
+	session, err := h.sessionService.GetSessionWithoutTickets(ctx, sessionID)
+	if err != nil {
+		utils.LogError("endTimedOutVoting", err)
+		return
+	}
+	if session == nil || !session.IsVotingActive || session.CurrentTicket == nil ||
+		session.VotingStartedAt == nil || !session.VotingStartedAt.Equal(startedAt) {
+		return
+	}
+
+	err = h.votingService.EndVoting(ctx, session)
+	if errors.Is(err, services.ErrSessionModified) {
+		return
+	}
+	if err != nil {
+		utils.LogError("endTimedOutVoting", err)
+		return
+	}
+
+	h.announceReveal(ctx, session, session.CurrentTicket.Votes, "", "time-limit")
+}
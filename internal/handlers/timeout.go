@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// PollRequestTimeout bounds a long poll, which waits up to pollTimeout for
+// events and must not be cut off by a shorter request timeout.
+const PollRequestTimeout = pollTimeout + 5*time.Second
+
+// isStreamingRequest reports whether a request is meant to stay open: a
+// WebSocket handshake, an event stream, a long poll, an NDJSON export or a
+// pprof profile. These get their own timeouts, if any, on their routes.
+func isStreamingRequest(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream") ||
+		strings.HasSuffix(r.URL.Path, "/poll") || strings.HasSuffix(r.URL.Path, ".ndjson") ||
+		strings.HasPrefix(r.URL.Path, "/debug/pprof/")
+}
+
+// RequestTimeout cancels requests that run longer than timeout, answering
+// 504, except streaming requests. A timeout of 0 disables it.
+func RequestTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		limited := middleware.Timeout(timeout)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isStreamingRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limited.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+// TimezoneCookieName holds the browser's IANA timezone, which app.js sets
+// so times can be shown in it for users without a timezone preference.
+const TimezoneCookieName = "poker_tz"
+
+// viewerLocation is the timezone to show times in: the user's preference,
+// then their browser's, then UTC.
+func viewerLocation(r *http.Request, user *models.User) *time.Location {
+	if user != nil && user.Preferences.Timezone != "" {
+		if loc, err := time.LoadLocation(user.Preferences.Timezone); err == nil {
+			return loc
+		}
+	}
+
+	if cookie, err := r.Cookie(TimezoneCookieName); err == nil {
+		if name, err := url.QueryUnescape(cookie.Value); err == nil && name != "" {
+			if loc, err := time.LoadLocation(name); err == nil {
+				return loc
+			}
+		}
+	}
+
+	return time.UTC
+}
+
+// localTime formats t in the viewer's timezone for templates.
+func localTime(t time.Time, loc *time.Location, layout string) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(layout)
+}
@@ -1,10 +1,17 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"poker-planning/internal/deck"
 	"poker-planning/internal/models"
+	"poker-planning/internal/services"
+	"poker-planning/internal/stats"
 	"poker-planning/internal/utils"
 
 	"github.com/go-chi/chi/v5"
@@ -17,15 +24,14 @@ func (h *Handler) SubmitVote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sessionID := chi.URLParam(r, "sessionID")
-	voteValue := utils.SanitizeInput(r.FormValue("vote"))
-
-	if validationErrors := utils.ValidateVoteValue(voteValue); validationErrors.HasErrors() {
-		utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
-		return
-	}
+	h.submitVote(w, r, chi.URLParam(r, "sessionID"), user, utils.SanitizeInput(r.FormValue("vote")), true)
+}
 
-	session, err := h.sessionService.GetSessionByID(sessionID)
+// submitVote casts a participant's vote on the current ticket and answers
+// the request. A displayed vote is a card as the participant sees it,
+// possibly converted into their display unit.
+func (h *Handler) submitVote(w http.ResponseWriter, r *http.Request, sessionID string, user *models.User, voteValue string, displayed bool) {
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -35,41 +41,190 @@ func (h *Handler) SubmitVote(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Allow voting during active voting OR after voting has ended (for vote changes)
-	// Only prevent voting if no current ticket is selected
-	if session.CurrentTicket == nil {
-		http.Error(w, "No active ticket", http.StatusBadRequest)
+	if displayed {
+		conversion, _ := h.conversion(r.Context(), session, user.ID)
+		voteValue = conversion.Normalize(voteValue, session.Deck())
+	}
+
+	// Validate vote value against the session's deck
+	if validationErrors := utils.ValidateVoteValue(voteValue, session.Deck()); validationErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
 		return
 	}
 
-	// Validate vote value
-	validVote := false
-	for _, card := range models.AllVotingCards() {
-		if card == voteValue {
-			validVote = true
-			break
+	// Voting is allowed during active voting OR after voting has ended (for
+	// vote changes); the service enforces who may vote and when
+	vote, err := h.votingService.SubmitVote(r.Context(), sessionID, user.ID, voteValue)
+	if err != nil {
+		writeServiceError(w, r, "SubmitVote", err, "Failed to submit vote")
+		return
+	}
+
+	h.broadcastVoteCast(r.Context(), session, user.ID, vote)
+	// Participants can poll the log, so the value waits for the reveal event
+	h.recordEvent(r.Context(), sessionID, services.EventVoteCast, vote.TicketID, user.ID, nil)
+
+	if session.AutoReveal && session.IsVotingActive {
+		session, err = h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+		if err != nil {
+			utils.LogError("SubmitVote", err)
+		} else if session != nil {
+			h.autoReveal(r.Context(), session)
+		}
+	}
+
+	finishAction(w, r, http.StatusOK, "/session/"+sessionID)
+}
+
+// broadcastVoteCast tells the session that a participant voted, and what,
+// except in Delphi mode, which never tells who voted what. It carries who
+// has voted so far, who may vote when a breakout group is voting and, for
+// a vote changed after the reveal, the new histogram, so that clients can
+// update without reloading the session.
+func (h *Handler) broadcastVoteCast(ctx context.Context, session *models.Session, userID string, vote *models.Vote) {
+	data := map[string]interface{}{"user_id": userID}
+	if !session.IsDelphi() {
+		data["vote"] = vote
+	}
+	message := models.SSEMessage{Type: "vote-cast", Data: data}
+
+	current, err := h.sessionService.GetSessionWithoutTickets(ctx, session.ID)
+	if err != nil {
+		utils.LogError("broadcastVoteCast", err, utils.ReportContext{SessionID: session.ID, UserID: userID})
+	} else if current != nil {
+		voted := votedIDs(current)
+		data["voted"] = voted
+		data["vote_count"] = len(voted)
+		if current.CurrentTicket != nil && len(current.CurrentTicket.BreakoutVoters) > 0 {
+			data["breakout"] = current.CurrentTicket.BreakoutVoters
+		}
+		if sessionPhase(current) == "revealed" && !current.IsDelphi() {
+			data["histogram"] = h.voteHistogram(current.CurrentTicket.Votes, stats.ForSession(current))
+		}
+		message.StateHash = stateHash(current)
+	}
+
+	h.wsService.Touch(session.ID, userID)
+	h.wsService.Broadcast(session.ID, message)
+}
+
+// autoReveal ends voting on a session with auto-reveal enabled once every
+// expected voter has voted: the ticket's breakout group, if it has one.
+// Stakeholders are never waited for, and away participants are not waited
+// for unless the session says otherwise. The session must have its current
+// ticket's votes loaded.
+func (h *Handler) autoReveal(ctx context.Context, session *models.Session) {
+	if !session.AutoReveal || !session.IsVotingActive || session.CurrentTicket == nil {
+		return
+	}
+
+	// Stakeholders' advisory votes neither hold up nor trigger the reveal
+	voted := make(map[string]bool)
+	for _, vote := range session.CurrentTicket.Votes {
+		if !vote.Advisory {
+			voted[vote.UserID] = true
 		}
 	}
-	if !validVote {
-		http.Error(w, "Invalid vote value", http.StatusBadRequest)
+
+	if len(voted) == 0 {
 		return
 	}
 
-	vote, err := h.votingService.SubmitVote(session.CurrentTicket.ID, user.ID, voteValue)
+	for _, participant := range session.Participants {
+		if participant.IsStakeholder || !session.CurrentTicket.CanVote(participant.ID) {
+			continue
+		}
+		if session.AutoRevealIgnoresAway && h.isAway(session.ID, participant) {
+			continue
+		}
+		if !voted[participant.ID] {
+			return
+		}
+	}
+
+	// Losing a race to the owner or another voter means voting already moved on
+	err := h.votingService.EndVoting(ctx, session)
+	if errors.Is(err, services.ErrSessionModified) {
+		return
+	}
 	if err != nil {
-		http.Error(w, "Failed to submit vote", http.StatusInternalServerError)
+		utils.LogError("autoReveal", err)
 		return
 	}
 
-	h.wsService.Broadcast(sessionID, models.SSEMessage{
-		Type: "vote-cast",
-		Data: map[string]interface{}{
-			"user_id": user.ID,
-			"vote":    vote,
-		},
+	h.announceReveal(ctx, session, session.CurrentTicket.Votes, "", "auto")
+}
+
+// announceReveal tells the session the votes of the round that just ended
+// and records it. cause is what ended it: "owner", "auto" or "time-limit".
+// A Delphi session only learns the round's aggregate, and its next round is
+// scheduled unless the votes converged or the ticket had all its rounds.
+func (h *Handler) announceReveal(ctx context.Context, session *models.Session, votes []models.Vote, userID, cause string) {
+	data := map[string]interface{}{
+		"ticket":            session.CurrentTicket,
+		"vote_change_until": session.VoteChangeDeadline(),
+	}
+	if cause != "owner" {
+		data["auto"] = true
+	}
+	if cause == "time-limit" {
+		data["timed_out"] = true
+	}
+
+	h.flagSplit(ctx, session, votes)
+	data["needs_split"] = session.CurrentTicket.NeedsSplit
+
+	delphi := h.delphiResult(ctx, session, votes)
+	if delphi != nil {
+		data["delphi"] = delphi
+	} else {
+		data["votes"] = votes
+		data["histogram"] = h.voteHistogram(votes, stats.ForSession(session))
+	}
+
+	h.wsService.Broadcast(session.ID, models.SSEMessage{
+		Type: "voting-ended",
+		Data: data,
 	})
+	if delphi == nil {
+		h.promptDiscussion(session.ID, session.CurrentTicket.ID, votes)
+	} else if delphi.Phase == delphiNextRound {
+		h.scheduleDelphiRound(session)
+	}
+	h.recordReveal(ctx, session, session.CurrentTicket.ID, userID, cause, votes)
+}
+
+// flagSplit flags the current ticket as needing to be split when a split
+// card is the most played card of the round, and clears the flag when a
+// later round is not. Call it before announcing the reveal, whose page
+// refresh offers the owner to split the ticket.
+func (h *Handler) flagSplit(ctx context.Context, session *models.Session, votes []models.Vote) {
+	ticket := session.CurrentTicket
+	ticketStats := stats.ForSession(session).Ticket(votes)
+	needsSplit := deck.IsSplitCard(ticketStats.Mode, session.SpecialCards())
+	if needsSplit == ticket.NeedsSplit {
+		return
+	}
 
-	w.WriteHeader(http.StatusOK)
+	if err := h.ticketService.SetNeedsSplit(ctx, ticket.ID, needsSplit); err != nil {
+		utils.LogError("flagSplit", err, utils.ReportContext{SessionID: session.ID})
+		return
+	}
+	ticket.NeedsSplit = needsSplit
+	if !needsSplit {
+		return
+	}
+
+	splitVotes := 0
+	for _, vote := range votes {
+		if vote.VoteValue == ticketStats.Mode {
+			splitVotes++
+		}
+	}
+	h.recordEvent(ctx, session.ID, services.EventTicketNeedsSplit, ticket.ID, "", map[string]interface{}{
+		"card":  ticketStats.Mode,
+		"votes": splitVotes,
+	})
 }
 
 func (h *Handler) StartVoting(w http.ResponseWriter, r *http.Request) {
@@ -81,7 +236,7 @@ func (h *Handler) StartVoting(w http.ResponseWriter, r *http.Request) {
 
 	sessionID := chi.URLParam(r, "sessionID")
 	
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -91,8 +246,8 @@ func (h *Handler) StartVoting(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if session.OwnerID != user.ID {
-		http.Error(w, "Only session owner can start voting", http.StatusForbidden)
+	if !session.CanDrive(user.ID) {
+		http.Error(w, "Only the session owner or driver can start voting", http.StatusForbidden)
 		return
 	}
 
@@ -101,17 +256,27 @@ func (h *Handler) StartVoting(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session.IsVotingActive = true
-	err = h.sessionService.UpdateSession(session)
-	if err != nil {
-		http.Error(w, "Failed to start voting", http.StatusInternalServerError)
+	// Votes on a ticket that is not being voted on were already revealed, so
+	// only start over when asked to, and keep that round in history
+	revealed := !session.IsVotingActive && len(session.CurrentTicket.Votes) > 0
+	if revealed && r.FormValue("revote") != "true" {
+		http.Error(w, "Votes for this ticket were already revealed; pass revote=true to start a new round", http.StatusConflict)
 		return
 	}
 
-	// Clear existing votes for this ticket
-	err = h.votingService.ClearVotesForTicket(session.CurrentTicket.ID)
+	if err := r.ParseForm(); err != nil {
+		utils.WriteHTMLError(w, http.StatusBadRequest, "Invalid form")
+		return
+	}
+	breakout, fieldErrors := parseBreakout(session, r.Form["breakout"])
+	if fieldErrors.HasErrors() {
+		utils.WriteFormValidationError(w, r, fieldErrors)
+		return
+	}
+
+	err = h.votingService.StartVoting(r.Context(), session, session.CurrentTicket.ID, revealed, breakout)
 	if err != nil {
-		http.Error(w, "Failed to clear votes", http.StatusInternalServerError)
+		writeServiceError(w, r, "StartVoting", err, "Failed to start voting")
 		return
 	}
 
@@ -119,6 +284,14 @@ func (h *Handler) StartVoting(w http.ResponseWriter, r *http.Request) {
 		Type: "voting-started",
 		Data: session.CurrentTicket,
 	})
+	eventData := map[string]interface{}{"revote": revealed}
+	if len(breakout) > 0 {
+		eventData["breakout"] = breakout
+	}
+	h.recordEvent(r.Context(), sessionID, services.EventVotingStarted, session.CurrentTicket.ID, user.ID, eventData)
+	h.scheduleBotVotes(r.Context(), sessionID, session.CurrentTicket.ID)
+	h.scheduleVotingTimeout(session)
+	h.pushVotingStarted(session, session.CurrentTicket, user.ID)
 
 	http.Redirect(w, r, "/session/"+sessionID, http.StatusSeeOther)
 }
@@ -132,7 +305,7 @@ func (h *Handler) EndVoting(w http.ResponseWriter, r *http.Request) {
 
 	sessionID := chi.URLParam(r, "sessionID")
 	
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -142,32 +315,30 @@ func (h *Handler) EndVoting(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if session.OwnerID != user.ID {
-		http.Error(w, "Only session owner can end voting", http.StatusForbidden)
+	if !session.CanDrive(user.ID) {
+		http.Error(w, "Only the session owner or driver can end voting", http.StatusForbidden)
 		return
 	}
 
-	session.IsVotingActive = false
-	err = h.sessionService.UpdateSession(session)
+	if session.CurrentTicket == nil {
+		http.Error(w, "No active ticket", http.StatusBadRequest)
+		return
+	}
+
+	err = h.votingService.EndVoting(r.Context(), session)
 	if err != nil {
-		http.Error(w, "Failed to end voting", http.StatusInternalServerError)
+		writeServiceError(w, r, "EndVoting", err, "Failed to end voting")
 		return
 	}
 
 	// Get updated votes for the ticket
-	votes, err := h.votingService.GetVotesForTicket(session.CurrentTicket.ID)
+	votes, err := h.votingService.GetVotesForTicket(r.Context(), session.CurrentTicket.ID)
 	if err != nil {
 		http.Error(w, "Failed to get votes", http.StatusInternalServerError)
 		return
 	}
 
-	h.wsService.Broadcast(sessionID, models.SSEMessage{
-		Type: "voting-ended",
-		Data: map[string]interface{}{
-			"ticket": session.CurrentTicket,
-			"votes":  votes,
-		},
-	})
+	h.announceReveal(r.Context(), session, votes, user.ID, "owner")
 
 	http.Redirect(w, r, "/session/"+sessionID, http.StatusSeeOther)
 }
@@ -181,7 +352,7 @@ func (h *Handler) NextTicket(w http.ResponseWriter, r *http.Request) {
 
 	sessionID := chi.URLParam(r, "sessionID")
 	
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -191,8 +362,8 @@ func (h *Handler) NextTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if session.OwnerID != user.ID {
-		http.Error(w, "Only session owner can advance tickets", http.StatusForbidden)
+	if !session.CanDrive(user.ID) {
+		http.Error(w, "Only the session owner or driver can advance tickets", http.StatusForbidden)
 		return
 	}
 
@@ -212,13 +383,17 @@ func (h *Handler) NextTicket(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if nextTicket != nil {
+		if err := services.CheckRequiredFields(session, nextTicket); err != nil {
+			writeServiceError(w, r, "NextTicket", err, "Failed to advance ticket")
+			return
+		}
 		session.CurrentTicketID = &nextTicket.ID
 	} else {
 		session.CurrentTicketID = nil
 	}
 	
 	session.IsVotingActive = false
-	err = h.sessionService.UpdateSession(session)
+	err = h.sessionService.UpdateSession(r.Context(), session)
 	if err != nil {
 		http.Error(w, "Failed to advance ticket", http.StatusInternalServerError)
 		return
@@ -228,6 +403,9 @@ func (h *Handler) NextTicket(w http.ResponseWriter, r *http.Request) {
 		Type: "ticket-changed",
 		Data: nextTicket,
 	})
+	if nextTicket != nil {
+		h.recordEvent(r.Context(), sessionID, services.EventTicketSelected, nextTicket.ID, user.ID, nil)
+	}
 
 	http.Redirect(w, r, "/session/"+sessionID, http.StatusSeeOther)
 }
@@ -242,7 +420,7 @@ func (h *Handler) SelectTicket(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "sessionID")
 	ticketIDStr := chi.URLParam(r, "ticketID")
 	
-	session, err := h.sessionService.GetSessionByID(sessionID)
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
 	if err != nil {
 		http.Error(w, "Failed to get session", http.StatusInternalServerError)
 		return
@@ -252,8 +430,8 @@ func (h *Handler) SelectTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if session.OwnerID != user.ID {
-		http.Error(w, "Only session owner can select tickets", http.StatusForbidden)
+	if !session.CanDrive(user.ID) {
+		http.Error(w, "Only the session owner or driver can select tickets", http.StatusForbidden)
 		return
 	}
 
@@ -278,10 +456,15 @@ func (h *Handler) SelectTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := services.CheckRequiredFields(session, selectedTicket); err != nil {
+		writeServiceError(w, r, "SelectTicket", err, "Failed to select ticket")
+		return
+	}
+
 	// Update session with selected ticket
 	session.CurrentTicketID = &ticketID
 	session.IsVotingActive = false
-	err = h.sessionService.UpdateSession(session)
+	err = h.sessionService.UpdateSession(r.Context(), session)
 	if err != nil {
 		http.Error(w, "Failed to select ticket", http.StatusInternalServerError)
 		return
@@ -291,6 +474,312 @@ func (h *Handler) SelectTicket(w http.ResponseWriter, r *http.Request) {
 		Type: "ticket-changed",
 		Data: selectedTicket,
 	})
+	h.recordEvent(r.Context(), sessionID, services.EventTicketSelected, ticketID, user.ID, nil)
+
+	http.Redirect(w, r, "/session/"+sessionID, http.StatusSeeOther)
+}
+
+func (h *Handler) ReopenTicket(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	ticketIDStr := chi.URLParam(r, "ticketID")
+
+	ticketID, err := strconv.Atoi(ticketIDStr)
+	if err != nil {
+		http.Error(w, "Invalid ticket ID", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if !session.CanDrive(user.ID) {
+		http.Error(w, "Only the session owner or driver can reopen tickets", http.StatusForbidden)
+		return
+	}
+
+	ticket, err := h.ticketService.GetTicketByID(r.Context(), ticketID)
+	if err != nil {
+		http.Error(w, "Failed to get ticket", http.StatusInternalServerError)
+		return
+	}
+	if ticket == nil {
+		http.Error(w, "Ticket not found", http.StatusNotFound)
+		return
+	}
+
+	if ticket.SessionID != sessionID {
+		http.Error(w, "Ticket does not belong to this session", http.StatusBadRequest)
+		return
+	}
+
+	// Keep the previous round's votes in history instead of discarding them,
+	// and the breakout group it was voted on by
+	err = h.votingService.ReopenTicket(r.Context(), session, ticketID, ticket.BreakoutVoters)
+	if err != nil {
+		writeServiceError(w, r, "ReopenTicket", err, "Failed to reopen voting")
+		return
+	}
+	ticket.FinalEstimate = nil
+	ticket.EstimateLow, ticket.EstimateHigh = nil, nil
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "voting-started",
+		Data: ticket,
+	})
+	h.recordEvent(r.Context(), sessionID, services.EventTicketReopened, ticketID, user.ID, nil)
+	h.scheduleBotVotes(r.Context(), sessionID, ticketID)
+	h.scheduleVotingTimeout(session)
+	h.pushVotingStarted(session, ticket, user.ID)
 
 	http.Redirect(w, r, "/session/"+sessionID, http.StatusSeeOther)
-}
\ No newline at end of file
+}
+
+func (h *Handler) GetVoteHistogram(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	ticketIDStr := chi.URLParam(r, "ticketID")
+
+	ticketID, err := strconv.Atoi(ticketIDStr)
+	if err != nil {
+		http.Error(w, "Invalid ticket ID", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	// Check if user is a participant
+	isParticipant := false
+	for _, participant := range session.Participants {
+		if participant.ID == user.ID {
+			isParticipant = true
+			break
+		}
+	}
+
+	if !isParticipant {
+		http.Error(w, "Not a session participant", http.StatusForbidden)
+		return
+	}
+
+	var ticket *models.Ticket
+	for i := range session.Tickets {
+		if session.Tickets[i].ID == ticketID {
+			ticket = &session.Tickets[i]
+			break
+		}
+	}
+
+	if ticket == nil {
+		http.Error(w, "Ticket not found", http.StatusNotFound)
+		return
+	}
+
+	// Votes on the ticket being voted on stay hidden until reveal
+	if session.IsVotingActive && session.CurrentTicketID != nil && *session.CurrentTicketID == ticketID {
+		http.Error(w, "Votes are not revealed yet", http.StatusConflict)
+		return
+	}
+
+	h.executeTemplate(w, "vote-histogram", stats.ForSession(session).Histogram(ticket.Votes))
+}
+
+func (h *Handler) SetRoundingStrategy(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+
+	strategy, ok := deck.ParseRoundingStrategy(r.FormValue("strategy"))
+	if !ok {
+		http.Error(w, "Invalid rounding strategy", http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only session owner can change the rounding strategy", http.StatusForbidden)
+		return
+	}
+
+	session.RoundingStrategy = string(strategy)
+	err = h.sessionService.UpdateSession(r.Context(), session)
+	if err != nil {
+		http.Error(w, "Failed to update rounding strategy", http.StatusInternalServerError)
+		return
+	}
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "session-updated",
+		Data: session,
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
+
+func (h *Handler) AcceptEstimate(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+
+	session, err := h.sessionService.GetSessionByID(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only session owner can accept estimates", http.StatusForbidden)
+		return
+	}
+
+	if session.CurrentTicket == nil {
+		http.Error(w, "No active ticket", http.StatusBadRequest)
+		return
+	}
+
+	if session.IsVotingActive {
+		http.Error(w, "End voting before accepting an estimate", http.StatusBadRequest)
+		return
+	}
+
+	// An explicit estimate overrides the suggestion, but must still be a card
+	finalEstimate := utils.SanitizeInput(r.FormValue("estimate"))
+	if finalEstimate != "" {
+		if validationErrors := utils.ValidateEstimate(finalEstimate, session.Deck()); validationErrors.HasErrors() {
+			utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
+			return
+		}
+	} else {
+		suggested := stats.ForSession(session).Suggested(session.CurrentTicket.Votes)
+		if suggested == nil {
+			http.Error(w, "No numeric votes to accept", http.StatusBadRequest)
+			return
+		}
+		finalEstimate = deck.FormatValue(*suggested)
+	}
+
+	// Why the team settled on it, kept with the ticket for later
+	rationale := utils.SanitizeInput(r.FormValue("rationale"))
+	assumptions := utils.SanitizeInput(r.FormValue("assumptions"))
+	if validationErrors := utils.ValidateDecision(rationale, assumptions); validationErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
+		return
+	}
+
+	// The range around it is the middle half of the votes unless the team
+	// settled on another
+	low, high := rangeCards(stats.VoteRange(session.CurrentTicket.Votes))
+	lowCard, highCard := utils.SanitizeInput(r.FormValue("estimate_low")), utils.SanitizeInput(r.FormValue("estimate_high"))
+	if lowCard != "" || highCard != "" {
+		if validationErrors := utils.ValidateEstimateRange(lowCard, highCard, session.Deck()); validationErrors.HasErrors() {
+			utils.WriteHTMLError(w, http.StatusBadRequest, validationErrors.Error())
+			return
+		}
+		low, high = &lowCard, &highCard
+	}
+
+	err = h.ticketService.SetFinalEstimate(r.Context(), session.CurrentTicket.ID, finalEstimate, low, high, rationale, assumptions)
+	if err != nil {
+		http.Error(w, "Failed to accept estimate", http.StatusInternalServerError)
+		return
+	}
+	session.CurrentTicket.FinalEstimate = &finalEstimate
+	session.CurrentTicket.EstimateLow = low
+	session.CurrentTicket.EstimateHigh = high
+	session.CurrentTicket.Rationale = rationale
+	session.CurrentTicket.Assumptions = assumptions
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "ticket-updated",
+		Data: session.CurrentTicket,
+	})
+	h.recordEvent(r.Context(), sessionID, services.EventEstimateAccepted, session.CurrentTicket.ID, user.ID, map[string]interface{}{
+		"estimate":      finalEstimate,
+		"estimate_low":  low,
+		"estimate_high": high,
+		"rationale":     rationale,
+		"assumptions":   assumptions,
+		"comment":       decisionComment(session, session.CurrentTicket),
+	})
+
+	http.Redirect(w, r, "/session/"+sessionID, http.StatusSeeOther)
+}
+
+// rangeCards is the cards at either end of a suggested range, as a ticket
+// keeps them, or nil without a range.
+func rangeCards(estimateRange *stats.Range) (low, high *string) {
+	if estimateRange == nil {
+		return nil, nil
+	}
+	lowCard, highCard := deck.FormatValue(estimateRange.Low), deck.FormatValue(estimateRange.High)
+	return &lowCard, &highCard
+}
+
+// decisionComment words a ticket's final estimate and the reasons behind it
+// as a comment for its issue, for hooks that write estimates back to a
+// tracker.
+func decisionComment(session *models.Session, ticket *models.Ticket) string {
+	if ticket.FinalEstimate == nil {
+		return ""
+	}
+
+	var comment strings.Builder
+	fmt.Fprintf(&comment, "Estimated at %s", deck.FormatCard(*ticket.FinalEstimate, session.EstimationUnit))
+	if estimateRange := ticket.EstimateRange(session.EstimationUnit); estimateRange != "" {
+		fmt.Fprintf(&comment, " (range %s)", estimateRange)
+	}
+	fmt.Fprintf(&comment, " in planning poker session %q.", session.Name)
+	if ticket.Rationale != "" {
+		fmt.Fprintf(&comment, "\nWhy: %s", ticket.Rationale)
+	}
+	if ticket.Assumptions != "" {
+		fmt.Fprintf(&comment, "\nAssumptions: %s", ticket.Assumptions)
+	}
+	return comment.String()
+}
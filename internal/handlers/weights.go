@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	minParticipantWeight = 0.1
+	maxParticipantWeight = 10
+)
+
+// participantWeights are the weights offered in the participant list.
+var participantWeights = []float64{0.5, 1, 1.5, 2, 3}
+
+// weightOptions lists the weights to offer for a participant, including
+// their current one if it was set to something else through the API.
+func weightOptions(current float64) []float64 {
+	options := append([]float64(nil), participantWeights...)
+	if current <= 0 {
+		return options
+	}
+	for _, option := range options {
+		if option == current {
+			return options
+		}
+	}
+	options = append(options, current)
+	sort.Float64s(options)
+	return options
+}
+
+// parseParticipantWeight reads how much a participant's votes count, where
+// 1 is an ordinary vote and 2 counts twice.
+func parseParticipantWeight(field, value string) (float64, utils.ValidationErrors) {
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(weight) || weight < minParticipantWeight || weight > maxParticipantWeight {
+		return 0, utils.ValidationErrors{{
+			Field:   field,
+			Message: fmt.Sprintf("Weight must be between %g and %g", minParticipantWeight, float64(maxParticipantWeight)),
+		}}
+	}
+	return weight, nil
+}
+
+// SetParticipantWeight lets the owner give a participant's votes more or
+// less say in the median, mean and suggested estimate, e.g. 2 for the owner
+// of the component being estimated.
+func (h *Handler) SetParticipantWeight(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	participantID := chi.URLParam(r, "userID")
+
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		http.Error(w, "Failed to get session", http.StatusInternalServerError)
+		return
+	}
+	if session == nil {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	if session.OwnerID != user.ID {
+		http.Error(w, "Only session owner can change vote weights", http.StatusForbidden)
+		return
+	}
+
+	var participant *models.User
+	for i := range session.Participants {
+		if session.Participants[i].ID == participantID {
+			participant = &session.Participants[i]
+			break
+		}
+	}
+	if participant == nil {
+		http.Error(w, "Participant not found", http.StatusNotFound)
+		return
+	}
+
+	weight, fieldErrors := parseParticipantWeight("weight", r.FormValue("weight"))
+	if fieldErrors.HasErrors() {
+		utils.WriteHTMLError(w, http.StatusBadRequest, fieldErrors.Error())
+		return
+	}
+
+	if err := h.sessionService.SetParticipantWeight(r.Context(), sessionID, participantID, weight); err != nil {
+		writeServiceError(w, r, "SetParticipantWeight", err, "Failed to set vote weight")
+		return
+	}
+	participant.Weight = weight
+
+	h.wsService.Broadcast(sessionID, models.SSEMessage{
+		Type: "session-updated",
+		Data: session,
+	})
+
+	finishAction(w, r, http.StatusNoContent, "/session/"+sessionID)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/utils"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// connectTokenTTL is how long a WebSocket connection token can be used to
+// connect. Established connections are not cut off when it runs out.
+const connectTokenTTL = time.Minute
+
+// ConnectToken is what clients get to open the session WebSocket without a
+// cookie.
+type ConnectToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	URL       string    `json:"url"`
+}
+
+// connectTokenMAC signs a user's right to connect to a session's WebSocket
+// until expires.
+func (h *Handler) connectTokenMAC(sessionID, userID string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(h.config.ConnectTokenSecret))
+	mac.Write([]byte("ws:" + sessionID + ":" + userID + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// connectTokenUser returns the user a connection token was issued to, or
+// nil when it is not valid for the session or has expired.
+func (h *Handler) connectTokenUser(r *http.Request, sessionID, token string) *models.User {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	userID := parts[0]
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(parts[2]), []byte(h.connectTokenMAC(sessionID, userID, expires))) != 1 {
+		return nil
+	}
+
+	user, err := h.userService.GetUserByID(r.Context(), userID)
+	if err != nil {
+		utils.LogError("connectTokenUser", err, utils.ReportContext{SessionID: sessionID, UserID: userID})
+		return nil
+	}
+	return user
+}
+
+// IsConnectTokenHandshake reports whether a request opens a session
+// WebSocket with a connection token. Such handshakes are authenticated by
+// the token alone, which could only be issued to someone already past the
+// instance passphrase and basic auth, so those can let them through.
+func IsConnectTokenHandshake(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/session/") &&
+		strings.HasSuffix(r.URL.Path, "/ws") && r.URL.Query().Get("token") != ""
+}
+
+// IssueConnectToken gives a participant a short-lived token to open the
+// session WebSocket with, for native clients and scripts that do not keep
+// cookies.
+func (h *Handler) IssueConnectToken(w http.ResponseWriter, r *http.Request) {
+	user := GetUserFromContext(r.Context())
+	if user == nil {
+		utils.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessionID := chi.URLParam(r, "sessionID")
+	session, err := h.sessionService.GetSessionWithoutTickets(r.Context(), sessionID)
+	if err != nil {
+		utils.LogError("IssueConnectToken", err, utils.ReportContext{SessionID: sessionID, UserID: user.ID})
+		utils.WriteJSONError(w, http.StatusInternalServerError, "Failed to get session")
+		return
+	}
+	if session == nil {
+		utils.WriteJSONError(w, http.StatusNotFound, "Session not found")
+		return
+	}
+	isParticipant := false
+	for _, participant := range session.Participants {
+		if participant.ID == user.ID {
+			isParticipant = true
+			break
+		}
+	}
+	if !isParticipant {
+		utils.WriteJSONError(w, http.StatusForbidden, "Not a session participant")
+		return
+	}
+
+	expiresAt := time.Now().Add(connectTokenTTL).Truncate(time.Second)
+	expires := expiresAt.Unix()
+	token := user.ID + "." + strconv.FormatInt(expires, 10) + "." + h.connectTokenMAC(sessionID, user.ID, expires)
+
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteJSON(w, http.StatusOK, ConnectToken{
+		Token:     token,
+		ExpiresAt: expiresAt.UTC(),
+		URL:       "/session/" + sessionID + "/ws?token=" + token,
+	})
+}
@@ -0,0 +1,89 @@
+package importers
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"poker-planning/internal/models"
+)
+
+// MaxActuals caps how many rows a single actuals import reads.
+const MaxActuals = 5000
+
+// ReadActuals reads what finished tickets took from a CSV or TSV export.
+// The first row names the columns: actual is required, along with ticket_id
+// or external_ref to say which ticket each row is for; unit is optional and
+// defaults to defaultUnit. Rows without an actual are skipped.
+func ReadActuals(r io.Reader, comma rune, defaultUnit string) ([]models.TicketActual, error) {
+	reader, columns, err := readCSVHeader(r, comma, "ticket_id", "external_ref", "actual", "unit")
+	if err != nil {
+		return nil, err
+	}
+	if columns["actual"] < 0 {
+		return nil, fmt.Errorf("%w: the first row must name an actual column", ErrInvalidFile)
+	}
+	if columns["ticket_id"] < 0 && columns["external_ref"] < 0 {
+		return nil, fmt.Errorf("%w: the first row must name a ticket_id or external_ref column", ErrInvalidFile)
+	}
+
+	var actuals []models.TicketActual
+	for row := 2; len(actuals) < MaxActuals; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+
+		value := strings.TrimSpace(columns.field(record, "actual"))
+		if value == "" {
+			continue
+		}
+
+		actual := models.TicketActual{
+			ExternalKey: strings.TrimSpace(columns.field(record, "external_ref")),
+			Unit:        strings.ToLower(strings.TrimSpace(columns.field(record, "unit"))),
+		}
+		if actual.Unit == "" {
+			actual.Unit = defaultUnit
+		}
+		if id := strings.TrimSpace(columns.field(record, "ticket_id")); id != "" {
+			actual.TicketID, err = strconv.Atoi(id)
+			if err != nil {
+				return nil, fmt.Errorf("%w: row %d: ticket_id must be a number", ErrInvalidFile, row)
+			}
+		}
+		actual.Actual, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: row %d: actual must be a number", ErrInvalidFile, row)
+		}
+		if err := ValidateActual(actual); err != nil {
+			return nil, fmt.Errorf("%w: row %d: %v", ErrInvalidFile, row, err)
+		}
+
+		actuals = append(actuals, actual)
+	}
+
+	return actuals, nil
+}
+
+// ValidateActual checks that an actual names its ticket and is a
+// non-negative amount of one of the actual units.
+func ValidateActual(actual models.TicketActual) error {
+	if actual.TicketID <= 0 && actual.ExternalKey == "" {
+		return fmt.Errorf("a ticket_id or external_ref is required")
+	}
+	if math.IsNaN(actual.Actual) || math.IsInf(actual.Actual, 0) || actual.Actual < 0 {
+		return fmt.Errorf("actual must be a non-negative number")
+	}
+	for _, unit := range models.ActualUnits {
+		if actual.Unit == unit {
+			return nil
+		}
+	}
+	return fmt.Errorf("unit must be one of: %s", strings.Join(models.ActualUnits, ", "))
+}
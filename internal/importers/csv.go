@@ -0,0 +1,119 @@
+package importers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"poker-planning/internal/models"
+)
+
+// ErrInvalidFile is wrapped by the errors for files that cannot be read as
+// tickets, as opposed to a tracker that could not be reached.
+var ErrInvalidFile = errors.New("invalid import file")
+
+// CSV imports tickets from a CSV or TSV export of any tracker. The first row
+// names the columns: title is required, description and external_ref are
+// optional, and others are ignored.
+type CSV struct {
+	Reader   io.Reader
+	Comma    rune   // ',' or '\t'; 0 picks a tab if the header row has one
+	JiraSite string // for Jira exports, the site from JiraSite to link keys to
+}
+
+// Import reads one ticket per row, skipping rows without a title, up to
+// MaxTickets.
+func (c *CSV) Import(ctx context.Context) ([]models.Ticket, error) {
+	reader, columns, err := readCSVHeader(c.Reader, c.Comma, "title", "description", "external_ref")
+	if err != nil {
+		return nil, err
+	}
+	if columns["title"] < 0 {
+		return nil, fmt.Errorf("%w: the first row must name a title column", ErrInvalidFile)
+	}
+	field := columns.field
+
+	var tickets []models.Ticket
+	for len(tickets) < MaxTickets {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+
+		title := field(record, "title")
+		if strings.TrimSpace(title) == "" {
+			continue
+		}
+		key := strings.TrimSpace(field(record, "external_ref"))
+		link := ""
+		if key != "" && c.JiraSite != "" {
+			link = JiraIssueURL(c.JiraSite, key)
+		}
+		tickets = append(tickets, newTicket(title, field(record, "description"), key, link))
+	}
+
+	return tickets, nil
+}
+
+// csvColumns maps the column names a reader looks for to their index in the
+// header row, or -1 when the file has no such column.
+type csvColumns map[string]int
+
+// field returns a record's value in a column, or "" if it has none.
+func (c csvColumns) field(record []string, column string) string {
+	if i := c[column]; i >= 0 && i < len(record) {
+		return record[i]
+	}
+	return ""
+}
+
+// readCSVHeader reads the header row of a CSV or TSV file and finds the
+// named columns in it, case-insensitively. comma 0 picks a tab if the
+// header row has one.
+func readCSVHeader(r io.Reader, comma rune, names ...string) (*csv.Reader, csvColumns, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	// Spreadsheets often save with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = comma
+	if reader.Comma == 0 {
+		reader.Comma = ','
+		if header, _, _ := bytes.Cut(data, []byte("\n")); bytes.ContainsRune(header, '\t') {
+			reader.Comma = '\t'
+		}
+	}
+	// TSV exports rarely quote their fields
+	reader.LazyQuotes = reader.Comma == '\t'
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("%w: the file is empty", ErrInvalidFile)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	columns := make(csvColumns, len(names))
+	for _, name := range names {
+		columns[name] = -1
+	}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if index, ok := columns[name]; ok && index < 0 {
+			columns[name] = i
+		}
+	}
+	return reader, columns, nil
+}
@@ -0,0 +1,53 @@
+// Package importers brings tickets into a session from issue trackers and
+// files. Each source is an Importer; supporting another tracker means
+// writing one more, and any tracker can already be used through its CSV
+// export. ReadActuals reads what finished tickets took back from such
+// exports.
+package importers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"poker-planning/internal/models"
+)
+
+// MaxTickets caps how many tickets a single import reads, so a wrong board
+// or cycle cannot flood a session.
+const MaxTickets = 500
+
+// Importer reads the tickets to estimate from one source. The tickets it
+// returns only carry a title, description, external key and external URL;
+// the caller validates them and adds them to the session.
+type Importer interface {
+	Import(ctx context.Context) ([]models.Ticket, error)
+}
+
+// newTicket builds an imported ticket, leaving the key and URL unset when
+// the source has none.
+func newTicket(title, description, key, link string) models.Ticket {
+	ticket := models.Ticket{
+		Title:       strings.TrimSpace(title),
+		Description: strings.TrimSpace(description),
+	}
+	if key != "" {
+		ticket.ExternalKey = &key
+	}
+	if link != "" {
+		ticket.ExternalURL = &link
+	}
+	return ticket
+}
+
+// checkResponse turns a non-2xx response from a tracker into an error that
+// includes the start of its body.
+func checkResponse(tracker string, resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s returned %s: %s", tracker, resp.Status, strings.TrimSpace(string(message)))
+}
@@ -0,0 +1,34 @@
+package importers
+
+import (
+	"net/url"
+	"strings"
+)
+
+// JiraSite reads the address of a Jira site, such as
+// "https://example.atlassian.net/", into the form issue links are built
+// from: scheme, host and any context path, without a trailing slash. It
+// returns false unless the address is an http or https URL.
+func JiraSite(address string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(address))
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", false
+	}
+	return u.Scheme + "://" + strings.ToLower(u.Host) + strings.TrimRight(u.Path, "/"), true
+}
+
+// JiraSiteOfIssue finds the site an issue lives on from its REST link, the
+// "self" field of Jira webhooks, e.g.
+// "https://example.atlassian.net/rest/api/2/issue/10001".
+func JiraSiteOfIssue(self string) (string, bool) {
+	site, _, ok := strings.Cut(self, "/rest/api/")
+	if !ok {
+		return "", false
+	}
+	return JiraSite(site)
+}
+
+// JiraIssueURL links to an issue on a site returned by JiraSite.
+func JiraIssueURL(site, key string) string {
+	return site + "/browse/" + key
+}
@@ -0,0 +1,135 @@
+package importers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"poker-planning/internal/models"
+)
+
+const linearEndpoint = "https://api.linear.app/graphql"
+
+// linearPageSize is how many issues each GraphQL request asks for.
+const linearPageSize = 100
+
+// Linear imports the issues in one cycle of a Linear team.
+type Linear struct {
+	APIKey   string
+	Team     string // team key, e.g. "ENG"
+	Cycle    int    // cycle number; 0 imports the team's active cycle
+	Endpoint string // defaults to the Linear GraphQL API
+}
+
+const linearIssuesQuery = `query Issues($filter: IssueFilter, $first: Int, $after: String) {
+  issues(filter: $filter, first: $first, after: $after) {
+    nodes { identifier title description url }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+
+type linearIssuesResponse struct {
+	Data struct {
+		Issues struct {
+			Nodes []struct {
+				Identifier  string `json:"identifier"`
+				Title       string `json:"title"`
+				Description string `json:"description"`
+				URL         string `json:"url"`
+			} `json:"nodes"`
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+		} `json:"issues"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Import fetches the cycle's issues, following pages up to MaxTickets. The
+// issue identifier becomes the ticket's external key.
+func (l *Linear) Import(ctx context.Context) ([]models.Ticket, error) {
+	cycle := map[string]interface{}{"isActive": map[string]bool{"eq": true}}
+	if l.Cycle > 0 {
+		cycle = map[string]interface{}{"number": map[string]int{"eq": l.Cycle}}
+	}
+	filter := map[string]interface{}{
+		"team":  map[string]interface{}{"key": map[string]string{"eq": l.Team}},
+		"cycle": cycle,
+	}
+
+	var tickets []models.Ticket
+	var after *string
+	for len(tickets) < MaxTickets {
+		page, err := l.query(ctx, map[string]interface{}{
+			"filter": filter,
+			"first":  linearPageSize,
+			"after":  after,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, issue := range page.Data.Issues.Nodes {
+			if len(tickets) == MaxTickets {
+				break
+			}
+			tickets = append(tickets, newTicket(issue.Title, issue.Description, issue.Identifier, issue.URL))
+		}
+
+		if !page.Data.Issues.PageInfo.HasNextPage {
+			break
+		}
+		cursor := page.Data.Issues.PageInfo.EndCursor
+		after = &cursor
+	}
+
+	return tickets, nil
+}
+
+func (l *Linear) query(ctx context.Context, variables map[string]interface{}) (*linearIssuesResponse, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     linearIssuesQuery,
+		"variables": variables,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Linear query: %w", err)
+	}
+
+	endpoint := l.Endpoint
+	if endpoint == "" {
+		endpoint = linearEndpoint
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Linear request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Personal API keys are sent as is, without a Bearer prefix
+	req.Header.Set("Authorization", l.APIKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Linear: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse("Linear", resp); err != nil {
+		return nil, err
+	}
+
+	var page linearIssuesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode Linear response: %w", err)
+	}
+	if len(page.Errors) > 0 {
+		return nil, fmt.Errorf("Linear returned an error: %s", page.Errors[0].Message)
+	}
+
+	return &page, nil
+}
@@ -0,0 +1,101 @@
+package importers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"poker-planning/internal/models"
+)
+
+const trelloEndpoint = "https://api.trello.com/1"
+
+// Trello imports the open cards in one list of a Trello board.
+type Trello struct {
+	APIKey   string
+	Token    string
+	Board    string // board ID or the short link from its URL
+	List     string // list name, matched without regard to case, or list ID
+	Endpoint string // defaults to the Trello REST API
+}
+
+type trelloList struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type trelloCard struct {
+	Name      string `json:"name"`
+	Desc      string `json:"desc"`
+	ShortLink string `json:"shortLink"`
+	ShortURL  string `json:"shortUrl"`
+}
+
+// Import fetches the list's cards, up to MaxTickets. The card's short link
+// becomes the ticket's external key.
+func (t *Trello) Import(ctx context.Context) ([]models.Ticket, error) {
+	var lists []trelloList
+	if err := t.get(ctx, "/boards/"+url.PathEscape(t.Board)+"/lists", url.Values{"fields": {"name"}}, &lists); err != nil {
+		return nil, err
+	}
+
+	listID := ""
+	for _, list := range lists {
+		if list.ID == t.List || strings.EqualFold(list.Name, t.List) {
+			listID = list.ID
+			break
+		}
+	}
+	if listID == "" {
+		return nil, fmt.Errorf("Trello board has no list named %q", t.List)
+	}
+
+	var cards []trelloCard
+	if err := t.get(ctx, "/lists/"+url.PathEscape(listID)+"/cards", url.Values{"fields": {"name,desc,shortLink,shortUrl"}}, &cards); err != nil {
+		return nil, err
+	}
+
+	tickets := make([]models.Ticket, 0, len(cards))
+	for _, card := range cards {
+		if len(tickets) == MaxTickets {
+			break
+		}
+		tickets = append(tickets, newTicket(card.Name, card.Desc, card.ShortLink, card.ShortURL))
+	}
+
+	return tickets, nil
+}
+
+func (t *Trello) get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	endpoint := t.Endpoint
+	if endpoint == "" {
+		endpoint = trelloEndpoint
+	}
+
+	query.Set("key", t.APIKey)
+	query.Set("token", t.Token)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create Trello request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query Trello: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse("Trello", resp); err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode Trello response: %w", err)
+	}
+
+	return nil
+}
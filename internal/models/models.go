@@ -1,27 +1,299 @@
 package models
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/description"
 )
 
 type User struct {
-	ID       string    `json:"id"`
-	Username string    `json:"username"`
-	CreatedAt time.Time `json:"created_at"`
-	LastSeen  time.Time `json:"last_seen"`
+	ID            string          `json:"id"`
+	Username      string          `json:"username"`
+	CreatedAt     time.Time       `json:"created_at"`
+	LastSeen      time.Time       `json:"last_seen"`
+	IsBot         bool            `json:"is_bot"`                   // server-driven participant, set on session participants
+	Weight        float64         `json:"weight,omitempty"`         // how much their votes count, set on session participants
+	IsStakeholder bool            `json:"is_stakeholder,omitempty"` // interested stakeholder rather than committed team member, set on session participants
+	Preferences   UserPreferences `json:"-"`                        // private to the user, never broadcast
+}
+
+// UserPreferences holds per-user settings. Empty strings mean "no
+// preference" and fall back to the app defaults.
+type UserPreferences struct {
+	PreferredUnit       string `json:"preferred_unit"`
+	AutoReady           bool   `json:"auto_ready"`
+	ReducedMotion       bool   `json:"reduced_motion"`
+	Timezone            string `json:"timezone"`
+	NotifyVotingStarted bool   `json:"notify_voting_started"`
+	NotifyVotesRevealed bool   `json:"notify_votes_revealed"`
+	NotificationLevel   string `json:"notification_level"` // loudest hint wanted: silent, subtle or chime (default)
+	DoNotDisturb        bool   `json:"do_not_disturb"`     // no nudges, emoji reactions or push notifications
 }
 
 type Session struct {
-	ID              string     `json:"id"`
-	Name            string     `json:"name"`
-	OwnerID         string     `json:"owner_id"`
-	CurrentTicketID *int       `json:"current_ticket_id"`
-	IsVotingActive  bool       `json:"is_voting_active"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
-	Participants    []User     `json:"participants,omitempty"`
-	Tickets         []Ticket   `json:"tickets,omitempty"`
-	CurrentTicket   *Ticket    `json:"current_ticket,omitempty"`
+	ID                    string    `json:"id"`
+	Name                  string    `json:"name"`
+	OwnerID               string    `json:"owner_id"`
+	DriverID              *string   `json:"driver_id"` // participant the owner handed voting control to; nil while the owner drives
+	CurrentTicketID       *int      `json:"current_ticket_id"`
+	DiscussingTicketID    *int      `json:"discussing_ticket_id"` // highlighted for discussion, apart from the voting ticket
+	IsVotingActive        bool      `json:"is_voting_active"`
+	RoundingStrategy      string    `json:"rounding_strategy"`
+	SuggestionBasis       string    `json:"suggestion_basis"` // median, mean or a percentile like p90
+	EstimationUnit        string    `json:"estimation_unit"`
+	ProjectID             *string   `json:"project_id"`
+	OrganizationID        *string    `json:"organization_id"` // nil for sessions open to anyone with the link
+	TeamID                *string    `json:"team_id"`
+	PreviousSessionID     *string    `json:"previous_session_id"`
+	MaxParticipants       *int       `json:"max_participants"` // nil uses the deployment default
+	MaxTickets            *int       `json:"max_tickets"`
+	IsPublic              bool       `json:"is_public"`   // listed in the lobby
+	AutoReveal            bool       `json:"auto_reveal"` // end voting once everyone has voted
+	AutoRevealIgnoresAway bool       `json:"auto_reveal_ignores_away"`
+	VotingTimeLimit       *int       `json:"voting_time_limit"` // seconds; nil lets voting run until it is ended
+	VotingStartedAt       *time.Time `json:"voting_started_at"`
+	VoteChangeWindow      *int       `json:"vote_change_window"` // seconds votes may change after reveal; nil always, 0 never
+	DelphiMaxRounds       *int       `json:"delphi_max_rounds"`  // rounds a ticket gets in Delphi mode; nil outside it
+	DelphiAgreement       int        `json:"delphi_agreement"`   // percent of votes on one card that ends Delphi rounds early
+	CustomSpecialCards    []deck.SpecialCard `json:"custom_special_cards"` // nil uses the deployment's special cards
+	CustomCardStyles      map[string]deck.CardStyle `json:"custom_card_styles"` // nil uses the deployment's card styles
+	RequiredFields        []TicketField `json:"required_fields"` // ticket fields that must be filled in before voting
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+	Participants          []User     `json:"participants,omitempty"`
+	Tickets               []Ticket   `json:"tickets,omitempty"`
+	CurrentTicket         *Ticket    `json:"current_ticket,omitempty"`
+	DiscussingTicket      *Ticket    `json:"discussing_ticket,omitempty"`
+}
+
+// IsDriver reports whether the owner handed voting control to the user.
+func (s *Session) IsDriver(userID string) bool {
+	return s.DriverID != nil && *s.DriverID == userID
+}
+
+// CanDrive reports whether the user runs the voting: starts and ends it and
+// picks the ticket. That is the owner, or the participant they handed
+// control to.
+func (s *Session) CanDrive(userID string) bool {
+	return s.OwnerID == userID || s.IsDriver(userID)
+}
+
+// SpecialCards are the special cards the session votes with: its own if the
+// owner chose them, otherwise the deployment's.
+func (s *Session) SpecialCards() []deck.SpecialCard {
+	if s.CustomSpecialCards != nil {
+		return s.CustomSpecialCards
+	}
+	return deck.SpecialCards
+}
+
+// SpecialCardLabel is what a special card of the session means, or empty
+// for other cards.
+func (s *Session) SpecialCardLabel(card string) string {
+	if card == deck.Abstain {
+		return "Sit this ticket out"
+	}
+	for _, special := range s.SpecialCards() {
+		if special.Value == card {
+			return special.Label
+		}
+	}
+	return ""
+}
+
+// CardStyles are how the session's cards are shown, by card: its own if the
+// owner chose them, otherwise the deployment's.
+func (s *Session) CardStyles() map[string]deck.CardStyle {
+	if s.CustomCardStyles != nil {
+		return s.CustomCardStyles
+	}
+	return deck.CardStyles
+}
+
+// DeckStyles are the styles of the session's deck, in deck order, with the
+// zero style for cards that have none.
+func (s *Session) DeckStyles() []deck.CardStyle {
+	cards := s.Deck()
+	styles := s.CardStyles()
+	deckStyles := make([]deck.CardStyle, len(cards))
+	for i, card := range cards {
+		deckStyles[i] = styles[card]
+	}
+	return deckStyles
+}
+
+// Deck is the cards the session votes with, numeric ones first and the
+// abstain card last.
+func (s *Session) Deck() deck.Deck {
+	return append(deck.WithSpecialCards(s.EstimationUnit, s.SpecialCards()), deck.Abstain)
+}
+
+// DefaultDelphiAgreement is the share of votes, in percent, that must land
+// on one card for a Delphi session to stop re-voting.
+const DefaultDelphiAgreement = 75
+
+// IsDelphi reports whether the session runs in Delphi mode: rounds reveal
+// only their aggregate, without who voted what, and voting starts again by
+// itself until the votes converge or the ticket has had DelphiMaxRounds.
+func (s *Session) IsDelphi() bool {
+	return s.DelphiMaxRounds != nil
+}
+
+// VotingDeadline is when voting ends by itself, or nil if voting is not
+// active or has no time limit.
+func (s *Session) VotingDeadline() *time.Time {
+	if !s.IsVotingActive || s.VotingTimeLimit == nil || s.VotingStartedAt == nil {
+		return nil
+	}
+	deadline := s.VotingStartedAt.Add(time.Duration(*s.VotingTimeLimit) * time.Second)
+	return &deadline
+}
+
+// VoteChangeDeadline is until when votes on the current ticket may still be
+// changed once revealed, or nil if there is no limit. Tickets revealed before
+// the session had a window are already locked.
+func (s *Session) VoteChangeDeadline() *time.Time {
+	if s.VoteChangeWindow == nil || s.IsVotingActive || s.CurrentTicket == nil {
+		return nil
+	}
+
+	var deadline time.Time
+	if s.CurrentTicket.RevealedAt != nil {
+		deadline = s.CurrentTicket.RevealedAt.Add(time.Duration(*s.VoteChangeWindow) * time.Second)
+	}
+	return &deadline
+}
+
+// VotesLocked reports whether the revealed votes on the current ticket can
+// no longer be changed.
+func (s *Session) VotesLocked() bool {
+	deadline := s.VoteChangeDeadline()
+	return deadline != nil && !time.Now().Before(*deadline)
+}
+
+// RequiresField reports whether the session's tickets must have field
+// filled in before they can be voted on.
+func (s *Session) RequiresField(field TicketField) bool {
+	for _, required := range s.RequiredFields {
+		if required == field {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingFields returns the fields the session requires that the ticket
+// leaves empty, so it cannot be voted on yet.
+func (s *Session) MissingFields(ticket Ticket) []TicketField {
+	var missing []TicketField
+	for _, field := range s.RequiredFields {
+		if !ticket.HasField(field) {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+type Project struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	OwnerID        string    `json:"owner_id"`
+	OrganizationID *string   `json:"organization_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	Sessions       []Session `json:"sessions,omitempty"`
+}
+
+// Organization groups users on a shared instance. Sessions and projects
+// created in an organization are only visible to its members.
+type Organization struct {
+	ID         string               `json:"id"`
+	Name       string               `json:"name"`
+	InviteCode string               `json:"-"` // joining link, for admins only
+	CreatedAt  time.Time            `json:"created_at"`
+	Members    []OrganizationMember `json:"members,omitempty"`
+}
+
+// OrganizationRole is what a member may do in an organization.
+type OrganizationRole string
+
+const (
+	RoleAdmin  OrganizationRole = "admin" // manages members and sees every session
+	RoleMember OrganizationRole = "member"
+)
+
+func ParseOrganizationRole(value string) (OrganizationRole, bool) {
+	switch OrganizationRole(value) {
+	case RoleAdmin, RoleMember:
+		return OrganizationRole(value), true
+	}
+	return "", false
+}
+
+type OrganizationMember struct {
+	User
+	Role     OrganizationRole `json:"role"`
+	JoinedAt time.Time        `json:"joined_at"`
+}
+
+// Team is a standing group within an organization that plans together. Its
+// defaults and members are applied to every team session it starts.
+type Team struct {
+	ID              string    `json:"id"`
+	OrganizationID  string    `json:"organization_id"`
+	Name            string    `json:"name"`
+	EstimationUnit  string    `json:"estimation_unit"`
+	VotingTimeLimit *int      `json:"voting_time_limit"` // seconds; nil for no limit
+	DaysPerPoint    *float64  `json:"days_per_point"`    // ideal days a point stands for, for members who vote in days; nil for none
+	CreatedAt       time.Time `json:"created_at"`
+	Members         []User    `json:"members,omitempty"`
+}
+
+// ReferenceStory is a story a team estimated before, kept in the team's
+// library so facilitators can pin it next to tickets of a similar size.
+// Points is a card of the team's deck; TicketID is the ticket it was saved
+// from, if any.
+type ReferenceStory struct {
+	ID        int       `json:"id"`
+	TeamID    string    `json:"team_id"`
+	Title     string    `json:"title"`
+	Points    string    `json:"points"`
+	TicketID  *int      `json:"ticket_id,omitempty"`
+	CreatedBy *string   `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DescriptionTemplate is one of a team's ticket description templates,
+// e.g. "Context / Acceptance Criteria / Out of scope". It pre-fills new
+// tickets of the team's sessions, which must fill in its required sections.
+type DescriptionTemplate struct {
+	ID        int                   `json:"id"`
+	TeamID    string                `json:"team_id"`
+	Name      string                `json:"name"`
+	Sections  []description.Section `json:"sections"`
+	CreatedBy *string               `json:"created_by"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
+// Prefill is the description a ticket written from the template starts with.
+func (t DescriptionTemplate) Prefill() string {
+	return description.Prefill(t.Sections)
+}
+
+// ActualUnits are what a ticket's actual is measured in: the effort it
+// took in hours, or its cycle time in days.
+var ActualUnits = []string{"hours", "days"}
+
+// TicketActual is what a ticket took once it was done, imported from the
+// tracker to compare with its estimate. It names the ticket by ID or by
+// issue key; an issue key stands for every ticket with that key.
+type TicketActual struct {
+	TicketID    int     `json:"ticket_id,omitempty"`
+	ExternalKey string  `json:"external_ref,omitempty"`
+	Actual      float64 `json:"actual"`
+	Unit        string  `json:"unit"`
 }
 
 type Ticket struct {
@@ -29,21 +301,161 @@ type Ticket struct {
 	SessionID     string  `json:"session_id"`
 	Title         string  `json:"title"`
 	Description   string  `json:"description"`
-	FinalEstimate *int    `json:"final_estimate"`
+	ExternalKey   *string `json:"external_key,omitempty"` // issue key in the tracker, e.g. PROJ-123
+	ExternalURL   *string `json:"external_url,omitempty"` // link back to the issue in the tracker
+	ExternalClosedAt *time.Time `json:"external_closed_at,omitempty"` // when the issue was closed in the tracker
+	FinalEstimate *string `json:"final_estimate"`
+	EstimateLow   *string `json:"estimate_low,omitempty"`  // suggested range around the final estimate,
+	EstimateHigh  *string `json:"estimate_high,omitempty"` // the middle half of the votes
+	Rationale     string  `json:"rationale,omitempty"`   // one line on why the final estimate was chosen
+	Assumptions   string  `json:"assumptions,omitempty"` // key assumptions the final estimate rests on
 	Position      int     `json:"position"`
+	ParentTicketID *int   `json:"parent_ticket_id,omitempty"`
+	IsSplit       bool    `json:"is_split"`
+	NeedsSplit    bool    `json:"needs_split"`    // a split card won the last vote, so it is too big to estimate
+	IsCalibration bool    `json:"is_calibration"` // a known reference story the team estimates first; left out of stats
+	PrevoteOpen   bool    `json:"prevote_open"`   // open for silent pre-votes ahead of the live session
+	BreakoutVoters []string `json:"breakout_voters,omitempty"` // the only participants who may vote on it; empty for everyone
 	CreatedAt     time.Time `json:"created_at"`
+	RevealedAt    *time.Time `json:"revealed_at"` // when voting on it last ended
 	Votes         []Vote  `json:"votes,omitempty"`
 }
 
+// EstimateRange words the suggested range around the final estimate, e.g.
+// "3–8 points", or is empty if the ticket has none.
+func (t Ticket) EstimateRange(unit string) string {
+	if t.EstimateLow == nil || t.EstimateHigh == nil {
+		return ""
+	}
+	if *t.EstimateLow == *t.EstimateHigh {
+		return deck.FormatCard(*t.EstimateLow, unit)
+	}
+	return *t.EstimateLow + "–" + deck.FormatCard(*t.EstimateHigh, unit)
+}
+
+// CanVote reports whether userID may vote on the ticket, which everyone
+// may unless it is being voted on by a breakout group.
+func (t Ticket) CanVote(userID string) bool {
+	if len(t.BreakoutVoters) == 0 {
+		return true
+	}
+	for _, voter := range t.BreakoutVoters {
+		if voter == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// TicketField is a ticket field a session can require to be filled in
+// before the ticket is voted on.
+type TicketField string
+
+const (
+	TicketDescription TicketField = "description"
+	TicketExternalURL TicketField = "external_url"
+	TicketExternalKey TicketField = "external_key"
+)
+
+// TicketFields lists the fields in the order the UI offers them.
+var TicketFields = []TicketField{TicketDescription, TicketExternalURL, TicketExternalKey}
+
+// Label is the field's name for people.
+func (f TicketField) Label() string {
+	switch f {
+	case TicketDescription:
+		return "Description"
+	case TicketExternalURL:
+		return "External link"
+	case TicketExternalKey:
+		return "Issue key"
+	}
+	return string(f)
+}
+
+func ParseTicketField(value string) (TicketField, bool) {
+	for _, field := range TicketFields {
+		if string(field) == value {
+			return field, true
+		}
+	}
+	return "", false
+}
+
+// HasField reports whether the ticket has field filled in.
+func (t Ticket) HasField(field TicketField) bool {
+	switch field {
+	case TicketDescription:
+		return strings.TrimSpace(t.Description) != ""
+	case TicketExternalURL:
+		return t.ExternalURL != nil && strings.TrimSpace(*t.ExternalURL) != ""
+	case TicketExternalKey:
+		return t.ExternalKey != nil && strings.TrimSpace(*t.ExternalKey) != ""
+	}
+	return true
+}
+
 type Vote struct {
 	ID        int       `json:"id"`
 	TicketID  int       `json:"ticket_id"`
 	UserID    string    `json:"user_id"`
 	VoteValue string    `json:"vote_value"`
+	Weight    float64   `json:"weight"` // the voter's participant weight, 1 unless the owner changed it
+	Advisory  bool      `json:"advisory,omitempty"` // cast by a stakeholder: shown apart and left out of the statistics
 	CreatedAt time.Time `json:"created_at"`
 	User      *User     `json:"user,omitempty"`
 }
 
+// VoteRound is one round of votes on a ticket. Earlier rounds were archived
+// when the ticket was voted on again.
+type VoteRound struct {
+	Round int    `json:"round"`
+	Votes []Vote `json:"votes"`
+}
+
+// TicketEstimate is how a ticket was estimated in an earlier session.
+type TicketEstimate struct {
+	TicketID       int        `json:"ticket_id"`
+	SessionID      string     `json:"session_id"`
+	SessionName    string     `json:"session_name"`
+	EstimationUnit string     `json:"estimation_unit"`
+	FinalEstimate  *string    `json:"final_estimate"`
+	Rounds         int        `json:"rounds"` // voting rounds, including the last one
+	CreatedAt      time.Time  `json:"created_at"`
+	RevealedAt     *time.Time `json:"revealed_at"`
+}
+
+// BotStrategy decides what a bot votes.
+type BotStrategy string
+
+const (
+	BotRandom      BotStrategy = "random"       // any numeric card
+	BotMimicMedian BotStrategy = "mimic-median" // the suggested estimate of the votes cast so far
+	BotFixed       BotStrategy = "fixed"        // always the same card
+)
+
+// BotStrategies lists the strategies in the order the UI offers them.
+var BotStrategies = []BotStrategy{BotRandom, BotMimicMedian, BotFixed}
+
+func ParseBotStrategy(value string) (BotStrategy, bool) {
+	for _, strategy := range BotStrategies {
+		if string(strategy) == value {
+			return strategy, true
+		}
+	}
+	return "", false
+}
+
+// Bot is a session participant whose votes the server casts, Delay after
+// voting starts.
+type Bot struct {
+	User
+	SessionID  string        `json:"session_id"`
+	Strategy   BotStrategy   `json:"strategy"`
+	FixedValue string        `json:"fixed_value,omitempty"`
+	Delay      time.Duration `json:"delay"`
+}
+
 type Participant struct {
 	SessionID string    `json:"session_id"`
 	UserID    string    `json:"user_id"`
@@ -57,9 +469,260 @@ type RecentEmoji struct {
 	UsedAt time.Time `json:"used_at"`
 }
 
+// LastVote is what a participant last voted in a session, for voting "same
+// as last time", with the session's cards for voting by number keys.
+type LastVote struct {
+	Vote  *string  `json:"last_vote"`
+	Cards []string `json:"cards"`
+}
+
+// AgendaKind is what an agenda item is for.
+type AgendaKind string
+
+const (
+	AgendaIntro   AgendaKind = "intro"
+	AgendaWarmUp  AgendaKind = "warm-up" // a ticket to calibrate the team's estimates on
+	AgendaTickets AgendaKind = "tickets" // a batch of backlog tickets
+	AgendaBreak   AgendaKind = "break"
+	AgendaRecap   AgendaKind = "recap"
+)
+
+// AgendaKinds lists the kinds in the order the UI offers them.
+var AgendaKinds = []AgendaKind{AgendaIntro, AgendaWarmUp, AgendaTickets, AgendaBreak, AgendaRecap}
+
+// Label is the kind's name for people, also the title of an item left
+// untitled.
+func (k AgendaKind) Label() string {
+	switch k {
+	case AgendaIntro:
+		return "Intro"
+	case AgendaWarmUp:
+		return "Warm-up"
+	case AgendaTickets:
+		return "Tickets"
+	case AgendaBreak:
+		return "Break"
+	case AgendaRecap:
+		return "Recap"
+	}
+	return string(k)
+}
+
+func ParseAgendaKind(value string) (AgendaKind, bool) {
+	for _, kind := range AgendaKinds {
+		if string(kind) == value {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// ParkingLotKind is what a parking lot item raises.
+type ParkingLotKind string
+
+const (
+	ParkingLotQuestion ParkingLotKind = "question"
+	ParkingLotRisk     ParkingLotKind = "risk"
+)
+
+// ParkingLotKinds lists the kinds in the order the UI offers them.
+var ParkingLotKinds = []ParkingLotKind{ParkingLotQuestion, ParkingLotRisk}
+
+// Label is the kind's name for people.
+func (k ParkingLotKind) Label() string {
+	switch k {
+	case ParkingLotQuestion:
+		return "Question"
+	case ParkingLotRisk:
+		return "Risk"
+	}
+	return string(k)
+}
+
+func ParseParkingLotKind(value string) (ParkingLotKind, bool) {
+	for _, kind := range ParkingLotKinds {
+		if string(kind) == value {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// ParkingLotItem is a question or risk deferred during a session so it can
+// be followed up afterwards, optionally about one of its tickets.
+type ParkingLotItem struct {
+	ID          int            `json:"id"`
+	SessionID   string         `json:"session_id"`
+	TicketID    *int           `json:"ticket_id"`
+	TicketTitle string         `json:"ticket_title,omitempty"`
+	UserID      string         `json:"user_id"`
+	Username    string         `json:"username"`
+	Kind        ParkingLotKind `json:"kind"`
+	Text        string         `json:"text"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// ActionItem is a follow-up task the owner records while reviewing a
+// session, optionally assigned to one of its participants.
+type ActionItem struct {
+	ID           int       `json:"id"`
+	SessionID    string    `json:"session_id"`
+	AssigneeID   *string   `json:"assignee_id"`
+	AssigneeName string    `json:"assignee_name,omitempty"`
+	Text         string    `json:"text"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// SessionSnapshot is a restore point of a session: its tickets, their votes
+// and where voting was, which the owner can roll the session back to.
+type SessionSnapshot struct {
+	ID            int       `json:"id"`
+	SessionID     string    `json:"session_id"`
+	Name          string    `json:"name"`
+	Automatic     bool      `json:"automatic"` // taken before a bulk change or a restore, not by the owner
+	TicketCount   int       `json:"ticket_count"`
+	VoteCount     int       `json:"vote_count"`
+	CreatedBy     *string   `json:"created_by"`
+	CreatedByName string    `json:"created_by_name,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// FacilitatorNotes are the owner's private notes on a session and its
+// tickets, for prep remarks and context the participants should not see.
+type FacilitatorNotes struct {
+	Session string         `json:"session"`
+	Tickets map[int]string `json:"tickets"` // by ticket ID; tickets without notes are left out
+}
+
+// FeedbackSummary aggregates the participants' 1-5 ratings of a session
+// without saying who gave which.
+type FeedbackSummary struct {
+	SessionID   string    `json:"session_id"`
+	SessionName string    `json:"session_name"`
+	CreatedAt   time.Time `json:"created_at"` // when the session was created
+	Responses   int       `json:"responses"`
+	Average     float64   `json:"average"`
+	Ratings     [5]int    `json:"ratings"`            // how many gave each rating, 1 first
+	Comments    []string  `json:"comments,omitempty"` // sorted, so their order gives nobody away
+}
+
+// FeedbackRating is how many responses gave one rating.
+type FeedbackRating struct {
+	Rating  int
+	Count   int
+	Percent float64 // of all responses
+}
+
+// AveragePercent is the average rating as a percentage of the best, for
+// drawing it as a bar.
+func (f FeedbackSummary) AveragePercent() float64 {
+	return f.Average * 100 / float64(len(f.Ratings))
+}
+
+// Distribution lists how many responses gave each rating, highest first.
+func (f FeedbackSummary) Distribution() []FeedbackRating {
+	distribution := make([]FeedbackRating, 0, len(f.Ratings))
+	for rating := len(f.Ratings); rating >= 1; rating-- {
+		entry := FeedbackRating{Rating: rating, Count: f.Ratings[rating-1]}
+		if f.Responses > 0 {
+			entry.Percent = float64(entry.Count) * 100 / float64(f.Responses)
+		}
+		distribution = append(distribution, entry)
+	}
+	return distribution
+}
+
+// AgendaItem is a step of the facilitator's plan for a session. It is
+// current from StartedAt until EndedAt, when the owner moves on.
+type AgendaItem struct {
+	ID             int        `json:"id"`
+	SessionID      string     `json:"session_id"`
+	Position       int        `json:"position"`
+	Kind           AgendaKind `json:"kind"`
+	Title          string     `json:"title"`
+	PlannedMinutes int        `json:"planned_minutes"`
+	StartedAt      *time.Time `json:"started_at"`
+	EndedAt        *time.Time `json:"ended_at"`
+}
+
+// IsCurrent reports whether the item has started and not yet ended.
+func (a AgendaItem) IsCurrent() bool {
+	return a.StartedAt != nil && a.EndedAt == nil
+}
+
+// Elapsed is how long the item took, or has taken so far if it is current.
+func (a AgendaItem) Elapsed() time.Duration {
+	if a.StartedAt == nil {
+		return 0
+	}
+	if a.EndedAt == nil {
+		return time.Since(*a.StartedAt)
+	}
+	return a.EndedAt.Sub(*a.StartedAt)
+}
+
+// Overrun reports whether the item took longer than planned.
+func (a AgendaItem) Overrun() bool {
+	return a.Elapsed() > time.Duration(a.PlannedMinutes)*time.Minute
+}
+
+// SessionEvent is an entry in a session's event log: a vote, a reveal or a
+// change to its tickets. TicketID and UserID are empty when the event is not
+// about a ticket or was not caused by a user.
+type SessionEvent struct {
+	ID        int64           `json:"id"`
+	SessionID string          `json:"session_id"`
+	Type      string          `json:"type"`
+	TicketID  *int            `json:"ticket_id"`
+	UserID    *string         `json:"user_id"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// HookSubscription asks for an event type to be POSTed to a URL as it
+// happens, e.g. by Zapier or Make. SessionID limits it to one session.
+type HookSubscription struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	TargetURL string    `json:"target_url"`
+	SessionID *string   `json:"session_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PushSubscription is a browser's Web Push endpoint for a user, with the
+// keys to encrypt notifications for it.
+type PushSubscription struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Endpoint  string    `json:"endpoint"`
+	P256dh    string    `json:"p256dh"`
+	Auth      string    `json:"auth"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PushNotification is the payload the service worker shows. URL is opened
+// when it is clicked, and a newer notification with the same Tag replaces
+// an older one.
+type PushNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url"`
+	Tag   string `json:"tag,omitempty"`
+}
+
 type SSEMessage struct {
-	Type string      `json:"type"`
-	Data interface{} `json:"data"`
+	Type   string      `json:"type"`
+	Data   interface{} `json:"data"`
+	Notify string      `json:"notify,omitempty"` // silent, subtle or chime, for the receiving user
+
+	// Coalesced is how many broadcasts of the same type this one stands
+	// for, when a burst of them was debounced
+	Coalesced int `json:"coalesced,omitempty"`
+
+	// StateHash fingerprints the session after this message, for clients
+	// that apply it as a delta to check they are still in sync
+	StateHash string `json:"state_hash,omitempty"`
 }
 
 type EmojiReaction struct {
@@ -69,17 +732,3 @@ type EmojiReaction struct {
 	FromUser *User `json:"from_user,omitempty"`
 	ToUser   *User `json:"to_user,omitempty"`
 }
-
-const (
-	VotingCards = "0,1,2,3,5,8,13,21,34,☕,?"
-)
-
-var FibonacciCards = []string{"0", "1", "2", "3", "5", "8", "13", "21", "34", "55", "89", "144"}
-var SpecialCards = []string{"☕", "?"}
-
-func AllVotingCards() []string {
-	cards := make([]string, len(FibonacciCards)+len(SpecialCards))
-	copy(cards, FibonacciCards)
-	copy(cards[len(FibonacciCards):], SpecialCards)
-	return cards
-}
\ No newline at end of file
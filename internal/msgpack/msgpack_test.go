@@ -0,0 +1,44 @@
+package msgpack
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  []byte
+	}{
+		{"nil", nil, []byte{0xc0}},
+		{"true", true, []byte{0xc3}},
+		{"fixint", 5, []byte{0x05}},
+		{"negative fixint", -3, []byte{0xfd}},
+		{"uint8", 200, []byte{0xcc, 0xc8}},
+		{"uint16", 1000, []byte{0xcd, 0x03, 0xe8}},
+		{"int8", -100, []byte{0xd0, 0x9c}},
+		{"float", 2.5, []byte{0xcb, 0x40, 0x04, 0, 0, 0, 0, 0, 0}},
+		{"fixstr", "☕", []byte{0xa3, 0xe2, 0x98, 0x95}},
+		{"str8", strings.Repeat("a", 40), append([]byte{0xd9, 40}, strings.Repeat("a", 40)...)},
+		{"array", []string{"1", "2"}, []byte{0x92, 0xa1, '1', 0xa1, '2'}},
+		{"sorted map", map[string]int{"b": 2, "a": 1}, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02}},
+		{"json tags", struct {
+			Type  string `json:"type"`
+			Empty string `json:"empty,omitempty"`
+		}{Type: "x"}, []byte{0x81, 0xa4, 't', 'y', 'p', 'e', 0xa1, 'x'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Marshal(%v) = % x, want % x", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: proto/poker/v1/poker.proto
+
+// The gRPC API for internal integrations. It mirrors the token-guarded HTTP
+// API: regenerate internal/pokerpb after changing this file with
+//
+//   protoc --go_out=. --go_opt=module=poker-planning \
+//          --go-grpc_out=. --go-grpc_opt=module=poker-planning \
+//          proto/poker/v1/poker.proto
+
+package pokerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	PlanningPoker_GetSession_FullMethodName   = "/poker.v1.PlanningPoker/GetSession"
+	PlanningPoker_ListTickets_FullMethodName  = "/poker.v1.PlanningPoker/ListTickets"
+	PlanningPoker_CreateTicket_FullMethodName = "/poker.v1.PlanningPoker/CreateTicket"
+	PlanningPoker_ListVotes_FullMethodName    = "/poker.v1.PlanningPoker/ListVotes"
+	PlanningPoker_StreamEvents_FullMethodName = "/poker.v1.PlanningPoker/StreamEvents"
+)
+
+// PlanningPokerClient is the client API for PlanningPoker service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PlanningPokerClient interface {
+	// GetSession returns a session, its participants and its current ticket.
+	GetSession(ctx context.Context, in *GetSessionRequest, opts ...grpc.CallOption) (*Session, error)
+	// ListTickets returns a session's tickets in backlog order.
+	ListTickets(ctx context.Context, in *ListTicketsRequest, opts ...grpc.CallOption) (*ListTicketsResponse, error)
+	// CreateTicket adds a ticket to the end of a session's backlog.
+	CreateTicket(ctx context.Context, in *CreateTicketRequest, opts ...grpc.CallOption) (*Ticket, error)
+	// ListVotes returns the votes on a ticket. Votes on a ticket that is
+	// being voted on stay hidden until they are revealed.
+	ListVotes(ctx context.Context, in *ListVotesRequest, opts ...grpc.CallOption) (*ListVotesResponse, error)
+	// StreamEvents sends a session's event log, oldest first, and then new
+	// events as they happen until the client cancels.
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (PlanningPoker_StreamEventsClient, error)
+}
+
+type planningPokerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPlanningPokerClient(cc grpc.ClientConnInterface) PlanningPokerClient {
+	return &planningPokerClient{cc}
+}
+
+func (c *planningPokerClient) GetSession(ctx context.Context, in *GetSessionRequest, opts ...grpc.CallOption) (*Session, error) {
+	out := new(Session)
+	err := c.cc.Invoke(ctx, PlanningPoker_GetSession_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planningPokerClient) ListTickets(ctx context.Context, in *ListTicketsRequest, opts ...grpc.CallOption) (*ListTicketsResponse, error) {
+	out := new(ListTicketsResponse)
+	err := c.cc.Invoke(ctx, PlanningPoker_ListTickets_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planningPokerClient) CreateTicket(ctx context.Context, in *CreateTicketRequest, opts ...grpc.CallOption) (*Ticket, error) {
+	out := new(Ticket)
+	err := c.cc.Invoke(ctx, PlanningPoker_CreateTicket_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planningPokerClient) ListVotes(ctx context.Context, in *ListVotesRequest, opts ...grpc.CallOption) (*ListVotesResponse, error) {
+	out := new(ListVotesResponse)
+	err := c.cc.Invoke(ctx, PlanningPoker_ListVotes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *planningPokerClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (PlanningPoker_StreamEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PlanningPoker_ServiceDesc.Streams[0], PlanningPoker_StreamEvents_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &planningPokerStreamEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PlanningPoker_StreamEventsClient interface {
+	Recv() (*SessionEvent, error)
+	grpc.ClientStream
+}
+
+type planningPokerStreamEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *planningPokerStreamEventsClient) Recv() (*SessionEvent, error) {
+	m := new(SessionEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PlanningPokerServer is the server API for PlanningPoker service.
+// All implementations must embed UnimplementedPlanningPokerServer
+// for forward compatibility
+type PlanningPokerServer interface {
+	// GetSession returns a session, its participants and its current ticket.
+	GetSession(context.Context, *GetSessionRequest) (*Session, error)
+	// ListTickets returns a session's tickets in backlog order.
+	ListTickets(context.Context, *ListTicketsRequest) (*ListTicketsResponse, error)
+	// CreateTicket adds a ticket to the end of a session's backlog.
+	CreateTicket(context.Context, *CreateTicketRequest) (*Ticket, error)
+	// ListVotes returns the votes on a ticket. Votes on a ticket that is
+	// being voted on stay hidden until they are revealed.
+	ListVotes(context.Context, *ListVotesRequest) (*ListVotesResponse, error)
+	// StreamEvents sends a session's event log, oldest first, and then new
+	// events as they happen until the client cancels.
+	StreamEvents(*StreamEventsRequest, PlanningPoker_StreamEventsServer) error
+	mustEmbedUnimplementedPlanningPokerServer()
+}
+
+// UnimplementedPlanningPokerServer must be embedded to have forward compatible implementations.
+type UnimplementedPlanningPokerServer struct {
+}
+
+func (UnimplementedPlanningPokerServer) GetSession(context.Context, *GetSessionRequest) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSession not implemented")
+}
+func (UnimplementedPlanningPokerServer) ListTickets(context.Context, *ListTicketsRequest) (*ListTicketsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTickets not implemented")
+}
+func (UnimplementedPlanningPokerServer) CreateTicket(context.Context, *CreateTicketRequest) (*Ticket, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTicket not implemented")
+}
+func (UnimplementedPlanningPokerServer) ListVotes(context.Context, *ListVotesRequest) (*ListVotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListVotes not implemented")
+}
+func (UnimplementedPlanningPokerServer) StreamEvents(*StreamEventsRequest, PlanningPoker_StreamEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedPlanningPokerServer) mustEmbedUnimplementedPlanningPokerServer() {}
+
+// UnsafePlanningPokerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PlanningPokerServer will
+// result in compilation errors.
+type UnsafePlanningPokerServer interface {
+	mustEmbedUnimplementedPlanningPokerServer()
+}
+
+func RegisterPlanningPokerServer(s grpc.ServiceRegistrar, srv PlanningPokerServer) {
+	s.RegisterService(&PlanningPoker_ServiceDesc, srv)
+}
+
+func _PlanningPoker_GetSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanningPokerServer).GetSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanningPoker_GetSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanningPokerServer).GetSession(ctx, req.(*GetSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanningPoker_ListTickets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTicketsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanningPokerServer).ListTickets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanningPoker_ListTickets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanningPokerServer).ListTickets(ctx, req.(*ListTicketsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanningPoker_CreateTicket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTicketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanningPokerServer).CreateTicket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanningPoker_CreateTicket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanningPokerServer).CreateTicket(ctx, req.(*CreateTicketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanningPoker_ListVotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListVotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlanningPokerServer).ListVotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlanningPoker_ListVotes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlanningPokerServer).ListVotes(ctx, req.(*ListVotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlanningPoker_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PlanningPokerServer).StreamEvents(m, &planningPokerStreamEventsServer{stream})
+}
+
+type PlanningPoker_StreamEventsServer interface {
+	Send(*SessionEvent) error
+	grpc.ServerStream
+}
+
+type planningPokerStreamEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *planningPokerStreamEventsServer) Send(m *SessionEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// PlanningPoker_ServiceDesc is the grpc.ServiceDesc for PlanningPoker service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PlanningPoker_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "poker.v1.PlanningPoker",
+	HandlerType: (*PlanningPokerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSession",
+			Handler:    _PlanningPoker_GetSession_Handler,
+		},
+		{
+			MethodName: "ListTickets",
+			Handler:    _PlanningPoker_ListTickets_Handler,
+		},
+		{
+			MethodName: "CreateTicket",
+			Handler:    _PlanningPoker_CreateTicket_Handler,
+		},
+		{
+			MethodName: "ListVotes",
+			Handler:    _PlanningPoker_ListVotes_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _PlanningPoker_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/poker/v1/poker.proto",
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+// MaxActionItems is how many action items a session can have.
+const MaxActionItems = 100
+
+// GetActionItems returns a session's action items, oldest first, with the
+// names of their assignees.
+func (s *SessionService) GetActionItems(ctx context.Context, sessionID string) ([]models.ActionItem, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT a.id, a.session_id, a.assignee_id, COALESCE(u.username, ''), a.text, a.created_by, a.created_at
+			  FROM action_items a
+			  LEFT JOIN users u ON u.id = a.assignee_id
+			  WHERE a.session_id = ?
+			  ORDER BY a.created_at, a.id`
+	rows, err := s.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get action items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.ActionItem{}
+	for rows.Next() {
+		var item models.ActionItem
+		err := rows.Scan(&item.ID, &item.SessionID, &item.AssigneeID, &item.AssigneeName, &item.Text,
+			&item.CreatedBy, &item.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan action item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// AddActionItem records a follow-up task for a session. The assignee, if
+// any, must be one of its participants.
+func (s *SessionService) AddActionItem(ctx context.Context, item models.ActionItem) (*models.ActionItem, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if item.AssigneeID != nil {
+		var name string
+		query := `SELECT u.username FROM participants p JOIN users u ON u.id = p.user_id
+				  WHERE p.session_id = ? AND p.user_id = ?`
+		err := tx.QueryRowContext(ctx, query, item.SessionID, *item.AssigneeID).Scan(&name)
+		if err == sql.ErrNoRows {
+			return nil, ErrAssigneeNotParticipant
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check assignee: %w", err)
+		}
+		item.AssigneeName = name
+	}
+
+	var count int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM action_items WHERE session_id = ?`, item.SessionID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to count action items: %w", err)
+	}
+	if count >= MaxActionItems {
+		return nil, ErrActionItemsFull
+	}
+
+	item.CreatedAt = time.Now()
+	result, err := tx.ExecContext(ctx, `INSERT INTO action_items (session_id, assignee_id, text, created_by, created_at)
+										VALUES (?, ?, ?, ?, ?)`,
+		item.SessionID, item.AssigneeID, item.Text, item.CreatedBy, item.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add action item: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get action item ID: %w", err)
+	}
+	item.ID = int(id)
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &item, nil
+}
+
+// DeleteActionItem removes an action item from a session.
+func (s *SessionService) DeleteActionItem(ctx context.Context, sessionID string, itemID int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM action_items WHERE id = ? AND session_id = ?`, itemID, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete action item: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrActionItemNotFound
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+// AccuracyPoint is an estimated ticket with what it actually took, one
+// point of a team's estimate-vs-actual scatter.
+type AccuracyPoint struct {
+	TicketID       int       `json:"ticket_id"`
+	Title          string    `json:"title"`
+	ExternalKey    *string   `json:"external_ref"`
+	SessionID      string    `json:"session_id"`
+	SessionName    string    `json:"session_name"`
+	EstimationUnit string    `json:"estimation_unit"`
+	Estimate       string    `json:"estimate"`
+	Actual         float64   `json:"actual"`
+	ActualUnit     string    `json:"actual_unit"`
+	RecordedAt     time.Time `json:"recorded_at"`
+}
+
+// RecordActuals stores what tickets took, replacing the actuals they had.
+// With a teamID, only tickets of the team's sessions are matched. It
+// returns how many tickets got an actual and the actuals that matched none.
+func (s *TicketService) RecordActuals(ctx context.Context, teamID string, actuals []models.TicketActual) (int, []models.TicketActual, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	recorded := 0
+	unmatched := []models.TicketActual{}
+	now := time.Now()
+	for _, actual := range actuals {
+		// A ticket ID wins over an issue key, which may match several tickets
+		result, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO ticket_actuals (ticket_id, actual, unit, recorded_at)
+											SELECT t.id, ?, ?, ? FROM tickets t
+											JOIN sessions s ON s.id = t.session_id
+											WHERE CASE WHEN ? > 0 THEN t.id = ? ELSE t.external_key = ? END
+											  AND (? = '' OR s.team_id = ?)`,
+			actual.Actual, actual.Unit, now, actual.TicketID, actual.TicketID, actual.ExternalKey, teamID, teamID)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to record actual: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to record actual: %w", err)
+		}
+		if n == 0 {
+			unmatched = append(unmatched, actual)
+		}
+		recorded += int(n)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return recorded, unmatched, nil
+}
+
+// GetTeamAccuracy returns the estimated tickets of a team's sessions that
+// have an actual, oldest first. Calibration stories are left out, as they
+// are of velocity, and so are tickets split into smaller ones.
+func (s *TicketService) GetTeamAccuracy(ctx context.Context, teamID string) ([]AccuracyPoint, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT t.id, t.title, t.external_key, t.session_id, s.name, s.estimation_unit,
+											   t.final_estimate, a.actual, a.unit, a.recorded_at
+										FROM tickets t
+										JOIN sessions s ON s.id = t.session_id
+										JOIN ticket_actuals a ON a.ticket_id = t.id
+										WHERE s.team_id = ? AND t.final_estimate IS NOT NULL
+										  AND t.is_calibration = 0 AND t.is_split = 0
+										ORDER BY t.created_at, t.id`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get actuals: %w", err)
+	}
+	defer rows.Close()
+
+	points := []AccuracyPoint{}
+	for rows.Next() {
+		var point AccuracyPoint
+		err := rows.Scan(&point.TicketID, &point.Title, &point.ExternalKey, &point.SessionID, &point.SessionName,
+			&point.EstimationUnit, &point.Estimate, &point.Actual, &point.ActualUnit, &point.RecordedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan actual: %w", err)
+		}
+		points = append(points, point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get actuals: %w", err)
+	}
+	return points, nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+const agendaColumns = `id, session_id, position, kind, title, planned_minutes, started_at, ended_at`
+
+func scanAgendaItem(row rowScanner, item *models.AgendaItem) error {
+	var startedAt, endedAt sql.NullTime
+	if err := row.Scan(&item.ID, &item.SessionID, &item.Position, &item.Kind, &item.Title, &item.PlannedMinutes, &startedAt, &endedAt); err != nil {
+		return err
+	}
+	if startedAt.Valid {
+		item.StartedAt = &startedAt.Time
+	}
+	if endedAt.Valid {
+		item.EndedAt = &endedAt.Time
+	}
+	return nil
+}
+
+// GetAgenda returns a session's agenda in order, empty if it has none.
+func (s *SessionService) GetAgenda(ctx context.Context, sessionID string) ([]models.AgendaItem, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+agendaColumns+` FROM agenda_items
+										 WHERE session_id = ? ORDER BY position`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get agenda: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.AgendaItem{}
+	for rows.Next() {
+		var item models.AgendaItem
+		if err := scanAgendaItem(rows, &item); err != nil {
+			return nil, fmt.Errorf("failed to scan agenda item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// SetAgenda replaces a session's agenda with items, in order. Once the
+// first item has started the agenda records how the session went, so it
+// can only be cleared, not replaced.
+func (s *SessionService) SetAgenda(ctx context.Context, sessionID string, items []models.AgendaItem) ([]models.AgendaItem, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var started int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM agenda_items
+								   WHERE session_id = ? AND started_at IS NOT NULL`, sessionID).Scan(&started)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check agenda: %w", err)
+	}
+	if started > 0 {
+		return nil, ErrAgendaStarted
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM agenda_items WHERE session_id = ?`, sessionID); err != nil {
+		return nil, fmt.Errorf("failed to delete agenda: %w", err)
+	}
+
+	saved := make([]models.AgendaItem, 0, len(items))
+	for i, item := range items {
+		item.SessionID = sessionID
+		item.Position = i + 1
+		item.StartedAt = nil
+		item.EndedAt = nil
+
+		result, err := tx.ExecContext(ctx, `INSERT INTO agenda_items (session_id, position, kind, title, planned_minutes)
+											VALUES (?, ?, ?, ?, ?)`, sessionID, item.Position, item.Kind, item.Title, item.PlannedMinutes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create agenda item: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get agenda item ID: %w", err)
+		}
+		item.ID = int(id)
+		saved = append(saved, item)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return saved, nil
+}
+
+// ClearAgenda deletes a session's agenda, progress and all.
+func (s *SessionService) ClearAgenda(ctx context.Context, sessionID string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM agenda_items WHERE session_id = ?`, sessionID); err != nil {
+		return fmt.Errorf("failed to delete agenda: %w", err)
+	}
+	return nil
+}
+
+// AdvanceAgenda ends the current agenda item, if any, and starts the next
+// one. It returns the whole agenda afterwards and the item now current,
+// which is nil when the last item has just ended.
+func (s *SessionService) AdvanceAgenda(ctx context.Context, sessionID string) ([]models.AgendaItem, *models.AgendaItem, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+agendaColumns+` FROM agenda_items
+									   WHERE session_id = ? ORDER BY position`, sessionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get agenda: %w", err)
+	}
+	var items []models.AgendaItem
+	for rows.Next() {
+		var item models.AgendaItem
+		if err := scanAgendaItem(rows, &item); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to scan agenda item: %w", err)
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to get agenda: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, nil, ErrNoAgenda
+	}
+
+	now := time.Now()
+	next := -1
+	ended := false
+	for i := range items {
+		if items[i].IsCurrent() {
+			if _, err := tx.ExecContext(ctx, `UPDATE agenda_items SET ended_at = ? WHERE id = ?`, now, items[i].ID); err != nil {
+				return nil, nil, fmt.Errorf("failed to end agenda item: %w", err)
+			}
+			items[i].EndedAt = &now
+			ended = true
+		}
+		if items[i].StartedAt == nil && next < 0 {
+			next = i
+		}
+	}
+	if next < 0 && !ended {
+		return nil, nil, ErrAgendaFinished
+	}
+
+	var current *models.AgendaItem
+	if next >= 0 {
+		if _, err := tx.ExecContext(ctx, `UPDATE agenda_items SET started_at = ? WHERE id = ?`, now, items[next].ID); err != nil {
+			return nil, nil, fmt.Errorf("failed to start agenda item: %w", err)
+		}
+		items[next].StartedAt = &now
+		current = &items[next]
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return items, current, nil
+}
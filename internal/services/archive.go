@@ -0,0 +1,1532 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+	"poker-planning/internal/stats"
+
+	"github.com/google/uuid"
+)
+
+// ArchiveVersion is bumped whenever the archive layout changes incompatibly.
+const ArchiveVersion = 1
+
+// Archive is a portable snapshot of every user, organization, team, project
+// and session with everything they hold. Rows keep the IDs of the instance
+// they came from; ImportArchive remaps them. Only sign-ins are left out.
+type Archive struct {
+	Version             int                         `json:"version"`
+	ExportedAt          time.Time                   `json:"exported_at"`
+	Users               []ArchiveUser               `json:"users"`
+	Preferences         []ArchivePreferences        `json:"preferences"`
+	RecentEmojis        []ArchiveRecentEmoji        `json:"recent_emojis"`
+	Organizations       []ArchiveOrganization       `json:"organizations"`
+	OrganizationMembers []ArchiveOrganizationMember `json:"organization_members"`
+	Teams               []ArchiveTeam               `json:"teams"`
+	TeamMembers         []ArchiveTeamMember         `json:"team_members"`
+	Projects            []ArchiveProject            `json:"projects"`
+	Sessions            []ArchiveSession            `json:"sessions"`
+	Participants        []ArchiveParticipant        `json:"participants"`
+	Bots                []ArchiveBot                `json:"bots"`
+	Tickets             []ArchiveTicket             `json:"tickets"`
+	Votes               []ArchiveVote               `json:"votes"`
+	VoteRounds          []ArchiveVote               `json:"vote_rounds"`
+
+	TicketActuals        []ArchiveTicketActual        `json:"ticket_actuals"`
+	TeamReferences       []ArchiveTeamReference       `json:"team_references"`
+	ReferencePins        []ArchiveReferencePin        `json:"session_reference_pins"`
+	DescriptionTemplates []ArchiveDescriptionTemplate `json:"team_description_templates"`
+	ActionItems          []ArchiveActionItem          `json:"action_items"`
+	AgendaItems          []ArchiveAgendaItem          `json:"agenda_items"`
+	FacilitatorNotes     []ArchiveFacilitatorNote     `json:"facilitator_notes"`
+	ParkingLotItems      []ArchiveParkingLotItem      `json:"parking_lot_items"`
+	SessionFeedback      []ArchiveSessionFeedback     `json:"session_feedback"`
+	SessionSnapshots     []ArchiveSessionSnapshot     `json:"session_snapshots"`
+	SessionEvents        []ArchiveSessionEvent        `json:"session_events"`
+	HookSubscriptions    []ArchiveHookSubscription    `json:"hook_subscriptions"`
+	PushSubscriptions    []ArchivePushSubscription    `json:"push_subscriptions"`
+}
+
+type ArchiveUser struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+type ArchivePreferences struct {
+	UserID              string `json:"user_id"`
+	PreferredUnit       string `json:"preferred_unit"`
+	AutoReady           bool   `json:"auto_ready"`
+	ReducedMotion       bool   `json:"reduced_motion"`
+	Timezone            string `json:"timezone"`
+	NotifyVotingStarted bool   `json:"notify_voting_started"`
+	NotifyVotesRevealed bool   `json:"notify_votes_revealed"`
+	NotificationLevel   string `json:"notification_level,omitempty"`
+	DoNotDisturb        bool   `json:"do_not_disturb,omitempty"`
+}
+
+type ArchiveRecentEmoji struct {
+	UserID string    `json:"user_id"`
+	Emoji  string    `json:"emoji"`
+	UsedAt time.Time `json:"used_at"`
+}
+
+type ArchiveOrganization struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	InviteCode string    `json:"invite_code"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ArchiveOrganizationMember struct {
+	OrganizationID string    `json:"organization_id"`
+	UserID         string    `json:"user_id"`
+	Role           string    `json:"role"`
+	JoinedAt       time.Time `json:"joined_at"`
+}
+
+type ArchiveTeam struct {
+	ID              string    `json:"id"`
+	OrganizationID  string    `json:"organization_id"`
+	Name            string    `json:"name"`
+	EstimationUnit  string    `json:"estimation_unit"`
+	VotingTimeLimit *int      `json:"voting_time_limit"`
+	DaysPerPoint    *float64  `json:"days_per_point"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ArchiveTeamMember struct {
+	TeamID  string    `json:"team_id"`
+	UserID  string    `json:"user_id"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+type ArchiveProject struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	OwnerID        string    `json:"owner_id"`
+	OrganizationID *string   `json:"organization_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ArchiveSession struct {
+	ID                    string    `json:"id"`
+	Name                  string    `json:"name"`
+	OwnerID               string    `json:"owner_id"`
+	CurrentTicketID       *int      `json:"current_ticket_id"`
+	DiscussingTicketID    *int      `json:"discussing_ticket_id,omitempty"`
+	IsVotingActive        bool      `json:"is_voting_active"`
+	RoundingStrategy      string    `json:"rounding_strategy"`
+	SuggestionBasis       string    `json:"suggestion_basis,omitempty"` // missing from older archives, meaning the median
+	EstimationUnit        string    `json:"estimation_unit"`
+	ProjectID             *string   `json:"project_id"`
+	OrganizationID        *string   `json:"organization_id"`
+	TeamID                *string   `json:"team_id"`
+	PreviousSessionID     *string   `json:"previous_session_id"`
+	MaxParticipants       *int      `json:"max_participants"`
+	MaxTickets            *int      `json:"max_tickets"`
+	IsPublic              bool      `json:"is_public"`
+	AutoReveal            bool      `json:"auto_reveal"`
+	AutoRevealIgnoresAway bool      `json:"auto_reveal_ignores_away"`
+	VotingTimeLimit       *int      `json:"voting_time_limit"`
+	VoteChangeWindow      *int      `json:"vote_change_window"`
+	DelphiMaxRounds       *int      `json:"delphi_max_rounds"`
+	DelphiAgreement       int       `json:"delphi_agreement,omitempty"` // missing from older archives, meaning the default
+	RequiredFields        string    `json:"required_fields,omitempty"`  // comma-separated; missing from older archives, meaning none
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+type ArchiveParticipant struct {
+	SessionID   string    `json:"session_id"`
+	UserID      string    `json:"user_id"`
+	Weight      float64   `json:"weight,omitempty"` // missing from older archives, meaning 1
+	Stakeholder bool      `json:"stakeholder,omitempty"`
+	DisplayUnit *string   `json:"display_unit,omitempty"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+type ArchiveBot struct {
+	UserID     string `json:"user_id"`
+	SessionID  string `json:"session_id"`
+	Strategy   string `json:"strategy"`
+	FixedValue string `json:"fixed_value"`
+	DelayMS    int64  `json:"delay_ms"`
+}
+
+type ArchiveTicket struct {
+	ID               int        `json:"id"`
+	SessionID        string     `json:"session_id"`
+	Title            string     `json:"title"`
+	Description      string     `json:"description"`
+	ExternalKey      *string    `json:"external_key,omitempty"`
+	ExternalURL      *string    `json:"external_url,omitempty"`
+	ExternalSource   *string    `json:"external_source,omitempty"`
+	ExternalClosedAt *time.Time `json:"external_closed_at,omitempty"`
+	FinalEstimate    *string    `json:"final_estimate"`
+	EstimateLow      *string    `json:"estimate_low,omitempty"`
+	EstimateHigh     *string    `json:"estimate_high,omitempty"`
+	Rationale        string     `json:"rationale,omitempty"`
+	Assumptions      string     `json:"assumptions,omitempty"`
+	Position         int        `json:"position"`
+	ParentTicketID   *int       `json:"parent_ticket_id"`
+	IsSplit          bool       `json:"is_split"`
+	NeedsSplit       bool       `json:"needs_split,omitempty"`
+	IsCalibration    bool       `json:"is_calibration"`
+	PrevoteOpen      bool       `json:"prevote_open"`
+	BreakoutVoters   []string   `json:"breakout_voters,omitempty"` // user IDs
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// ArchiveVote is a current vote or, when Round is set, an archived one.
+type ArchiveVote struct {
+	TicketID   int        `json:"ticket_id"`
+	Round      int        `json:"round,omitempty"`
+	UserID     string     `json:"user_id"`
+	VoteValue  string     `json:"vote_value"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+type ArchiveTicketActual struct {
+	TicketID   int       `json:"ticket_id"`
+	Actual     float64   `json:"actual"`
+	Unit       string    `json:"unit"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+type ArchiveTeamReference struct {
+	ID        int       `json:"id"`
+	TeamID    string    `json:"team_id"`
+	Title     string    `json:"title"`
+	Points    string    `json:"points"`
+	TicketID  *int      `json:"ticket_id"`
+	CreatedBy *string   `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ArchiveReferencePin struct {
+	SessionID   string    `json:"session_id"`
+	ReferenceID int       `json:"reference_id"`
+	PinnedAt    time.Time `json:"pinned_at"`
+}
+
+type ArchiveDescriptionTemplate struct {
+	TeamID    string    `json:"team_id"`
+	Name      string    `json:"name"`
+	Sections  string    `json:"sections"`
+	CreatedBy *string   `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ArchiveActionItem struct {
+	SessionID  string    `json:"session_id"`
+	AssigneeID *string   `json:"assignee_id"`
+	Text       string    `json:"text"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type ArchiveAgendaItem struct {
+	SessionID      string     `json:"session_id"`
+	Position       int        `json:"position"`
+	Kind           string     `json:"kind"`
+	Title          string     `json:"title"`
+	PlannedMinutes int        `json:"planned_minutes"`
+	StartedAt      *time.Time `json:"started_at"`
+	EndedAt        *time.Time `json:"ended_at"`
+}
+
+type ArchiveFacilitatorNote struct {
+	SessionID string    `json:"session_id"`
+	TicketID  *int      `json:"ticket_id"` // nil for the note on the session
+	Text      string    `json:"text"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ArchiveParkingLotItem struct {
+	SessionID string    `json:"session_id"`
+	TicketID  *int      `json:"ticket_id"`
+	UserID    string    `json:"user_id"`
+	Kind      string    `json:"kind"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ArchiveSessionFeedback struct {
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id"`
+	Rating    int       `json:"rating"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ArchiveSessionSnapshot is a restore point; its state refers to tickets and
+// users by their IDs, which are remapped on import like everything else.
+type ArchiveSessionSnapshot struct {
+	SessionID   string          `json:"session_id"`
+	Name        string          `json:"name"`
+	Automatic   bool            `json:"automatic"`
+	TicketCount int             `json:"ticket_count"`
+	VoteCount   int             `json:"vote_count"`
+	State       json.RawMessage `json:"state"`
+	CreatedBy   *string         `json:"created_by"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// ArchiveSessionEvent is an entry of a session's event log. Its ticket and
+// user are remapped on import; IDs inside its data are kept as they were.
+type ArchiveSessionEvent struct {
+	SessionID string          `json:"session_id"`
+	Type      string          `json:"type"`
+	TicketID  *int            `json:"ticket_id"`
+	UserID    *string         `json:"user_id"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type ArchiveHookSubscription struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	TargetURL string    `json:"target_url"`
+	SessionID *string   `json:"session_id"` // nil for every session
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ArchivePushSubscription struct {
+	UserID    string    `json:"user_id"`
+	Endpoint  string    `json:"endpoint"`
+	P256dh    string    `json:"p256dh"`
+	Auth      string    `json:"auth"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ConflictPolicy decides what ImportArchive does with a user, organization,
+// project or session whose ID already exists in the target database.
+// Users are the same person on both sides, so an existing one is always
+// reused unless the policy is ConflictFail.
+type ConflictPolicy string
+
+const (
+	ConflictSkip  ConflictPolicy = "skip"   // keep the existing row; a skipped session skips its tickets and votes
+	ConflictNewID ConflictPolicy = "new-id" // import the row under a fresh ID
+	ConflictFail  ConflictPolicy = "fail"   // abort the whole import
+)
+
+func ParseConflictPolicy(value string) (ConflictPolicy, bool) {
+	for _, policy := range []ConflictPolicy{ConflictSkip, ConflictNewID, ConflictFail} {
+		if string(policy) == value {
+			return policy, true
+		}
+	}
+	return "", false
+}
+
+// ImportResult counts what ImportArchive wrote and skipped.
+type ImportResult struct {
+	Users    int
+	Projects int
+	Sessions int
+	Tickets  int
+	Votes    int
+	Skipped  int
+}
+
+var errImportConflict = errors.New("record already exists")
+
+type ArchiveService struct {
+	db *sql.DB
+}
+
+func NewArchiveService(db *sql.DB) *ArchiveService {
+	return &ArchiveService{db: db}
+}
+
+// Export reads the whole database into an Archive inside one transaction, so
+// the snapshot is consistent even while the server is running. It is meant
+// for the CLI and has no query timeout.
+func (s *ArchiveService) Export(ctx context.Context) (*Archive, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	archive := &Archive{Version: ArchiveVersion, ExportedAt: time.Now()}
+
+	err = queryRows(ctx, tx, `SELECT id, username, created_at, last_seen FROM users ORDER BY created_at`, func(rows *sql.Rows) error {
+		var user ArchiveUser
+		err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt, &user.LastSeen)
+		archive.Users = append(archive.Users, user)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export users: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT user_id, preferred_unit, auto_ready, reduced_motion, timezone, notify_voting_started, notify_votes_revealed, notification_level, do_not_disturb
+							  FROM user_preferences`, func(rows *sql.Rows) error {
+		var prefs ArchivePreferences
+		err := rows.Scan(&prefs.UserID, &prefs.PreferredUnit, &prefs.AutoReady, &prefs.ReducedMotion,
+			&prefs.Timezone, &prefs.NotifyVotingStarted, &prefs.NotifyVotesRevealed, &prefs.NotificationLevel, &prefs.DoNotDisturb)
+		archive.Preferences = append(archive.Preferences, prefs)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export preferences: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT user_id, emoji, used_at FROM recent_emojis`, func(rows *sql.Rows) error {
+		var emoji ArchiveRecentEmoji
+		err := rows.Scan(&emoji.UserID, &emoji.Emoji, &emoji.UsedAt)
+		archive.RecentEmojis = append(archive.RecentEmojis, emoji)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export recent emojis: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT id, name, invite_code, created_at FROM organizations ORDER BY created_at`, func(rows *sql.Rows) error {
+		var org ArchiveOrganization
+		err := rows.Scan(&org.ID, &org.Name, &org.InviteCode, &org.CreatedAt)
+		archive.Organizations = append(archive.Organizations, org)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export organizations: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT organization_id, user_id, role, joined_at FROM organization_members`, func(rows *sql.Rows) error {
+		var member ArchiveOrganizationMember
+		err := rows.Scan(&member.OrganizationID, &member.UserID, &member.Role, &member.JoinedAt)
+		archive.OrganizationMembers = append(archive.OrganizationMembers, member)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export organization members: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT id, organization_id, name, estimation_unit, voting_time_limit, days_per_point, created_at FROM teams ORDER BY created_at`, func(rows *sql.Rows) error {
+		var team ArchiveTeam
+		err := rows.Scan(&team.ID, &team.OrganizationID, &team.Name, &team.EstimationUnit, &team.VotingTimeLimit, &team.DaysPerPoint, &team.CreatedAt)
+		archive.Teams = append(archive.Teams, team)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export teams: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT team_id, user_id, added_at FROM team_members`, func(rows *sql.Rows) error {
+		var member ArchiveTeamMember
+		err := rows.Scan(&member.TeamID, &member.UserID, &member.AddedAt)
+		archive.TeamMembers = append(archive.TeamMembers, member)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export team members: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT id, name, owner_id, organization_id, created_at FROM projects ORDER BY created_at`, func(rows *sql.Rows) error {
+		var project ArchiveProject
+		err := rows.Scan(&project.ID, &project.Name, &project.OwnerID, &project.OrganizationID, &project.CreatedAt)
+		archive.Projects = append(archive.Projects, project)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export projects: %w", err)
+	}
+
+	if err := exportSessions(ctx, tx, archive, ""); err != nil {
+		return nil, err
+	}
+	if err := exportSessionData(ctx, tx, archive); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// exportSessions adds sessions with their participants, bots, tickets and
+// votes to an archive: one session, or all of them if sessionID is empty.
+func exportSessions(ctx context.Context, tx *sql.Tx, archive *Archive, sessionID string) error {
+	// filter restricts a query to the session, if there is one
+	var args []interface{}
+	filter := func(condition string) string {
+		if sessionID == "" {
+			return ""
+		}
+		return " WHERE " + condition
+	}
+	if sessionID != "" {
+		args = append(args, sessionID)
+	}
+
+	err := queryRows(ctx, tx, `SELECT id, name, owner_id, current_ticket_id, is_voting_active, rounding_strategy, suggestion_basis, estimation_unit,
+									 project_id, organization_id, team_id, previous_session_id, max_participants, max_tickets, is_public,
+									 auto_reveal, auto_reveal_ignores_away, voting_time_limit, vote_change_window, delphi_max_rounds, delphi_agreement,
+									 required_fields, discussing_ticket_id, created_at, updated_at
+							  FROM sessions`+filter("id = ?")+` ORDER BY created_at`, func(rows *sql.Rows) error {
+		var session ArchiveSession
+		err := rows.Scan(&session.ID, &session.Name, &session.OwnerID, &session.CurrentTicketID, &session.IsVotingActive,
+			&session.RoundingStrategy, &session.SuggestionBasis, &session.EstimationUnit, &session.ProjectID, &session.OrganizationID, &session.TeamID, &session.PreviousSessionID,
+			&session.MaxParticipants, &session.MaxTickets, &session.IsPublic, &session.AutoReveal,
+			&session.AutoRevealIgnoresAway, &session.VotingTimeLimit, &session.VoteChangeWindow, &session.DelphiMaxRounds, &session.DelphiAgreement,
+			&session.RequiredFields, &session.DiscussingTicketID, &session.CreatedAt, &session.UpdatedAt)
+		archive.Sessions = append(archive.Sessions, session)
+		return err
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export sessions: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT session_id, user_id, weight, is_stakeholder, display_unit, joined_at FROM participants`+filter("session_id = ?"), func(rows *sql.Rows) error {
+		var participant ArchiveParticipant
+		err := rows.Scan(&participant.SessionID, &participant.UserID, &participant.Weight, &participant.Stakeholder, &participant.DisplayUnit, &participant.JoinedAt)
+		archive.Participants = append(archive.Participants, participant)
+		return err
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export participants: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT user_id, session_id, strategy, fixed_value, delay_ms FROM bots`+filter("session_id = ?"), func(rows *sql.Rows) error {
+		var bot ArchiveBot
+		err := rows.Scan(&bot.UserID, &bot.SessionID, &bot.Strategy, &bot.FixedValue, &bot.DelayMS)
+		archive.Bots = append(archive.Bots, bot)
+		return err
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export bots: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT id, session_id, title, COALESCE(description, ''), external_key, external_url, external_source, external_closed_at, final_estimate,
+									 estimate_low, estimate_high, decision_rationale, decision_assumptions, position, parent_ticket_id, is_split, needs_split, is_calibration, prevote_open, breakout_voters, created_at
+							  FROM tickets`+filter("session_id = ?")+` ORDER BY id`, func(rows *sql.Rows) error {
+		var ticket ArchiveTicket
+		var breakout string
+		err := rows.Scan(&ticket.ID, &ticket.SessionID, &ticket.Title, &ticket.Description, &ticket.ExternalKey,
+			&ticket.ExternalURL, &ticket.ExternalSource, &ticket.ExternalClosedAt, &ticket.FinalEstimate, &ticket.EstimateLow, &ticket.EstimateHigh, &ticket.Rationale, &ticket.Assumptions, &ticket.Position, &ticket.ParentTicketID, &ticket.IsSplit, &ticket.NeedsSplit, &ticket.IsCalibration, &ticket.PrevoteOpen, &breakout, &ticket.CreatedAt)
+		ticket.BreakoutVoters = decodeBreakout(breakout)
+		archive.Tickets = append(archive.Tickets, ticket)
+		return err
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export tickets: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT ticket_id, user_id, vote_value, created_at FROM votes`+filter("ticket_id IN (SELECT id FROM tickets WHERE session_id = ?)")+` ORDER BY id`, func(rows *sql.Rows) error {
+		var vote ArchiveVote
+		err := rows.Scan(&vote.TicketID, &vote.UserID, &vote.VoteValue, &vote.CreatedAt)
+		archive.Votes = append(archive.Votes, vote)
+		return err
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export votes: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT ticket_id, round, user_id, vote_value, created_at, archived_at FROM vote_rounds`+filter("ticket_id IN (SELECT id FROM tickets WHERE session_id = ?)")+` ORDER BY id`, func(rows *sql.Rows) error {
+		var vote ArchiveVote
+		err := rows.Scan(&vote.TicketID, &vote.Round, &vote.UserID, &vote.VoteValue, &vote.CreatedAt, &vote.ArchivedAt)
+		archive.VoteRounds = append(archive.VoteRounds, vote)
+		return err
+	}, args...)
+	if err != nil {
+		return fmt.Errorf("failed to export vote rounds: %w", err)
+	}
+
+	return nil
+}
+
+func queryRows(ctx context.Context, tx *sql.Tx, query string, scan func(*sql.Rows) error, args ...interface{}) error {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// archiveImport carries the ID remapping of one import. A missing entry
+// means the row was skipped, so rows that reference it are skipped too.
+type archiveImport struct {
+	ctx        context.Context
+	tx         *sql.Tx
+	policy     ConflictPolicy
+	fresh      bool   // every row gets a new ID, as for session bundles
+	owner      string // if set, owns every imported session
+	result     ImportResult
+	users      map[string]string
+	orgs       map[string]string
+	teams      map[string]string
+	projects   map[string]string
+	sessions   map[string]string
+	tickets    map[int]int
+	references map[int]int
+}
+
+// ImportArchive writes an archive into the database in one transaction.
+// Users, projects and sessions that already exist are handled according to
+// policy; tickets and votes always get new IDs. References between imported
+// rows are remapped to the IDs they end up with.
+func (s *ArchiveService) ImportArchive(ctx context.Context, archive *Archive, policy ConflictPolicy) (*ImportResult, error) {
+	if archive.Version != ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", archive.Version)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	imp := &archiveImport{
+		ctx:        ctx,
+		tx:         tx,
+		policy:     policy,
+		users:      make(map[string]string),
+		orgs:       make(map[string]string),
+		teams:      make(map[string]string),
+		projects:   make(map[string]string),
+		sessions:   make(map[string]string),
+		tickets:    make(map[int]int),
+		references: make(map[int]int),
+	}
+
+	steps := []struct {
+		name string
+		run  func(*Archive) error
+	}{
+		{"users", imp.importUsers},
+		{"organizations", imp.importOrganizations},
+		{"teams", imp.importTeams},
+		{"projects", imp.importProjects},
+		{"sessions", imp.importSessions},
+		{"tickets", imp.importTickets},
+		{"votes", imp.importVotes},
+		{"session links", imp.linkSessions},
+		{"team data", imp.importTeamData},
+		{"session data", imp.importSessionData},
+		{"subscriptions", imp.importSubscriptions},
+	}
+	for _, step := range steps {
+		if err := step.run(archive); err != nil {
+			return nil, fmt.Errorf("failed to import %s: %w", step.name, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &imp.result, nil
+}
+
+// resolveID decides the ID a row with a string primary key is imported
+// under, or "" if it should be skipped.
+func (imp *archiveImport) resolveID(table, id string) (string, error) {
+	return imp.resolveIDWith(table, id, imp.policy)
+}
+
+// resolveIDWith is resolveID with another conflict policy than the import's.
+func (imp *archiveImport) resolveIDWith(table, id string, policy ConflictPolicy) (string, error) {
+	if imp.fresh {
+		return uuid.New().String(), nil
+	}
+
+	var exists bool
+	err := imp.tx.QueryRowContext(imp.ctx, `SELECT EXISTS(SELECT 1 FROM `+table+` WHERE id = ?)`, id).Scan(&exists)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return id, nil
+	}
+
+	switch policy {
+	case ConflictNewID:
+		return uuid.New().String(), nil
+	case ConflictFail:
+		return "", fmt.Errorf("%s %s: %w", table, id, errImportConflict)
+	default:
+		imp.result.Skipped++
+		return "", nil
+	}
+}
+
+func (imp *archiveImport) importUsers(archive *Archive) error {
+	for _, user := range archive.Users {
+		// An existing user is the same person, so they are never copied
+		policy := imp.policy
+		if policy == ConflictNewID {
+			policy = ConflictSkip
+		}
+		id, err := imp.resolveIDWith("users", user.ID, policy)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			// Keep pointing at them
+			imp.users[user.ID] = user.ID
+			continue
+		}
+
+		_, err = imp.tx.ExecContext(imp.ctx, `INSERT INTO users (id, username, created_at, last_seen) VALUES (?, ?, ?, ?)`,
+			id, user.Username, user.CreatedAt, user.LastSeen)
+		if err != nil {
+			return err
+		}
+		imp.users[user.ID] = id
+		imp.result.Users++
+
+		for _, prefs := range archive.Preferences {
+			if prefs.UserID != user.ID {
+				continue
+			}
+			_, err = imp.tx.ExecContext(imp.ctx, `INSERT INTO user_preferences (user_id, preferred_unit, auto_ready, reduced_motion, timezone, notify_voting_started, notify_votes_revealed, notification_level, do_not_disturb, updated_at)
+												  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				id, prefs.PreferredUnit, prefs.AutoReady, prefs.ReducedMotion, prefs.Timezone,
+				prefs.NotifyVotingStarted, prefs.NotifyVotesRevealed, prefs.NotificationLevel, prefs.DoNotDisturb, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, emoji := range archive.RecentEmojis {
+			if emoji.UserID != user.ID {
+				continue
+			}
+			_, err = imp.tx.ExecContext(imp.ctx, `INSERT INTO recent_emojis (user_id, emoji, used_at) VALUES (?, ?, ?)`,
+				id, emoji.Emoji, emoji.UsedAt)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (imp *archiveImport) importOrganizations(archive *Archive) error {
+	for _, org := range archive.Organizations {
+		id, err := imp.resolveID("organizations", org.ID)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			// Like users, a skipped organization is the one already here
+			imp.orgs[org.ID] = org.ID
+			continue
+		}
+
+		// Invite codes are unique, so a copy imported next to its original
+		// needs a fresh one
+		inviteCode := org.InviteCode
+		var taken bool
+		err = imp.tx.QueryRowContext(imp.ctx, `SELECT EXISTS(SELECT 1 FROM organizations WHERE invite_code = ?)`, inviteCode).Scan(&taken)
+		if err != nil {
+			return err
+		}
+		if taken || inviteCode == "" {
+			inviteCode = newInviteCode()
+		}
+
+		_, err = imp.tx.ExecContext(imp.ctx, `INSERT INTO organizations (id, name, invite_code, created_at) VALUES (?, ?, ?, ?)`,
+			id, org.Name, inviteCode, org.CreatedAt)
+		if err != nil {
+			return err
+		}
+		imp.orgs[org.ID] = id
+	}
+
+	for _, member := range archive.OrganizationMembers {
+		orgID, orgOK := imp.orgs[member.OrganizationID]
+		userID, userOK := imp.users[member.UserID]
+		if !orgOK || !userOK {
+			continue
+		}
+
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT OR IGNORE INTO organization_members (organization_id, user_id, role, joined_at) VALUES (?, ?, ?, ?)`,
+			orgID, userID, member.Role, member.JoinedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (imp *archiveImport) importTeams(archive *Archive) error {
+	for _, team := range archive.Teams {
+		orgID, ok := imp.orgs[team.OrganizationID]
+		if !ok {
+			imp.result.Skipped++
+			continue
+		}
+
+		id, err := imp.resolveID("teams", team.ID)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			continue
+		}
+
+		_, err = imp.tx.ExecContext(imp.ctx, `INSERT INTO teams (id, organization_id, name, estimation_unit, voting_time_limit, days_per_point, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			id, orgID, team.Name, team.EstimationUnit, team.VotingTimeLimit, team.DaysPerPoint, team.CreatedAt)
+		if err != nil {
+			return err
+		}
+		imp.teams[team.ID] = id
+	}
+
+	for _, member := range archive.TeamMembers {
+		teamID, teamOK := imp.teams[member.TeamID]
+		userID, userOK := imp.users[member.UserID]
+		if !teamOK || !userOK {
+			continue
+		}
+
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT OR IGNORE INTO team_members (team_id, user_id, added_at) VALUES (?, ?, ?)`,
+			teamID, userID, member.AddedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mapOrganization remaps an organization reference. Rows whose organization
+// was not imported are skipped rather than published outside it.
+func (imp *archiveImport) mapOrganization(orgID *string) (*string, bool) {
+	if orgID == nil {
+		return nil, true
+	}
+	mapped, ok := imp.orgs[*orgID]
+	return &mapped, ok
+}
+
+func (imp *archiveImport) importProjects(archive *Archive) error {
+	for _, project := range archive.Projects {
+		ownerID, ok := imp.users[project.OwnerID]
+		if !ok {
+			imp.result.Skipped++
+			continue
+		}
+
+		orgID, ok := imp.mapOrganization(project.OrganizationID)
+		if !ok {
+			imp.result.Skipped++
+			continue
+		}
+
+		id, err := imp.resolveID("projects", project.ID)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			continue
+		}
+
+		_, err = imp.tx.ExecContext(imp.ctx, `INSERT INTO projects (id, name, owner_id, organization_id, created_at) VALUES (?, ?, ?, ?, ?)`,
+			id, project.Name, ownerID, orgID, project.CreatedAt)
+		if err != nil {
+			return err
+		}
+		imp.projects[project.ID] = id
+		imp.result.Projects++
+	}
+
+	return nil
+}
+
+// importSessions inserts sessions without their current ticket and previous
+// session, which linkSessions fills in once everything they point at exists.
+func (imp *archiveImport) importSessions(archive *Archive) error {
+	for _, session := range archive.Sessions {
+		ownerID, ok := imp.users[session.OwnerID]
+		if imp.owner != "" {
+			ownerID, ok = imp.owner, true
+		}
+		if !ok {
+			imp.result.Skipped++
+			continue
+		}
+
+		orgID, ok := imp.mapOrganization(session.OrganizationID)
+		if !ok {
+			imp.result.Skipped++
+			continue
+		}
+
+		id, err := imp.resolveID("sessions", session.ID)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			continue
+		}
+
+		var projectID *string
+		if session.ProjectID != nil {
+			if mapped, ok := imp.projects[*session.ProjectID]; ok {
+				projectID = &mapped
+			}
+		}
+
+		var teamID *string
+		if session.TeamID != nil {
+			if mapped, ok := imp.teams[*session.TeamID]; ok {
+				teamID = &mapped
+			}
+		}
+		delphiAgreement := session.DelphiAgreement
+		if delphiAgreement == 0 {
+			delphiAgreement = models.DefaultDelphiAgreement
+		}
+		basis, _ := stats.ParseBasis(session.SuggestionBasis)
+
+		_, err = imp.tx.ExecContext(imp.ctx, `INSERT INTO sessions (id, name, owner_id, is_voting_active, rounding_strategy, suggestion_basis, estimation_unit,
+																	project_id, organization_id, team_id, max_participants, max_tickets, is_public, auto_reveal,
+																	auto_reveal_ignores_away, voting_time_limit, vote_change_window, delphi_max_rounds, delphi_agreement,
+																	required_fields, created_at, updated_at)
+											  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, session.Name, ownerID, session.IsVotingActive, session.RoundingStrategy, string(basis), session.EstimationUnit,
+			projectID, orgID, teamID, session.MaxParticipants, session.MaxTickets, session.IsPublic, session.AutoReveal,
+			session.AutoRevealIgnoresAway, session.VotingTimeLimit, session.VoteChangeWindow, session.DelphiMaxRounds, delphiAgreement,
+			encodeRequiredFields(decodeRequiredFields(session.RequiredFields)), session.CreatedAt, session.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		imp.sessions[session.ID] = id
+		imp.result.Sessions++
+	}
+
+	for _, participant := range archive.Participants {
+		sessionID, sessionOK := imp.sessions[participant.SessionID]
+		userID, userOK := imp.users[participant.UserID]
+		if !sessionOK || !userOK {
+			continue
+		}
+
+		weight := participant.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT OR IGNORE INTO participants (session_id, user_id, weight, is_stakeholder, display_unit, joined_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			sessionID, userID, weight, participant.Stakeholder, participant.DisplayUnit, participant.JoinedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, bot := range archive.Bots {
+		sessionID, sessionOK := imp.sessions[bot.SessionID]
+		userID, userOK := imp.users[bot.UserID]
+		if !sessionOK || !userOK {
+			continue
+		}
+
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT OR IGNORE INTO bots (user_id, session_id, strategy, fixed_value, delay_ms) VALUES (?, ?, ?, ?, ?)`,
+			userID, sessionID, bot.Strategy, bot.FixedValue, bot.DelayMS)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (imp *archiveImport) importTickets(archive *Archive) error {
+	for _, ticket := range archive.Tickets {
+		sessionID, ok := imp.sessions[ticket.SessionID]
+		if !ok {
+			continue
+		}
+
+		// Breakout voters who were not imported are left out of the group
+		var breakout []string
+		for _, voter := range ticket.BreakoutVoters {
+			if userID, ok := imp.users[voter]; ok {
+				breakout = append(breakout, userID)
+			}
+		}
+
+		result, err := imp.tx.ExecContext(imp.ctx, `INSERT INTO tickets (session_id, title, description, external_key, external_url, external_source, external_closed_at, final_estimate, estimate_low, estimate_high, decision_rationale, decision_assumptions, position, is_split, needs_split, is_calibration, prevote_open, breakout_voters, created_at)
+													VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, ticket.Title, ticket.Description, ticket.ExternalKey, ticket.ExternalURL, ticket.ExternalSource, ticket.ExternalClosedAt, ticket.FinalEstimate, ticket.EstimateLow, ticket.EstimateHigh, ticket.Rationale, ticket.Assumptions, ticket.Position, ticket.IsSplit, ticket.NeedsSplit, ticket.IsCalibration, ticket.PrevoteOpen, encodeBreakout(breakout), ticket.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		imp.tickets[ticket.ID] = int(id)
+		imp.result.Tickets++
+	}
+
+	// Parents may come after their children in the archive
+	for _, ticket := range archive.Tickets {
+		if ticket.ParentTicketID == nil {
+			continue
+		}
+		id, ok := imp.tickets[ticket.ID]
+		parentID, parentOK := imp.tickets[*ticket.ParentTicketID]
+		if !ok || !parentOK {
+			continue
+		}
+
+		_, err := imp.tx.ExecContext(imp.ctx, `UPDATE tickets SET parent_ticket_id = ? WHERE id = ?`, parentID, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (imp *archiveImport) importVotes(archive *Archive) error {
+	for _, vote := range archive.Votes {
+		ticketID, ticketOK := imp.tickets[vote.TicketID]
+		userID, userOK := imp.users[vote.UserID]
+		if !ticketOK || !userOK {
+			continue
+		}
+
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT INTO votes (ticket_id, user_id, vote_value, created_at) VALUES (?, ?, ?, ?)`,
+			ticketID, userID, vote.VoteValue, vote.CreatedAt)
+		if err != nil {
+			return err
+		}
+		imp.result.Votes++
+	}
+
+	for _, vote := range archive.VoteRounds {
+		ticketID, ticketOK := imp.tickets[vote.TicketID]
+		userID, userOK := imp.users[vote.UserID]
+		if !ticketOK || !userOK {
+			continue
+		}
+
+		archivedAt := vote.CreatedAt
+		if vote.ArchivedAt != nil {
+			archivedAt = *vote.ArchivedAt
+		}
+
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT INTO vote_rounds (ticket_id, round, user_id, vote_value, created_at, archived_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			ticketID, vote.Round, userID, vote.VoteValue, vote.CreatedAt, archivedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (imp *archiveImport) linkSessions(archive *Archive) error {
+	for _, session := range archive.Sessions {
+		id, ok := imp.sessions[session.ID]
+		if !ok {
+			continue
+		}
+
+		var currentTicketID *int
+		if session.CurrentTicketID != nil {
+			if mapped, ok := imp.tickets[*session.CurrentTicketID]; ok {
+				currentTicketID = &mapped
+			}
+		}
+
+		var discussingTicketID *int
+		if session.DiscussingTicketID != nil {
+			if mapped, ok := imp.tickets[*session.DiscussingTicketID]; ok {
+				discussingTicketID = &mapped
+			}
+		}
+
+		var previousSessionID *string
+		if session.PreviousSessionID != nil {
+			if mapped, ok := imp.sessions[*session.PreviousSessionID]; ok {
+				previousSessionID = &mapped
+			}
+		}
+
+		if currentTicketID == nil && discussingTicketID == nil && previousSessionID == nil {
+			continue
+		}
+
+		_, err := imp.tx.ExecContext(imp.ctx, `UPDATE sessions SET current_ticket_id = ?, discussing_ticket_id = ?, previous_session_id = ? WHERE id = ?`,
+			currentTicketID, discussingTicketID, previousSessionID, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportSessionData adds what teams and sessions hold besides their tickets
+// and votes to an archive, along with the hook and push subscriptions.
+func exportSessionData(ctx context.Context, tx *sql.Tx, archive *Archive) error {
+	err := queryRows(ctx, tx, `SELECT ticket_id, actual, unit, recorded_at FROM ticket_actuals`, func(rows *sql.Rows) error {
+		var actual ArchiveTicketActual
+		err := rows.Scan(&actual.TicketID, &actual.Actual, &actual.Unit, &actual.RecordedAt)
+		archive.TicketActuals = append(archive.TicketActuals, actual)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export ticket actuals: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT id, team_id, title, points, ticket_id, created_by, created_at FROM team_references ORDER BY id`, func(rows *sql.Rows) error {
+		var reference ArchiveTeamReference
+		err := rows.Scan(&reference.ID, &reference.TeamID, &reference.Title, &reference.Points, &reference.TicketID, &reference.CreatedBy, &reference.CreatedAt)
+		archive.TeamReferences = append(archive.TeamReferences, reference)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export team references: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT session_id, reference_id, pinned_at FROM session_reference_pins`, func(rows *sql.Rows) error {
+		var pin ArchiveReferencePin
+		err := rows.Scan(&pin.SessionID, &pin.ReferenceID, &pin.PinnedAt)
+		archive.ReferencePins = append(archive.ReferencePins, pin)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export reference pins: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT team_id, name, sections, created_by, created_at FROM team_description_templates ORDER BY id`, func(rows *sql.Rows) error {
+		var template ArchiveDescriptionTemplate
+		err := rows.Scan(&template.TeamID, &template.Name, &template.Sections, &template.CreatedBy, &template.CreatedAt)
+		archive.DescriptionTemplates = append(archive.DescriptionTemplates, template)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export description templates: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT session_id, assignee_id, text, created_by, created_at FROM action_items ORDER BY id`, func(rows *sql.Rows) error {
+		var item ArchiveActionItem
+		err := rows.Scan(&item.SessionID, &item.AssigneeID, &item.Text, &item.CreatedBy, &item.CreatedAt)
+		archive.ActionItems = append(archive.ActionItems, item)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export action items: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT session_id, position, kind, title, planned_minutes, started_at, ended_at FROM agenda_items ORDER BY id`, func(rows *sql.Rows) error {
+		var item ArchiveAgendaItem
+		err := rows.Scan(&item.SessionID, &item.Position, &item.Kind, &item.Title, &item.PlannedMinutes, &item.StartedAt, &item.EndedAt)
+		archive.AgendaItems = append(archive.AgendaItems, item)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export agenda items: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT session_id, ticket_id, text, updated_at FROM facilitator_notes ORDER BY id`, func(rows *sql.Rows) error {
+		var note ArchiveFacilitatorNote
+		err := rows.Scan(&note.SessionID, &note.TicketID, &note.Text, &note.UpdatedAt)
+		archive.FacilitatorNotes = append(archive.FacilitatorNotes, note)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export facilitator notes: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT session_id, ticket_id, user_id, kind, text, created_at FROM parking_lot_items ORDER BY id`, func(rows *sql.Rows) error {
+		var item ArchiveParkingLotItem
+		err := rows.Scan(&item.SessionID, &item.TicketID, &item.UserID, &item.Kind, &item.Text, &item.CreatedAt)
+		archive.ParkingLotItems = append(archive.ParkingLotItems, item)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export parking lot items: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT session_id, user_id, rating, comment, created_at FROM session_feedback`, func(rows *sql.Rows) error {
+		var feedback ArchiveSessionFeedback
+		err := rows.Scan(&feedback.SessionID, &feedback.UserID, &feedback.Rating, &feedback.Comment, &feedback.CreatedAt)
+		archive.SessionFeedback = append(archive.SessionFeedback, feedback)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export session feedback: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT session_id, name, automatic, ticket_count, vote_count, state, created_by, created_at FROM session_snapshots ORDER BY id`, func(rows *sql.Rows) error {
+		var snapshot ArchiveSessionSnapshot
+		var state string
+		err := rows.Scan(&snapshot.SessionID, &snapshot.Name, &snapshot.Automatic, &snapshot.TicketCount, &snapshot.VoteCount, &state, &snapshot.CreatedBy, &snapshot.CreatedAt)
+		snapshot.State = json.RawMessage(state)
+		archive.SessionSnapshots = append(archive.SessionSnapshots, snapshot)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export session snapshots: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT session_id, type, ticket_id, user_id, data, created_at FROM session_events ORDER BY id`, func(rows *sql.Rows) error {
+		var event ArchiveSessionEvent
+		var data string
+		err := rows.Scan(&event.SessionID, &event.Type, &event.TicketID, &event.UserID, &data, &event.CreatedAt)
+		event.Data = json.RawMessage(data)
+		archive.SessionEvents = append(archive.SessionEvents, event)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export session events: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT id, event, target_url, session_id, created_at FROM hook_subscriptions ORDER BY created_at`, func(rows *sql.Rows) error {
+		var hook ArchiveHookSubscription
+		err := rows.Scan(&hook.ID, &hook.Event, &hook.TargetURL, &hook.SessionID, &hook.CreatedAt)
+		archive.HookSubscriptions = append(archive.HookSubscriptions, hook)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export hook subscriptions: %w", err)
+	}
+
+	err = queryRows(ctx, tx, `SELECT user_id, endpoint, p256dh, auth, created_at FROM push_subscriptions ORDER BY created_at`, func(rows *sql.Rows) error {
+		var push ArchivePushSubscription
+		err := rows.Scan(&push.UserID, &push.Endpoint, &push.P256dh, &push.Auth, &push.CreatedAt)
+		archive.PushSubscriptions = append(archive.PushSubscriptions, push)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export push subscriptions: %w", err)
+	}
+
+	return nil
+}
+
+// mapTicket remaps an optional ticket reference. ok is false when the
+// ticket was not imported.
+func (imp *archiveImport) mapTicket(ticketID *int) (*int, bool) {
+	if ticketID == nil {
+		return nil, true
+	}
+	mapped, ok := imp.tickets[*ticketID]
+	return &mapped, ok
+}
+
+// mapUser remaps an optional user reference, dropping it when the user was
+// not imported.
+func (imp *archiveImport) mapUser(userID *string) *string {
+	if userID == nil {
+		return nil
+	}
+	mapped, ok := imp.users[*userID]
+	if !ok {
+		return nil
+	}
+	return &mapped
+}
+
+// importTeamData imports the reference stories and description templates of
+// imported teams, and the actuals of imported tickets.
+func (imp *archiveImport) importTeamData(archive *Archive) error {
+	for _, actual := range archive.TicketActuals {
+		ticketID, ok := imp.tickets[actual.TicketID]
+		if !ok {
+			continue
+		}
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT OR REPLACE INTO ticket_actuals (ticket_id, actual, unit, recorded_at) VALUES (?, ?, ?, ?)`,
+			ticketID, actual.Actual, actual.Unit, actual.RecordedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, reference := range archive.TeamReferences {
+		teamID, ok := imp.teams[reference.TeamID]
+		if !ok {
+			continue
+		}
+		// The reference keeps its title and points without the ticket
+		ticketID, ok := imp.mapTicket(reference.TicketID)
+		if !ok {
+			ticketID = nil
+		}
+
+		result, err := imp.tx.ExecContext(imp.ctx, `INSERT INTO team_references (team_id, title, points, ticket_id, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			teamID, reference.Title, reference.Points, ticketID, imp.mapUser(reference.CreatedBy), reference.CreatedAt)
+		if err != nil {
+			return err
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		imp.references[reference.ID] = int(id)
+	}
+
+	for _, template := range archive.DescriptionTemplates {
+		teamID, ok := imp.teams[template.TeamID]
+		if !ok {
+			continue
+		}
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT INTO team_description_templates (team_id, name, sections, created_by, created_at) VALUES (?, ?, ?, ?, ?)`,
+			teamID, template.Name, template.Sections, imp.mapUser(template.CreatedBy), template.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importSessionData imports what imported sessions hold besides their
+// tickets and votes: pins, action items, agenda, notes, parking lot,
+// feedback, restore points and the event log.
+func (imp *archiveImport) importSessionData(archive *Archive) error {
+	for _, pin := range archive.ReferencePins {
+		sessionID, sessionOK := imp.sessions[pin.SessionID]
+		referenceID, referenceOK := imp.references[pin.ReferenceID]
+		if !sessionOK || !referenceOK {
+			continue
+		}
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT OR IGNORE INTO session_reference_pins (session_id, reference_id, pinned_at) VALUES (?, ?, ?)`,
+			sessionID, referenceID, pin.PinnedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, item := range archive.ActionItems {
+		sessionID, sessionOK := imp.sessions[item.SessionID]
+		createdBy, userOK := imp.users[item.CreatedBy]
+		if !sessionOK || !userOK {
+			continue
+		}
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT INTO action_items (session_id, assignee_id, text, created_by, created_at) VALUES (?, ?, ?, ?, ?)`,
+			sessionID, imp.mapUser(item.AssigneeID), item.Text, createdBy, item.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, item := range archive.AgendaItems {
+		sessionID, ok := imp.sessions[item.SessionID]
+		if !ok {
+			continue
+		}
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT INTO agenda_items (session_id, position, kind, title, planned_minutes, started_at, ended_at) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, item.Position, item.Kind, item.Title, item.PlannedMinutes, item.StartedAt, item.EndedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, note := range archive.FacilitatorNotes {
+		sessionID, sessionOK := imp.sessions[note.SessionID]
+		ticketID, ticketOK := imp.mapTicket(note.TicketID)
+		if !sessionOK || !ticketOK {
+			continue
+		}
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT OR IGNORE INTO facilitator_notes (session_id, ticket_id, text, updated_at) VALUES (?, ?, ?, ?)`,
+			sessionID, ticketID, note.Text, note.UpdatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, item := range archive.ParkingLotItems {
+		sessionID, sessionOK := imp.sessions[item.SessionID]
+		userID, userOK := imp.users[item.UserID]
+		if !sessionOK || !userOK {
+			continue
+		}
+		ticketID, ok := imp.mapTicket(item.TicketID)
+		if !ok {
+			ticketID = nil
+		}
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT INTO parking_lot_items (session_id, ticket_id, user_id, kind, text, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			sessionID, ticketID, userID, item.Kind, item.Text, item.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, feedback := range archive.SessionFeedback {
+		sessionID, sessionOK := imp.sessions[feedback.SessionID]
+		userID, userOK := imp.users[feedback.UserID]
+		if !sessionOK || !userOK {
+			continue
+		}
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT OR IGNORE INTO session_feedback (session_id, user_id, rating, comment, created_at) VALUES (?, ?, ?, ?, ?)`,
+			sessionID, userID, feedback.Rating, feedback.Comment, feedback.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, snapshot := range archive.SessionSnapshots {
+		sessionID, ok := imp.sessions[snapshot.SessionID]
+		if !ok {
+			continue
+		}
+		var state snapshotState
+		if err := json.Unmarshal(snapshot.State, &state); err != nil {
+			return fmt.Errorf("restore point %q: %w", snapshot.Name, err)
+		}
+		data, err := json.Marshal(imp.remapSnapshot(sessionID, &state))
+		if err != nil {
+			return err
+		}
+		_, err = imp.tx.ExecContext(imp.ctx, `INSERT INTO session_snapshots (session_id, name, automatic, ticket_count, vote_count, state, created_by, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, snapshot.Name, snapshot.Automatic, snapshot.TicketCount, snapshot.VoteCount, string(data), imp.mapUser(snapshot.CreatedBy), snapshot.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, event := range archive.SessionEvents {
+		sessionID, ok := imp.sessions[event.SessionID]
+		if !ok {
+			continue
+		}
+		// Events outlive their tickets, so one that is gone is kept without it
+		ticketID, ok := imp.mapTicket(event.TicketID)
+		if !ok {
+			ticketID = nil
+		}
+		data := string(event.Data)
+		if data == "" {
+			data = "{}"
+		}
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT INTO session_events (session_id, type, ticket_id, user_id, data, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			sessionID, event.Type, ticketID, imp.mapUser(event.UserID), data, event.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remapSnapshot points a restore point's state at the imported tickets and
+// users. Tickets deleted before the export were not imported, so they are
+// left out of it along with their votes.
+func (imp *archiveImport) remapSnapshot(sessionID string, state *snapshotState) *snapshotState {
+	remapped := &snapshotState{
+		IsVotingActive:  state.IsVotingActive,
+		VotingStartedAt: state.VotingStartedAt,
+		Tickets:         []snapshotTicket{},
+		Votes:           []ArchiveVote{},
+		VoteRounds:      []ArchiveVote{},
+	}
+	if id, ok := imp.mapTicket(state.CurrentTicketID); ok {
+		remapped.CurrentTicketID = id
+	}
+	if id, ok := imp.mapTicket(state.DiscussingTicketID); ok {
+		remapped.DiscussingTicketID = id
+	}
+
+	for _, ticket := range state.Tickets {
+		id, ok := imp.tickets[ticket.ID]
+		if !ok {
+			continue
+		}
+		ticket.ID = id
+		ticket.SessionID = sessionID
+		if parentID, ok := imp.mapTicket(ticket.ParentTicketID); ok {
+			ticket.ParentTicketID = parentID
+		} else {
+			ticket.ParentTicketID = nil
+		}
+		var breakout []string
+		for _, voter := range ticket.BreakoutVoters {
+			if userID, ok := imp.users[voter]; ok {
+				breakout = append(breakout, userID)
+			}
+		}
+		ticket.BreakoutVoters = breakout
+		remapped.Tickets = append(remapped.Tickets, ticket)
+	}
+
+	remapVotes := func(votes []ArchiveVote) []ArchiveVote {
+		kept := []ArchiveVote{}
+		for _, vote := range votes {
+			ticketID, ticketOK := imp.tickets[vote.TicketID]
+			userID, userOK := imp.users[vote.UserID]
+			if !ticketOK || !userOK {
+				continue
+			}
+			vote.TicketID, vote.UserID = ticketID, userID
+			kept = append(kept, vote)
+		}
+		return kept
+	}
+	remapped.Votes = remapVotes(state.Votes)
+	remapped.VoteRounds = remapVotes(state.VoteRounds)
+
+	return remapped
+}
+
+// importSubscriptions imports hook subscriptions, those of every session and
+// those of imported sessions, and the push subscriptions of imported users.
+// A browser can only be subscribed once, so a push subscription already here
+// is kept.
+func (imp *archiveImport) importSubscriptions(archive *Archive) error {
+	for _, hook := range archive.HookSubscriptions {
+		var sessionID *string
+		if hook.SessionID != nil {
+			mapped, ok := imp.sessions[*hook.SessionID]
+			if !ok {
+				continue
+			}
+			sessionID = &mapped
+		}
+
+		id, err := imp.resolveID("hook_subscriptions", hook.ID)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			continue
+		}
+
+		_, err = imp.tx.ExecContext(imp.ctx, `INSERT INTO hook_subscriptions (id, event, target_url, session_id, created_at) VALUES (?, ?, ?, ?, ?)`,
+			id, hook.Event, hook.TargetURL, sessionID, hook.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, push := range archive.PushSubscriptions {
+		userID, ok := imp.users[push.UserID]
+		if !ok {
+			continue
+		}
+		_, err := imp.tx.ExecContext(imp.ctx, `INSERT OR IGNORE INTO push_subscriptions (id, user_id, endpoint, p256dh, auth, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+			uuid.New().String(), userID, push.Endpoint, push.P256dh, push.Auth, push.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+)
+
+// archiveSeed puts one row in every table, so the round trip below notices
+// a table the archive leaves behind.
+const archiveSeed = `
+INSERT INTO users (id, username) VALUES ('u1', 'alice'), ('u2', 'bob');
+INSERT INTO user_preferences (user_id, timezone) VALUES ('u1', 'Europe/Paris');
+INSERT INTO recent_emojis (user_id, emoji) VALUES ('u1', '🎉');
+INSERT INTO organizations (id, name, invite_code) VALUES ('o1', 'Acme', 'invite');
+INSERT INTO organization_members (organization_id, user_id, role) VALUES ('o1', 'u1', 'admin');
+INSERT INTO teams (id, organization_id, name) VALUES ('t1', 'o1', 'Core');
+INSERT INTO team_members (team_id, user_id) VALUES ('t1', 'u2');
+INSERT INTO projects (id, name, owner_id) VALUES ('p1', 'Launch', 'u1');
+INSERT INTO sessions (id, name, owner_id, project_id, team_id) VALUES ('s1', 'Sprint 1', 'u1', 'p1', 't1');
+INSERT INTO participants (session_id, user_id) VALUES ('s1', 'u1'), ('s1', 'u2');
+INSERT INTO users (id, username) VALUES ('b1', 'Bot');
+INSERT INTO bots (user_id, session_id, strategy) VALUES ('b1', 's1', 'random');
+INSERT INTO tickets (id, session_id, title, position, final_estimate) VALUES (10, 's1', 'Login', 1, '5'), (11, 's1', 'Logout', 2, NULL);
+UPDATE sessions SET current_ticket_id = 11 WHERE id = 's1';
+INSERT INTO votes (ticket_id, user_id, vote_value) VALUES (10, 'u2', '5');
+INSERT INTO vote_rounds (ticket_id, round, user_id, vote_value) VALUES (10, 1, 'u2', '8');
+INSERT INTO ticket_actuals (ticket_id, actual, unit) VALUES (10, 4, 'points');
+INSERT INTO team_references (id, team_id, title, points, ticket_id, created_by) VALUES (20, 't1', 'Login', '5', 10, 'u1');
+INSERT INTO session_reference_pins (session_id, reference_id) VALUES ('s1', 20);
+INSERT INTO team_description_templates (team_id, name, sections, created_by, created_at) VALUES ('t1', 'Story', 'Why', 'u1', CURRENT_TIMESTAMP);
+INSERT INTO action_items (session_id, assignee_id, text, created_by, created_at) VALUES ('s1', 'u2', 'Split epics', 'u1', CURRENT_TIMESTAMP);
+INSERT INTO agenda_items (session_id, position, kind, title, planned_minutes) VALUES ('s1', 0, 'estimation', 'Estimate', 30);
+INSERT INTO facilitator_notes (session_id, ticket_id, text, updated_at) VALUES ('s1', 11, 'Ask ops', CURRENT_TIMESTAMP);
+INSERT INTO parking_lot_items (session_id, ticket_id, user_id, kind, text, created_at) VALUES ('s1', 11, 'u2', 'question', 'SSO?', CURRENT_TIMESTAMP);
+INSERT INTO session_feedback (session_id, user_id, rating, created_at) VALUES ('s1', 'u2', 4, CURRENT_TIMESTAMP);
+INSERT INTO session_snapshots (session_id, name, ticket_count, vote_count, state, created_by, created_at)
+	VALUES ('s1', 'Before import', 1, 1, '{"current_ticket_id":10,"tickets":[{"id":10,"session_id":"s1","title":"Login","position":1}],"votes":[{"ticket_id":10,"user_id":"u2","vote_value":"5"}],"vote_rounds":[]}', 'u1', CURRENT_TIMESTAMP);
+INSERT INTO session_events (session_id, type, ticket_id, user_id) VALUES ('s1', 'vote-cast', 10, 'u2');
+INSERT INTO hook_subscriptions (id, event, target_url, session_id) VALUES ('h1', 'votes-revealed', 'https://example.com/hook', 's1');
+INSERT INTO push_subscriptions (id, user_id, endpoint, p256dh, auth) VALUES ('ps1', 'u2', 'https://fcm.googleapis.com/fcm/send/abc', 'key', 'secret');
+`
+
+// unarchivedTables are left out of archives on purpose.
+var unarchivedTables = map[string]bool{
+	"goose_db_version": true, // the target has its own migrations
+	"sqlite_sequence":  true,
+	"login_sessions":   true, // sign-ins stay on the instance that issued them
+}
+
+func tableCounts(t *testing.T, db *sql.DB) map[string]int {
+	t.Helper()
+	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type = 'table'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		if !unarchivedTables[name] {
+			tables = append(tables, name)
+		}
+	}
+	rows.Close()
+
+	counts := make(map[string]int)
+	for _, table := range tables {
+		var count int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		counts[table] = count
+	}
+	return counts
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := newTestDB(t).DB
+	if _, err := source.Exec(archiveSeed); err != nil {
+		t.Fatal(err)
+	}
+	want := tableCounts(t, source)
+	for table, count := range want {
+		if count == 0 {
+			t.Errorf("%s has no rows in the seed; add it to the archive and the seed, or to unarchivedTables", table)
+		}
+	}
+
+	archive, err := NewArchiveService(source).Export(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Archive
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	target := newTestDB(t).DB
+	if _, err := NewArchiveService(target).ImportArchive(ctx, &decoded, ConflictNewID); err != nil {
+		t.Fatal(err)
+	}
+	got := tableCounts(t, target)
+	for table, count := range want {
+		if got[table] != count {
+			t.Errorf("%s: imported %d rows, want %d", table, got[table], count)
+		}
+	}
+
+	// Importing again copies sessions but reuses the users already there
+	if _, err := NewArchiveService(target).ImportArchive(ctx, &decoded, ConflictNewID); err != nil {
+		t.Fatal(err)
+	}
+	again := tableCounts(t, target)
+	if again["users"] != want["users"] {
+		t.Errorf("users after a second import = %d, want %d", again["users"], want["users"])
+	}
+	if again["sessions"] != 2*want["sessions"] {
+		t.Errorf("sessions after a second import = %d, want %d", again["sessions"], 2*want["sessions"])
+	}
+
+	// The restore point refers to the imported ticket, not the original one
+	var state string
+	err = target.QueryRow(`SELECT state FROM session_snapshots s JOIN tickets t ON t.session_id = s.session_id
+						   WHERE t.title = 'Login' ORDER BY s.id LIMIT 1`).Scan(&state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot snapshotState
+	if err := json.Unmarshal([]byte(state), &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	var loginID int
+	if err := target.QueryRow(`SELECT MIN(id) FROM tickets WHERE title = 'Login'`).Scan(&loginID); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.CurrentTicketID == nil || *snapshot.CurrentTicketID != loginID || len(snapshot.Votes) != 1 || snapshot.Votes[0].TicketID != loginID {
+		t.Errorf("restore point state = %s, want it to refer to ticket %d", state, loginID)
+	}
+}
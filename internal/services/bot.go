@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// AddBot creates a bot user and makes it a participant of the session.
+func (s *SessionService) AddBot(ctx context.Context, sessionID, name string, strategy models.BotStrategy, fixedValue string, delay time.Duration) (*models.Bot, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	userID := uuid.New().String()
+	now := time.Now()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO users (id, username, created_at, last_seen) VALUES (?, ?, ?, ?)`,
+		userID, name, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bot user: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO participants (session_id, user_id, joined_at) VALUES (?, ?, ?)`,
+		sessionID, userID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add bot as participant: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO bots (user_id, session_id, strategy, fixed_value, delay_ms, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		userID, sessionID, strategy, fixedValue, delay.Milliseconds(), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bot: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &models.Bot{
+		User: models.User{
+			ID:        userID,
+			Username:  name,
+			CreatedAt: now,
+			LastSeen:  now,
+			IsBot:     true,
+		},
+		SessionID:  sessionID,
+		Strategy:   strategy,
+		FixedValue: fixedValue,
+		Delay:      delay,
+	}, nil
+}
+
+// GetBots returns the bots taking part in a session.
+func (s *SessionService) GetBots(ctx context.Context, sessionID string) ([]models.Bot, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT u.id, u.username, u.created_at, u.last_seen, b.strategy, b.fixed_value, b.delay_ms
+			  FROM bots b
+			  JOIN users u ON u.id = b.user_id
+			  JOIN participants p ON p.user_id = b.user_id AND p.session_id = b.session_id
+			  WHERE b.session_id = ?
+			  ORDER BY b.created_at`
+
+	rows, err := s.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bots: %w", err)
+	}
+	defer rows.Close()
+
+	var bots []models.Bot
+	for rows.Next() {
+		bot := models.Bot{SessionID: sessionID}
+		var delayMS int64
+		err := rows.Scan(&bot.ID, &bot.Username, &bot.CreatedAt, &bot.LastSeen, &bot.Strategy, &bot.FixedValue, &delayMS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bot: %w", err)
+		}
+		bot.IsBot = true
+		bot.Delay = time.Duration(delayMS) * time.Millisecond
+		bots = append(bots, bot)
+	}
+
+	return bots, rows.Err()
+}
+
+// RemoveBot takes a bot out of a session. The bot user stays behind so its
+// past votes keep a name. It returns false if the session has no such bot.
+func (s *SessionService) RemoveBot(ctx context.Context, sessionID, userID string) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM bots WHERE session_id = ? AND user_id = ?`, sessionID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove bot: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove bot: %w", err)
+	}
+	if removed == 0 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM participants WHERE session_id = ? AND user_id = ?`, sessionID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove bot participant: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
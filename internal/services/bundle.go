@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidBundle refuses a file that is not a session bundle this version
+// can read.
+var ErrInvalidBundle = newError(ErrValidation, "This is not a session bundle; export one from a session's page")
+
+// ExportSession writes one session into an archive of its own, a bundle:
+// the session, its tickets, votes and vote history, its participants and
+// bots, and the users they refer to by name. References to the instance it
+// came from, its project, organization, team and previous session, are left
+// out, so the bundle can be imported anywhere.
+func (s *ArchiveService) ExportSession(ctx context.Context, sessionID string) (*Archive, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	archive := &Archive{Version: ArchiveVersion, ExportedAt: time.Now()}
+	if err := exportSessions(ctx, tx, archive, sessionID); err != nil {
+		return nil, err
+	}
+	if len(archive.Sessions) == 0 {
+		return nil, nil
+	}
+
+	session := &archive.Sessions[0]
+	session.ProjectID = nil
+	session.OrganizationID = nil
+	session.TeamID = nil
+	session.PreviousSessionID = nil
+
+	err = queryRows(ctx, tx, `SELECT id, username, created_at, last_seen FROM users
+							  WHERE id IN (SELECT owner_id FROM sessions WHERE id = ?1
+										   UNION SELECT user_id FROM participants WHERE session_id = ?1
+										   UNION SELECT v.user_id FROM votes v JOIN tickets t ON t.id = v.ticket_id WHERE t.session_id = ?1
+										   UNION SELECT vr.user_id FROM vote_rounds vr JOIN tickets t ON t.id = vr.ticket_id WHERE t.session_id = ?1)
+							  ORDER BY created_at`, func(rows *sql.Rows) error {
+		var user ArchiveUser
+		err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt, &user.LastSeen)
+		archive.Users = append(archive.Users, user)
+		return err
+	}, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export users: %w", err)
+	}
+
+	return archive, nil
+}
+
+// checkBundle refuses a bundle that is not one session whose tickets, votes
+// and vote history all refer to each other and to the bundle's users.
+func checkBundle(bundle *Archive) error {
+	if bundle.Version != ArchiveVersion || len(bundle.Sessions) != 1 {
+		return ErrInvalidBundle
+	}
+	session := bundle.Sessions[0]
+
+	users := make(map[string]bool)
+	for _, user := range bundle.Users {
+		users[user.ID] = true
+	}
+
+	tickets := make(map[int]bool)
+	for _, ticket := range bundle.Tickets {
+		if ticket.SessionID != session.ID || tickets[ticket.ID] {
+			return ErrInvalidBundle
+		}
+		tickets[ticket.ID] = true
+	}
+	for _, ticket := range bundle.Tickets {
+		if ticket.ParentTicketID != nil && !tickets[*ticket.ParentTicketID] {
+			return ErrInvalidBundle
+		}
+	}
+	for _, ticketID := range []*int{session.CurrentTicketID, session.DiscussingTicketID} {
+		if ticketID != nil && !tickets[*ticketID] {
+			return ErrInvalidBundle
+		}
+	}
+
+	voted := make(map[ArchiveVote]bool)
+	for _, vote := range bundle.Votes {
+		key := ArchiveVote{TicketID: vote.TicketID, UserID: vote.UserID}
+		if !tickets[vote.TicketID] || !users[vote.UserID] || voted[key] {
+			return ErrInvalidBundle
+		}
+		voted[key] = true
+	}
+	for _, vote := range bundle.VoteRounds {
+		if !tickets[vote.TicketID] || !users[vote.UserID] {
+			return ErrInvalidBundle
+		}
+	}
+
+	return nil
+}
+
+// ImportSessionBundle recreates a bundle's session as a new session owned by
+// ownerID, who joins it, returning its ID. Everything gets a new ID, even on
+// the instance it came from. Each of the bundle's users, its old owner too,
+// becomes a new placeholder user with the same name, so votes and
+// participants keep who they belong to without touching anyone's account
+// here. The session starts with voting stopped, in orgID if it is set.
+func (s *ArchiveService) ImportSessionBundle(ctx context.Context, bundle *Archive, ownerID string, orgID *string) (string, *ImportResult, error) {
+	if err := checkBundle(bundle); err != nil {
+		return "", nil, err
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	imp := &archiveImport{
+		ctx:      ctx,
+		tx:       tx,
+		fresh:    true,
+		owner:    ownerID,
+		users:    make(map[string]string),
+		orgs:     make(map[string]string),
+		teams:    make(map[string]string),
+		projects: make(map[string]string),
+		sessions: make(map[string]string),
+		tickets:  make(map[int]int),
+	}
+
+	session := bundle.Sessions[0]
+	session.OrganizationID = nil
+	session.IsVotingActive = false
+	bundle.Sessions = []ArchiveSession{session}
+
+	for _, user := range bundle.Users {
+		id := uuid.New().String()
+		_, err := tx.ExecContext(ctx, `INSERT INTO users (id, username, created_at, last_seen) VALUES (?, ?, ?, ?)`,
+			id, user.Username, user.CreatedAt, user.LastSeen)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to import users: %w", err)
+		}
+		imp.users[user.ID] = id
+		imp.result.Users++
+	}
+
+	steps := []struct {
+		name string
+		run  func(*Archive) error
+	}{
+		{"session", imp.importSessions},
+		{"tickets", imp.importTickets},
+		{"votes", imp.importVotes},
+		{"session links", imp.linkSessions},
+	}
+	for _, step := range steps {
+		if err := step.run(bundle); err != nil {
+			return "", nil, fmt.Errorf("failed to import %s: %w", step.name, err)
+		}
+	}
+
+	sessionID := imp.sessions[session.ID]
+	_, err = tx.ExecContext(ctx, `INSERT OR IGNORE INTO participants (session_id, user_id, joined_at) VALUES (?, ?, ?)`,
+		sessionID, ownerID, time.Now())
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to join session: %w", err)
+	}
+
+	if orgID != nil {
+		_, err = tx.ExecContext(ctx, `UPDATE sessions SET organization_id = ? WHERE id = ?`, orgID, sessionID)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to set session organization: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return sessionID, &imp.result, nil
+}
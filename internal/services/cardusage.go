@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetCardUsage counts how often each card was played in a team's sessions,
+// across every round of voting. Pre-votes and calibration stories are left
+// out.
+func (s *TeamService) GetCardUsage(ctx context.Context, teamID string) (map[string]int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT v.vote_value, COUNT(*)
+			  FROM (SELECT ticket_id, vote_value FROM votes
+					UNION ALL
+					SELECT ticket_id, vote_value FROM vote_rounds WHERE round != ?) v
+			  JOIN tickets t ON t.id = v.ticket_id
+			  JOIN sessions s ON s.id = t.session_id
+			  WHERE s.team_id = ? AND t.is_calibration = 0
+			  GROUP BY v.vote_value`
+	rows, err := s.db.QueryContext(ctx, query, PrevoteRound, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get card usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := make(map[string]int)
+	for rows.Next() {
+		var card string
+		var count int
+		if err := rows.Scan(&card, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan card usage: %w", err)
+		}
+		usage[card] = count
+	}
+
+	return usage, rows.Err()
+}
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// queryTimeout bounds the database work of a single service call, on top of
+// any deadline the caller's context already carries.
+const queryTimeout = 5 * time.Second
+
+func withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, queryTimeout)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/description"
+	"poker-planning/internal/models"
+)
+
+// MaxDescriptionTemplates is how many description templates a team can have.
+const MaxDescriptionTemplates = 20
+
+const templateColumns = `id, team_id, name, sections, created_by, created_at`
+
+func scanTemplate(row rowScanner, template *models.DescriptionTemplate) error {
+	var sections string
+	if err := row.Scan(&template.ID, &template.TeamID, &template.Name, &sections, &template.CreatedBy, &template.CreatedAt); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(sections), &template.Sections); err != nil {
+		return fmt.Errorf("invalid sections of description template %d: %w", template.ID, err)
+	}
+	return nil
+}
+
+// GetDescriptionTemplates returns a team's ticket description templates in
+// the order they were added.
+func (s *TeamService) GetDescriptionTemplates(ctx context.Context, teamID string) ([]models.DescriptionTemplate, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+templateColumns+` FROM team_description_templates WHERE team_id = ? ORDER BY id`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get description templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []models.DescriptionTemplate{}
+	for rows.Next() {
+		var template models.DescriptionTemplate
+		if err := scanTemplate(rows, &template); err != nil {
+			return nil, fmt.Errorf("failed to scan description template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+	return templates, rows.Err()
+}
+
+// GetDescriptionTemplate returns one of a team's description templates, or
+// nil if the team has no such template.
+func (s *TeamService) GetDescriptionTemplate(ctx context.Context, teamID string, templateID int) (*models.DescriptionTemplate, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var template models.DescriptionTemplate
+	row := s.db.QueryRowContext(ctx, `SELECT `+templateColumns+` FROM team_description_templates WHERE id = ? AND team_id = ?`, templateID, teamID)
+	if err := scanTemplate(row, &template); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get description template: %w", err)
+	}
+	return &template, nil
+}
+
+// AddDescriptionTemplate adds a description template to a team. The
+// sections must have passed description.Validate.
+func (s *TeamService) AddDescriptionTemplate(ctx context.Context, teamID, name string, sections []description.Section, userID string) (*models.DescriptionTemplate, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM team_description_templates WHERE team_id = ?`, teamID).Scan(&count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count description templates: %w", err)
+	}
+	if count >= MaxDescriptionTemplates {
+		return nil, ErrTemplatesFull
+	}
+
+	data, err := json.Marshal(sections)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sections: %w", err)
+	}
+
+	template := &models.DescriptionTemplate{
+		TeamID:    teamID,
+		Name:      name,
+		Sections:  sections,
+		CreatedBy: &userID,
+		CreatedAt: time.Now(),
+	}
+
+	result, err := s.db.ExecContext(ctx, `INSERT INTO team_description_templates (team_id, name, sections, created_by, created_at)
+										  VALUES (?, ?, ?, ?, ?)`,
+		teamID, name, string(data), userID, template.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add description template: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get description template ID: %w", err)
+	}
+	template.ID = int(id)
+
+	return template, nil
+}
+
+// DeleteDescriptionTemplate removes one of a team's description templates.
+// Tickets written from it keep their descriptions.
+func (s *TeamService) DeleteDescriptionTemplate(ctx context.Context, teamID string, templateID int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM team_description_templates WHERE id = ? AND team_id = ?`, templateID, teamID)
+	if err != nil {
+		return fmt.Errorf("failed to delete description template: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrTemplateNotFound
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import "errors"
+
+// Error kinds. Every error a service returns for a request it refuses, rather
+// than one it failed to carry out, wraps one of these so handlers can pick
+// the HTTP status with errors.Is.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrForbidden  = errors.New("forbidden")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("invalid input")
+)
+
+// Error is a refused request. Message is written for the end user and is
+// safe to show as-is.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func newError(kind error, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Specific refusals, for callers that need to tell them apart from others of
+// the same kind.
+var (
+	ErrTicketNotFound         = newError(ErrNotFound, "Ticket not found")
+	ErrSessionModified        = newError(ErrConflict, "The session was changed by someone else; reload and try again")
+	ErrNotParticipant         = newError(ErrForbidden, "Not a session participant")
+	ErrNoActiveTicket         = newError(ErrValidation, "No active ticket")
+	ErrVotingNotActive        = newError(ErrConflict, "Voting has not been started for this ticket")
+	ErrMemberNotFound         = newError(ErrNotFound, "Member not found")
+	ErrLastAdmin              = newError(ErrConflict, "An organization needs at least one admin")
+	ErrVotesLocked            = newError(ErrConflict, "Votes on this ticket can no longer be changed")
+	ErrHookNotFound           = newError(ErrNotFound, "Hook subscription not found")
+	ErrNoAgenda               = newError(ErrNotFound, "This session has no agenda")
+	ErrAgendaStarted          = newError(ErrConflict, "The agenda has started; clear it to plan a new one")
+	ErrAgendaFinished         = newError(ErrConflict, "The agenda is finished")
+	ErrReferenceNotFound      = newError(ErrNotFound, "Reference story not found")
+	ErrTemplateNotFound       = newError(ErrNotFound, "Description template not found")
+	ErrTemplatesFull          = newError(ErrConflict, "This team has too many description templates; remove ones it no longer uses")
+	ErrPrevotingClosed        = newError(ErrConflict, "This ticket is not open for pre-votes")
+	ErrParkingLotItemNotFound = newError(ErrNotFound, "Parking lot item not found")
+	ErrParkingLotFull         = newError(ErrConflict, "The parking lot is full; remove items that were followed up")
+	ErrActionItemNotFound     = newError(ErrNotFound, "Action item not found")
+	ErrActionItemsFull        = newError(ErrConflict, "This session has too many action items")
+	ErrAssigneeNotParticipant = newError(ErrValidation, "Action items can only be assigned to session participants")
+	ErrSnapshotNotFound       = newError(ErrNotFound, "Restore point not found")
+	ErrSnapshotsFull          = newError(ErrConflict, "This session has too many restore points; delete ones you no longer need")
+	ErrNotInBreakout          = newError(ErrForbidden, "This ticket is being voted on by a breakout group you are not in")
+)
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Session event types in the event log.
+const (
+	EventVoteCast         = "vote-cast"
+	EventVotingStarted    = "voting-started"
+	EventVotesRevealed    = "votes-revealed"
+	EventTicketCreated    = "ticket-created"
+	EventTicketUpdated    = "ticket-updated"
+	EventTicketSplit      = "ticket-split"
+	EventTicketNeedsSplit = "ticket-needs-split"
+	EventTicketDeleted    = "ticket-deleted"
+	EventTicketsDeleted   = "tickets-deleted"
+	EventTicketSelected   = "ticket-selected"
+	EventTicketReopened   = "ticket-reopened"
+	EventEstimateAccepted = "estimate-accepted"
+	EventAgendaAdvanced   = "agenda-advanced"
+	EventPrevoteCast      = "prevote-cast"
+	EventActionItemAdded  = "action-item-added"
+	EventDriverChanged    = "driver-changed"
+	EventSessionRestored  = "session-restored"
+)
+
+// EventTypes lists every event type, for subscribing to them.
+var EventTypes = []string{
+	EventVoteCast, EventVotingStarted, EventVotesRevealed, EventTicketCreated, EventTicketUpdated, EventTicketSplit,
+	EventTicketNeedsSplit, EventTicketDeleted, EventTicketsDeleted, EventTicketSelected, EventTicketReopened, EventEstimateAccepted,
+	EventAgendaAdvanced, EventPrevoteCast, EventActionItemAdded, EventDriverChanged, EventSessionRestored,
+}
+
+func IsEventType(value string) bool {
+	for _, eventType := range EventTypes {
+		if eventType == value {
+			return true
+		}
+	}
+	return false
+}
+
+// EventService keeps each session's event log for analytics.
+type EventService struct {
+	db *sql.DB
+}
+
+func NewEventService(db *sql.DB) *EventService {
+	return &EventService{db: db}
+}
+
+// RecordEvent appends an event to a session's log and returns it. ticketID
+// is 0 and userID empty when the event has none; data is stored as JSON.
+func (s *EventService) RecordEvent(ctx context.Context, sessionID, eventType string, ticketID int, userID string, data interface{}) (*models.SessionEvent, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	encoded := []byte("{}")
+	if data != nil {
+		var err error
+		if encoded, err = json.Marshal(data); err != nil {
+			return nil, fmt.Errorf("failed to encode event data: %w", err)
+		}
+	}
+
+	var ticket *int
+	if ticketID != 0 {
+		ticket = &ticketID
+	}
+	var user *string
+	if userID != "" {
+		user = &userID
+	}
+
+	query := `INSERT INTO session_events (session_id, type, ticket_id, user_id, data, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?)`
+	now := time.Now()
+	result, err := s.db.ExecContext(ctx, query, sessionID, eventType, ticket, user, string(encoded), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event ID: %w", err)
+	}
+
+	return &models.SessionEvent{
+		ID:        id,
+		SessionID: sessionID,
+		Type:      eventType,
+		TicketID:  ticket,
+		UserID:    user,
+		Data:      json.RawMessage(encoded),
+		CreatedAt: now,
+	}, nil
+}
+
+// EachEvent calls fn with the session's events after the given event ID,
+// oldest first, without loading the whole log into memory. It stops at the
+// first error fn returns. The log can be long, so only ctx bounds the query.
+func (s *EventService) EachEvent(ctx context.Context, sessionID string, afterID int64, fn func(models.SessionEvent) error) error {
+	query := `SELECT id, session_id, type, ticket_id, user_id, data, created_at
+			  FROM session_events
+			  WHERE session_id = ? AND id > ?
+			  ORDER BY id`
+
+	rows, err := s.db.QueryContext(ctx, query, sessionID, afterID)
+	if err != nil {
+		return fmt.Errorf("failed to get events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event models.SessionEvent
+		var data string
+		err := rows.Scan(&event.ID, &event.SessionID, &event.Type, &event.TicketID, &event.UserID, &data, &event.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to scan event: %w", err)
+		}
+		event.Data = json.RawMessage(data)
+
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// RecentEvents returns the latest events of a type across all sessions,
+// newest first, as samples for no-code tools setting up a hook.
+func (s *EventService) RecentEvents(ctx context.Context, eventType string, limit int) ([]models.SessionEvent, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, session_id, type, ticket_id, user_id, data, created_at
+			  FROM session_events
+			  WHERE type = ?
+			  ORDER BY id DESC
+			  LIMIT ?`
+
+	rows, err := s.db.QueryContext(ctx, query, eventType, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.SessionEvent{}
+	for rows.Next() {
+		var event models.SessionEvent
+		var data string
+		err := rows.Scan(&event.ID, &event.SessionID, &event.Type, &event.TicketID, &event.UserID, &data, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		event.Data = json.RawMessage(data)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// EventsSince returns up to limit of the session's events after the given
+// event ID, oldest first, for clients that poll for changes.
+func (s *EventService) EventsSince(ctx context.Context, sessionID string, afterID int64, limit int) ([]models.SessionEvent, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, session_id, type, ticket_id, user_id, data, created_at
+			  FROM session_events
+			  WHERE session_id = ? AND id > ?
+			  ORDER BY id
+			  LIMIT ?`
+
+	rows, err := s.db.QueryContext(ctx, query, sessionID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.SessionEvent{}
+	for rows.Next() {
+		var event models.SessionEvent
+		var data string
+		err := rows.Scan(&event.ID, &event.SessionID, &event.Type, &event.TicketID, &event.UserID, &data, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		event.Data = json.RawMessage(data)
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// LatestEventID returns the ID of the session's newest event, or 0 if it has
+// none yet.
+func (s *EventService) LatestEventID(ctx context.Context, sessionID string) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var id int64
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM session_events WHERE session_id = ?`, sessionID).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest event: %w", err)
+	}
+	return id, nil
+}
+
+// CreateHook subscribes targetURL to an event type, in every session or,
+// when sessionID is set, in that one.
+func (s *EventService) CreateHook(ctx context.Context, eventType, targetURL, sessionID string) (*models.HookSubscription, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	hook := &models.HookSubscription{
+		ID:        uuid.New().String(),
+		Event:     eventType,
+		TargetURL: targetURL,
+		CreatedAt: time.Now(),
+	}
+	if sessionID != "" {
+		hook.SessionID = &sessionID
+	}
+
+	query := `INSERT INTO hook_subscriptions (id, event, target_url, session_id, created_at) VALUES (?, ?, ?, ?, ?)`
+	_, err := s.db.ExecContext(ctx, query, hook.ID, hook.Event, hook.TargetURL, hook.SessionID, hook.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hook: %w", err)
+	}
+
+	return hook, nil
+}
+
+// ListHooks returns every hook subscription, oldest first.
+func (s *EventService) ListHooks(ctx context.Context) ([]models.HookSubscription, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return s.queryHooks(ctx, `SELECT id, event, target_url, session_id, created_at
+							  FROM hook_subscriptions ORDER BY created_at, id`)
+}
+
+// HooksFor returns the subscriptions to an event in a session.
+func (s *EventService) HooksFor(ctx context.Context, eventType, sessionID string) ([]models.HookSubscription, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return s.queryHooks(ctx, `SELECT id, event, target_url, session_id, created_at
+							  FROM hook_subscriptions
+							  WHERE event = ? AND (session_id IS NULL OR session_id = ?)`, eventType, sessionID)
+}
+
+func (s *EventService) queryHooks(ctx context.Context, query string, args ...interface{}) ([]models.HookSubscription, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hooks: %w", err)
+	}
+	defer rows.Close()
+
+	hooks := []models.HookSubscription{}
+	for rows.Next() {
+		var hook models.HookSubscription
+		if err := rows.Scan(&hook.ID, &hook.Event, &hook.TargetURL, &hook.SessionID, &hook.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan hook: %w", err)
+		}
+		hooks = append(hooks, hook)
+	}
+
+	return hooks, rows.Err()
+}
+
+// DeleteHook removes a hook subscription.
+func (s *EventService) DeleteHook(ctx context.Context, hookID string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM hook_subscriptions WHERE id = ?`, hookID)
+	if err != nil {
+		return fmt.Errorf("failed to delete hook: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete hook: %w", err)
+	} else if deleted == 0 {
+		return ErrHookNotFound
+	}
+	return nil
+}
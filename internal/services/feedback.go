@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+// feedbackColumns aggregates session_feedback rows, grouped by session, in
+// the order scanFeedbackSummary reads them.
+const feedbackColumns = `COUNT(*), AVG(f.rating), SUM(f.rating = 1), SUM(f.rating = 2), SUM(f.rating = 3), SUM(f.rating = 4), SUM(f.rating = 5)`
+
+func scanFeedbackSummary(row rowScanner, summary *models.FeedbackSummary, extra ...interface{}) error {
+	dest := append(extra, &summary.Responses, &summary.Average,
+		&summary.Ratings[0], &summary.Ratings[1], &summary.Ratings[2], &summary.Ratings[3], &summary.Ratings[4])
+	return row.Scan(dest...)
+}
+
+// SubmitFeedback records a participant's 1-5 rating of a session and an
+// optional comment. Rating again replaces their earlier feedback.
+func (s *SessionService) SubmitFeedback(ctx context.Context, sessionID, userID string, rating int, comment string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var isParticipant bool
+	participantQuery := `SELECT EXISTS(SELECT 1 FROM participants WHERE session_id = ? AND user_id = ?)`
+	if err := tx.QueryRowContext(ctx, participantQuery, sessionID, userID).Scan(&isParticipant); err != nil {
+		return fmt.Errorf("failed to check participant: %w", err)
+	}
+	if !isParticipant {
+		return ErrNotParticipant
+	}
+
+	query := `INSERT INTO session_feedback (session_id, user_id, rating, comment, created_at)
+			  VALUES (?, ?, ?, ?, ?)
+			  ON CONFLICT(session_id, user_id) DO UPDATE SET rating = excluded.rating, comment = excluded.comment, created_at = excluded.created_at`
+	if _, err := tx.ExecContext(ctx, query, sessionID, userID, rating, comment, time.Now()); err != nil {
+		return fmt.Errorf("failed to save feedback: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// HasFeedback reports whether a user has rated a session.
+func (s *SessionService) HasFeedback(ctx context.Context, sessionID, userID string) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+	err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM session_feedback WHERE session_id = ? AND user_id = ?)`,
+		sessionID, userID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check feedback: %w", err)
+	}
+	return exists, nil
+}
+
+// GetFeedbackSummary aggregates a session's feedback anonymously. It returns
+// nil if nobody has rated the session.
+func (s *SessionService) GetFeedbackSummary(ctx context.Context, sessionID string) (*models.FeedbackSummary, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	summary := models.FeedbackSummary{SessionID: sessionID}
+	query := `SELECT s.name, s.created_at, ` + feedbackColumns + `
+			  FROM session_feedback f
+			  JOIN sessions s ON s.id = f.session_id
+			  WHERE f.session_id = ?
+			  GROUP BY s.id`
+	err := scanFeedbackSummary(s.db.QueryRowContext(ctx, query, sessionID), &summary, &summary.SessionName, &summary.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT comment FROM session_feedback
+										 WHERE session_id = ? AND comment != ''
+										 ORDER BY comment`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback comments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var comment string
+		if err := rows.Scan(&comment); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback comment: %w", err)
+		}
+		summary.Comments = append(summary.Comments, comment)
+	}
+
+	return &summary, rows.Err()
+}
+
+// GetFeedbackTrend returns the feedback of each of a team's rated sessions,
+// oldest first and without comments, to see whether its sessions are
+// getting better.
+func (s *TeamService) GetFeedbackTrend(ctx context.Context, teamID string) ([]models.FeedbackSummary, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT s.id, s.name, s.created_at, ` + feedbackColumns + `
+			  FROM session_feedback f
+			  JOIN sessions s ON s.id = f.session_id
+			  WHERE s.team_id = ?
+			  GROUP BY s.id
+			  ORDER BY s.created_at`
+	rows, err := s.db.QueryContext(ctx, query, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feedback trend: %w", err)
+	}
+	defer rows.Close()
+
+	trend := []models.FeedbackSummary{}
+	for rows.Next() {
+		var summary models.FeedbackSummary
+		if err := scanFeedbackSummary(rows, &summary, &summary.SessionID, &summary.SessionName, &summary.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan feedback: %w", err)
+		}
+		trend = append(trend, summary)
+	}
+
+	return trend, rows.Err()
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+// LoginSessionTTL is how long a sign-in lasts without activity.
+const LoginSessionTTL = 6 * time.Hour
+
+func hashLoginToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateLoginSession signs a user in and returns the opaque token for their
+// session cookie. User IDs are shown to everyone in a session, so users
+// whose identity matters, like directory users, must not be identified by
+// them. Expired sign-ins are cleared on the way.
+func (s *UserService) CreateLoginSession(ctx context.Context, userID string) (string, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate login token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(secret)
+
+	now := time.Now()
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM login_sessions WHERE expires_at < ?`, now); err != nil {
+		return "", fmt.Errorf("failed to clear expired login sessions: %w", err)
+	}
+
+	query := `INSERT INTO login_sessions (token_hash, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)`
+	if _, err := s.db.ExecContext(ctx, query, hashLoginToken(token), userID, now, now.Add(LoginSessionTTL)); err != nil {
+		return "", fmt.Errorf("failed to create login session: %w", err)
+	}
+	return token, nil
+}
+
+// GetUserByLoginSession returns the user signed in with token and extends
+// the sign-in, or nil if the token is unknown or expired.
+func (s *UserService) GetUserByLoginSession(ctx context.Context, token string) (*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	hash := hashLoginToken(token)
+	now := time.Now()
+	var userID string
+	err := s.db.QueryRowContext(ctx, `SELECT user_id FROM login_sessions WHERE token_hash = ? AND expires_at > ?`, hash, now).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login session: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE login_sessions SET expires_at = ? WHERE token_hash = ?`, now.Add(LoginSessionTTL), hash); err != nil {
+		return nil, fmt.Errorf("failed to extend login session: %w", err)
+	}
+	return s.GetUserByID(ctx, userID)
+}
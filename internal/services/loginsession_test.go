@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"poker-planning/internal/database"
+)
+
+// newTestDB opens a fresh, migrated database for a test.
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestLoginSession(t *testing.T) {
+	ctx := context.Background()
+	users := NewUserService(newTestDB(t).DB)
+
+	user, err := users.CreateUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := users.CreateLoginSession(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := users.GetUserByLoginSession(ctx, token)
+	if err != nil || got == nil || got.ID != user.ID {
+		t.Fatalf("GetUserByLoginSession(token) = %v, %v, want %s", got, err, user.ID)
+	}
+
+	// The user ID every session shows must not sign anyone in
+	for _, forged := range []string{user.ID, hashLoginToken(token), ""} {
+		if got, err := users.GetUserByLoginSession(ctx, forged); err != nil || got != nil {
+			t.Errorf("GetUserByLoginSession(%q) = %v, %v, want nobody", forged, got, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+// GetFacilitatorNotes returns the owner's private notes on a session and its
+// tickets. Only ever show them to the owner.
+func (s *SessionService) GetFacilitatorNotes(ctx context.Context, sessionID string) (*models.FacilitatorNotes, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT ticket_id, text FROM facilitator_notes WHERE session_id = ?`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get facilitator notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := &models.FacilitatorNotes{Tickets: make(map[int]string)}
+	for rows.Next() {
+		var ticketID sql.NullInt64
+		var text string
+		if err := rows.Scan(&ticketID, &text); err != nil {
+			return nil, fmt.Errorf("failed to scan facilitator note: %w", err)
+		}
+		if ticketID.Valid {
+			notes.Tickets[int(ticketID.Int64)] = text
+		} else {
+			notes.Session = text
+		}
+	}
+
+	return notes, rows.Err()
+}
+
+// SetFacilitatorNote saves the owner's private note on a session, or on one
+// of its tickets when ticketID is set. An empty note removes it.
+func (s *SessionService) SetFacilitatorNote(ctx context.Context, sessionID string, ticketID *int, text string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if ticketID != nil {
+		var exists bool
+		query := `SELECT EXISTS(SELECT 1 FROM tickets WHERE id = ? AND session_id = ?)`
+		if err := tx.QueryRowContext(ctx, query, *ticketID, sessionID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check ticket: %w", err)
+		}
+		if !exists {
+			return ErrTicketNotFound
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM facilitator_notes WHERE session_id = ? AND ticket_id IS ?`, sessionID, ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to clear facilitator note: %w", err)
+	}
+	if text != "" {
+		_, err = tx.ExecContext(ctx, `INSERT INTO facilitator_notes (session_id, ticket_id, text, updated_at) VALUES (?, ?, ?, ?)`,
+			sessionID, ticketID, text, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to save facilitator note: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
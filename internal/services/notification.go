@@ -0,0 +1,59 @@
+package services
+
+// Notification levels suggest how a client announces a message: not at all,
+// with a subtle hint like flashing the tab title, or with a chime.
+const (
+	NotifySilent = "silent"
+	NotifySubtle = "subtle"
+	NotifyChime  = "chime"
+)
+
+// NotificationLevels lists the levels from quietest to loudest.
+var NotificationLevels = []string{NotifySilent, NotifySubtle, NotifyChime}
+
+// messageNotifications is how loudly each WebSocket message type should be
+// announced to someone who wants every hint. Types not listed are silent.
+var messageNotifications = map[string]string{
+	"voting-started":    NotifyChime,
+	"voting-ended":      NotifyChime,
+	"nudge":             NotifyChime,
+	"session-ended":     NotifyChime,
+	"vote-cast":         NotifySubtle,
+	"user-joined":       NotifySubtle,
+	"user-left":         NotifySubtle,
+	"emoji-reaction":    NotifySubtle,
+	"discussion-prompt": NotifySubtle,
+	"agenda-advanced":   NotifySubtle,
+	"ticket-created":    NotifySubtle,
+	"tickets-imported":  NotifySubtle,
+	"prevote-cast":      NotifySubtle,
+}
+
+// IsNotificationLevel reports whether level is a known notification level.
+// The empty level is not one; preferences store it for the default.
+func IsNotificationLevel(level string) bool {
+	return notificationRank(level) >= 0
+}
+
+// NotificationLevel returns how loudly a message of messageType should be
+// announced to a user whose preference caps the level at preference. An
+// empty preference allows every level.
+func NotificationLevel(messageType, preference string) string {
+	level, ok := messageNotifications[messageType]
+	if !ok {
+		return NotifySilent
+	}
+	if rank := notificationRank(preference); rank >= 0 && rank < notificationRank(level) {
+		return preference
+	}
+	return level
+}
+
+func notificationRank(level string) int {
+	for i, known := range NotificationLevels {
+		if known == level {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"poker-planning/internal/models"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/google/uuid"
+)
+
+// pushTTL is how long push services hold a notification for a device that
+// is offline. A voting round is long over after ten minutes.
+const pushTTL = 10 * 60
+
+// pushServiceHosts are the push services of the major browsers; a leading
+// dot matches any subdomain. Endpoints anywhere else are refused, so a
+// subscription cannot make the server send requests to an address of its
+// choosing, such as one on the private network.
+var pushServiceHosts = []string{
+	"fcm.googleapis.com",                // Chrome and other Chromium browsers
+	"android.googleapis.com",            // older Chrome subscriptions
+	"updates.push.services.mozilla.com", // Firefox
+	"web.push.apple.com",                // Safari
+	".notify.windows.com",               // Edge on Windows
+}
+
+var (
+	ErrInvalidPushEndpoint = newError(ErrValidation, "Push subscriptions must come from a browser's push service")
+	ErrPushEndpointTaken   = newError(ErrConflict, "This browser is subscribed to push notifications for someone else")
+)
+
+// validPushEndpoint reports whether a push subscription's endpoint is an
+// https URL on one of the pushServiceHosts, without an IP address, port or
+// credentials.
+func validPushEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.User != nil || u.Port() != "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if net.ParseIP(host) != nil {
+		return false
+	}
+	for _, allowed := range pushServiceHosts {
+		if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// VAPIDKeys identify this instance to push services. Subject is a contact
+// e-mail address or https URL for the push service operators.
+type VAPIDKeys struct {
+	PublicKey  string
+	PrivateKey string
+	Subject    string
+}
+
+// NotifyService keeps users' Web Push subscriptions and sends them push
+// notifications.
+type NotifyService struct {
+	db   *sql.DB
+	keys VAPIDKeys
+}
+
+func NewNotifyService(db *sql.DB, keys VAPIDKeys) *NotifyService {
+	// webpush-go adds the mailto: scheme itself
+	keys.Subject = strings.TrimPrefix(keys.Subject, "mailto:")
+	return &NotifyService{db: db, keys: keys}
+}
+
+// Enabled reports whether VAPID keys are configured, without which nothing
+// can be pushed.
+func (s *NotifyService) Enabled() bool {
+	return s.keys.PublicKey != "" && s.keys.PrivateKey != ""
+}
+
+// PublicKey is the VAPID public key browsers subscribe with.
+func (s *NotifyService) PublicKey() string {
+	return s.keys.PublicKey
+}
+
+// Subscribe stores a browser's push subscription for a user. A browser that
+// subscribes again replaces its keys, but one subscribed for someone else
+// is refused with ErrPushEndpointTaken until they unsubscribe.
+func (s *NotifyService) Subscribe(ctx context.Context, userID, endpoint, p256dh, auth string) error {
+	if !validPushEndpoint(endpoint) {
+		return ErrInvalidPushEndpoint
+	}
+
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT INTO push_subscriptions (id, user_id, endpoint, p256dh, auth, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?)
+			  ON CONFLICT(endpoint) DO UPDATE SET p256dh = excluded.p256dh, auth = excluded.auth
+			  WHERE push_subscriptions.user_id = excluded.user_id`
+	result, err := s.db.ExecContext(ctx, query, uuid.New().String(), userID, endpoint, p256dh, auth, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to save push subscription: %w", err)
+	}
+	saved, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to save push subscription: %w", err)
+	}
+	if saved == 0 {
+		return ErrPushEndpointTaken
+	}
+	return nil
+}
+
+// Unsubscribe removes a user's push subscription.
+func (s *NotifyService) Unsubscribe(ctx context.Context, userID, endpoint string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `DELETE FROM push_subscriptions WHERE user_id = ? AND endpoint = ?`, userID, endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	return nil
+}
+
+// Subscriptions returns a user's push subscriptions.
+func (s *NotifyService) Subscriptions(ctx context.Context, userID string) ([]models.PushSubscription, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, endpoint, p256dh, auth, created_at
+										 FROM push_subscriptions WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get push subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []models.PushSubscription{}
+	for rows.Next() {
+		var subscription models.PushSubscription
+		if err := rows.Scan(&subscription.ID, &subscription.UserID, &subscription.Endpoint, &subscription.P256dh, &subscription.Auth, &subscription.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan push subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// Notify pushes a notification to every browser a user subscribed. Push
+// services answer 404 or 410 for subscriptions that are gone, which are
+// deleted.
+func (s *NotifyService) Notify(ctx context.Context, userID string, notification models.PushNotification) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	subscriptions, err := s.Subscriptions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode push notification: %w", err)
+	}
+
+	var errs []error
+	for _, subscription := range subscriptions {
+		// Subscriptions saved before endpoints were checked
+		if !validPushEndpoint(subscription.Endpoint) {
+			continue
+		}
+
+		resp, err := webpush.SendNotificationWithContext(ctx, payload, &webpush.Subscription{
+			Endpoint: subscription.Endpoint,
+			Keys:     webpush.Keys{P256dh: subscription.P256dh, Auth: subscription.Auth},
+		}, &webpush.Options{
+			Subscriber:      s.keys.Subject,
+			VAPIDPublicKey:  s.keys.PublicKey,
+			VAPIDPrivateKey: s.keys.PrivateKey,
+			TTL:             pushTTL,
+			Urgency:         webpush.UrgencyHigh,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to push to %s: %w", subscription.ID, err))
+			continue
+		}
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+			if err := s.Unsubscribe(ctx, userID, subscription.Endpoint); err != nil {
+				errs = append(errs, err)
+			}
+		case resp.StatusCode/100 != 2:
+			errs = append(errs, fmt.Errorf("push to %s returned %s", subscription.ID, resp.Status))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type OrganizationService struct {
+	db *sql.DB
+}
+
+func NewOrganizationService(db *sql.DB) *OrganizationService {
+	return &OrganizationService{db: db}
+}
+
+func newInviteCode() string {
+	code := make([]byte, 12)
+	rand.Read(code)
+	return hex.EncodeToString(code)
+}
+
+// CreateOrganization creates an organization with its creator as the first
+// admin.
+func (s *OrganizationService) CreateOrganization(ctx context.Context, name, creatorID string) (*models.Organization, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	org := &models.Organization{
+		ID:         uuid.New().String(),
+		Name:       name,
+		InviteCode: newInviteCode(),
+		CreatedAt:  time.Now(),
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO organizations (id, name, invite_code, created_at) VALUES (?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, query, org.ID, org.Name, org.InviteCode, org.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	memberQuery := `INSERT INTO organization_members (organization_id, user_id, role, joined_at) VALUES (?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, memberQuery, org.ID, creatorID, models.RoleAdmin, org.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add organization admin: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return org, nil
+}
+
+// GetOrganizationByID loads an organization with its members, admins first.
+func (s *OrganizationService) GetOrganizationByID(ctx context.Context, orgID string) (*models.Organization, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return s.getOrganization(ctx, `WHERE id = ?`, orgID)
+}
+
+// GetOrganizationByInviteCode finds the organization an invite link is for.
+func (s *OrganizationService) GetOrganizationByInviteCode(ctx context.Context, code string) (*models.Organization, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return s.getOrganization(ctx, `WHERE invite_code = ?`, code)
+}
+
+func (s *OrganizationService) getOrganization(ctx context.Context, where string, arg string) (*models.Organization, error) {
+	var org models.Organization
+	query := `SELECT id, name, invite_code, created_at FROM organizations ` + where
+
+	err := s.db.QueryRowContext(ctx, query, arg).Scan(&org.ID, &org.Name, &org.InviteCode, &org.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	members, err := s.getMembers(ctx, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization members: %w", err)
+	}
+	org.Members = members
+
+	return &org, nil
+}
+
+func (s *OrganizationService) getMembers(ctx context.Context, orgID string) ([]models.OrganizationMember, error) {
+	query := `SELECT u.id, u.username, u.created_at, u.last_seen, m.role, m.joined_at
+			  FROM organization_members m
+			  JOIN users u ON u.id = m.user_id
+			  WHERE m.organization_id = ?
+			  ORDER BY m.role = 'admin' DESC, u.username`
+
+	rows, err := s.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []models.OrganizationMember
+	for rows.Next() {
+		var member models.OrganizationMember
+		err := rows.Scan(&member.ID, &member.Username, &member.CreatedAt, &member.LastSeen, &member.Role, &member.JoinedAt)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// GetOrganizationsForUser lists the organizations a user belongs to, without
+// their members.
+func (s *OrganizationService) GetOrganizationsForUser(ctx context.Context, userID string) ([]models.Organization, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT o.id, o.name, o.created_at
+			  FROM organizations o
+			  JOIN organization_members m ON m.organization_id = o.id
+			  WHERE m.user_id = ?
+			  ORDER BY o.name`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organizations: %w", err)
+	}
+	defer rows.Close()
+
+	var orgs []models.Organization
+	for rows.Next() {
+		var org models.Organization
+		if err := rows.Scan(&org.ID, &org.Name, &org.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+
+	return orgs, rows.Err()
+}
+
+// GetMemberRole returns a user's role in an organization, or "" if they are
+// not a member.
+func (s *OrganizationService) GetMemberRole(ctx context.Context, orgID, userID string) (models.OrganizationRole, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var role models.OrganizationRole
+	query := `SELECT role FROM organization_members WHERE organization_id = ? AND user_id = ?`
+	err := s.db.QueryRowContext(ctx, query, orgID, userID).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get member role: %w", err)
+	}
+	return role, nil
+}
+
+// AddMember adds a user to an organization. It reports false if they
+// already were a member.
+func (s *OrganizationService) AddMember(ctx context.Context, orgID, userID string, role models.OrganizationRole) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT OR IGNORE INTO organization_members (organization_id, user_id, role, joined_at) VALUES (?, ?, ?, ?)`
+	result, err := s.db.ExecContext(ctx, query, orgID, userID, role, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to add member: %w", err)
+	}
+
+	added, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to add member: %w", err)
+	}
+	return added > 0, nil
+}
+
+// SetMemberRole changes a member's role. The last admin cannot step down.
+func (s *OrganizationService) SetMemberRole(ctx context.Context, orgID, userID string, role models.OrganizationRole) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if role != models.RoleAdmin {
+		if err := ensureOtherAdmin(ctx, tx, orgID, userID); err != nil {
+			return err
+		}
+	}
+
+	query := `UPDATE organization_members SET role = ? WHERE organization_id = ? AND user_id = ?`
+	result, err := tx.ExecContext(ctx, query, role, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set member role: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to set member role: %w", err)
+	} else if updated == 0 {
+		return ErrMemberNotFound
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember takes a user out of an organization, along with their seats
+// in its sessions that they do not own and their places on its teams. The
+// last admin cannot be removed.
+func (s *OrganizationService) RemoveMember(ctx context.Context, orgID, userID string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := ensureOtherAdmin(ctx, tx, orgID, userID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM organization_members WHERE organization_id = ? AND user_id = ?`, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	if removed, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	} else if removed == 0 {
+		return ErrMemberNotFound
+	}
+
+	query := `DELETE FROM participants
+			  WHERE user_id = ? AND session_id IN (SELECT id FROM sessions WHERE organization_id = ? AND owner_id != ?)`
+	_, err = tx.ExecContext(ctx, query, userID, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove member from sessions: %w", err)
+	}
+
+	teamQuery := `DELETE FROM team_members
+				  WHERE user_id = ? AND team_id IN (SELECT id FROM teams WHERE organization_id = ?)`
+	_, err = tx.ExecContext(ctx, teamQuery, userID, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to remove member from teams: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ensureOtherAdmin fails with a conflict when userID is the organization's
+// only admin, so it always keeps someone who can manage it.
+func ensureOtherAdmin(ctx context.Context, tx *sql.Tx, orgID, userID string) error {
+	var others int
+	query := `SELECT COUNT(*) FROM organization_members WHERE organization_id = ? AND role = ? AND user_id != ?`
+	err := tx.QueryRowContext(ctx, query, orgID, models.RoleAdmin, userID).Scan(&others)
+	if err != nil {
+		return fmt.Errorf("failed to count admins: %w", err)
+	}
+
+	var role models.OrganizationRole
+	err = tx.QueryRowContext(ctx, `SELECT role FROM organization_members WHERE organization_id = ? AND user_id = ?`, orgID, userID).Scan(&role)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get member role: %w", err)
+	}
+
+	if role == models.RoleAdmin && others == 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// RegenerateInviteCode replaces the invite link, so the old one stops
+// working.
+func (s *OrganizationService) RegenerateInviteCode(ctx context.Context, orgID string) (string, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	code := newInviteCode()
+	_, err := s.db.ExecContext(ctx, `UPDATE organizations SET invite_code = ? WHERE id = ?`, code, orgID)
+	if err != nil {
+		return "", fmt.Errorf("failed to regenerate invite code: %w", err)
+	}
+	return code, nil
+}
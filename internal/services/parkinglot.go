@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+// MaxParkingLotItems is how many items a session's parking lot holds.
+const MaxParkingLotItems = 200
+
+// GetParkingLot returns a session's parking lot items, oldest first, with
+// who raised them and the titles of the tickets they are about.
+func (s *SessionService) GetParkingLot(ctx context.Context, sessionID string) ([]models.ParkingLotItem, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT p.id, p.session_id, p.ticket_id, COALESCE(t.title, ''), p.user_id, u.username, p.kind, p.text, p.created_at
+			  FROM parking_lot_items p
+			  JOIN users u ON u.id = p.user_id
+			  LEFT JOIN tickets t ON t.id = p.ticket_id
+			  WHERE p.session_id = ?
+			  ORDER BY p.created_at, p.id`
+	rows, err := s.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parking lot: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.ParkingLotItem{}
+	for rows.Next() {
+		var item models.ParkingLotItem
+		err := rows.Scan(&item.ID, &item.SessionID, &item.TicketID, &item.TicketTitle, &item.UserID, &item.Username,
+			&item.Kind, &item.Text, &item.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan parking lot item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// AddParkingLotItem parks a question or risk raised by a participant,
+// optionally about one of the session's tickets.
+func (s *SessionService) AddParkingLotItem(ctx context.Context, item models.ParkingLotItem) (*models.ParkingLotItem, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var isParticipant bool
+	participantQuery := `SELECT EXISTS(SELECT 1 FROM participants WHERE session_id = ? AND user_id = ?)`
+	if err := tx.QueryRowContext(ctx, participantQuery, item.SessionID, item.UserID).Scan(&isParticipant); err != nil {
+		return nil, fmt.Errorf("failed to check participant: %w", err)
+	}
+	if !isParticipant {
+		return nil, ErrNotParticipant
+	}
+
+	if item.TicketID != nil {
+		err := tx.QueryRowContext(ctx, `SELECT title FROM tickets WHERE id = ? AND session_id = ?`,
+			*item.TicketID, item.SessionID).Scan(&item.TicketTitle)
+		if err == sql.ErrNoRows {
+			return nil, ErrTicketNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ticket: %w", err)
+		}
+	}
+
+	var count int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM parking_lot_items WHERE session_id = ?`, item.SessionID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to count parking lot items: %w", err)
+	}
+	if count >= MaxParkingLotItems {
+		return nil, ErrParkingLotFull
+	}
+
+	item.CreatedAt = time.Now()
+	result, err := tx.ExecContext(ctx, `INSERT INTO parking_lot_items (session_id, ticket_id, user_id, kind, text, created_at)
+										VALUES (?, ?, ?, ?, ?, ?)`,
+		item.SessionID, item.TicketID, item.UserID, item.Kind, item.Text, item.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add parking lot item: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parking lot item ID: %w", err)
+	}
+	item.ID = int(id)
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &item, nil
+}
+
+// DeleteParkingLotItem removes an item from a session's parking lot, e.g.
+// once it was followed up. Only whoever raised it can, unless anyone is
+// set, as for the session owner.
+func (s *SessionService) DeleteParkingLotItem(ctx context.Context, sessionID string, itemID int, userID string, anyone bool) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM parking_lot_items WHERE id = ? AND session_id = ? AND (? OR user_id = ?)`,
+		itemID, sessionID, anyone, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete parking lot item: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrParkingLotItemNotFound
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+// PrevoteRound is the round of vote_rounds that holds a ticket's pre-votes,
+// cast before it comes up in the live session. Live rounds count from 1.
+const PrevoteRound = 0
+
+// PrevoteStatus is how far pre-voting on a ticket has got, as one user
+// sees it.
+type PrevoteStatus struct {
+	Count int    // participants who pre-voted
+	Mine  string // the user's own pre-vote, empty if they have none
+}
+
+// SetPrevoting opens a ticket for silent pre-votes ahead of the live
+// session, or closes it. Pre-votes already cast are kept.
+func (s *TicketService) SetPrevoting(ctx context.Context, ticketID int, open bool) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `UPDATE tickets SET prevote_open = ? WHERE id = ?`, open, ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to update ticket: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrTicketNotFound
+	}
+	return nil
+}
+
+// SubmitPrevote records a participant's pre-vote on a ticket that is open
+// for pre-voting and not estimated yet, replacing one they cast before. It
+// returns how many participants have pre-voted on the ticket.
+func (s *VotingService) SubmitPrevote(ctx context.Context, sessionID string, ticketID int, userID, voteValue string) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var isParticipant bool
+	participantQuery := `SELECT EXISTS(SELECT 1 FROM participants WHERE session_id = ? AND user_id = ?)`
+	err = tx.QueryRowContext(ctx, participantQuery, sessionID, userID).Scan(&isParticipant)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check participant: %w", err)
+	}
+	if !isParticipant {
+		return 0, ErrNotParticipant
+	}
+
+	var open, estimated bool
+	err = tx.QueryRowContext(ctx, `SELECT prevote_open, final_estimate IS NOT NULL FROM tickets WHERE id = ? AND session_id = ?`,
+		ticketID, sessionID).Scan(&open, &estimated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrTicketNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get ticket: %w", err)
+	}
+	if !open || estimated {
+		return 0, ErrPrevotingClosed
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM vote_rounds WHERE ticket_id = ? AND round = ? AND user_id = ?`, ticketID, PrevoteRound, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to replace pre-vote: %w", err)
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `INSERT INTO vote_rounds (ticket_id, round, user_id, vote_value, created_at, archived_at)
+								  VALUES (?, ?, ?, ?, ?, ?)`,
+		ticketID, PrevoteRound, userID, voteValue, now, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to submit pre-vote: %w", err)
+	}
+
+	var count int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM vote_rounds WHERE ticket_id = ? AND round = ?`, ticketID, PrevoteRound).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pre-votes: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetPrevotes returns the pre-votes on a ticket with the voters' names and
+// weights, oldest first.
+func (s *VotingService) GetPrevotes(ctx context.Context, ticketID int) ([]models.Vote, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT vr.id, vr.ticket_id, vr.user_id, vr.vote_value, vr.created_at,
+					 u.username, COALESCE(p.weight, 1), COALESCE(p.is_stakeholder, FALSE)
+			  FROM vote_rounds vr
+			  JOIN users u ON u.id = vr.user_id
+			  JOIN tickets t ON t.id = vr.ticket_id
+			  LEFT JOIN participants p ON p.session_id = t.session_id AND p.user_id = vr.user_id
+			  WHERE vr.ticket_id = ? AND vr.round = ?
+			  ORDER BY vr.created_at`
+
+	rows, err := s.db.QueryContext(ctx, query, ticketID, PrevoteRound)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pre-votes: %w", err)
+	}
+	defer rows.Close()
+
+	var votes []models.Vote
+	for rows.Next() {
+		var vote models.Vote
+		var user models.User
+		err := rows.Scan(&vote.ID, &vote.TicketID, &vote.UserID, &vote.VoteValue, &vote.CreatedAt, &user.Username, &vote.Weight, &vote.Advisory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan pre-vote: %w", err)
+		}
+		user.ID = vote.UserID
+		vote.User = &user
+		votes = append(votes, vote)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get pre-votes: %w", err)
+	}
+
+	return votes, nil
+}
+
+// GetPrevoteStatus returns, for each ticket of a session that has
+// pre-votes, how many there are and what userID pre-voted.
+func (s *VotingService) GetPrevoteStatus(ctx context.Context, sessionID, userID string) (map[int]PrevoteStatus, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT vr.ticket_id, COUNT(*), COALESCE(MAX(CASE WHEN vr.user_id = ? THEN vr.vote_value END), '')
+			  FROM vote_rounds vr
+			  JOIN tickets t ON t.id = vr.ticket_id
+			  WHERE t.session_id = ? AND vr.round = ?
+			  GROUP BY vr.ticket_id`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, sessionID, PrevoteRound)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pre-vote status: %w", err)
+	}
+	defer rows.Close()
+
+	status := make(map[int]PrevoteStatus)
+	for rows.Next() {
+		var ticketID int
+		var ticketStatus PrevoteStatus
+		if err := rows.Scan(&ticketID, &ticketStatus.Count, &ticketStatus.Mine); err != nil {
+			return nil, fmt.Errorf("failed to scan pre-vote status: %w", err)
+		}
+		status[ticketID] = ticketStatus
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get pre-vote status: %w", err)
+	}
+
+	return status, nil
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type ProjectService struct {
+	db *sql.DB
+}
+
+func NewProjectService(db *sql.DB) *ProjectService {
+	return &ProjectService{db: db}
+}
+
+// CreateProject creates a project, in an organization unless orgID is nil.
+func (s *ProjectService) CreateProject(ctx context.Context, name, ownerID string, orgID *string) (*models.Project, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	projectID := uuid.New().String()
+	now := time.Now()
+
+	query := `INSERT INTO projects (id, name, owner_id, organization_id, created_at) VALUES (?, ?, ?, ?, ?)`
+	_, err := s.db.ExecContext(ctx, query, projectID, name, ownerID, orgID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return &models.Project{
+		ID:             projectID,
+		Name:           name,
+		OwnerID:        ownerID,
+		OrganizationID: orgID,
+		CreatedAt:      now,
+	}, nil
+}
+
+// GetProjectByID loads a project with its sessions, oldest first. Each
+// session carries its tickets (without votes) so velocity can be computed.
+func (s *ProjectService) GetProjectByID(ctx context.Context, projectID string) (*models.Project, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var project models.Project
+	query := `SELECT id, name, owner_id, organization_id, created_at FROM projects WHERE id = ?`
+
+	err := s.db.QueryRowContext(ctx, query, projectID).Scan(
+		&project.ID,
+		&project.Name,
+		&project.OwnerID,
+		&project.OrganizationID,
+		&project.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	sessions, err := getVelocitySessions(ctx, s.db, `project_id = ?`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project sessions: %w", err)
+	}
+	project.Sessions = sessions
+
+	return &project, nil
+}
+
+// IsProjectParticipant reports whether a user takes part in any session of
+// a project.
+func (s *ProjectService) IsProjectParticipant(ctx context.Context, projectID, userID string) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM participants p JOIN sessions s ON s.id = p.session_id
+							WHERE s.project_id = ? AND p.user_id = ?)`
+	if err := s.db.QueryRowContext(ctx, query, projectID, userID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check project participant: %w", err)
+	}
+	return exists, nil
+}
+
+func (s *ProjectService) GetProjectsForOwner(ctx context.Context, ownerID string) ([]models.Project, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, name, owner_id, organization_id, created_at 
+			  FROM projects 
+			  WHERE owner_id = ? 
+			  ORDER BY created_at DESC`
+
+	rows, err := s.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []models.Project
+	for rows.Next() {
+		var project models.Project
+		err := rows.Scan(&project.ID, &project.Name, &project.OwnerID, &project.OrganizationID, &project.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, project)
+	}
+
+	return projects, nil
+}
+
+// getVelocitySessions loads the sessions matching a condition, oldest first,
+// with their tickets but not their votes, which is enough to compute
+// velocity. Projects and teams both use it.
+func getVelocitySessions(ctx context.Context, db *sql.DB, where string, arg string) ([]models.Session, error) {
+	query := `SELECT id, name, owner_id, estimation_unit, project_id, organization_id, team_id, created_at, updated_at 
+			  FROM sessions 
+			  WHERE ` + where + ` 
+			  ORDER BY created_at`
+
+	rows, err := db.QueryContext(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var session models.Session
+		err := rows.Scan(
+			&session.ID,
+			&session.Name,
+			&session.OwnerID,
+			&session.EstimationUnit,
+			&session.ProjectID,
+			&session.OrganizationID,
+			&session.TeamID,
+			&session.CreatedAt,
+			&session.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		tickets, err := getSessionTicketsWithoutVotes(ctx, db, sessions[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tickets for session %s: %w", sessions[i].ID, err)
+		}
+		sessions[i].Tickets = tickets
+	}
+
+	return sessions, nil
+}
+
+func getSessionTicketsWithoutVotes(ctx context.Context, db *sql.DB, sessionID string) ([]models.Ticket, error) {
+	query := `SELECT ` + ticketColumns + ` 
+			  FROM tickets 
+			  WHERE session_id = ? 
+			  ORDER BY position`
+
+	rows, err := db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tickets []models.Ticket
+	for rows.Next() {
+		var ticket models.Ticket
+		err := scanTicket(rows, &ticket)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, nil
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+const referenceColumns = `r.id, r.team_id, r.title, r.points, r.ticket_id, r.created_by, r.created_at`
+
+func scanReference(row rowScanner, reference *models.ReferenceStory) error {
+	return row.Scan(&reference.ID, &reference.TeamID, &reference.Title, &reference.Points,
+		&reference.TicketID, &reference.CreatedBy, &reference.CreatedAt)
+}
+
+func (s *TeamService) queryReferences(ctx context.Context, query string, args ...interface{}) ([]models.ReferenceStory, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	references := []models.ReferenceStory{}
+	for rows.Next() {
+		var reference models.ReferenceStory
+		if err := scanReference(rows, &reference); err != nil {
+			return nil, err
+		}
+		references = append(references, reference)
+	}
+	return references, rows.Err()
+}
+
+// GetReferences returns a team's reference story library, smallest
+// estimates first as the cards sort, then by title.
+func (s *TeamService) GetReferences(ctx context.Context, teamID string) ([]models.ReferenceStory, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	references, err := s.queryReferences(ctx, `SELECT `+referenceColumns+` FROM team_references r
+											   WHERE r.team_id = ?
+											   ORDER BY CAST(r.points AS REAL), r.points, r.title COLLATE NOCASE`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reference stories: %w", err)
+	}
+	return references, nil
+}
+
+// AddReference adds a story to a team's library. ticketID is the ticket it
+// was saved from, or nil.
+func (s *TeamService) AddReference(ctx context.Context, teamID, title, points string, ticketID *int, userID string) (*models.ReferenceStory, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	reference := &models.ReferenceStory{
+		TeamID:    teamID,
+		Title:     title,
+		Points:    points,
+		TicketID:  ticketID,
+		CreatedBy: &userID,
+		CreatedAt: time.Now(),
+	}
+
+	result, err := s.db.ExecContext(ctx, `INSERT INTO team_references (team_id, title, points, ticket_id, created_by, created_at)
+										  VALUES (?, ?, ?, ?, ?, ?)`,
+		teamID, title, points, ticketID, userID, reference.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add reference story: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reference story ID: %w", err)
+	}
+	reference.ID = int(id)
+
+	return reference, nil
+}
+
+// DeleteReference removes a story from a team's library, unpinning it
+// everywhere.
+func (s *TeamService) DeleteReference(ctx context.Context, teamID string, referenceID int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM team_references WHERE id = ? AND team_id = ?`, referenceID, teamID)
+	if err != nil {
+		return fmt.Errorf("failed to delete reference story: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrReferenceNotFound
+	}
+	return nil
+}
+
+// GetPinnedReferences returns the reference stories pinned in a session, in
+// the order they were pinned.
+func (s *TeamService) GetPinnedReferences(ctx context.Context, sessionID string) ([]models.ReferenceStory, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	references, err := s.queryReferences(ctx, `SELECT `+referenceColumns+` FROM team_references r
+											   JOIN session_reference_pins p ON p.reference_id = r.id
+											   WHERE p.session_id = ?
+											   ORDER BY p.pinned_at, r.id`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pinned reference stories: %w", err)
+	}
+	return references, nil
+}
+
+// PinReference pins one of a team's reference stories in a session. Pinning
+// it again changes nothing.
+func (s *TeamService) PinReference(ctx context.Context, sessionID, teamID string, referenceID int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `INSERT OR IGNORE INTO session_reference_pins (session_id, reference_id, pinned_at)
+										  SELECT ?, id, ? FROM team_references WHERE id = ? AND team_id = ?`,
+		sessionID, time.Now(), referenceID, teamID)
+	if err != nil {
+		return fmt.Errorf("failed to pin reference story: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		// Either already pinned or not one of the team's stories
+		var exists bool
+		err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM team_references WHERE id = ? AND team_id = ?)`,
+			referenceID, teamID).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check reference story: %w", err)
+		}
+		if !exists {
+			return ErrReferenceNotFound
+		}
+	}
+	return nil
+}
+
+// UnpinReference takes a reference story off a session.
+func (s *TeamService) UnpinReference(ctx context.Context, sessionID string, referenceID int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `DELETE FROM session_reference_pins WHERE session_id = ? AND reference_id = ?`, sessionID, referenceID)
+	if err != nil {
+		return fmt.Errorf("failed to unpin reference story: %w", err)
+	}
+	return nil
+}
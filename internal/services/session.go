@@ -1,11 +1,16 @@
 package services
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"poker-planning/internal/deck"
 	"poker-planning/internal/models"
+	"poker-planning/internal/stats"
 
 	"github.com/google/uuid"
 )
@@ -18,24 +23,29 @@ func NewSessionService(db *sql.DB) *SessionService {
 	return &SessionService{db: db}
 }
 
-func (s *SessionService) CreateSession(name, ownerID string) (*models.Session, error) {
+// CreateSession starts a session with ownerID as its first participant, in
+// the organization orgID when it is set.
+func (s *SessionService) CreateSession(ctx context.Context, name, ownerID, estimationUnit string, orgID *string) (*models.Session, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	sessionID := uuid.New().String()
 	now := time.Now()
 
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
-	query := `INSERT INTO sessions (id, name, owner_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`
-	_, err = tx.Exec(query, sessionID, name, ownerID, now, now)
+	query := `INSERT INTO sessions (id, name, owner_id, estimation_unit, organization_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, query, sessionID, name, ownerID, estimationUnit, orgID, now, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
 	participantQuery := `INSERT INTO participants (session_id, user_id, joined_at) VALUES (?, ?, ?)`
-	_, err = tx.Exec(participantQuery, sessionID, ownerID, now)
+	_, err = tx.ExecContext(ctx, participantQuery, sessionID, ownerID, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add owner as participant: %w", err)
 	}
@@ -45,25 +55,280 @@ func (s *SessionService) CreateSession(name, ownerID string) (*models.Session, e
 	}
 
 	return &models.Session{
-		ID:        sessionID,
-		Name:      name,
-		OwnerID:   ownerID,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:                    sessionID,
+		Name:                  name,
+		OwnerID:               ownerID,
+		RoundingStrategy:      string(deck.DefaultRoundingStrategy),
+		SuggestionBasis:       string(stats.DefaultBasis),
+		EstimationUnit:        estimationUnit,
+		OrganizationID:        orgID,
+		AutoRevealIgnoresAway: true,
+		DelphiAgreement:       models.DefaultDelphiAgreement,
+		CreatedAt:             now,
+		UpdatedAt:             now,
 	}, nil
 }
 
-func (s *SessionService) GetSessionByID(sessionID string) (*models.Session, error) {
+// CreateFollowUpSession starts a session that continues a previous one: same
+// owner, settings, project, organization and team, linked back to it, and
+// holding copies of every ticket that was not given a final estimate. Votes
+// are not carried over.
+func (s *SessionService) CreateFollowUpSession(ctx context.Context, previous *models.Session, name string) (*models.Session, int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sessionID := uuid.New().String()
+	now := time.Now()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO sessions (id, name, owner_id, rounding_strategy, suggestion_basis, estimation_unit, project_id, organization_id, team_id, previous_session_id, max_participants, max_tickets, is_public, auto_reveal, auto_reveal_ignores_away, voting_time_limit, vote_change_window, delphi_max_rounds, delphi_agreement, required_fields, created_at, updated_at) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, query, sessionID, name, previous.OwnerID, previous.RoundingStrategy, previous.SuggestionBasis, previous.EstimationUnit, previous.ProjectID, previous.OrganizationID, previous.TeamID, previous.ID, previous.MaxParticipants, previous.MaxTickets, previous.IsPublic, previous.AutoReveal, previous.AutoRevealIgnoresAway, previous.VotingTimeLimit, previous.VoteChangeWindow, previous.DelphiMaxRounds, previous.DelphiAgreement, encodeRequiredFields(previous.RequiredFields), now, now)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	participantQuery := `INSERT INTO participants (session_id, user_id, joined_at) VALUES (?, ?, ?)`
+	_, err = tx.ExecContext(ctx, participantQuery, sessionID, previous.OwnerID, now)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to add owner as participant: %w", err)
+	}
+
+	copied, err := copyUnestimatedTickets(ctx, tx, previous.ID, sessionID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &models.Session{
+		ID:                    sessionID,
+		Name:                  name,
+		OwnerID:               previous.OwnerID,
+		RoundingStrategy:      previous.RoundingStrategy,
+		SuggestionBasis:       previous.SuggestionBasis,
+		EstimationUnit:        previous.EstimationUnit,
+		ProjectID:             previous.ProjectID,
+		OrganizationID:        previous.OrganizationID,
+		TeamID:                previous.TeamID,
+		PreviousSessionID:     &previous.ID,
+		MaxParticipants:       previous.MaxParticipants,
+		MaxTickets:            previous.MaxTickets,
+		IsPublic:              previous.IsPublic,
+		AutoReveal:            previous.AutoReveal,
+		AutoRevealIgnoresAway: previous.AutoRevealIgnoresAway,
+		VotingTimeLimit:       previous.VotingTimeLimit,
+		VoteChangeWindow:      previous.VoteChangeWindow,
+		DelphiMaxRounds:       previous.DelphiMaxRounds,
+		DelphiAgreement:       previous.DelphiAgreement,
+		RequiredFields:        previous.RequiredFields,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}, copied, nil
+}
+
+// CreateTeamSession starts a session for a team: in the team's organization,
+// with its deck and voting time limit, and with every team member already
+// a participant alongside the owner.
+func (s *SessionService) CreateTeamSession(ctx context.Context, team *models.Team, name, ownerID string) (*models.Session, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sessionID := uuid.New().String()
+	now := time.Now()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO sessions (id, name, owner_id, estimation_unit, organization_id, team_id, voting_time_limit, created_at, updated_at) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, query, sessionID, name, ownerID, team.EstimationUnit, team.OrganizationID, team.ID, team.VotingTimeLimit, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	participantQuery := `INSERT INTO participants (session_id, user_id, joined_at) VALUES (?, ?, ?)`
+	_, err = tx.ExecContext(ctx, participantQuery, sessionID, ownerID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add owner as participant: %w", err)
+	}
+
+	memberQuery := `INSERT OR IGNORE INTO participants (session_id, user_id, joined_at) 
+					SELECT ?, user_id, ? FROM team_members WHERE team_id = ?`
+	_, err = tx.ExecContext(ctx, memberQuery, sessionID, now, team.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invite team members: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &models.Session{
+		ID:                    sessionID,
+		Name:                  name,
+		OwnerID:               ownerID,
+		RoundingStrategy:      string(deck.DefaultRoundingStrategy),
+		SuggestionBasis:       string(stats.DefaultBasis),
+		EstimationUnit:        team.EstimationUnit,
+		OrganizationID:        &team.OrganizationID,
+		TeamID:                &team.ID,
+		AutoRevealIgnoresAway: true,
+		VotingTimeLimit:       team.VotingTimeLimit,
+		DelphiAgreement:       models.DefaultDelphiAgreement,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}, nil
+}
+
+// CreateProjectSession starts a session in a project and, for an
+// organization's project, in the organization too.
+func (s *SessionService) CreateProjectSession(ctx context.Context, project *models.Project, name, ownerID, estimationUnit string) (*models.Session, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sessionID := uuid.New().String()
+	now := time.Now()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO sessions (id, name, owner_id, estimation_unit, project_id, organization_id, created_at, updated_at) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, query, sessionID, name, ownerID, estimationUnit, project.ID, project.OrganizationID, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	participantQuery := `INSERT INTO participants (session_id, user_id, joined_at) VALUES (?, ?, ?)`
+	_, err = tx.ExecContext(ctx, participantQuery, sessionID, ownerID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add owner as participant: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &models.Session{
+		ID:                    sessionID,
+		Name:                  name,
+		OwnerID:               ownerID,
+		RoundingStrategy:      string(deck.DefaultRoundingStrategy),
+		SuggestionBasis:       string(stats.DefaultBasis),
+		EstimationUnit:        estimationUnit,
+		ProjectID:             &project.ID,
+		OrganizationID:        project.OrganizationID,
+		AutoRevealIgnoresAway: true,
+		DelphiAgreement:       models.DefaultDelphiAgreement,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}, nil
+}
+
+func (s *SessionService) GetSessionByID(ctx context.Context, sessionID string) (*models.Session, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return s.getSession(ctx, sessionID, 0, 0)
+}
+
+// GetSessionWithTicketPage loads a session with only a window of its tickets
+// (and their votes), plus the total number of tickets in the session. The
+// current ticket is always loaded, even when it falls outside the window.
+func (s *SessionService) GetSessionWithTicketPage(ctx context.Context, sessionID string, offset, limit int) (*models.Session, int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	session, err := s.getSession(ctx, sessionID, offset, limit)
+	if err != nil || session == nil {
+		return session, 0, err
+	}
+
+	var total int
+	err = s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tickets WHERE session_id = ?`, sessionID).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count tickets: %w", err)
+	}
+
+	return session, total, nil
+}
+
+// TicketIndex returns the 1-based place of a ticket in the session backlog,
+// for a current ticket that lies beyond the loaded page.
+func (s *SessionService) TicketIndex(ctx context.Context, sessionID string, ticket *models.Ticket) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var before int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tickets WHERE session_id = ? AND position < ?`,
+		sessionID, ticket.Position).Scan(&before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count earlier tickets: %w", err)
+	}
+	return before + 1, nil
+}
+
+// GetSessionWithoutTickets loads a session, its participants and its current
+// ticket but not the backlog, for callers that only need membership.
+func (s *SessionService) GetSessionWithoutTickets(ctx context.Context, sessionID string) (*models.Session, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	return s.getSession(ctx, sessionID, 0, -1)
+}
+
+// getSession loads a session with its participants and tickets. A limit of
+// zero loads every ticket and a negative limit loads none; the current ticket
+// is loaded either way.
+func (s *SessionService) getSession(ctx context.Context, sessionID string, offset, limit int) (*models.Session, error) {
 	var session models.Session
-	query := `SELECT id, name, owner_id, current_ticket_id, is_voting_active, created_at, updated_at 
+	query := `SELECT id, name, owner_id, driver_id, current_ticket_id, is_voting_active, rounding_strategy, suggestion_basis, estimation_unit, project_id, organization_id, team_id, previous_session_id, max_participants, max_tickets, is_public, auto_reveal, auto_reveal_ignores_away, voting_time_limit, voting_started_at, vote_change_window, delphi_max_rounds, delphi_agreement, discussing_ticket_id, special_cards, card_styles, required_fields, created_at, updated_at 
 			  FROM sessions WHERE id = ?`
 	
-	err := s.db.QueryRow(query, sessionID).Scan(
+	var specialCards, cardStyles sql.NullString
+	var requiredFields string
+	err := s.db.QueryRowContext(ctx, query, sessionID).Scan(
 		&session.ID,
 		&session.Name,
 		&session.OwnerID,
+		&session.DriverID,
 		&session.CurrentTicketID,
 		&session.IsVotingActive,
+		&session.RoundingStrategy,
+		&session.SuggestionBasis,
+		&session.EstimationUnit,
+		&session.ProjectID,
+		&session.OrganizationID,
+		&session.TeamID,
+		&session.PreviousSessionID,
+		&session.MaxParticipants,
+		&session.MaxTickets,
+		&session.IsPublic,
+		&session.AutoReveal,
+		&session.AutoRevealIgnoresAway,
+		&session.VotingTimeLimit,
+		&session.VotingStartedAt,
+		&session.VoteChangeWindow,
+		&session.DelphiMaxRounds,
+		&session.DelphiAgreement,
+		&session.DiscussingTicketID,
+		&specialCards,
+		&cardStyles,
+		&requiredFields,
 		&session.CreatedAt,
 		&session.UpdatedAt,
 	)
@@ -73,43 +338,148 @@ func (s *SessionService) GetSessionByID(sessionID string) (*models.Session, erro
 		}
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
+	if session.CustomSpecialCards, err = decodeSpecialCards(specialCards); err != nil {
+		return nil, err
+	}
+	if session.CustomCardStyles, err = decodeCardStyles(cardStyles); err != nil {
+		return nil, err
+	}
+	session.RequiredFields = decodeRequiredFields(requiredFields)
 
-	participants, err := s.getSessionParticipants(sessionID)
+	participants, err := s.getSessionParticipants(ctx, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get participants: %w", err)
 	}
 	session.Participants = participants
 
-	tickets, err := s.getSessionTickets(sessionID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get tickets: %w", err)
+	var tickets []models.Ticket
+	if limit >= 0 {
+		tickets, err = s.getSessionTickets(ctx, sessionID, offset, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tickets: %w", err)
+		}
+		session.Tickets = tickets
 	}
-	session.Tickets = tickets
 
 	if session.CurrentTicketID != nil {
 		for i, ticket := range tickets {
 			if ticket.ID == *session.CurrentTicketID {
 				session.CurrentTicket = &tickets[i]
-				
-				// Get votes for the current ticket
-				votes, err := s.getTicketVotes(*session.CurrentTicketID)
-				if err != nil {
-					return nil, fmt.Errorf("failed to get ticket votes: %w", err)
-				}
-				session.CurrentTicket.Votes = votes
 				break
 			}
 		}
+
+		if session.CurrentTicket == nil {
+			currentTicket, err := s.getTicketWithVotes(ctx, *session.CurrentTicketID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get current ticket: %w", err)
+			}
+			session.CurrentTicket = currentTicket
+		}
+	}
+
+	if session.DiscussingTicketID != nil {
+		for i, ticket := range tickets {
+			if ticket.ID == *session.DiscussingTicketID {
+				session.DiscussingTicket = &tickets[i]
+				break
+			}
+		}
+
+		if session.DiscussingTicket == nil {
+			discussingTicket, err := s.getTicketWithVotes(ctx, *session.DiscussingTicketID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get discussing ticket: %w", err)
+			}
+			session.DiscussingTicket = discussingTicket
+		}
 	}
 
 	return &session, nil
 }
 
-func (s *SessionService) JoinSession(sessionID, userID string) (bool, error) {
+// GetPublicSessions returns the sessions listed in the lobby for a user, most
+// recently active first, with their participants loaded. Sessions of an
+// organization are only listed for its members.
+func (s *SessionService) GetPublicSessions(ctx context.Context, userID string, limit int) ([]models.Session, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, name, owner_id, estimation_unit, organization_id, max_participants, is_public, created_at, updated_at 
+			  FROM sessions 
+			  WHERE is_public = TRUE 
+			    AND (organization_id IS NULL OR organization_id IN (SELECT organization_id FROM organization_members WHERE user_id = ?))
+			  ORDER BY updated_at DESC 
+			  LIMIT ?`
+	
+	return s.listSessions(ctx, query, userID, limit)
+}
+
+// GetOrganizationSessions returns every session of an organization, most
+// recently active first, with their participants loaded.
+func (s *SessionService) GetOrganizationSessions(ctx context.Context, orgID string) ([]models.Session, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, name, owner_id, estimation_unit, organization_id, max_participants, is_public, created_at, updated_at 
+			  FROM sessions 
+			  WHERE organization_id = ? 
+			  ORDER BY updated_at DESC`
+
+	return s.listSessions(ctx, query, orgID)
+}
+
+// listSessions runs a query selecting the summary columns of sessions and
+// loads their participants.
+func (s *SessionService) listSessions(ctx context.Context, query string, args ...interface{}) ([]models.Session, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var session models.Session
+		err := rows.Scan(
+			&session.ID,
+			&session.Name,
+			&session.OwnerID,
+			&session.EstimationUnit,
+			&session.OrganizationID,
+			&session.MaxParticipants,
+			&session.IsPublic,
+			&session.CreatedAt,
+			&session.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	for i := range sessions {
+		participants, err := s.getSessionParticipants(ctx, sessions[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get participants: %w", err)
+		}
+		sessions[i].Participants = participants
+	}
+
+	return sessions, nil
+}
+
+func (s *SessionService) JoinSession(ctx context.Context, sessionID, userID string) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	// Check if user is already a participant
 	checkQuery := `SELECT COUNT(*) FROM participants WHERE session_id = ? AND user_id = ?`
 	var count int
-	err := s.db.QueryRow(checkQuery, sessionID, userID).Scan(&count)
+	err := s.db.QueryRowContext(ctx, checkQuery, sessionID, userID).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("failed to check participant status: %w", err)
 	}
@@ -121,7 +491,7 @@ func (s *SessionService) JoinSession(sessionID, userID string) (bool, error) {
 	
 	// Add user as participant
 	insertQuery := `INSERT INTO participants (session_id, user_id, joined_at) VALUES (?, ?, ?)`
-	_, err = s.db.Exec(insertQuery, sessionID, userID, time.Now())
+	_, err = s.db.ExecContext(ctx, insertQuery, sessionID, userID, time.Now())
 	if err != nil {
 		return false, fmt.Errorf("failed to join session: %w", err)
 	}
@@ -130,23 +500,123 @@ func (s *SessionService) JoinSession(sessionID, userID string) (bool, error) {
 	return true, nil
 }
 
-func (s *SessionService) LeaveSession(sessionID, userID string) error {
-	query := `DELETE FROM participants WHERE session_id = ? AND user_id = ?`
-	_, err := s.db.Exec(query, sessionID, userID)
+func (s *SessionService) LeaveSession(ctx context.Context, sessionID, userID string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM participants WHERE session_id = ? AND user_id = ?`
+	if _, err = tx.ExecContext(ctx, query, sessionID, userID); err != nil {
 		return fmt.Errorf("failed to leave session: %w", err)
 	}
+
+	// Control goes back to the owner when the driver leaves
+	_, err = tx.ExecContext(ctx, `UPDATE sessions SET driver_id = NULL WHERE id = ? AND driver_id = ?`, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to clear driver: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// TransferOwnership hands a session from one owner to another, who joins
+// it if needed, while the previous owner leaves. It does nothing and
+// returns false if fromID no longer owns the session.
+func (s *SessionService) TransferOwnership(ctx context.Context, sessionID, fromID, toID string) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The new owner drives the session themselves
+	result, err := tx.ExecContext(ctx, `UPDATE sessions SET owner_id = ?, driver_id = NULL, updated_at = ? WHERE id = ? AND owner_id = ?`,
+		toID, time.Now(), sessionID, fromID)
+	if err != nil {
+		return false, fmt.Errorf("failed to transfer session: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return false, fmt.Errorf("failed to transfer session: %w", err)
+	} else if updated == 0 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO participants (session_id, user_id, joined_at) VALUES (?, ?, ?)
+								  ON CONFLICT(session_id, user_id) DO NOTHING`, sessionID, toID, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to add owner as participant: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM participants WHERE session_id = ? AND user_id = ?`, sessionID, fromID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove previous owner: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// SetParticipantWeight changes how much a participant's votes count towards
+// the session's median, mean and suggested estimate.
+func (s *SessionService) SetParticipantWeight(ctx context.Context, sessionID, userID string, weight float64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE participants SET weight = ? WHERE session_id = ? AND user_id = ?`
+	result, err := s.db.ExecContext(ctx, query, weight, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set participant weight: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to set participant weight: %w", err)
+	} else if updated == 0 {
+		return ErrNotParticipant
+	}
 	return nil
 }
 
-func (s *SessionService) getSessionParticipants(sessionID string) ([]models.User, error) {
-	query := `SELECT u.id, u.username, u.created_at, u.last_seen 
+// SetParticipantStakeholder marks a participant as an interested
+// stakeholder, whose votes are advisory, or back as a committed team member.
+func (s *SessionService) SetParticipantStakeholder(ctx context.Context, sessionID, userID string, stakeholder bool) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE participants SET is_stakeholder = ? WHERE session_id = ? AND user_id = ?`
+	result, err := s.db.ExecContext(ctx, query, stakeholder, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set participant role: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to set participant role: %w", err)
+	} else if updated == 0 {
+		return ErrNotParticipant
+	}
+	return nil
+}
+
+func (s *SessionService) getSessionParticipants(ctx context.Context, sessionID string) ([]models.User, error) {
+	query := `SELECT u.id, u.username, u.created_at, u.last_seen, b.user_id IS NOT NULL, p.weight, p.is_stakeholder
 			  FROM users u 
 			  JOIN participants p ON u.id = p.user_id 
+			  LEFT JOIN bots b ON b.user_id = u.id AND b.session_id = p.session_id
 			  WHERE p.session_id = ? 
 			  ORDER BY p.joined_at`
 	
-	rows, err := s.db.Query(query, sessionID)
+	rows, err := s.db.QueryContext(ctx, query, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +625,7 @@ func (s *SessionService) getSessionParticipants(sessionID string) ([]models.User
 	var participants []models.User
 	for rows.Next() {
 		var user models.User
-		err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt, &user.LastSeen)
+		err := rows.Scan(&user.ID, &user.Username, &user.CreatedAt, &user.LastSeen, &user.IsBot, &user.Weight, &user.IsStakeholder)
 		if err != nil {
 			return nil, err
 		}
@@ -165,13 +635,18 @@ func (s *SessionService) getSessionParticipants(sessionID string) ([]models.User
 	return participants, nil
 }
 
-func (s *SessionService) getSessionTickets(sessionID string) ([]models.Ticket, error) {
-	query := `SELECT id, session_id, title, description, final_estimate, position, created_at 
+func (s *SessionService) getSessionTickets(ctx context.Context, sessionID string, offset, limit int) ([]models.Ticket, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+
+	query := `SELECT ` + ticketColumns + ` 
 			  FROM tickets 
 			  WHERE session_id = ? 
-			  ORDER BY position`
+			  ORDER BY position 
+			  LIMIT ? OFFSET ?`
 	
-	rows, err := s.db.Query(query, sessionID)
+	rows, err := s.db.QueryContext(ctx, query, sessionID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -180,47 +655,73 @@ func (s *SessionService) getSessionTickets(sessionID string) ([]models.Ticket, e
 	var tickets []models.Ticket
 	for rows.Next() {
 		var ticket models.Ticket
-		err := rows.Scan(
-			&ticket.ID,
-			&ticket.SessionID,
-			&ticket.Title,
-			&ticket.Description,
-			&ticket.FinalEstimate,
-			&ticket.Position,
-			&ticket.CreatedAt,
-		)
+		err := scanTicket(rows, &ticket)
 		if err != nil {
 			return nil, err
 		}
-		
-		// Load votes for each ticket
-		votes, err := s.getTicketVotes(ticket.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get votes for ticket %d: %w", ticket.ID, err)
-		}
-		ticket.Votes = votes
-		
 		tickets = append(tickets, ticket)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := loadTicketVotes(ctx, s.db, tickets); err != nil {
+		return nil, fmt.Errorf("failed to get ticket votes: %w", err)
+	}
 
 	return tickets, nil
 }
 
-func (s *SessionService) getTicketVotes(ticketID int) ([]models.Vote, error) {
+func (s *SessionService) getTicketWithVotes(ctx context.Context, ticketID int) (*models.Ticket, error) {
+	var ticket models.Ticket
+	query := `SELECT ` + ticketColumns + ` FROM tickets WHERE id = ?`
+	err := scanTicket(s.db.QueryRowContext(ctx, query, ticketID), &ticket)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	tickets := []models.Ticket{ticket}
+	if err := loadTicketVotes(ctx, s.db, tickets); err != nil {
+		return nil, err
+	}
+
+	return &tickets[0], nil
+}
+
+// loadTicketVotes fills in the votes for a batch of tickets with a single
+// query instead of one query per ticket.
+func loadTicketVotes(ctx context.Context, db *sql.DB, tickets []models.Ticket) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(tickets))
+	args := make([]interface{}, len(tickets))
+	index := make(map[int]int, len(tickets))
+	for i, ticket := range tickets {
+		placeholders[i] = "?"
+		args[i] = ticket.ID
+		index[ticket.ID] = i
+	}
+
 	query := `SELECT v.id, v.ticket_id, v.user_id, v.vote_value, v.created_at,
-					 u.username
+					 u.username, COALESCE(p.weight, 1), COALESCE(p.is_stakeholder, FALSE)
 			  FROM votes v
 			  JOIN users u ON v.user_id = u.id
-			  WHERE v.ticket_id = ?
+			  JOIN tickets t ON t.id = v.ticket_id
+			  LEFT JOIN participants p ON p.session_id = t.session_id AND p.user_id = v.user_id
+			  WHERE v.ticket_id IN (` + strings.Join(placeholders, ", ") + `)
 			  ORDER BY v.created_at`
 	
-	rows, err := s.db.Query(query, ticketID)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	var votes []models.Vote
 	for rows.Next() {
 		var vote models.Vote
 		var user models.User
@@ -232,31 +733,175 @@ func (s *SessionService) getTicketVotes(ticketID int) ([]models.Vote, error) {
 			&vote.VoteValue,
 			&vote.CreatedAt,
 			&user.Username,
+			&vote.Weight,
+			&vote.Advisory,
 		)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		
 		user.ID = vote.UserID
 		vote.User = &user
-		votes = append(votes, vote)
+		ticket := &tickets[index[vote.TicketID]]
+		ticket.Votes = append(ticket.Votes, vote)
 	}
 
-	return votes, nil
+	return rows.Err()
 }
 
-func (s *SessionService) UpdateSession(session *models.Session) error {
+// SetDiscussingTicket highlights one of the session's tickets for
+// discussion without making it the voting ticket, or clears the highlight
+// for a nil ticketID.
+func (s *SessionService) SetDiscussingTicket(ctx context.Context, sessionID string, ticketID *int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE sessions SET discussing_ticket_id = ?
+			  WHERE id = ? AND (? IS NULL OR EXISTS(SELECT 1 FROM tickets WHERE id = ? AND session_id = sessions.id))`
+	result, err := s.db.ExecContext(ctx, query, ticketID, sessionID, ticketID, ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to set discussing ticket: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrTicketNotFound
+	}
+	return nil
+}
+
+// SetDriver hands voting control of a session to one of its participants,
+// or gives it back to the owner for a nil driverID.
+func (s *SessionService) SetDriver(ctx context.Context, sessionID string, driverID *string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE sessions SET driver_id = ?, updated_at = ?
+			  WHERE id = ? AND (? IS NULL OR EXISTS(SELECT 1 FROM participants WHERE session_id = sessions.id AND user_id = ?))`
+	result, err := s.db.ExecContext(ctx, query, driverID, time.Now(), sessionID, driverID, driverID)
+	if err != nil {
+		return fmt.Errorf("failed to set driver: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotParticipant
+	}
+	return nil
+}
+
+// decodeSpecialCards reads a session's special_cards column. NULL, for the
+// deployment's special cards, is nil.
+func decodeSpecialCards(value sql.NullString) ([]deck.SpecialCard, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	cards := []deck.SpecialCard{}
+	if err := json.Unmarshal([]byte(value.String), &cards); err != nil {
+		return nil, fmt.Errorf("failed to decode special cards: %w", err)
+	}
+	return cards, nil
+}
+
+// SetSpecialCards replaces the special cards a session votes with. Nil goes
+// back to the deployment's.
+func (s *SessionService) SetSpecialCards(ctx context.Context, sessionID string, cards []deck.SpecialCard) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var encoded *string
+	if cards != nil {
+		data, err := json.Marshal(cards)
+		if err != nil {
+			return fmt.Errorf("failed to encode special cards: %w", err)
+		}
+		value := string(data)
+		encoded = &value
+	}
+
+	_, err := s.db.ExecContext(ctx, `UPDATE sessions SET special_cards = ?, updated_at = ? WHERE id = ?`, encoded, time.Now(), sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to set special cards: %w", err)
+	}
+	return nil
+}
+
+// decodeCardStyles reads a session's card_styles column. NULL, for the
+// deployment's styles, decodes to nil.
+func decodeCardStyles(value sql.NullString) (map[string]deck.CardStyle, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+	styles := map[string]deck.CardStyle{}
+	if err := json.Unmarshal([]byte(value.String), &styles); err != nil {
+		return nil, fmt.Errorf("failed to decode card styles: %w", err)
+	}
+	return styles, nil
+}
+
+// SetCardStyles replaces how a session's cards are shown. Nil goes back to
+// the deployment's styles.
+func (s *SessionService) SetCardStyles(ctx context.Context, sessionID string, styles map[string]deck.CardStyle) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var encoded *string
+	if styles != nil {
+		data, err := json.Marshal(styles)
+		if err != nil {
+			return fmt.Errorf("failed to encode card styles: %w", err)
+		}
+		value := string(data)
+		encoded = &value
+	}
+
+	_, err := s.db.ExecContext(ctx, `UPDATE sessions SET card_styles = ?, updated_at = ? WHERE id = ?`, encoded, time.Now(), sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to set card styles: %w", err)
+	}
+	return nil
+}
+
+// decodeRequiredFields reads a session's required_fields column, skipping
+// fields this version does not know.
+func decodeRequiredFields(value string) []models.TicketField {
+	fields := []models.TicketField{}
+	for _, name := range strings.Split(value, ",") {
+		if field, ok := models.ParseTicketField(name); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func encodeRequiredFields(fields []models.TicketField) string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = string(field)
+	}
+	return strings.Join(names, ",")
+}
+
+// UpdateSession saves the session's name, voting state and deck. A ticket
+// that was up for discussion stops being discussed once it is the current
+// ticket.
+func (s *SessionService) UpdateSession(ctx context.Context, session *models.Session) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	query := `UPDATE sessions SET 
 			  name = ?, 
 			  current_ticket_id = ?, 
+			  discussing_ticket_id = NULLIF(discussing_ticket_id, ?), 
 			  is_voting_active = ?, 
+			  rounding_strategy = ?, 
+			  estimation_unit = ?, 
 			  updated_at = ? 
 			  WHERE id = ?`
 	
-	_, err := s.db.Exec(query,
+	_, err := s.db.ExecContext(ctx, query,
 		session.Name,
 		session.CurrentTicketID,
+		session.CurrentTicketID,
 		session.IsVotingActive,
+		session.RoundingStrategy,
+		session.EstimationUnit,
 		time.Now(),
 		session.ID,
 	)
@@ -267,12 +912,76 @@ func (s *SessionService) UpdateSession(session *models.Session) error {
 	return nil
 }
 
-func (s *SessionService) DeleteSession(sessionID string) error {
+// UpdateSessionSettings persists the owner-editable settings of a session
+// without touching its voting state.
+func (s *SessionService) UpdateSessionSettings(ctx context.Context, session *models.Session) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE sessions SET 
+			  name = ?, 
+			  rounding_strategy = ?, 
+			  suggestion_basis = ?, 
+			  estimation_unit = ?, 
+			  max_participants = ?, 
+			  max_tickets = ?, 
+			  is_public = ?, 
+			  auto_reveal = ?, 
+			  auto_reveal_ignores_away = ?, 
+			  voting_time_limit = ?, 
+			  vote_change_window = ?, 
+			  delphi_max_rounds = ?, 
+			  delphi_agreement = ?, 
+			  required_fields = ?, 
+			  updated_at = ? 
+			  WHERE id = ?`
+	
+	_, err := s.db.ExecContext(ctx, query,
+		session.Name,
+		session.RoundingStrategy,
+		session.SuggestionBasis,
+		session.EstimationUnit,
+		session.MaxParticipants,
+		session.MaxTickets,
+		session.IsPublic,
+		session.AutoReveal,
+		session.AutoRevealIgnoresAway,
+		session.VotingTimeLimit,
+		session.VoteChangeWindow,
+		session.DelphiMaxRounds,
+		session.DelphiAgreement,
+		encodeRequiredFields(session.RequiredFields),
+		time.Now(),
+		session.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update session settings: %w", err)
+	}
+	
+	return nil
+}
+
+func (s *SessionService) SetSessionProject(ctx context.Context, sessionID string, projectID *string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE sessions SET project_id = ?, updated_at = ? WHERE id = ?`
+	_, err := s.db.ExecContext(ctx, query, projectID, time.Now(), sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to set session project: %w", err)
+	}
+	return nil
+}
+
+func (s *SessionService) DeleteSession(ctx context.Context, sessionID string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	// Note: SQLite with ON DELETE CASCADE will automatically handle deletion of:
 	// - participants
 	// - tickets (and their votes due to ticket FK constraint)
 	query := `DELETE FROM sessions WHERE id = ?`
-	_, err := s.db.Exec(query, sessionID)
+	_, err := s.db.ExecContext(ctx, query, sessionID)
 	if err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+// MaxSessionSnapshots is how many restore points a session keeps.
+// Automatic ones make room for themselves by replacing the oldest automatic
+// one; the owner's own are only removed by the owner.
+const MaxSessionSnapshots = 20
+
+// snapshotState is what a restore point holds, stored as JSON. Tickets and
+// votes keep their IDs, so restoring puts back the very same tickets.
+type snapshotState struct {
+	CurrentTicketID    *int             `json:"current_ticket_id"`
+	DiscussingTicketID *int             `json:"discussing_ticket_id"`
+	IsVotingActive     bool             `json:"is_voting_active"`
+	VotingStartedAt    *time.Time       `json:"voting_started_at"`
+	Tickets            []snapshotTicket `json:"tickets"`
+	Votes              []ArchiveVote    `json:"votes"`
+	VoteRounds         []ArchiveVote    `json:"vote_rounds"`
+}
+
+type snapshotTicket struct {
+	ArchiveTicket
+	RevealedAt *time.Time `json:"revealed_at,omitempty"`
+}
+
+// captureSnapshot reads a session's tickets, votes and voting state.
+func captureSnapshot(ctx context.Context, tx *sql.Tx, sessionID string) (*snapshotState, error) {
+	var state snapshotState
+	err := tx.QueryRowContext(ctx, `SELECT current_ticket_id, discussing_ticket_id, is_voting_active, voting_started_at FROM sessions WHERE id = ?`,
+		sessionID).Scan(&state.CurrentTicketID, &state.DiscussingTicketID, &state.IsVotingActive, &state.VotingStartedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	state.Tickets = []snapshotTicket{}
+	err = queryRows(ctx, tx, `SELECT id, title, COALESCE(description, ''), external_key, external_url, external_source, external_closed_at, final_estimate,
+									 estimate_low, estimate_high, decision_rationale, decision_assumptions, position, parent_ticket_id, is_split, needs_split,
+									 is_calibration, prevote_open, breakout_voters, revealed_at, created_at
+							  FROM tickets WHERE session_id = ? ORDER BY position`, func(rows *sql.Rows) error {
+		ticket := snapshotTicket{ArchiveTicket: ArchiveTicket{SessionID: sessionID}}
+		var breakout string
+		err := rows.Scan(&ticket.ID, &ticket.Title, &ticket.Description, &ticket.ExternalKey, &ticket.ExternalURL, &ticket.ExternalSource, &ticket.ExternalClosedAt,
+			&ticket.FinalEstimate, &ticket.EstimateLow, &ticket.EstimateHigh, &ticket.Rationale, &ticket.Assumptions, &ticket.Position, &ticket.ParentTicketID, &ticket.IsSplit,
+			&ticket.NeedsSplit, &ticket.IsCalibration, &ticket.PrevoteOpen, &breakout, &ticket.RevealedAt, &ticket.CreatedAt)
+		ticket.BreakoutVoters = decodeBreakout(breakout)
+		state.Tickets = append(state.Tickets, ticket)
+		return err
+	}, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tickets: %w", err)
+	}
+
+	state.Votes = []ArchiveVote{}
+	err = queryRows(ctx, tx, `SELECT v.ticket_id, v.user_id, v.vote_value, v.created_at
+							  FROM votes v JOIN tickets t ON t.id = v.ticket_id
+							  WHERE t.session_id = ? ORDER BY v.id`, func(rows *sql.Rows) error {
+		var vote ArchiveVote
+		err := rows.Scan(&vote.TicketID, &vote.UserID, &vote.VoteValue, &vote.CreatedAt)
+		state.Votes = append(state.Votes, vote)
+		return err
+	}, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get votes: %w", err)
+	}
+
+	state.VoteRounds = []ArchiveVote{}
+	err = queryRows(ctx, tx, `SELECT r.ticket_id, r.round, r.user_id, r.vote_value, r.created_at, r.archived_at
+							  FROM vote_rounds r JOIN tickets t ON t.id = r.ticket_id
+							  WHERE t.session_id = ? ORDER BY r.id`, func(rows *sql.Rows) error {
+		var vote ArchiveVote
+		err := rows.Scan(&vote.TicketID, &vote.Round, &vote.UserID, &vote.VoteValue, &vote.CreatedAt, &vote.ArchivedAt)
+		state.VoteRounds = append(state.VoteRounds, vote)
+		return err
+	}, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vote rounds: %w", err)
+	}
+
+	return &state, nil
+}
+
+// saveSnapshot stores a restore point of the session as it is now. When
+// the session has no room left, an automatic restore point replaces the
+// oldest automatic one and the owner's own fails with ErrSnapshotsFull.
+func saveSnapshot(ctx context.Context, tx *sql.Tx, sessionID, name, userID string, automatic bool) (*models.SessionSnapshot, error) {
+	state, err := captureSnapshot(ctx, tx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM session_snapshots WHERE session_id = ?`, sessionID).Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to count restore points: %w", err)
+	}
+	if count >= MaxSessionSnapshots {
+		if !automatic {
+			return nil, ErrSnapshotsFull
+		}
+		result, err := tx.ExecContext(ctx, `DELETE FROM session_snapshots WHERE id = (
+												SELECT id FROM session_snapshots WHERE session_id = ? AND automatic
+												ORDER BY created_at, id LIMIT 1)`, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make room for restore point: %w", err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return nil, ErrSnapshotsFull
+		}
+	}
+
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode restore point: %w", err)
+	}
+
+	snapshot := models.SessionSnapshot{
+		SessionID:   sessionID,
+		Name:        name,
+		Automatic:   automatic,
+		TicketCount: len(state.Tickets),
+		VoteCount:   len(state.Votes),
+		CreatedBy:   &userID,
+		CreatedAt:   time.Now(),
+	}
+	result, err := tx.ExecContext(ctx, `INSERT INTO session_snapshots (session_id, name, automatic, ticket_count, vote_count, state, created_by, created_at)
+										VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		sessionID, name, automatic, snapshot.TicketCount, snapshot.VoteCount, string(encoded), userID, snapshot.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save restore point: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restore point ID: %w", err)
+	}
+	snapshot.ID = int(id)
+
+	return &snapshot, nil
+}
+
+// CreateSnapshot saves a restore point of a session's tickets, votes and
+// voting state. Automatic restore points are taken before bulk changes.
+func (s *SessionService) CreateSnapshot(ctx context.Context, sessionID, name, userID string, automatic bool) (*models.SessionSnapshot, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	snapshot, err := saveSnapshot(ctx, tx, sessionID, name, userID, automatic)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// GetSnapshots returns a session's restore points, newest first.
+func (s *SessionService) GetSnapshots(ctx context.Context, sessionID string) ([]models.SessionSnapshot, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT s.id, s.session_id, s.name, s.automatic, s.ticket_count, s.vote_count, s.created_by, COALESCE(u.username, ''), s.created_at
+			  FROM session_snapshots s
+			  LEFT JOIN users u ON u.id = s.created_by
+			  WHERE s.session_id = ?
+			  ORDER BY s.created_at DESC, s.id DESC`
+	rows, err := s.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restore points: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []models.SessionSnapshot{}
+	for rows.Next() {
+		var snapshot models.SessionSnapshot
+		err := rows.Scan(&snapshot.ID, &snapshot.SessionID, &snapshot.Name, &snapshot.Automatic, &snapshot.TicketCount,
+			&snapshot.VoteCount, &snapshot.CreatedBy, &snapshot.CreatedByName, &snapshot.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan restore point: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
+
+// DeleteSnapshot removes one of a session's restore points.
+func (s *SessionService) DeleteSnapshot(ctx context.Context, sessionID string, snapshotID int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM session_snapshots WHERE id = ? AND session_id = ?`, snapshotID, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete restore point: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrSnapshotNotFound
+	}
+	return nil
+}
+
+// RestoreSnapshot rolls a session back to a restore point: tickets added
+// since are deleted, deleted ones come back with their IDs, and every
+// ticket, vote and the voting state are as they were. Votes of users who
+// no longer exist are left out. The session as it was before is saved as
+// an automatic restore point first, so a restore can itself be undone. It
+// returns the restore point it rolled back to.
+func (s *SessionService) RestoreSnapshot(ctx context.Context, sessionID string, snapshotID int, userID string) (*models.SessionSnapshot, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	snapshot := models.SessionSnapshot{ID: snapshotID, SessionID: sessionID}
+	var encoded string
+	err = tx.QueryRowContext(ctx, `SELECT name, automatic, ticket_count, vote_count, created_by, created_at, state
+								   FROM session_snapshots WHERE id = ? AND session_id = ?`, snapshotID, sessionID).Scan(
+		&snapshot.Name, &snapshot.Automatic, &snapshot.TicketCount, &snapshot.VoteCount, &snapshot.CreatedBy, &snapshot.CreatedAt, &encoded)
+	if err == sql.ErrNoRows {
+		return nil, ErrSnapshotNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restore point: %w", err)
+	}
+
+	var state snapshotState
+	if err := json.Unmarshal([]byte(encoded), &state); err != nil {
+		return nil, fmt.Errorf("failed to decode restore point: %w", err)
+	}
+
+	if _, err := saveSnapshot(ctx, tx, sessionID, "Before restoring "+snapshot.Name, userID, true); err != nil {
+		return nil, err
+	}
+
+	if err := applySnapshot(ctx, tx, sessionID, &state); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+// applySnapshot puts a session's tickets, votes and voting state back the
+// way a restore point has them.
+func applySnapshot(ctx context.Context, tx *sql.Tx, sessionID string, state *snapshotState) error {
+	// Nothing may point at a ticket that is about to go
+	_, err := tx.ExecContext(ctx, `UPDATE sessions SET current_ticket_id = NULL, discussing_ticket_id = NULL WHERE id = ?`, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to clear current ticket: %w", err)
+	}
+
+	keep := make(map[int]bool)
+	for _, ticket := range state.Tickets {
+		keep[ticket.ID] = true
+	}
+	existing := make(map[int]bool)
+	err = queryRows(ctx, tx, `SELECT id FROM tickets WHERE session_id = ?`, func(rows *sql.Rows) error {
+		var id int
+		err := rows.Scan(&id)
+		existing[id] = true
+		return err
+	}, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get tickets: %w", err)
+	}
+	for id := range existing {
+		if keep[id] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM tickets WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete ticket: %w", err)
+		}
+	}
+
+	for _, table := range []string{"votes", "vote_rounds"} {
+		_, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE ticket_id IN (SELECT id FROM tickets WHERE session_id = ?)`, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+
+	// Parents are linked once every ticket is back
+	for _, ticket := range state.Tickets {
+		args := []interface{}{ticket.Title, ticket.Description, ticket.ExternalKey, ticket.ExternalURL, ticket.ExternalSource, ticket.ExternalClosedAt,
+			ticket.FinalEstimate, ticket.EstimateLow, ticket.EstimateHigh, ticket.Rationale, ticket.Assumptions, ticket.Position, ticket.IsSplit, ticket.NeedsSplit,
+			ticket.IsCalibration, ticket.PrevoteOpen, encodeBreakout(ticket.BreakoutVoters), ticket.RevealedAt, ticket.CreatedAt, ticket.ID}
+		if existing[ticket.ID] {
+			_, err = tx.ExecContext(ctx, `UPDATE tickets SET title = ?, description = ?, external_key = ?, external_url = ?,
+											  external_source = ?, external_closed_at = ?, final_estimate = ?, estimate_low = ?, estimate_high = ?, decision_rationale = ?, decision_assumptions = ?,
+											  position = ?, is_split = ?, needs_split = ?, is_calibration = ?, prevote_open = ?, breakout_voters = ?,
+											  revealed_at = ?, created_at = ?, parent_ticket_id = NULL
+										  WHERE id = ?`, args...)
+		} else {
+			_, err = tx.ExecContext(ctx, `INSERT INTO tickets (title, description, external_key, external_url, external_source, external_closed_at,
+											  final_estimate, estimate_low, estimate_high, decision_rationale, decision_assumptions, position, is_split, needs_split,
+											  is_calibration, prevote_open, breakout_voters, revealed_at, created_at, id, session_id)
+										  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, append(args, sessionID)...)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore ticket: %w", err)
+		}
+	}
+	for _, ticket := range state.Tickets {
+		if ticket.ParentTicketID == nil || !keep[*ticket.ParentTicketID] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE tickets SET parent_ticket_id = ? WHERE id = ?`, *ticket.ParentTicketID, ticket.ID); err != nil {
+			return fmt.Errorf("failed to restore parent ticket: %w", err)
+		}
+	}
+
+	for _, vote := range state.Votes {
+		_, err := tx.ExecContext(ctx, `INSERT INTO votes (ticket_id, user_id, vote_value, created_at)
+									   SELECT ?, ?, ?, ? WHERE EXISTS (SELECT 1 FROM users WHERE id = ?)`,
+			vote.TicketID, vote.UserID, vote.VoteValue, vote.CreatedAt, vote.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to restore vote: %w", err)
+		}
+	}
+	for _, vote := range state.VoteRounds {
+		_, err := tx.ExecContext(ctx, `INSERT INTO vote_rounds (ticket_id, round, user_id, vote_value, created_at, archived_at)
+									   SELECT ?, ?, ?, ?, ?, ? WHERE EXISTS (SELECT 1 FROM users WHERE id = ?)`,
+			vote.TicketID, vote.Round, vote.UserID, vote.VoteValue, vote.CreatedAt, vote.ArchivedAt, vote.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to restore vote round: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE sessions SET current_ticket_id = ?, discussing_ticket_id = ?, is_voting_active = ?,
+									  voting_started_at = ?, updated_at = ?
+								  WHERE id = ?`,
+		state.CurrentTicketID, state.DiscussingTicketID, state.IsVotingActive, state.VotingStartedAt, time.Now(), sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to restore voting state: %w", err)
+	}
+	return nil
+}
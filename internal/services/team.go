@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"poker-planning/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type TeamService struct {
+	db *sql.DB
+}
+
+func NewTeamService(db *sql.DB) *TeamService {
+	return &TeamService{db: db}
+}
+
+// CreateTeam creates a team in an organization with the defaults its
+// sessions start from. It has no members until they are added.
+func (s *TeamService) CreateTeam(ctx context.Context, orgID, name, estimationUnit string, votingTimeLimit *int, daysPerPoint *float64) (*models.Team, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	team := &models.Team{
+		ID:              uuid.New().String(),
+		OrganizationID:  orgID,
+		Name:            name,
+		EstimationUnit:  estimationUnit,
+		VotingTimeLimit: votingTimeLimit,
+		DaysPerPoint:    daysPerPoint,
+		CreatedAt:       time.Now(),
+	}
+
+	query := `INSERT INTO teams (id, organization_id, name, estimation_unit, voting_time_limit, days_per_point, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err := s.db.ExecContext(ctx, query, team.ID, team.OrganizationID, team.Name, team.EstimationUnit, team.VotingTimeLimit, team.DaysPerPoint, team.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create team: %w", err)
+	}
+
+	return team, nil
+}
+
+// GetTeamByID loads a team with its members.
+func (s *TeamService) GetTeamByID(ctx context.Context, teamID string) (*models.Team, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var team models.Team
+	query := `SELECT id, organization_id, name, estimation_unit, voting_time_limit, days_per_point, created_at FROM teams WHERE id = ?`
+	err := s.db.QueryRowContext(ctx, query, teamID).Scan(
+		&team.ID,
+		&team.OrganizationID,
+		&team.Name,
+		&team.EstimationUnit,
+		&team.VotingTimeLimit,
+		&team.DaysPerPoint,
+		&team.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	members, err := s.getTeamMembers(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team members: %w", err)
+	}
+	team.Members = members
+
+	return &team, nil
+}
+
+func (s *TeamService) getTeamMembers(ctx context.Context, teamID string) ([]models.User, error) {
+	query := `SELECT u.id, u.username, u.created_at, u.last_seen
+			  FROM team_members m
+			  JOIN users u ON u.id = m.user_id
+			  WHERE m.team_id = ?
+			  ORDER BY u.username`
+
+	rows, err := s.db.QueryContext(ctx, query, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []models.User
+	for rows.Next() {
+		var member models.User
+		if err := rows.Scan(&member.ID, &member.Username, &member.CreatedAt, &member.LastSeen); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// GetTeamsForOrganization lists an organization's teams by name, without
+// their members.
+func (s *TeamService) GetTeamsForOrganization(ctx context.Context, orgID string) ([]models.Team, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, organization_id, name, estimation_unit, voting_time_limit, days_per_point, created_at
+			  FROM teams
+			  WHERE organization_id = ?
+			  ORDER BY name`
+
+	rows, err := s.db.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get teams: %w", err)
+	}
+	defer rows.Close()
+
+	var teams []models.Team
+	for rows.Next() {
+		var team models.Team
+		err := rows.Scan(&team.ID, &team.OrganizationID, &team.Name, &team.EstimationUnit, &team.VotingTimeLimit, &team.DaysPerPoint, &team.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		teams = append(teams, team)
+	}
+
+	return teams, rows.Err()
+}
+
+// UpdateTeam saves a team's name and session defaults. Sessions the team
+// already started keep the settings they were created with.
+func (s *TeamService) UpdateTeam(ctx context.Context, team *models.Team) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE teams SET name = ?, estimation_unit = ?, voting_time_limit = ?, days_per_point = ? WHERE id = ?`
+	_, err := s.db.ExecContext(ctx, query, team.Name, team.EstimationUnit, team.VotingTimeLimit, team.DaysPerPoint, team.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update team: %w", err)
+	}
+	return nil
+}
+
+// DeleteTeam removes a team. Its sessions stay in the organization.
+func (s *TeamService) DeleteTeam(ctx context.Context, teamID string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `DELETE FROM teams WHERE id = ?`, teamID)
+	if err != nil {
+		return fmt.Errorf("failed to delete team: %w", err)
+	}
+	return nil
+}
+
+// AddTeamMember adds a user to a team's standing participant list. It
+// reports false if they already were on it.
+func (s *TeamService) AddTeamMember(ctx context.Context, teamID, userID string) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT OR IGNORE INTO team_members (team_id, user_id, added_at) VALUES (?, ?, ?)`
+	result, err := s.db.ExecContext(ctx, query, teamID, userID, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to add team member: %w", err)
+	}
+
+	added, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to add team member: %w", err)
+	}
+	return added > 0, nil
+}
+
+// RemoveTeamMember takes a user off a team. They stay in the sessions the
+// team already started.
+func (s *TeamService) RemoveTeamMember(ctx context.Context, teamID, userID string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM team_members WHERE team_id = ? AND user_id = ?`, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+	if removed, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	} else if removed == 0 {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// GetTeamSessions loads the sessions a team has held, oldest first, with
+// their tickets so velocity can be computed.
+func (s *TeamService) GetTeamSessions(ctx context.Context, teamID string) ([]models.Session, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	sessions, err := getVelocitySessions(ctx, s.db, `team_id = ?`, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team sessions: %w", err)
+	}
+	return sessions, nil
+}
@@ -1,13 +1,93 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
+	"poker-planning/internal/deck"
 	"poker-planning/internal/models"
 )
 
+// TicketFilter selects tickets by estimation state for bulk operations.
+type TicketFilter string
+
+const (
+	TicketFilterAll         TicketFilter = "all"
+	TicketFilterUnestimated TicketFilter = "unestimated"
+	TicketFilterEstimated   TicketFilter = "estimated"
+)
+
+func ParseTicketFilter(value string) (TicketFilter, bool) {
+	switch TicketFilter(value) {
+	case TicketFilterAll, TicketFilterUnestimated, TicketFilterEstimated:
+		return TicketFilter(value), true
+	}
+	return "", false
+}
+
+func (f TicketFilter) condition() string {
+	switch f {
+	case TicketFilterUnestimated:
+		return " AND final_estimate IS NULL"
+	case TicketFilterEstimated:
+		return " AND final_estimate IS NOT NULL"
+	default:
+		return ""
+	}
+}
+
+// ticketColumns is the column list scanned by scanTicket.
+const ticketColumns = `id, session_id, title, description, external_key, external_url, external_closed_at, final_estimate, position, parent_ticket_id, is_split, is_calibration, prevote_open, created_at, revealed_at, decision_rationale, decision_assumptions, needs_split, estimate_low, estimate_high, breakout_voters`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTicket(row rowScanner, ticket *models.Ticket) error {
+	var breakout string
+	err := row.Scan(
+		&ticket.ID,
+		&ticket.SessionID,
+		&ticket.Title,
+		&ticket.Description,
+		&ticket.ExternalKey,
+		&ticket.ExternalURL,
+		&ticket.ExternalClosedAt,
+		&ticket.FinalEstimate,
+		&ticket.Position,
+		&ticket.ParentTicketID,
+		&ticket.IsSplit,
+		&ticket.IsCalibration,
+		&ticket.PrevoteOpen,
+		&ticket.CreatedAt,
+		&ticket.RevealedAt,
+		&ticket.Rationale,
+		&ticket.Assumptions,
+		&ticket.NeedsSplit,
+		&ticket.EstimateLow,
+		&ticket.EstimateHigh,
+		&breakout,
+	)
+	ticket.BreakoutVoters = decodeBreakout(breakout)
+	return err
+}
+
+// decodeBreakout reads a ticket's breakout_voters column, the user IDs of
+// its breakout group separated by commas.
+func decodeBreakout(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+func encodeBreakout(userIDs []string) string {
+	return strings.Join(userIDs, ",")
+}
+
 type TicketService struct {
 	db *sql.DB
 }
@@ -16,21 +96,31 @@ func NewTicketService(db *sql.DB) *TicketService {
 	return &TicketService{db: db}
 }
 
-func (s *TicketService) CreateTicket(sessionID, title, description string) (*models.Ticket, error) {
+// CreateTicket adds a ticket to the end of the session's queue. externalKey
+// is the issue key in the tracker, or empty if there is none.
+func (s *TicketService) CreateTicket(ctx context.Context, sessionID, title, description, externalKey string) (*models.Ticket, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	now := time.Now()
 	
 	// Get next position
 	var maxPosition int
 	posQuery := `SELECT COALESCE(MAX(position), 0) FROM tickets WHERE session_id = ?`
-	err := s.db.QueryRow(posQuery, sessionID).Scan(&maxPosition)
+	err := s.db.QueryRowContext(ctx, posQuery, sessionID).Scan(&maxPosition)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get max position: %w", err)
 	}
 
-	query := `INSERT INTO tickets (session_id, title, description, position, created_at) 
-			  VALUES (?, ?, ?, ?, ?)`
+	var key *string
+	if externalKey != "" {
+		key = &externalKey
+	}
+
+	query := `INSERT INTO tickets (session_id, title, description, external_key, position, created_at) 
+			  VALUES (?, ?, ?, ?, ?, ?)`
 	
-	result, err := s.db.Exec(query, sessionID, title, description, maxPosition+1, now)
+	result, err := s.db.ExecContext(ctx, query, sessionID, title, description, key, maxPosition+1, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ticket: %w", err)
 	}
@@ -45,25 +135,150 @@ func (s *TicketService) CreateTicket(sessionID, title, description string) (*mod
 		SessionID:   sessionID,
 		Title:       title,
 		Description: description,
+		ExternalKey: key,
 		Position:    maxPosition + 1,
 		CreatedAt:   now,
 	}, nil
 }
 
-func (s *TicketService) GetTicketByID(ticketID int) (*models.Ticket, error) {
+// ImportTickets appends tickets brought in from a tracker or file to the end
+// of the session backlog in one transaction. Only the title, description,
+// external key and external URL of each ticket are used; source, such as
+// "linear" or "jira", is recorded with them.
+func (s *TicketService) ImportTickets(ctx context.Context, sessionID, source string, tickets []models.Ticket) ([]models.Ticket, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var maxPosition int
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(position), 0) FROM tickets WHERE session_id = ?`, sessionID).Scan(&maxPosition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get max position: %w", err)
+	}
+
+	insertQuery := `INSERT INTO tickets (session_id, title, description, external_key, external_url, external_source, position, created_at)
+					VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	created := make([]models.Ticket, 0, len(tickets))
+	for i, ticket := range tickets {
+		position := maxPosition + i + 1
+		result, err := tx.ExecContext(ctx, insertQuery, sessionID, ticket.Title, ticket.Description,
+			ticket.ExternalKey, ticket.ExternalURL, source, position, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import ticket: %w", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ticket ID: %w", err)
+		}
+
+		created = append(created, models.Ticket{
+			ID:          int(id),
+			SessionID:   sessionID,
+			Title:       ticket.Title,
+			Description: ticket.Description,
+			ExternalKey: ticket.ExternalKey,
+			ExternalURL: ticket.ExternalURL,
+			Position:    position,
+			CreatedAt:   now,
+		})
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return created, nil
+}
+
+// SyncExternalIssue applies a change made to an issue in Jira to the tickets
+// imported from it that have not been estimated yet: a new title, unless
+// empty, and whether the issue is closed. Only tickets imported from Jira
+// that link to the issue at issueURL are touched, so the same key in
+// another tracker or on another Jira site is left alone. It returns the
+// tickets that changed.
+func (s *TicketService) SyncExternalIssue(ctx context.Context, externalKey, issueURL, title string, closed bool) ([]models.Ticket, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT `+ticketColumns+` FROM tickets
+									   WHERE external_source = 'jira' AND external_key = ? AND external_url = ?
+									   AND final_estimate IS NULL`, externalKey, issueURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tickets: %w", err)
+	}
+
+	var tickets []models.Ticket
+	for rows.Next() {
+		var ticket models.Ticket
+		if err := scanTicket(rows, &ticket); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan ticket: %w", err)
+		}
+		tickets = append(tickets, ticket)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get tickets: %w", err)
+	}
+
+	now := time.Now()
+	var changed []models.Ticket
+	for _, ticket := range tickets {
+		updated := false
+		if title != "" && ticket.Title != title {
+			ticket.Title = title
+			updated = true
+		}
+		if closed != (ticket.ExternalClosedAt != nil) {
+			ticket.ExternalClosedAt = nil
+			if closed {
+				ticket.ExternalClosedAt = &now
+			}
+			updated = true
+		}
+		if !updated {
+			continue
+		}
+
+		_, err := tx.ExecContext(ctx, `UPDATE tickets SET title = ?, external_closed_at = ? WHERE id = ?`,
+			ticket.Title, ticket.ExternalClosedAt, ticket.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update ticket: %w", err)
+		}
+		changed = append(changed, ticket)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return changed, nil
+}
+
+func (s *TicketService) GetTicketByID(ctx context.Context, ticketID int) (*models.Ticket, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	var ticket models.Ticket
-	query := `SELECT id, session_id, title, description, final_estimate, position, created_at 
+	query := `SELECT ` + ticketColumns + ` 
 			  FROM tickets WHERE id = ?`
 	
-	err := s.db.QueryRow(query, ticketID).Scan(
-		&ticket.ID,
-		&ticket.SessionID,
-		&ticket.Title,
-		&ticket.Description,
-		&ticket.FinalEstimate,
-		&ticket.Position,
-		&ticket.CreatedAt,
-	)
+	err := scanTicket(s.db.QueryRowContext(ctx, query, ticketID), &ticket)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -74,18 +289,25 @@ func (s *TicketService) GetTicketByID(ticketID int) (*models.Ticket, error) {
 	return &ticket, nil
 }
 
-func (s *TicketService) UpdateTicket(ticket *models.Ticket) error {
+func (s *TicketService) UpdateTicket(ctx context.Context, ticket *models.Ticket) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	query := `UPDATE tickets SET 
 			  title = ?, 
 			  description = ?, 
 			  final_estimate = ?, 
+			  decision_rationale = ?,
+			  decision_assumptions = ?,
 			  position = ? 
 			  WHERE id = ?`
 	
-	_, err := s.db.Exec(query,
+	_, err := s.db.ExecContext(ctx, query,
 		ticket.Title,
 		ticket.Description,
 		ticket.FinalEstimate,
+		ticket.Rationale,
+		ticket.Assumptions,
 		ticket.Position,
 		ticket.ID,
 	)
@@ -96,17 +318,68 @@ func (s *TicketService) UpdateTicket(ticket *models.Ticket) error {
 	return nil
 }
 
-func (s *TicketService) DeleteTicket(ticketID int) error {
+// SetCalibration marks a ticket as a calibration story, or back as an
+// ordinary one.
+func (s *TicketService) SetCalibration(ctx context.Context, ticketID int, calibration bool) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `UPDATE tickets SET is_calibration = ? WHERE id = ?`, calibration, ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to update ticket: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrTicketNotFound
+	}
+	return nil
+}
+
+// GetCalibrationTickets returns a session's calibration stories in backlog
+// order, with their votes.
+func (s *TicketService) GetCalibrationTickets(ctx context.Context, sessionID string) ([]models.Ticket, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+ticketColumns+` FROM tickets
+										 WHERE session_id = ? AND is_calibration = 1
+										 ORDER BY position`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get calibration tickets: %w", err)
+	}
+	defer rows.Close()
+
+	var tickets []models.Ticket
+	for rows.Next() {
+		var ticket models.Ticket
+		if err := scanTicket(rows, &ticket); err != nil {
+			return nil, fmt.Errorf("failed to scan ticket: %w", err)
+		}
+		tickets = append(tickets, ticket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get calibration tickets: %w", err)
+	}
+
+	if err := loadTicketVotes(ctx, s.db, tickets); err != nil {
+		return nil, fmt.Errorf("failed to load votes: %w", err)
+	}
+	return tickets, nil
+}
+
+func (s *TicketService) DeleteTicket(ctx context.Context, ticketID int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	// Get the ticket to find its position and session
-	ticket, err := s.GetTicketByID(ticketID)
+	ticket, err := s.GetTicketByID(ctx, ticketID)
 	if err != nil {
 		return fmt.Errorf("failed to get ticket: %w", err)
 	}
 	if ticket == nil {
-		return fmt.Errorf("ticket not found")
+		return ErrTicketNotFound
 	}
 
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -114,7 +387,7 @@ func (s *TicketService) DeleteTicket(ticketID int) error {
 
 	// Delete the ticket
 	deleteQuery := `DELETE FROM tickets WHERE id = ?`
-	_, err = tx.Exec(deleteQuery, ticketID)
+	_, err = tx.ExecContext(ctx, deleteQuery, ticketID)
 	if err != nil {
 		return fmt.Errorf("failed to delete ticket: %w", err)
 	}
@@ -122,7 +395,7 @@ func (s *TicketService) DeleteTicket(ticketID int) error {
 	// Update positions of subsequent tickets
 	updateQuery := `UPDATE tickets SET position = position - 1 
 					WHERE session_id = ? AND position > ?`
-	_, err = tx.Exec(updateQuery, ticket.SessionID, ticket.Position)
+	_, err = tx.ExecContext(ctx, updateQuery, ticket.SessionID, ticket.Position)
 	if err != nil {
 		return fmt.Errorf("failed to update positions: %w", err)
 	}
@@ -134,13 +407,176 @@ func (s *TicketService) DeleteTicket(ticketID int) error {
 	return nil
 }
 
-func (s *TicketService) GetTicketsForSession(sessionID string) ([]models.Ticket, error) {
-	query := `SELECT id, session_id, title, description, final_estimate, position, created_at 
+// DuplicateTicket inserts a copy of a ticket's title and description right
+// after it. Votes and the final estimate are not copied.
+func (s *TicketService) DuplicateTicket(ctx context.Context, ticketID int) (*models.Ticket, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	original, err := s.GetTicketByID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil {
+		return nil, ErrTicketNotFound
+	}
+
+	tickets, err := s.insertAfter(ctx, original, []string{original.Title}, original.Description, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tickets[0], nil
+}
+
+// SplitTicket inserts child tickets right after a ticket and marks it as
+// split. Children inherit the parent's description.
+func (s *TicketService) SplitTicket(ctx context.Context, ticketID int, titles []string) ([]models.Ticket, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	parent, err := s.GetTicketByID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, ErrTicketNotFound
+	}
+
+	return s.insertAfter(ctx, parent, titles, parent.Description, &parent.ID)
+}
+
+// insertAfter shifts the tickets following anchor down and inserts one new
+// ticket per title in the gap. When parentID is set the anchor is marked split.
+func (s *TicketService) insertAfter(ctx context.Context, anchor *models.Ticket, titles []string, description string, parentID *int) ([]models.Ticket, error) {
+	now := time.Now()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	shiftQuery := `UPDATE tickets SET position = position + ? 
+				   WHERE session_id = ? AND position > ?`
+	_, err = tx.ExecContext(ctx, shiftQuery, len(titles), anchor.SessionID, anchor.Position)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update positions: %w", err)
+	}
+
+	insertQuery := `INSERT INTO tickets (session_id, title, description, position, parent_ticket_id, created_at) 
+					VALUES (?, ?, ?, ?, ?, ?)`
+
+	tickets := make([]models.Ticket, 0, len(titles))
+	for i, title := range titles {
+		position := anchor.Position + i + 1
+		result, err := tx.ExecContext(ctx, insertQuery, anchor.SessionID, title, description, position, parentID, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ticket: %w", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ticket ID: %w", err)
+		}
+
+		tickets = append(tickets, models.Ticket{
+			ID:             int(id),
+			SessionID:      anchor.SessionID,
+			Title:          title,
+			Description:    description,
+			Position:       position,
+			ParentTicketID: parentID,
+			CreatedAt:      now,
+		})
+	}
+
+	if parentID != nil {
+		_, err = tx.ExecContext(ctx, `UPDATE tickets SET is_split = TRUE WHERE id = ?`, *parentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark ticket as split: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return tickets, nil
+}
+
+func (s *TicketService) CountTickets(ctx context.Context, sessionID string, filter TicketFilter) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var count int
+	query := `SELECT COUNT(*) FROM tickets WHERE session_id = ?` + filter.condition()
+	err := s.db.QueryRowContext(ctx, query, sessionID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tickets: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteTickets removes every ticket of a session matching the filter in one
+// transaction, renumbers the remaining positions and clears the session's
+// current ticket if it was deleted. It returns the number of tickets deleted.
+func (s *TicketService) DeleteTickets(ctx context.Context, sessionID string, filter TicketFilter) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deleteQuery := `DELETE FROM tickets WHERE session_id = ?` + filter.condition()
+	result, err := tx.ExecContext(ctx, deleteQuery, sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete tickets: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deleted tickets: %w", err)
+	}
+
+	// Number the rest from 1 in their current order, ties broken by ID
+	renumberQuery := `UPDATE tickets SET position = renumbered.position
+					  FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY position, id) AS position
+							FROM tickets WHERE session_id = ?) AS renumbered
+					  WHERE tickets.id = renumbered.id`
+	_, err = tx.ExecContext(ctx, renumberQuery, sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update positions: %w", err)
+	}
+
+	sessionQuery := `UPDATE sessions SET current_ticket_id = NULL, is_voting_active = FALSE, updated_at = ? 
+					 WHERE id = ? AND current_ticket_id IS NOT NULL 
+					 AND current_ticket_id NOT IN (SELECT id FROM tickets WHERE session_id = ?)`
+	_, err = tx.ExecContext(ctx, sessionQuery, time.Now(), sessionID, sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear current ticket: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return int(deleted), nil
+}
+
+func (s *TicketService) GetTicketsForSession(ctx context.Context, sessionID string) ([]models.Ticket, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + ticketColumns + ` 
 			  FROM tickets 
 			  WHERE session_id = ? 
 			  ORDER BY position`
 	
-	rows, err := s.db.Query(query, sessionID)
+	rows, err := s.db.QueryContext(ctx, query, sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tickets: %w", err)
 	}
@@ -149,15 +585,7 @@ func (s *TicketService) GetTicketsForSession(sessionID string) ([]models.Ticket,
 	var tickets []models.Ticket
 	for rows.Next() {
 		var ticket models.Ticket
-		err := rows.Scan(
-			&ticket.ID,
-			&ticket.SessionID,
-			&ticket.Title,
-			&ticket.Description,
-			&ticket.FinalEstimate,
-			&ticket.Position,
-			&ticket.CreatedAt,
-		)
+		err := scanTicket(rows, &ticket)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan ticket: %w", err)
 		}
@@ -167,17 +595,78 @@ func (s *TicketService) GetTicketsForSession(sessionID string) ([]models.Ticket,
 	return tickets, nil
 }
 
-func (s *TicketService) SetFinalEstimate(ticketID int, estimate int) error {
-	query := `UPDATE tickets SET final_estimate = ? WHERE id = ?`
-	_, err := s.db.Exec(query, estimate, ticketID)
+// SetFinalEstimate settles a ticket's estimate, with the cards at either
+// end of the suggested range around it if there is one, an optional
+// one-line rationale and the key assumptions behind it.
+func (s *TicketService) SetFinalEstimate(ctx context.Context, ticketID int, estimate string, low, high *string, rationale, assumptions string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE tickets SET final_estimate = ?, estimate_low = ?, estimate_high = ?, decision_rationale = ?, decision_assumptions = ? WHERE id = ?`
+	_, err := s.db.ExecContext(ctx, query, estimate, low, high, rationale, assumptions, ticketID)
 	if err != nil {
 		return fmt.Errorf("failed to set final estimate: %w", err)
 	}
 	return nil
 }
 
-func (s *TicketService) ReorderTickets(sessionID string, ticketIDs []int) error {
-	tx, err := s.db.Begin()
+func (s *TicketService) ClearFinalEstimate(ctx context.Context, ticketID int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE tickets SET final_estimate = NULL, estimate_low = NULL, estimate_high = NULL, decision_rationale = '', decision_assumptions = '' WHERE id = ?`
+	_, err := s.db.ExecContext(ctx, query, ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to clear final estimate: %w", err)
+	}
+	return nil
+}
+
+// SetNeedsSplit flags a ticket as too big to estimate, or clears the flag.
+func (s *TicketService) SetNeedsSplit(ctx context.Context, ticketID int, needsSplit bool) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `UPDATE tickets SET needs_split = ? WHERE id = ?`, needsSplit, ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to flag ticket for splitting: %w", err)
+	}
+	return nil
+}
+
+// copyUnestimatedTickets appends every ticket of one session that has no final
+// estimate to the end of another session's queue. Votes are not copied. It
+// returns the number of tickets copied.
+func copyUnestimatedTickets(ctx context.Context, tx *sql.Tx, fromSessionID, toSessionID string) (int, error) {
+	var maxPosition int
+	posQuery := `SELECT COALESCE(MAX(position), 0) FROM tickets WHERE session_id = ?`
+	err := tx.QueryRowContext(ctx, posQuery, toSessionID).Scan(&maxPosition)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get max position: %w", err)
+	}
+
+	copyQuery := `INSERT INTO tickets (session_id, title, description, external_key, external_url, external_source, external_closed_at, position, created_at)
+				  SELECT ?, title, description, external_key, external_url, external_source, external_closed_at, ? + ROW_NUMBER() OVER (ORDER BY position), ?
+				  FROM tickets
+				  WHERE session_id = ? AND final_estimate IS NULL`
+	result, err := tx.ExecContext(ctx, copyQuery, toSessionID, maxPosition, time.Now(), fromSessionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to copy tickets: %w", err)
+	}
+
+	copied, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count copied tickets: %w", err)
+	}
+
+	return int(copied), nil
+}
+
+func (s *TicketService) ReorderTickets(ctx context.Context, sessionID string, ticketIDs []int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -186,7 +675,7 @@ func (s *TicketService) ReorderTickets(sessionID string, ticketIDs []int) error
 	query := `UPDATE tickets SET position = ? WHERE id = ? AND session_id = ?`
 	
 	for i, ticketID := range ticketIDs {
-		_, err = tx.Exec(query, i+1, ticketID, sessionID)
+		_, err = tx.ExecContext(ctx, query, i+1, ticketID, sessionID)
 		if err != nil {
 			return fmt.Errorf("failed to update ticket position: %w", err)
 		}
@@ -197,4 +686,228 @@ func (s *TicketService) ReorderTickets(sessionID string, ticketIDs []int) error
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+// maxTicketHistory caps how many earlier estimates are listed per ticket.
+const maxTicketHistory = 5
+
+// GetEstimationHistory finds earlier estimates of a session's tickets in the
+// other sessions the user took part in, newest first. Tickets match on their
+// external key, or on their title ignoring case when they have none. Only
+// tickets that were voted on or given a final estimate are listed.
+func (s *TicketService) GetEstimationHistory(ctx context.Context, sessionID, userID string, tickets []models.Ticket) (map[int][]models.TicketEstimate, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var keys, titles []interface{}
+	for _, ticket := range tickets {
+		if ticket.ExternalKey != nil {
+			keys = append(keys, *ticket.ExternalKey)
+		} else {
+			titles = append(titles, ticket.Title)
+		}
+	}
+
+	var conditions []string
+	if len(keys) > 0 {
+		conditions = append(conditions, `t.external_key IN (`+placeholderList(len(keys))+`)`)
+	}
+	if len(titles) > 0 {
+		conditions = append(conditions, `t.title COLLATE NOCASE IN (`+placeholderList(len(titles))+`)`)
+	}
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT t.id, t.title, t.external_key, t.final_estimate, t.created_at, t.revealed_at,
+					 s.id, s.name, s.estimation_unit,
+					 (SELECT COUNT(DISTINCT round) FROM vote_rounds WHERE ticket_id = t.id AND round > 0) +
+					 EXISTS (SELECT 1 FROM votes WHERE ticket_id = t.id) AS rounds
+			  FROM tickets t
+			  JOIN sessions s ON s.id = t.session_id
+			  JOIN participants p ON p.session_id = s.id AND p.user_id = ?
+			  WHERE t.session_id != ? AND (` + strings.Join(conditions, " OR ") + `)
+			  ORDER BY COALESCE(t.revealed_at, t.created_at) DESC`
+
+	args := append([]interface{}{userID, sessionID}, keys...)
+	args = append(args, titles...)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get estimation history: %w", err)
+	}
+	defer rows.Close()
+
+	history := make(map[int][]models.TicketEstimate)
+	for rows.Next() {
+		var estimate models.TicketEstimate
+		var title string
+		var key *string
+		err := rows.Scan(&estimate.TicketID, &title, &key, &estimate.FinalEstimate, &estimate.CreatedAt, &estimate.RevealedAt,
+			&estimate.SessionID, &estimate.SessionName, &estimate.EstimationUnit, &estimate.Rounds)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan estimation history: %w", err)
+		}
+		if estimate.FinalEstimate == nil && estimate.Rounds == 0 {
+			continue
+		}
+
+		for _, ticket := range tickets {
+			var matches bool
+			if ticket.ExternalKey != nil {
+				matches = key != nil && *key == *ticket.ExternalKey
+			} else {
+				matches = strings.EqualFold(title, ticket.Title)
+			}
+			if matches && len(history[ticket.ID]) < maxTicketHistory {
+				history[ticket.ID] = append(history[ticket.ID], estimate)
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get estimation history: %w", err)
+	}
+
+	return history, nil
+}
+
+// placeholderList returns n comma-separated query placeholders.
+func placeholderList(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// TicketQuery filters the tickets returned by FindTickets.
+type TicketQuery struct {
+	ExternalKey string // only tickets with this key, if set
+	Limit       int
+	Offset      int
+}
+
+// TicketRecord is a ticket with its session and every round of votes on it,
+// oldest first. The last round holds the ticket's current votes.
+type TicketRecord struct {
+	models.Ticket
+	SessionName    string
+	EstimationUnit string
+	SpecialCards   []deck.SpecialCard // of the session
+	Rounds         []models.VoteRound
+}
+
+// FindTickets lists tickets across all sessions, oldest first, for
+// reporting outside the app.
+func (s *TicketService) FindTickets(ctx context.Context, q TicketQuery) ([]TicketRecord, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT t.id, t.session_id, t.title, t.description, t.external_key, t.external_url, t.final_estimate, t.estimate_low, t.estimate_high, t.position,
+					 t.parent_ticket_id, t.is_split, t.is_calibration, t.created_at, t.revealed_at, t.decision_rationale, t.decision_assumptions, t.needs_split, s.name, s.estimation_unit, s.special_cards
+			  FROM tickets t
+			  JOIN sessions s ON s.id = t.session_id
+			  WHERE ? = '' OR t.external_key = ?
+			  ORDER BY t.id
+			  LIMIT ? OFFSET ?`
+
+	rows, err := s.db.QueryContext(ctx, query, q.ExternalKey, q.ExternalKey, q.Limit, q.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tickets: %w", err)
+	}
+	defer rows.Close()
+
+	var records []TicketRecord
+	var tickets []models.Ticket
+	for rows.Next() {
+		var record TicketRecord
+		var specialCards sql.NullString
+		ticket := &record.Ticket
+		err := rows.Scan(&ticket.ID, &ticket.SessionID, &ticket.Title, &ticket.Description, &ticket.ExternalKey,
+			&ticket.ExternalURL, &ticket.FinalEstimate, &ticket.EstimateLow, &ticket.EstimateHigh, &ticket.Position, &ticket.ParentTicketID, &ticket.IsSplit, &ticket.IsCalibration,
+			&ticket.CreatedAt, &ticket.RevealedAt, &ticket.Rationale, &ticket.Assumptions, &ticket.NeedsSplit, &record.SessionName, &record.EstimationUnit, &specialCards)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ticket: %w", err)
+		}
+		session := models.Session{EstimationUnit: record.EstimationUnit}
+		if session.CustomSpecialCards, err = decodeSpecialCards(specialCards); err != nil {
+			return nil, err
+		}
+		record.SpecialCards = session.SpecialCards()
+		records = append(records, record)
+		tickets = append(tickets, record.Ticket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find tickets: %w", err)
+	}
+
+	if err := loadTicketVotes(ctx, s.db, tickets); err != nil {
+		return nil, fmt.Errorf("failed to load votes: %w", err)
+	}
+	archived, err := s.loadArchivedRounds(ctx, tickets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vote rounds: %w", err)
+	}
+
+	for i := range records {
+		ticket := tickets[i]
+		rounds := archived[ticket.ID]
+		if len(ticket.Votes) > 0 {
+			next := 1
+			if len(rounds) > 0 {
+				next = rounds[len(rounds)-1].Round + 1
+			}
+			rounds = append(rounds, models.VoteRound{Round: next, Votes: ticket.Votes})
+		}
+		records[i].Votes = ticket.Votes
+		records[i].Rounds = rounds
+	}
+
+	return records, nil
+}
+
+// loadArchivedRounds loads the earlier rounds of votes on the tickets, as
+// archived by ArchiveVotesForTicket, keyed by ticket ID.
+func (s *TicketService) loadArchivedRounds(ctx context.Context, tickets []models.Ticket) (map[int][]models.VoteRound, error) {
+	if len(tickets) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, len(tickets))
+	for i, ticket := range tickets {
+		args[i] = ticket.ID
+	}
+
+	query := `SELECT vr.ticket_id, vr.round, vr.user_id, vr.vote_value, vr.created_at,
+					 u.username, COALESCE(p.weight, 1), COALESCE(p.is_stakeholder, FALSE)
+			  FROM vote_rounds vr
+			  JOIN users u ON u.id = vr.user_id
+			  JOIN tickets t ON t.id = vr.ticket_id
+			  LEFT JOIN participants p ON p.session_id = t.session_id AND p.user_id = vr.user_id
+			  WHERE vr.ticket_id IN (` + placeholderList(len(tickets)) + `)
+			  ORDER BY vr.ticket_id, vr.round, vr.id`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rounds := make(map[int][]models.VoteRound)
+	for rows.Next() {
+		var vote models.Vote
+		var user models.User
+		var round int
+		err := rows.Scan(&vote.TicketID, &round, &vote.UserID, &vote.VoteValue, &vote.CreatedAt, &user.Username, &vote.Weight, &vote.Advisory)
+		if err != nil {
+			return nil, err
+		}
+		user.ID = vote.UserID
+		vote.User = &user
+
+		ticketRounds := rounds[vote.TicketID]
+		if len(ticketRounds) == 0 || ticketRounds[len(ticketRounds)-1].Round != round {
+			ticketRounds = append(ticketRounds, models.VoteRound{Round: round})
+		}
+		last := &ticketRounds[len(ticketRounds)-1]
+		last.Votes = append(last.Votes, vote)
+		rounds[vote.TicketID] = ticketRounds
+	}
+
+	return rounds, rows.Err()
+}
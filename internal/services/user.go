@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -18,12 +19,15 @@ func NewUserService(db *sql.DB) *UserService {
 	return &UserService{db: db}
 }
 
-func (s *UserService) CreateUser(username string) (*models.User, error) {
+func (s *UserService) CreateUser(ctx context.Context, username string) (*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	userID := uuid.New().String()
 	now := time.Now()
 
 	query := `INSERT INTO users (id, username, created_at, last_seen) VALUES (?, ?, ?, ?)`
-	_, err := s.db.Exec(query, userID, username, now, now)
+	_, err := s.db.ExecContext(ctx, query, userID, username, now, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
@@ -36,15 +40,55 @@ func (s *UserService) CreateUser(username string) (*models.User, error) {
 	}, nil
 }
 
-func (s *UserService) GetUserByID(userID string) (*models.User, error) {
+// GetOrCreateDirectoryUser finds the user signed in through the directory
+// entry with this DN, creating them on first sign-in. Their username follows
+// the directory's name for them.
+func (s *UserService) GetOrCreateDirectoryUser(ctx context.Context, dn, username string) (*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	now := time.Now()
+	query := `INSERT INTO users (id, username, ldap_dn, created_at, last_seen) VALUES (?, ?, ?, ?, ?)
+			  ON CONFLICT(ldap_dn) DO UPDATE SET username = excluded.username, last_seen = excluded.last_seen`
+	_, err := s.db.ExecContext(ctx, query, uuid.New().String(), username, dn, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save directory user: %w", err)
+	}
+
+	var userID string
+	if err := s.db.QueryRowContext(ctx, `SELECT id FROM users WHERE ldap_dn = ?`, dn).Scan(&userID); err != nil {
+		return nil, fmt.Errorf("failed to get directory user: %w", err)
+	}
+
+	return s.GetUserByID(ctx, userID)
+}
+
+func (s *UserService) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	var user models.User
-	query := `SELECT id, username, created_at, last_seen FROM users WHERE id = ?`
+	query := `SELECT u.id, u.username, u.created_at, u.last_seen,
+					 COALESCE(p.preferred_unit, ''), COALESCE(p.auto_ready, FALSE), COALESCE(p.reduced_motion, FALSE),
+					 COALESCE(p.timezone, ''), COALESCE(p.notify_voting_started, FALSE), COALESCE(p.notify_votes_revealed, FALSE),
+					 COALESCE(p.notification_level, ''), COALESCE(p.do_not_disturb, FALSE)
+			  FROM users u 
+			  LEFT JOIN user_preferences p ON p.user_id = u.id 
+			  WHERE u.id = ?`
 	
-	err := s.db.QueryRow(query, userID).Scan(
+	err := s.db.QueryRowContext(ctx, query, userID).Scan(
 		&user.ID,
 		&user.Username,
 		&user.CreatedAt,
 		&user.LastSeen,
+		&user.Preferences.PreferredUnit,
+		&user.Preferences.AutoReady,
+		&user.Preferences.ReducedMotion,
+		&user.Preferences.Timezone,
+		&user.Preferences.NotifyVotingStarted,
+		&user.Preferences.NotifyVotesRevealed,
+		&user.Preferences.NotificationLevel,
+		&user.Preferences.DoNotDisturb,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -56,20 +100,255 @@ func (s *UserService) GetUserByID(userID string) (*models.User, error) {
 	return &user, nil
 }
 
-func (s *UserService) UpdateLastSeen(userID string) error {
+// UpdatePreferences replaces a user's stored preferences.
+func (s *UserService) UpdatePreferences(ctx context.Context, userID string, prefs models.UserPreferences) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT INTO user_preferences (user_id, preferred_unit, auto_ready, reduced_motion, timezone, notify_voting_started, notify_votes_revealed, notification_level, do_not_disturb, updated_at) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) 
+			  ON CONFLICT(user_id) DO UPDATE SET 
+			  preferred_unit = excluded.preferred_unit, 
+			  auto_ready = excluded.auto_ready, 
+			  reduced_motion = excluded.reduced_motion, 
+			  timezone = excluded.timezone, 
+			  notify_voting_started = excluded.notify_voting_started, 
+			  notify_votes_revealed = excluded.notify_votes_revealed, 
+			  notification_level = excluded.notification_level, 
+			  do_not_disturb = excluded.do_not_disturb, 
+			  updated_at = excluded.updated_at`
+	
+	_, err := s.db.ExecContext(ctx, query,
+		userID,
+		prefs.PreferredUnit,
+		prefs.AutoReady,
+		prefs.ReducedMotion,
+		prefs.Timezone,
+		prefs.NotifyVotingStarted,
+		prefs.NotifyVotesRevealed,
+		prefs.NotificationLevel,
+		prefs.DoNotDisturb,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update preferences: %w", err)
+	}
+	
+	return nil
+}
+
+// DoNotDisturb returns which participants of a session are in do not
+// disturb, and must not be nudged, sent emoji reactions or pushed.
+func (s *UserService) DoNotDisturb(ctx context.Context, sessionID string) (map[string]bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT p.user_id FROM participants p
+			  JOIN user_preferences up ON up.user_id = p.user_id
+			  WHERE p.session_id = ? AND up.do_not_disturb`
+	rows, err := s.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get do not disturb participants: %w", err)
+	}
+	defer rows.Close()
+
+	users := make(map[string]bool)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan do not disturb participant: %w", err)
+		}
+		users[userID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get do not disturb participants: %w", err)
+	}
+
+	return users, nil
+}
+
+// MaxRecentEmojis is how many distinct emojis are remembered per user.
+const MaxRecentEmojis = 6
+
+// RecordRecentEmoji marks an emoji as just used by the user and forgets the
+// oldest ones beyond MaxRecentEmojis.
+func (s *UserService) RecordRecentEmoji(ctx context.Context, userID, emoji string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	upsertQuery := `INSERT INTO recent_emojis (user_id, emoji, used_at) VALUES (?, ?, ?) 
+					ON CONFLICT(user_id, emoji) DO UPDATE SET used_at = excluded.used_at`
+	_, err = tx.ExecContext(ctx, upsertQuery, userID, emoji, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record emoji: %w", err)
+	}
+
+	pruneQuery := `DELETE FROM recent_emojis 
+				   WHERE user_id = ? AND emoji NOT IN (
+					   SELECT emoji FROM recent_emojis WHERE user_id = ? ORDER BY used_at DESC LIMIT ?
+				   )`
+	_, err = tx.ExecContext(ctx, pruneQuery, userID, userID, MaxRecentEmojis)
+	if err != nil {
+		return fmt.Errorf("failed to prune recent emojis: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *UserService) GetRecentEmojis(ctx context.Context, userID string) ([]models.RecentEmoji, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT user_id, emoji, used_at FROM recent_emojis 
+			  WHERE user_id = ? 
+			  ORDER BY used_at DESC 
+			  LIMIT ?`
+	
+	rows, err := s.db.QueryContext(ctx, query, userID, MaxRecentEmojis)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent emojis: %w", err)
+	}
+	defer rows.Close()
+
+	var emojis []models.RecentEmoji
+	for rows.Next() {
+		var emoji models.RecentEmoji
+		if err := rows.Scan(&emoji.UserID, &emoji.Emoji, &emoji.UsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recent emoji: %w", err)
+		}
+		emojis = append(emojis, emoji)
+	}
+
+	return emojis, nil
+}
+
+// LastVote returns what a participant last voted in a session, with the
+// session's cards. It returns nil if the user is not a participant.
+func (s *UserService) LastVote(ctx context.Context, sessionID, userID string) (*models.LastVote, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var lastVote models.LastVote
+	var session models.Session
+	var specialCards sql.NullString
+	query := `SELECT p.last_vote, s.estimation_unit, s.special_cards
+			  FROM participants p
+			  JOIN sessions s ON s.id = p.session_id
+			  WHERE p.session_id = ? AND p.user_id = ?`
+	err := s.db.QueryRowContext(ctx, query, sessionID, userID).Scan(&lastVote.Vote, &session.EstimationUnit, &specialCards)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last vote: %w", err)
+	}
+
+	if session.CustomSpecialCards, err = decodeSpecialCards(specialCards); err != nil {
+		return nil, err
+	}
+
+	lastVote.Cards = session.Deck()
+	return &lastVote, nil
+}
+
+// SetLastVote replaces what a participant last voted in a session, e.g. to
+// set up the next "same as last time". Votes set it as they are cast.
+func (s *UserService) SetLastVote(ctx context.Context, sessionID, userID, vote string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `UPDATE participants SET last_vote = ? WHERE session_id = ? AND user_id = ?`, vote, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set last vote: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to set last vote: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotParticipant
+	}
+	return nil
+}
+
+// DisplayUnit returns the unit a participant sees a session's cards in, or
+// an empty string for the session's own.
+func (s *UserService) DisplayUnit(ctx context.Context, sessionID, userID string) (string, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var unit string
+	query := `SELECT COALESCE(display_unit, '') FROM participants WHERE session_id = ? AND user_id = ?`
+	err := s.db.QueryRowContext(ctx, query, sessionID, userID).Scan(&unit)
+	if err != nil && err != sql.ErrNoRows {
+		return "", fmt.Errorf("failed to get display unit: %w", err)
+	}
+	return unit, nil
+}
+
+// SetDisplayUnit changes the unit a participant sees a session's cards in;
+// an empty unit shows them as they are.
+func (s *UserService) SetDisplayUnit(ctx context.Context, sessionID, userID, unit string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, `UPDATE participants SET display_unit = NULLIF(?, '') WHERE session_id = ? AND user_id = ?`, unit, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to set display unit: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to set display unit: %w", err)
+	}
+	if rows == 0 {
+		return ErrNotParticipant
+	}
+	return nil
+}
+
+// IsSessionOwner reports whether the user currently owns the session.
+func (s *UserService) IsSessionOwner(ctx context.Context, sessionID, userID string) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var owner bool
+	query := `SELECT EXISTS(SELECT 1 FROM sessions WHERE id = ? AND owner_id = ?)`
+	if err := s.db.QueryRowContext(ctx, query, sessionID, userID).Scan(&owner); err != nil {
+		return false, fmt.Errorf("failed to check session owner: %w", err)
+	}
+	return owner, nil
+}
+
+func (s *UserService) UpdateLastSeen(ctx context.Context, userID string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	query := `UPDATE users SET last_seen = ? WHERE id = ?`
-	_, err := s.db.Exec(query, time.Now(), userID)
+	_, err := s.db.ExecContext(ctx, query, time.Now(), userID)
 	if err != nil {
 		return fmt.Errorf("failed to update last seen: %w", err)
 	}
 	return nil
 }
 
-func (s *UserService) CleanupInactiveUsers() error {
+func (s *UserService) CleanupInactiveUsers(ctx context.Context) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	cutoff := time.Now().Add(-6 * time.Hour)
 	query := `DELETE FROM users WHERE last_seen < ?`
 	
-	_, err := s.db.Exec(query, cutoff)
+	_, err := s.db.ExecContext(ctx, query, cutoff)
 	if err != nil {
 		return fmt.Errorf("failed to cleanup inactive users: %w", err)
 	}
@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"poker-planning/internal/models"
@@ -16,13 +18,69 @@ func NewVotingService(db *sql.DB) *VotingService {
 	return &VotingService{db: db}
 }
 
-func (s *VotingService) SubmitVote(ticketID int, userID, voteValue string) (*models.Vote, error) {
+// SubmitVote records a user's vote on the session's current ticket. Only
+// participants can vote, and only while voting is active or, to change a
+// vote, after a round on the ticket has been revealed. The checks and the
+// write share a transaction so they see the same session state.
+func (s *VotingService) SubmitVote(ctx context.Context, sessionID, userID, voteValue string) (*models.Vote, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var isParticipant bool
+	participantQuery := `SELECT EXISTS(SELECT 1 FROM participants WHERE session_id = ? AND user_id = ?)`
+	err = tx.QueryRowContext(ctx, participantQuery, sessionID, userID).Scan(&isParticipant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check participant: %w", err)
+	}
+	if !isParticipant {
+		return nil, ErrNotParticipant
+	}
+
+	var ticketID sql.NullInt64
+	var isVotingActive bool
+	var voteCount int
+	var changeWindow sql.NullInt64
+	var revealedAt sql.NullTime
+	var breakout string
+	stateQuery := `SELECT s.current_ticket_id, s.is_voting_active,
+					      (SELECT COUNT(*) FROM votes v WHERE v.ticket_id = s.current_ticket_id),
+					      s.vote_change_window, t.revealed_at, COALESCE(t.breakout_voters, '')
+				   FROM sessions s
+				   LEFT JOIN tickets t ON t.id = s.current_ticket_id
+				   WHERE s.id = ?`
+	err = tx.QueryRowContext(ctx, stateQuery, sessionID).Scan(&ticketID, &isVotingActive, &voteCount, &changeWindow, &revealedAt, &breakout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voting state: %w", err)
+	}
+	if !ticketID.Valid {
+		return nil, ErrNoActiveTicket
+	}
+	if !isVotingActive && voteCount == 0 {
+		return nil, ErrVotingNotActive
+	}
+	if !(models.Ticket{BreakoutVoters: decodeBreakout(breakout)}).CanVote(userID) {
+		return nil, ErrNotInBreakout
+	}
+
 	now := time.Now()
+
+	// Revealed votes may only change within the session's window
+	if !isVotingActive && changeWindow.Valid {
+		if !revealedAt.Valid || !now.Before(revealedAt.Time.Add(time.Duration(changeWindow.Int64)*time.Second)) {
+			return nil, ErrVotesLocked
+		}
+	}
 	
 	query := `INSERT OR REPLACE INTO votes (ticket_id, user_id, vote_value, created_at) 
 			  VALUES (?, ?, ?, ?)`
 	
-	result, err := s.db.Exec(query, ticketID, userID, voteValue, now)
+	result, err := tx.ExecContext(ctx, query, ticketID.Int64, userID, voteValue, now)
 	if err != nil {
 		return nil, fmt.Errorf("failed to submit vote: %w", err)
 	}
@@ -32,24 +90,39 @@ func (s *VotingService) SubmitVote(ticketID int, userID, voteValue string) (*mod
 		return nil, fmt.Errorf("failed to get vote ID: %w", err)
 	}
 
+	// Remembered for voting "same as last time" on later tickets
+	_, err = tx.ExecContext(ctx, `UPDATE participants SET last_vote = ? WHERE session_id = ? AND user_id = ?`, voteValue, sessionID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remember last vote: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return &models.Vote{
 		ID:        int(voteID),
-		TicketID:  ticketID,
+		TicketID:  int(ticketID.Int64),
 		UserID:    userID,
 		VoteValue: voteValue,
 		CreatedAt: now,
 	}, nil
 }
 
-func (s *VotingService) GetVotesForTicket(ticketID int) ([]models.Vote, error) {
+func (s *VotingService) GetVotesForTicket(ctx context.Context, ticketID int) ([]models.Vote, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	query := `SELECT v.id, v.ticket_id, v.user_id, v.vote_value, v.created_at,
-					 u.username
+					 u.username, COALESCE(p.weight, 1), COALESCE(p.is_stakeholder, FALSE)
 			  FROM votes v
 			  JOIN users u ON v.user_id = u.id
+			  JOIN tickets t ON t.id = v.ticket_id
+			  LEFT JOIN participants p ON p.session_id = t.session_id AND p.user_id = v.user_id
 			  WHERE v.ticket_id = ?
 			  ORDER BY v.created_at`
 	
-	rows, err := s.db.Query(query, ticketID)
+	rows, err := s.db.QueryContext(ctx, query, ticketID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get votes: %w", err)
 	}
@@ -67,6 +140,8 @@ func (s *VotingService) GetVotesForTicket(ticketID int) ([]models.Vote, error) {
 			&vote.VoteValue,
 			&vote.CreatedAt,
 			&user.Username,
+			&vote.Weight,
+			&vote.Advisory,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan vote: %w", err)
@@ -80,10 +155,13 @@ func (s *VotingService) GetVotesForTicket(ticketID int) ([]models.Vote, error) {
 	return votes, nil
 }
 
-func (s *VotingService) ClearVotesForTicket(ticketID int) error {
+func (s *VotingService) ClearVotesForTicket(ctx context.Context, ticketID int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM votes WHERE ticket_id = ?`
 	
-	_, err := s.db.Exec(query, ticketID)
+	_, err := s.db.ExecContext(ctx, query, ticketID)
 	if err != nil {
 		return fmt.Errorf("failed to clear votes: %w", err)
 	}
@@ -91,13 +169,16 @@ func (s *VotingService) ClearVotesForTicket(ticketID int) error {
 	return nil
 }
 
-func (s *VotingService) GetUserVoteForTicket(ticketID int, userID string) (*models.Vote, error) {
+func (s *VotingService) GetUserVoteForTicket(ctx context.Context, ticketID int, userID string) (*models.Vote, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
 	var vote models.Vote
 	query := `SELECT id, ticket_id, user_id, vote_value, created_at 
 			  FROM votes 
 			  WHERE ticket_id = ? AND user_id = ?`
 	
-	err := s.db.QueryRow(query, ticketID, userID).Scan(
+	err := s.db.QueryRowContext(ctx, query, ticketID, userID).Scan(
 		&vote.ID,
 		&vote.TicketID,
 		&vote.UserID,
@@ -112,4 +193,237 @@ func (s *VotingService) GetUserVoteForTicket(ticketID int, userID string) (*mode
 	}
 
 	return &vote, nil
-}
\ No newline at end of file
+}
+// CurrentRound returns which round of votes on a ticket its current votes
+// are: one more than the rounds archived before them.
+func (s *VotingService) CurrentRound(ctx context.Context, ticketID int) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	var round int
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(round), 0) + 1 FROM vote_rounds WHERE ticket_id = ?`, ticketID).Scan(&round)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current round: %w", err)
+	}
+	return round, nil
+}
+
+// ArchiveVotesForTicket moves the current votes for a ticket into vote_rounds
+// as a new round and clears them, so a fresh round can start without losing
+// what was voted before.
+func (s *VotingService) ArchiveVotesForTicket(ctx context.Context, ticketID int) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = archiveVotes(ctx, tx, ticketID)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func archiveVotes(ctx context.Context, tx *sql.Tx, ticketID int) error {
+	var round int
+	roundQuery := `SELECT COALESCE(MAX(round), 0) + 1 FROM vote_rounds WHERE ticket_id = ?`
+	err := tx.QueryRowContext(ctx, roundQuery, ticketID).Scan(&round)
+	if err != nil {
+		return fmt.Errorf("failed to get next round: %w", err)
+	}
+
+	archiveQuery := `INSERT INTO vote_rounds (ticket_id, round, user_id, vote_value, created_at, archived_at)
+					 SELECT ticket_id, ?, user_id, vote_value, created_at, ?
+					 FROM votes WHERE ticket_id = ?`
+	_, err = tx.ExecContext(ctx, archiveQuery, round, time.Now(), ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to archive votes: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM votes WHERE ticket_id = ?`, ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to clear votes: %w", err)
+	}
+
+	return nil
+}
+
+// StartVoting makes ticketID the session's current ticket and opens voting on
+// it, closing it for pre-votes. Votes already on the ticket are archived as
+// a past round when archive is set and discarded otherwise. Everything
+// happens in one transaction that fails with ErrSessionModified if the
+// session's updated_at no longer matches the loaded session, and is refused
+// if the ticket leaves out fields the session requires. Only the
+// participants in breakout may vote, or everyone when it is empty.
+func (s *VotingService) StartVoting(ctx context.Context, session *models.Session, ticketID int, archive bool, breakout []string) error {
+	return s.startVoting(ctx, session, ticketID, archive, breakout, false)
+}
+
+// ReopenTicket restarts voting on an estimated ticket like StartVoting with
+// archive set, clearing its final estimate and decision record in the same
+// transaction, so a refused reopen leaves the ticket untouched.
+func (s *VotingService) ReopenTicket(ctx context.Context, session *models.Session, ticketID int, breakout []string) error {
+	return s.startVoting(ctx, session, ticketID, true, breakout, true)
+}
+
+func (s *VotingService) startVoting(ctx context.Context, session *models.Session, ticketID int, archive bool, breakout []string, reopen bool) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err = requireFields(ctx, tx, session, ticketID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	query := `UPDATE sessions SET current_ticket_id = ?, is_voting_active = TRUE, voting_started_at = ?, updated_at = ?
+			  WHERE id = ? AND updated_at = ?`
+	err = updateSessionState(ctx, tx, query, ticketID, now, now, session.ID, session.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	// Pre-voting ends once the ticket comes up live
+	_, err = tx.ExecContext(ctx, `UPDATE tickets SET prevote_open = FALSE, breakout_voters = ? WHERE id = ?`, encodeBreakout(breakout), ticketID)
+	if err != nil {
+		return fmt.Errorf("failed to open ticket for voting: %w", err)
+	}
+
+	if reopen {
+		_, err = tx.ExecContext(ctx, `UPDATE tickets SET final_estimate = NULL, estimate_low = NULL, estimate_high = NULL, decision_rationale = '', decision_assumptions = '' WHERE id = ?`, ticketID)
+		if err != nil {
+			return fmt.Errorf("failed to clear final estimate: %w", err)
+		}
+	}
+
+	if archive {
+		err = archiveVotes(ctx, tx, ticketID)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM votes WHERE ticket_id = ?`, ticketID)
+		if err != nil {
+			err = fmt.Errorf("failed to clear votes: %w", err)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if session.CurrentTicket != nil && session.CurrentTicket.ID == ticketID {
+		session.CurrentTicket.BreakoutVoters = breakout
+	}
+	session.CurrentTicketID = &ticketID
+	session.IsVotingActive = true
+	session.VotingStartedAt = &now
+	session.UpdatedAt = now
+	return nil
+}
+
+// CheckRequiredFields refuses a ticket that leaves out fields the session
+// requires before a ticket can be voted on, listing them.
+func CheckRequiredFields(session *models.Session, ticket *models.Ticket) error {
+	missing := session.MissingFields(*ticket)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	labels := make([]string, len(missing))
+	for i, field := range missing {
+		labels[i] = field.Label()
+	}
+	return newError(ErrValidation, fmt.Sprintf("%q is missing what this session requires before voting: %s", ticket.Title, strings.Join(labels, ", ")))
+}
+
+// requireFields is CheckRequiredFields for a ticket loaded in tx.
+func requireFields(ctx context.Context, tx *sql.Tx, session *models.Session, ticketID int) error {
+	if len(session.RequiredFields) == 0 {
+		return nil
+	}
+
+	ticket := models.Ticket{ID: ticketID}
+	err := tx.QueryRowContext(ctx, `SELECT title, COALESCE(description, ''), external_key, external_url FROM tickets WHERE id = ? AND session_id = ?`, ticketID, session.ID).
+		Scan(&ticket.Title, &ticket.Description, &ticket.ExternalKey, &ticket.ExternalURL)
+	if err == sql.ErrNoRows {
+		return ErrTicketNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get ticket: %w", err)
+	}
+	return CheckRequiredFields(session, &ticket)
+}
+
+// EndVoting closes voting on a session, revealing the votes. Like
+// StartVoting it fails with ErrSessionModified if the session changed since
+// it was loaded.
+func (s *VotingService) EndVoting(ctx context.Context, session *models.Session) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	query := `UPDATE sessions SET is_voting_active = FALSE, updated_at = ?
+			  WHERE id = ? AND updated_at = ?`
+	err = updateSessionState(ctx, tx, query, now, session.ID, session.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if session.CurrentTicketID != nil {
+		_, err = tx.ExecContext(ctx, `UPDATE tickets SET revealed_at = ? WHERE id = ?`, now, *session.CurrentTicketID)
+		if err != nil {
+			return fmt.Errorf("failed to mark ticket revealed: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	session.IsVotingActive = false
+	session.UpdatedAt = now
+	if session.CurrentTicket != nil {
+		session.CurrentTicket.RevealedAt = &now
+	}
+	return nil
+}
+
+// updateSessionState runs a conditional session update and reports
+// ErrSessionModified when the updated_at condition matched no row.
+func updateSessionState(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) error {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return ErrSessionModified
+	}
+
+	return nil
+}
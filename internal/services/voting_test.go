@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"poker-planning/internal/database"
+	"poker-planning/internal/models"
+)
+
+// newBenchSession opens a fresh database and sets up a session with the given
+// number of participants and one ticket that is being voted on.
+func newBenchSession(b *testing.B, participants int) (*database.DB, *models.Session, []string) {
+	b.Helper()
+	ctx := context.Background()
+
+	db, err := database.NewDB(filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	users := NewUserService(db.DB)
+	sessions := NewSessionService(db.DB)
+
+	owner, err := users.CreateUser(ctx, "owner")
+	if err != nil {
+		b.Fatal(err)
+	}
+	session, err := sessions.CreateSession(ctx, "Bench", owner.ID, "points", nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	userIDs := make([]string, participants)
+	for i := range userIDs {
+		user, err := users.CreateUser(ctx, fmt.Sprintf("voter%d", i))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := sessions.JoinSession(ctx, session.ID, user.ID); err != nil {
+			b.Fatal(err)
+		}
+		userIDs[i] = user.ID
+	}
+
+	ticket, err := NewTicketService(db.DB).CreateTicket(ctx, session.ID, "Bench ticket", "", "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := NewVotingService(db.DB).StartVoting(ctx, session, ticket.ID, false, nil); err != nil {
+		b.Fatal(err)
+	}
+
+	return db, session, userIDs
+}
+
+func BenchmarkSubmitVote(b *testing.B) {
+	db, session, userIDs := newBenchSession(b, 50)
+	voting := NewVotingService(db.DB)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := voting.SubmitVote(ctx, session.ID, userIDs[i%len(userIDs)], "5"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSubmitVoteParallel(b *testing.B) {
+	db, session, userIDs := newBenchSession(b, 50)
+	voting := NewVotingService(db.DB)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			if _, err := voting.SubmitVote(ctx, session.ID, userIDs[i%len(userIDs)], "8"); err != nil {
+				b.Error(err)
+				return
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkGetSessionByID(b *testing.B) {
+	db, session, userIDs := newBenchSession(b, 50)
+	ctx := context.Background()
+
+	voting := NewVotingService(db.DB)
+	for _, userID := range userIDs {
+		if _, err := voting.SubmitVote(ctx, session.ID, userID, "3"); err != nil {
+			b.Fatal(err)
+		}
+	}
+	sessions := NewSessionService(db.DB)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := sessions.GetSessionByID(ctx, session.ID); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReopenTicketRefusedKeepsEstimate(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t).DB
+
+	owner, err := NewUserService(db).CreateUser(ctx, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := NewSessionService(db).CreateSession(ctx, "Sprint", owner.ID, "points", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tickets := NewTicketService(db)
+	ticket, err := tickets.CreateTicket(ctx, session.ID, "Login", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tickets.SetFinalEstimate(ctx, ticket.ID, "5", nil, nil, "Like logout", "No SSO"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A session loaded before someone else started voting is stale
+	stale := *session
+	voting := NewVotingService(db)
+	if err := voting.StartVoting(ctx, session, ticket.ID, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := voting.ReopenTicket(ctx, &stale, ticket.ID, nil); !errors.Is(err, ErrSessionModified) {
+		t.Fatalf("ReopenTicket(stale session) = %v, want ErrSessionModified", err)
+	}
+
+	got, err := tickets.GetTicketByID(ctx, ticket.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.FinalEstimate == nil || *got.FinalEstimate != "5" {
+		t.Errorf("final estimate after a refused reopen = %v, want 5", got.FinalEstimate)
+	}
+
+	if err := voting.ReopenTicket(ctx, session, ticket.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, err = tickets.GetTicketByID(ctx, ticket.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got.FinalEstimate != nil {
+		t.Errorf("final estimate after a reopen = %v, want none", *got.FinalEstimate)
+	}
+}
@@ -1,20 +1,37 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"regexp"
 	"sync"
+	"time"
 
 	"poker-planning/internal/models"
+	"poker-planning/internal/msgpack"
+	"poker-planning/internal/utils"
 
 	"github.com/gorilla/websocket"
 )
 
+// Encodings of WebSocket messages, negotiated as the connection's
+// subprotocol. Clients that ask for none get JSON.
+const (
+	EncodingJSON    = "json"
+	EncodingMsgpack = "msgpack"
+)
+
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true // Allow connections from any origin
 	},
+	// permessage-deflate, for clients that offer it
+	EnableCompression: true,
+	Subprotocols:      []string{EncodingMsgpack, EncodingJSON},
 }
 
 type WSClient struct {
@@ -23,14 +40,51 @@ type WSClient struct {
 	UserID    string
 	Conn      *websocket.Conn
 	Send      chan models.SSEMessage
+	Hidden    bool // the tab is in the background, guarded by WSService.mutex
+	Encoding  string // EncodingJSON or EncodingMsgpack, for messages to the client
+
+	// NotificationLevel is the user's preferred loudest notification
+	// level, guarded by WSService.mutex
+	NotificationLevel string
 }
 
 type WSService struct {
-	clients    map[string]*WSClient
-	register   chan *WSClient
-	unregister chan *WSClient
-	broadcast  chan BroadcastMessage
-	mutex      sync.RWMutex
+	clients     map[string]*WSClient
+	register    chan *WSClient
+	unregister  chan *WSClient
+	broadcast   chan BroadcastMessage
+	mutex       sync.RWMutex
+	userService *UserService // remembers each user's recent emojis
+
+	activityMutex sync.Mutex
+	lastActive    map[string]time.Time // sessionID_userID -> last activity
+
+	debounce      time.Duration // zero sends every broadcast as it comes
+	debounceMutex sync.Mutex
+	debounced     map[string]*debouncedBroadcast // sessionID|type -> open window
+}
+
+// debouncedBroadcast is a debounce window of one message type in one
+// session: the latest message that came in during it, and how many did.
+type debouncedBroadcast struct {
+	sessionID string
+	message   models.SSEMessage
+	count     int
+	timer     *time.Timer
+}
+
+// coalescedTypes are the broadcasts clients answer by reloading the
+// session, so during a burst only the latest of each one matters.
+var coalescedTypes = map[string]bool{
+	"vote-cast":           true,
+	"prevote-cast":        true,
+	"user-joined":         true,
+	"user-left":           true,
+	"ticket-updated":      true,
+	"session-updated":     true,
+	"agenda-updated":      true,
+	"references-updated":  true,
+	"parking-lot-updated": true,
 }
 
 type BroadcastMessage struct {
@@ -38,15 +92,26 @@ type BroadcastMessage struct {
 	Message   models.SSEMessage
 }
 
-func NewWSService() *WSService {
+func NewWSService(userService *UserService) *WSService {
 	return &WSService{
-		clients:    make(map[string]*WSClient),
-		register:   make(chan *WSClient),
-		unregister: make(chan *WSClient),
-		broadcast:  make(chan BroadcastMessage),
+		clients:     make(map[string]*WSClient),
+		register:    make(chan *WSClient),
+		unregister:  make(chan *WSClient),
+		broadcast:   make(chan BroadcastMessage),
+		userService: userService,
+		lastActive:  make(map[string]time.Time),
+		debounced:   make(map[string]*debouncedBroadcast),
 	}
 }
 
+// SetDebounce sets how long a burst of the same broadcast is collected in a
+// session. The first one is sent right away, and the latest of the rest once
+// the window closes, with Coalesced set to how many it stands for. Zero turns
+// debouncing off. Call it before Run.
+func (ws *WSService) SetDebounce(window time.Duration) {
+	ws.debounce = window
+}
+
 func (ws *WSService) Run() {
 	for {
 		select {
@@ -62,7 +127,17 @@ func (ws *WSService) Run() {
 				delete(ws.clients, client.ID)
 				close(client.Send)
 			}
+			stillConnected := false
+			for _, other := range ws.clients {
+				if other.SessionID == client.SessionID && other.UserID == client.UserID {
+					stillConnected = true
+					break
+				}
+			}
 			ws.mutex.Unlock()
+			if !stillConnected {
+				ws.Forget(client.SessionID, client.UserID)
+			}
 			log.Printf("WebSocket client disconnected: %s", client.ID)
 
 		case message := <-ws.broadcast:
@@ -85,7 +160,11 @@ func (ws *WSService) Run() {
 	}
 }
 
-func (ws *WSService) HandleWebSocket(w http.ResponseWriter, r *http.Request, sessionID, userID string) {
+// HandleWebSocket upgrades the request and registers the client. snapshot,
+// when set, is called once the client is registered and its message is sent
+// right after the connection confirmation; anything that changes after it
+// was taken is broadcast to the client as usual.
+func (ws *WSService) HandleWebSocket(w http.ResponseWriter, r *http.Request, sessionID, userID string, snapshot func() (models.SSEMessage, error)) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade error: %v", err)
@@ -99,9 +178,28 @@ func (ws *WSService) HandleWebSocket(w http.ResponseWriter, r *http.Request, ses
 		UserID:    userID,
 		Conn:      conn,
 		Send:      make(chan models.SSEMessage, 256),
+		Encoding:  EncodingJSON,
+	}
+	if conn.Subprotocol() == EncodingMsgpack {
+		client.Encoding = EncodingMsgpack
+	}
+	if user, err := ws.userService.GetUserByID(r.Context(), userID); err != nil {
+		log.Printf("Failed to get notification level: %v", err)
+	} else if user != nil {
+		client.NotificationLevel = user.Preferences.NotificationLevel
 	}
 
 	ws.register <- client
+	ws.Touch(sessionID, userID)
+
+	if snapshot != nil {
+		message, err := snapshot()
+		if err != nil {
+			utils.LogError("WebSocket snapshot", err, utils.ReportContext{SessionID: sessionID, UserID: userID})
+		} else {
+			client.Send <- message
+		}
+	}
 
 	go ws.writePump(client)
 	go ws.readPump(client)
@@ -118,7 +216,7 @@ func (ws *WSService) readPump(client *WSClient) {
 		_, message, err := client.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
+				utils.LogError("WebSocket read", err, utils.ReportContext{SessionID: client.SessionID, UserID: client.UserID})
 			}
 			break
 		}
@@ -139,8 +237,7 @@ func (ws *WSService) writePump(client *WSClient) {
 		},
 	}
 
-	data, _ := json.Marshal(connectMsg)
-	if err := client.Conn.WriteMessage(websocket.TextMessage, data); err != nil {
+	if err := client.write(connectMsg); err != nil {
 		return
 	}
 
@@ -152,13 +249,15 @@ func (ws *WSService) writePump(client *WSClient) {
 				return
 			}
 
-			data, err := json.Marshal(message)
-			if err != nil {
-				log.Printf("JSON marshal error: %v", err)
-				continue
-			}
+			ws.mutex.RLock()
+			message.Notify = NotificationLevel(message.Type, client.NotificationLevel)
+			ws.mutex.RUnlock()
 
-			if err := client.Conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			if err := client.write(message); err != nil {
+				if errors.Is(err, errMarshal) {
+					utils.LogError("WebSocket marshal "+message.Type, err, utils.ReportContext{SessionID: client.SessionID, UserID: client.UserID})
+					continue
+				}
 				log.Printf("WebSocket write error: %v", err)
 				return
 			}
@@ -166,13 +265,102 @@ func (ws *WSService) writePump(client *WSClient) {
 	}
 }
 
+var errMarshal = errors.New("failed to encode message")
+
+// write sends a message in the client's encoding: JSON as text and
+// MessagePack as binary messages.
+func (client *WSClient) write(message models.SSEMessage) error {
+	messageType := websocket.TextMessage
+	var data []byte
+	var err error
+	if client.Encoding == EncodingMsgpack {
+		messageType = websocket.BinaryMessage
+		data, err = msgpack.Marshal(message)
+	} else {
+		data, err = json.Marshal(message)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", errMarshal, err)
+	}
+	return client.Conn.WriteMessage(messageType, data)
+}
+
 func (ws *WSService) Broadcast(sessionID string, message models.SSEMessage) {
+	if ws.debounce <= 0 {
+		ws.send(sessionID, message)
+		return
+	}
+	if !coalescedTypes[message.Type] {
+		// Send what is waiting first so that clients see everything in order
+		ws.flushDebounced(sessionID)
+		ws.send(sessionID, message)
+		return
+	}
+
+	key := sessionID + "|" + message.Type
+	ws.debounceMutex.Lock()
+	if pending, ok := ws.debounced[key]; ok {
+		pending.message = message
+		pending.count++
+		ws.debounceMutex.Unlock()
+		return
+	}
+	pending := &debouncedBroadcast{sessionID: sessionID}
+	pending.timer = time.AfterFunc(ws.debounce, func() { ws.closeWindow(key, pending) })
+	ws.debounced[key] = pending
+	ws.debounceMutex.Unlock()
+
+	ws.send(sessionID, message)
+}
+
+func (ws *WSService) send(sessionID string, message models.SSEMessage) {
 	ws.broadcast <- BroadcastMessage{
 		SessionID: sessionID,
 		Message:   message,
 	}
 }
 
+// closeWindow ends a debounce window and sends the latest message that came
+// in during it, if any.
+func (ws *WSService) closeWindow(key string, pending *debouncedBroadcast) {
+	ws.debounceMutex.Lock()
+	if ws.debounced[key] != pending {
+		// Already flushed
+		ws.debounceMutex.Unlock()
+		return
+	}
+	delete(ws.debounced, key)
+	ws.debounceMutex.Unlock()
+
+	if pending.count > 0 {
+		message := pending.message
+		message.Coalesced = pending.count
+		ws.send(pending.sessionID, message)
+	}
+}
+
+// flushDebounced ends every debounce window of a session at once.
+func (ws *WSService) flushDebounced(sessionID string) {
+	var flushed []*debouncedBroadcast
+	ws.debounceMutex.Lock()
+	for key, pending := range ws.debounced {
+		if pending.sessionID == sessionID {
+			pending.timer.Stop()
+			delete(ws.debounced, key)
+			flushed = append(flushed, pending)
+		}
+	}
+	ws.debounceMutex.Unlock()
+
+	for _, pending := range flushed {
+		if pending.count > 0 {
+			message := pending.message
+			message.Coalesced = pending.count
+			ws.send(sessionID, message)
+		}
+	}
+}
+
 func (ws *WSService) SendToUser(sessionID, userID string, message models.SSEMessage) {
 	ws.mutex.RLock()
 	defer ws.mutex.RUnlock()
@@ -189,6 +377,19 @@ func (ws *WSService) SendToUser(sessionID, userID string, message models.SSEMess
 	}
 }
 
+// SetNotificationLevel applies a user's new notification preference to
+// their open connections.
+func (ws *WSService) SetNotificationLevel(userID, level string) {
+	ws.mutex.Lock()
+	defer ws.mutex.Unlock()
+
+	for _, client := range ws.clients {
+		if client.UserID == userID {
+			client.NotificationLevel = level
+		}
+	}
+}
+
 func (ws *WSService) GetClientCount(sessionID string) int {
 	ws.mutex.RLock()
 	defer ws.mutex.RUnlock()
@@ -202,6 +403,91 @@ func (ws *WSService) GetClientCount(sessionID string) int {
 	return count
 }
 
+// Touch records activity from a user in a session, such as a WebSocket
+// message or a vote.
+func (ws *WSService) Touch(sessionID, userID string) {
+	ws.activityMutex.Lock()
+	defer ws.activityMutex.Unlock()
+
+	ws.lastActive[sessionID+"_"+userID] = time.Now()
+}
+
+// Forget drops a user's activity in a session, once their last connection
+// to it closes or they leave it, so it does not pile up for the life of the
+// server.
+func (ws *WSService) Forget(sessionID, userID string) {
+	ws.activityMutex.Lock()
+	defer ws.activityMutex.Unlock()
+
+	delete(ws.lastActive, sessionID+"_"+userID)
+}
+
+// LastActive returns when a user was last active in a session since the
+// server started, or false if they have not been since they connected.
+func (ws *WSService) LastActive(sessionID, userID string) (time.Time, bool) {
+	ws.activityMutex.Lock()
+	defer ws.activityMutex.Unlock()
+
+	lastActive, ok := ws.lastActive[sessionID+"_"+userID]
+	return lastActive, ok
+}
+
+// ConnectedUsers returns the IDs of users with an open connection to the session.
+func (ws *WSService) ConnectedUsers(sessionID string) map[string]bool {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+
+	users := make(map[string]bool)
+	for _, client := range ws.clients {
+		if client.SessionID == sessionID {
+			users[client.UserID] = true
+		}
+	}
+	return users
+}
+
+// InBackground reports whether a user has no session tab in front of them:
+// either no connection to the session, or one from a background tab.
+func (ws *WSService) InBackground(sessionID, userID string) bool {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+
+	client, ok := ws.clients[sessionID+"_"+userID]
+	return !ok || client.Hidden
+}
+
+// ClientCounts returns the number of open connections per session.
+func (ws *WSService) ClientCounts() map[string]int {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+
+	counts := make(map[string]int)
+	for _, client := range ws.clients {
+		counts[client.SessionID]++
+	}
+	return counts
+}
+
+// ActiveSessions returns the IDs of sessions with at least one open connection.
+func (ws *WSService) ActiveSessions() []string {
+	ws.mutex.RLock()
+	defer ws.mutex.RUnlock()
+
+	seen := make(map[string]bool)
+	var sessionIDs []string
+	for _, client := range ws.clients {
+		if !seen[client.SessionID] {
+			seen[client.SessionID] = true
+			sessionIDs = append(sessionIDs, client.SessionID)
+		}
+	}
+	return sessionIDs
+}
+
+// pointerTarget matches the element IDs the facilitator can point at, like
+// ticket-12 or card-3.
+var pointerTarget = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]{0,63}$`)
+
 // ClientMessage represents a message sent from client to server
 type ClientMessage struct {
 	Type string      `json:"type"`
@@ -215,8 +501,23 @@ func (ws *WSService) handleClientMessage(client *WSClient, message []byte) {
 		return
 	}
 	
+	// Any message from the client counts as activity
+	ws.Touch(client.SessionID, client.UserID)
+	
 	switch clientMsg.Type {
+	case "activity":
+		// Periodic ping from an active browser tab; Touch above is all it needs
+	case "visibility":
+		// The tab went to the background or came back
+		fields, _ := clientMsg.Data.(map[string]interface{})
+		hidden, _ := fields["hidden"].(bool)
+		ws.mutex.Lock()
+		client.Hidden = hidden
+		ws.mutex.Unlock()
 	case "emoji-reaction":
+		if ws.declineEmoji(client, clientMsg.Data) {
+			return
+		}
 		// Broadcast emoji reaction to all clients in the session
 		emojiMessage := models.SSEMessage{
 			Type: "emoji-reaction",
@@ -224,7 +525,138 @@ func (ws *WSService) handleClientMessage(client *WSClient, message []byte) {
 		}
 		ws.Broadcast(client.SessionID, emojiMessage)
 		log.Printf("Emoji reaction broadcasted to session %s", client.SessionID)
+		ws.recordRecentEmoji(client, clientMsg.Data)
+	case "last-vote":
+		// The client asks for its last vote, e.g. to offer "same as last time"
+		ws.sendLastVote(client)
+	case "set-last-vote":
+		ws.setLastVote(client, clientMsg.Data)
+	case "pointer":
+		// The facilitator points at a ticket or card for everyone to see
+		ws.relayPointer(client, clientMsg.Data)
 	default:
 		log.Printf("Unknown client message type: %s", clientMsg.Type)
 	}
-}
\ No newline at end of file
+}
+
+// declineEmoji reports whether an emoji reaction is aimed at a participant
+// in do not disturb. The sender is told with an emoji-declined message
+// instead of the reaction going out.
+func (ws *WSService) declineEmoji(client *WSClient, data interface{}) bool {
+	fields, _ := data.(map[string]interface{})
+	targetUserID, _ := fields["target_user_id"].(string)
+	if targetUserID == "" {
+		return false
+	}
+
+	doNotDisturb, err := ws.userService.DoNotDisturb(context.Background(), client.SessionID)
+	if err != nil {
+		log.Printf("Failed to get do not disturb participants: %v", err)
+		return false
+	}
+	if !doNotDisturb[targetUserID] {
+		return false
+	}
+
+	targetUsername, _ := fields["target_username"].(string)
+	ws.SendToUser(client.SessionID, client.UserID, models.SSEMessage{
+		Type: "emoji-declined",
+		Data: map[string]string{
+			"target_user_id":  targetUserID,
+			"target_username": targetUsername,
+			"reason":          "do-not-disturb",
+		},
+	})
+	return true
+}
+
+func (ws *WSService) recordRecentEmoji(client *WSClient, data interface{}) {
+	fields, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	emoji, _ := fields["emoji"].(string)
+	if utils.ValidateEmoji(emoji).HasErrors() {
+		return
+	}
+
+	// Attribute the emoji to the connection's user, not the client-supplied sender
+	if err := ws.userService.RecordRecentEmoji(context.Background(), client.UserID, emoji); err != nil {
+		log.Printf("Failed to record recent emoji: %v", err)
+	}
+}
+
+// sendLastVote sends the client's user their last vote in the session as a
+// last-vote message.
+func (ws *WSService) sendLastVote(client *WSClient) {
+	lastVote, err := ws.userService.LastVote(context.Background(), client.SessionID, client.UserID)
+	if err != nil {
+		log.Printf("Failed to get last vote: %v", err)
+		return
+	}
+	if lastVote == nil {
+		return
+	}
+
+	ws.SendToUser(client.SessionID, client.UserID, models.SSEMessage{
+		Type: "last-vote",
+		Data: lastVote,
+	})
+}
+
+func (ws *WSService) setLastVote(client *WSClient, data interface{}) {
+	fields, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	lastVote, err := ws.userService.LastVote(context.Background(), client.SessionID, client.UserID)
+	if err != nil {
+		log.Printf("Failed to get last vote: %v", err)
+		return
+	}
+	if lastVote == nil {
+		return
+	}
+
+	vote, _ := fields["vote"].(string)
+	if utils.ValidateVoteValue(vote, lastVote.Cards).HasErrors() {
+		return
+	}
+
+	if err := ws.userService.SetLastVote(context.Background(), client.SessionID, client.UserID, vote); err != nil {
+		log.Printf("Failed to set last vote: %v", err)
+		return
+	}
+	ws.sendLastVote(client)
+}
+
+// relayPointer passes the element the session owner points at on to every
+// client in the session, or clears the highlight for an empty target.
+// Pointers are not stored: clients that join later see none.
+func (ws *WSService) relayPointer(client *WSClient, data interface{}) {
+	fields, _ := data.(map[string]interface{})
+	target, _ := fields["target"].(string)
+	if target != "" && !pointerTarget.MatchString(target) {
+		return
+	}
+
+	owner, err := ws.userService.IsSessionOwner(context.Background(), client.SessionID, client.UserID)
+	if err != nil {
+		utils.LogError("WebSocket pointer", err, utils.ReportContext{SessionID: client.SessionID, UserID: client.UserID})
+		return
+	}
+	if !owner {
+		return
+	}
+
+	var pointed interface{}
+	if target != "" {
+		pointed = target
+	}
+	ws.Broadcast(client.SessionID, models.SSEMessage{
+		Type: "pointer",
+		Data: map[string]interface{}{"user_id": client.UserID, "target": pointed},
+	})
+}
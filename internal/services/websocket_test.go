@@ -0,0 +1,118 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+// TestBroadcastDebounce checks that a burst of the same broadcast reaches
+// clients as its first and latest message, and that other broadcasts send
+// what is waiting before them.
+func TestBroadcastDebounce(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	ws := NewWSService(nil)
+	ws.SetDebounce(50 * time.Millisecond)
+	go ws.Run()
+
+	client := &WSClient{ID: "s_u", SessionID: "s", UserID: "u", Send: make(chan models.SSEMessage, 256)}
+	ws.register <- client
+	receive := func() models.SSEMessage {
+		select {
+		case message := <-client.Send:
+			return message
+		case <-time.After(time.Second):
+			t.Fatal("no broadcast received")
+			return models.SSEMessage{}
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		ws.Broadcast("s", models.SSEMessage{Type: "vote-cast", Data: i})
+	}
+	if message := receive(); message.Data != 0 || message.Coalesced != 0 {
+		t.Errorf("first broadcast = %v (coalesced %d), want 0 sent at once", message.Data, message.Coalesced)
+	}
+	if message := receive(); message.Data != 9 || message.Coalesced != 9 {
+		t.Errorf("end of window = %v (coalesced %d), want 9 standing for 9", message.Data, message.Coalesced)
+	}
+
+	ws.Broadcast("s", models.SSEMessage{Type: "vote-cast", Data: "a"})
+	ws.Broadcast("s", models.SSEMessage{Type: "vote-cast", Data: "b"})
+	ws.Broadcast("s", models.SSEMessage{Type: "voting-ended"})
+	for _, want := range []interface{}{"a", "b", nil} {
+		if message := receive(); message.Data != want {
+			t.Errorf("got %s %v, want %v", message.Type, message.Data, want)
+		}
+	}
+
+	// The flushed window does not send again
+	select {
+	case message := <-client.Send:
+		t.Errorf("unexpected %s after flush", message.Type)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// BenchmarkBroadcast measures fanning one message out to every client of a
+// session while other sessions are connected too. Clients have no real
+// connection; their send channels are drained directly. Clients that fall
+// more than 256 messages behind are dropped, as in production.
+func BenchmarkBroadcast(b *testing.B) {
+	for _, clients := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("clients=%d", clients), func(b *testing.B) {
+			log.SetOutput(io.Discard)
+			defer log.SetOutput(os.Stderr)
+
+			ws := NewWSService(nil)
+			go ws.Run()
+
+			// Nine idle sessions the broadcast has to skip over
+			var drained sync.WaitGroup
+			var listeners []*WSClient
+			for session := 0; session < 10; session++ {
+				for i := 0; i < clients; i++ {
+					client := &WSClient{
+						ID:        fmt.Sprintf("s%d_u%d", session, i),
+						SessionID: fmt.Sprintf("s%d", session),
+						UserID:    fmt.Sprintf("u%d", i),
+						Send:      make(chan models.SSEMessage, 256),
+					}
+					ws.register <- client
+
+					if session == 0 {
+						listeners = append(listeners, client)
+						drained.Add(1)
+						go func() {
+							defer drained.Done()
+							for range client.Send {
+							}
+						}()
+					}
+				}
+			}
+
+			message := models.SSEMessage{Type: "vote-cast", Data: map[string]interface{}{"user_id": "u0"}}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ws.Broadcast("s0", message)
+			}
+
+			// Unregistering is handled after every broadcast, and closes the
+			// channels once the clients have everything queued
+			for _, client := range listeners {
+				ws.unregister <- client
+			}
+			drained.Wait()
+		})
+	}
+}
@@ -0,0 +1,614 @@
+// Package stats computes the statistics shown for rounds of votes and whole
+// sessions, for the pages and the API alike.
+package stats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+)
+
+// TicketStats summarises the votes on a ticket, or on several.
+type TicketStats struct {
+	Median      float64      `json:"median"`
+	Mean        float64      `json:"mean"`
+	Mode        string       `json:"mode"`
+	Percentiles []Percentile `json:"percentiles"` // the configured percentiles, in increasing order
+	Range       *Range       `json:"range"`       // middle half of the numeric votes, nil without any
+	Basis       Basis        `json:"basis"`       // statistic the suggestion starts from
+	Suggested   float64      `json:"suggested"`   // basis snapped to a card using the session rounding strategy
+	HasValues   bool         `json:"has_values"`  // indicates if there are numeric votes
+	Weighted    bool         `json:"weighted"`    // some votes count more or less than others in the median and mean
+	Abstentions int          `json:"abstentions"` // votes on the abstain card, which no other statistic includes
+	Infinite    int          `json:"infinite"`    // votes on ∞, which the median and mean leave out
+}
+
+// Percentile is the value at or below which a given share of the votes lie.
+type Percentile struct {
+	Percent int     `json:"percent"`
+	Value   float64 `json:"value"`
+}
+
+// Range is a suggested estimate range: the middle half of the numeric
+// votes, from the RangeLow to the RangeHigh percentile, for teams that track
+// ranges instead of single numbers. Both ends are votes, so cards.
+type Range struct {
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// Percentiles a Range spans.
+const (
+	RangeLow  = 25
+	RangeHigh = 75
+)
+
+// VoteCount is how many votes a card got.
+type VoteCount struct {
+	Value      string `json:"value"`
+	Count      int    `json:"count"`
+	Percentage int    `json:"percentage"` // whole percent; a histogram's add up to 100
+	Infinite   bool   `json:"infinite"`   // the ∞ card, flagged since the median and mean leave it out
+}
+
+// VoteGroup is how many votes went to a band of the deck's numeric cards,
+// or to its other cards.
+type VoteGroup struct {
+	Group      string `json:"group"`          // low, medium, high or other
+	From       string `json:"from,omitempty"` // lowest card of the band
+	To         string `json:"to,omitempty"`   // highest card of the band
+	Count      int    `json:"count"`
+	Percentage int    `json:"percentage"`
+}
+
+// Groups a rollup sorts votes into, in order.
+const (
+	GroupLow    = "low"
+	GroupMedium = "medium"
+	GroupHigh   = "high"
+	GroupOther  = "other" // special cards, abstentions and cards the deck does not have
+)
+
+// LargeSession is how many voters make a session large enough that its
+// exports roll votes up into groups unless asked not to.
+const LargeSession = 30
+
+// ParticipantStat is how a participant voted over a session.
+type ParticipantStat struct {
+	VoteCount  int     `json:"vote_count"`
+	MedianVote float64 `json:"median_vote"`
+}
+
+// Consensus measures how far a round of votes agreed. Median, mean, spread
+// and standard deviation only use numeric cards and are null when there
+// were none.
+type Consensus struct {
+	Votes       int      `json:"votes"`
+	Abstentions int      `json:"abstentions"` // votes on the abstain card, left out of everything else
+	Infinite    int      `json:"infinite"`    // votes on ∞, left out of the median, mean, spread and std_dev
+	Median      *float64 `json:"median"`
+	Mean        *float64 `json:"mean"`
+	Mode        string   `json:"mode"`
+	Agreement   float64  `json:"agreement"` // share of votes on the most played card, 0-1
+	Spread      *float64 `json:"spread"`    // highest minus lowest numeric vote
+	StdDev      *float64 `json:"std_dev"`
+	Unanimous   bool     `json:"unanimous"`
+	Weighted    bool     `json:"weighted"` // some participants' votes count more than others
+}
+
+// Basis is the statistic of the numeric votes a suggested estimate starts
+// from: the median, the mean, or a percentile like p90 for teams that
+// deliberately estimate on the safe side.
+type Basis string
+
+const (
+	BasisMedian Basis = "median"
+	BasisMean   Basis = "mean"
+)
+
+const DefaultBasis = BasisMedian
+
+// Percentiles are computed for every ticket and offered as bases, in
+// increasing order. Sessions may keep a percentile basis that is no longer
+// among them.
+var Percentiles = []int{70, 90}
+
+// PercentileBasis is the basis for the given percentile.
+func PercentileBasis(percent int) Basis {
+	return Basis("p" + strconv.Itoa(percent))
+}
+
+// Bases are the bases a session can choose from.
+func Bases() []Basis {
+	bases := []Basis{BasisMedian, BasisMean}
+	for _, percent := range Percentiles {
+		bases = append(bases, PercentileBasis(percent))
+	}
+	return bases
+}
+
+// ParseBasis reads a basis: median, mean or p1 to p99.
+func ParseBasis(value string) (Basis, bool) {
+	basis := Basis(strings.ToLower(strings.TrimSpace(value)))
+	if basis == BasisMedian || basis == BasisMean {
+		return basis, true
+	}
+	if _, ok := basis.percentile(); ok {
+		return basis, true
+	}
+	return DefaultBasis, false
+}
+
+// percentile is the percentile a basis stands for, if it is one.
+func (b Basis) percentile() (int, bool) {
+	if !strings.HasPrefix(string(b), "p") {
+		return 0, false
+	}
+	percent, err := strconv.Atoi(string(b)[1:])
+	if err != nil || percent < 1 || percent > 99 || PercentileBasis(percent) != b {
+		return 0, false
+	}
+	return percent, true
+}
+
+// Label is how the basis reads in a sentence: "median", "mean" or "P90".
+func (b Basis) Label() string {
+	if _, ok := b.percentile(); ok {
+		return strings.ToUpper(string(b))
+	}
+	return string(b)
+}
+
+// ParsePercentiles reads a comma-separated list of percentiles between 1
+// and 99, e.g. "70,90", sorted and without duplicates.
+func ParsePercentiles(value string) ([]int, error) {
+	seen := make(map[int]bool)
+	var percentiles []int
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		percent, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(field), "p"))
+		if err != nil || percent < 1 || percent > 99 {
+			return nil, fmt.Errorf("percentile %q is not a whole number between 1 and 99", field)
+		}
+		if !seen[percent] {
+			seen[percent] = true
+			percentiles = append(percentiles, percent)
+		}
+	}
+	sort.Ints(percentiles)
+	return percentiles, nil
+}
+
+// Deck is what the statistics of votes depend on: the cards, in order,
+// which of them are special, which statistic a suggestion starts from and
+// how it is snapped to a card.
+type Deck struct {
+	Cards    deck.Deck
+	Special  []deck.SpecialCard
+	Basis    Basis
+	Rounding deck.RoundingStrategy
+}
+
+// ForSession is the deck a session votes with.
+func ForSession(session *models.Session) Deck {
+	strategy, ok := deck.ParseRoundingStrategy(session.RoundingStrategy)
+	if !ok {
+		strategy = deck.DefaultRoundingStrategy
+	}
+	basis, _ := ParseBasis(session.SuggestionBasis)
+	return Deck{Cards: session.Deck(), Special: session.SpecialCards(), Basis: basis, Rounding: strategy}
+}
+
+// Histogram counts votes per card, ordered as the cards appear in the deck,
+// with percentages that add up to 100. Values the deck does not contain sort
+// last.
+func (d Deck) Histogram(votes []models.Vote) []VoteCount {
+	votes = committed(votes)
+	voteCounts := make(map[string]int)
+	total := len(votes)
+
+	for _, vote := range votes {
+		voteCounts[vote.VoteValue]++
+	}
+
+	histogram := []VoteCount{}
+	for voteValue, count := range voteCounts {
+		histogram = append(histogram, VoteCount{
+			Value:    voteValue,
+			Count:    count,
+			Infinite: voteValue == deck.Infinity,
+		})
+	}
+
+	// Order bars by deck position so they stay put between reveals;
+	// special cards already sit at the end of the deck
+	sort.SliceStable(histogram, func(i, j int) bool {
+		pi, pj := d.Cards.Order(histogram[i].Value), d.Cards.Order(histogram[j].Value)
+		iOK, jOK := pi >= 0, pj >= 0
+		if iOK != jOK {
+			return iOK
+		}
+		if !iOK {
+			return histogram[i].Value < histogram[j].Value
+		}
+		return pi < pj
+	})
+
+	counts := make([]int, len(histogram))
+	for i, bar := range histogram {
+		counts[i] = bar.Count
+	}
+	for i, percentage := range percentages(counts, total) {
+		histogram[i].Percentage = percentage
+	}
+	return histogram
+}
+
+// Rollup groups votes into low, medium and high thirds of the deck's
+// numeric cards, then other votes, for results with too many different
+// votes to read card by card. A numeric vote between two cards falls in
+// the band of the card above it. Every group is listed, with or without
+// votes, except bands of decks with fewer than three numeric cards.
+func (d Deck) Rollup(votes []models.Vote) []VoteGroup {
+	votes = committed(votes)
+	cards := deck.NumericValues(d.Cards)
+	groups := []VoteGroup{{Group: GroupLow}, {Group: GroupMedium}, {Group: GroupHigh}, {Group: GroupOther}}
+	for i, card := range cards {
+		group := &groups[i*3/len(cards)]
+		if group.From == "" {
+			group.From = deck.FormatValue(card)
+		}
+		group.To = deck.FormatValue(card)
+	}
+
+	for _, vote := range votes {
+		value, ok := deck.NumericValue(vote.VoteValue)
+		if !ok || len(cards) == 0 {
+			groups[3].Count++
+			continue
+		}
+		band := sort.SearchFloat64s(cards, value)
+		if band == len(cards) {
+			band--
+		}
+		groups[band*3/len(cards)].Count++
+	}
+
+	var rollup []VoteGroup
+	for _, group := range groups {
+		if group.From != "" || group.Group == GroupOther {
+			rollup = append(rollup, group)
+		}
+	}
+	counts := make([]int, len(rollup))
+	for i, group := range rollup {
+		counts[i] = group.Count
+	}
+	for i, percentage := range percentages(counts, len(votes)) {
+		rollup[i].Percentage = percentage
+	}
+	return rollup
+}
+
+// percentages turns counts out of total into whole percentages that add up
+// to 100, by rounding down and handing the points left over to the counts
+// that lost the most to rounding, earlier ones first on ties.
+func percentages(counts []int, total int) []int {
+	result := make([]int, len(counts))
+	if total == 0 {
+		return result
+	}
+
+	left := 100
+	order := make([]int, len(counts))
+	for i, count := range counts {
+		result[i] = count * 100 / total
+		left -= result[i]
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return counts[order[a]]*100%total > counts[order[b]]*100%total
+	})
+	for _, i := range order {
+		if left <= 0 {
+			break
+		}
+		result[i]++
+		left--
+	}
+	return result
+}
+
+// weightedValue is a numeric vote and how much it counts.
+type weightedValue struct {
+	value  float64
+	weight float64
+}
+
+// Weight is how much a vote counts. Votes that were not loaded with their
+// participant count once.
+func Weight(vote models.Vote) float64 {
+	if vote.Weight <= 0 {
+		return 1
+	}
+	return vote.Weight
+}
+
+// committed leaves out the advisory votes of stakeholders, which are shown
+// on their own and do not count toward any statistic.
+func committed(votes []models.Vote) []models.Vote {
+	var result []models.Vote
+	for _, vote := range votes {
+		if !vote.Advisory {
+			result = append(result, vote)
+		}
+	}
+	return result
+}
+
+// Advisory returns the votes cast by stakeholders, which the statistics
+// leave out.
+func Advisory(votes []models.Vote) []models.Vote {
+	var result []models.Vote
+	for _, vote := range votes {
+		if vote.Advisory {
+			result = append(result, vote)
+		}
+	}
+	return result
+}
+
+// numericVotes collects the numeric votes sorted by value, skipping special
+// cards like ☕ and ? and advisory votes. It also reports whether any of
+// them is weighted.
+func numericVotes(votes []models.Vote) ([]weightedValue, bool) {
+	var values []weightedValue
+	weighted := false
+	for _, vote := range committed(votes) {
+		val, ok := deck.NumericValue(vote.VoteValue)
+		if !ok {
+			continue
+		}
+
+		weight := Weight(vote)
+		weighted = weighted || weight != 1
+		values = append(values, weightedValue{value: val, weight: weight})
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].value < values[j].value
+	})
+	return values, weighted
+}
+
+// weightedMedian is the lowest value with at least half the total weight at
+// or below it. With equal weights that is the middle vote, or the left middle
+// one for an even number of votes. values must be sorted and not empty.
+func weightedMedian(values []weightedValue) float64 {
+	return weightedPercentile(values, 50)
+}
+
+// weightedPercentile is the lowest value with at least percent of the total
+// weight at or below it, so that it is always one of the votes. values must
+// be sorted and not empty.
+func weightedPercentile(values []weightedValue, percent int) float64 {
+	var total float64
+	for _, v := range values {
+		total += v.weight
+	}
+
+	var cumulative float64
+	for _, v := range values {
+		cumulative += v.weight
+		if cumulative >= total*float64(percent)/100 {
+			return v.value
+		}
+	}
+	return values[len(values)-1].value
+}
+
+// weightedMean is the mean of values by their weights. values must not be
+// empty.
+func weightedMean(values []weightedValue) float64 {
+	var sum, totalWeight float64
+	for _, v := range values {
+		sum += v.value * v.weight
+		totalWeight += v.weight
+	}
+	return sum / totalWeight
+}
+
+// Median is the weighted median of the numeric votes, or nil without any.
+// It does not depend on the deck, since numeric cards are their own values.
+func Median(votes []models.Vote) *float64 {
+	values, _ := numericVotes(votes)
+	if len(values) == 0 {
+		return nil
+	}
+
+	median := weightedMedian(values)
+	return &median
+}
+
+// VoteRange is the Range of the numeric votes, weighted by participant, or
+// nil without any. Like Median it does not depend on the deck.
+func VoteRange(votes []models.Vote) *Range {
+	values, _ := numericVotes(votes)
+	if len(values) == 0 {
+		return nil
+	}
+	return voteRange(values)
+}
+
+// voteRange is the Range of values, which must be sorted and not empty.
+func voteRange(values []weightedValue) *Range {
+	return &Range{Low: weightedPercentile(values, RangeLow), High: weightedPercentile(values, RangeHigh)}
+}
+
+// Ticket summarises votes on a ticket. Votes on special cards that do not
+// count toward consensus are left out of the mode, and abstentions and
+// votes on ∞ are counted on their own. Advisory votes are left out.
+func (d Deck) Ticket(votes []models.Vote) TicketStats {
+	votes = committed(votes)
+	if len(votes) == 0 {
+		return TicketStats{
+			Median:    0,
+			Mean:      0,
+			Mode:      "N/A",
+			HasValues: false,
+		}
+	}
+
+	// Median and mean only use numeric votes, weighted by participant
+	values, weighted := numericVotes(votes)
+	stats := TicketStats{HasValues: len(values) > 0, Weighted: weighted}
+
+	if len(values) > 0 {
+		stats.Median = weightedMedian(values)
+		stats.Mean = weightedMean(values)
+		for _, percent := range Percentiles {
+			stats.Percentiles = append(stats.Percentiles, Percentile{Percent: percent, Value: weightedPercentile(values, percent)})
+		}
+		stats.Range = voteRange(values)
+		stats.Basis = d.basis()
+		if suggested, ok := d.round(d.basisValue(values)); ok {
+			stats.Suggested = suggested
+		}
+	}
+
+	voteFrequency := make(map[string]int)
+	for _, vote := range votes {
+		if vote.VoteValue == deck.Infinity {
+			stats.Infinite++
+		}
+		if vote.VoteValue == deck.Abstain {
+			stats.Abstentions++
+		} else if deck.CountsTowardConsensus(vote.VoteValue, d.Special) {
+			voteFrequency[vote.VoteValue]++
+		}
+	}
+
+	// Calculate mode (for all votes that count, including non-numeric)
+	maxCount := 0
+	var modes []string
+
+	for value, count := range voteFrequency {
+		if count > maxCount {
+			maxCount = count
+			modes = []string{value}
+		} else if count == maxCount {
+			modes = append(modes, value)
+		}
+	}
+	sort.Strings(modes)
+
+	if len(modes) == 1 {
+		stats.Mode = modes[0]
+	} else if len(modes) == len(voteFrequency) {
+		// All values appear equally - no mode
+		stats.Mode = "None"
+	} else {
+		// Multiple modes
+		stats.Mode = fmt.Sprintf("Multiple: %v", modes)
+	}
+
+	return stats
+}
+
+// round snaps a value to one of the deck's numeric cards.
+func (d Deck) round(value float64) (float64, bool) {
+	strategy := d.Rounding
+	if strategy == "" {
+		strategy = deck.DefaultRoundingStrategy
+	}
+	return deck.Round(value, d.Cards, strategy)
+}
+
+func (d Deck) basis() Basis {
+	if d.Basis == "" {
+		return DefaultBasis
+	}
+	return d.Basis
+}
+
+// basisValue is the deck's basis statistic of values, which must be sorted
+// and not empty.
+func (d Deck) basisValue(values []weightedValue) float64 {
+	basis := d.basis()
+	if basis == BasisMean {
+		return weightedMean(values)
+	}
+	if percent, ok := basis.percentile(); ok {
+		return weightedPercentile(values, percent)
+	}
+	return weightedMedian(values)
+}
+
+// Suggested snaps the deck's basis statistic of the votes, the median unless
+// the session chose another, to a card using its rounding strategy. It
+// returns nil when there are no numeric votes.
+func (d Deck) Suggested(votes []models.Vote) *float64 {
+	values, _ := numericVotes(votes)
+	if len(values) == 0 {
+		return nil
+	}
+
+	suggested, ok := d.round(d.basisValue(values))
+	if !ok {
+		return nil
+	}
+	return &suggested
+}
+
+// Consensus measures how far a round of votes agreed. Agreement and
+// unanimity leave out abstentions and votes on special cards that do not
+// count toward consensus, and like every statistic it leaves out advisory
+// votes.
+func (d Deck) Consensus(votes []models.Vote) Consensus {
+	votes = committed(votes)
+	stats := d.Ticket(votes)
+	consensus := Consensus{Votes: len(votes), Abstentions: stats.Abstentions, Infinite: stats.Infinite, Mode: stats.Mode, Weighted: stats.Weighted}
+
+	counts := make(map[string]int)
+	counted := 0
+	for _, vote := range votes {
+		if deck.CountsTowardConsensus(vote.VoteValue, d.Special) {
+			counts[vote.VoteValue]++
+			counted++
+		}
+	}
+	for _, count := range counts {
+		if share := float64(count) / float64(counted); share > consensus.Agreement {
+			consensus.Agreement = share
+		}
+	}
+	consensus.Unanimous = counted > 0 && len(counts) == 1
+
+	values, _ := numericVotes(votes)
+	if len(values) == 0 {
+		return consensus
+	}
+
+	median, mean := stats.Median, stats.Mean
+	spread := values[len(values)-1].value - values[0].value
+	var variance, totalWeight float64
+	for _, v := range values {
+		variance += v.weight * (v.value - mean) * (v.value - mean)
+		totalWeight += v.weight
+	}
+	stdDev := math.Sqrt(variance / totalWeight)
+
+	consensus.Median = &median
+	consensus.Mean = &mean
+	consensus.Spread = &spread
+	consensus.StdDev = &stdDev
+	return consensus
+}
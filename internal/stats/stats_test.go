@@ -0,0 +1,332 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"poker-planning/internal/deck"
+	"poker-planning/internal/models"
+)
+
+// votes makes one vote per value, each from its own participant.
+func votes(values ...string) []models.Vote {
+	result := make([]models.Vote, len(values))
+	for i, value := range values {
+		result[i] = models.Vote{UserID: string(rune('a' + i)), VoteValue: value}
+	}
+	return result
+}
+
+var testDeck = Deck{
+	Cards:    append(deck.WithSpecialCards("points", deck.SpecialCards), deck.Abstain),
+	Special:  deck.SpecialCards,
+	Rounding: deck.RoundNearest,
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name  string
+		votes []models.Vote
+		want  *float64
+	}{
+		{"empty", nil, nil},
+		{"only special cards", votes("☕", "?", deck.Abstain), nil},
+		{"odd", votes("8", "1", "3"), float(3)},
+		{"even takes the left middle", votes("5", "3", "8", "1"), float(3)},
+		{"special cards are skipped", votes("5", "☕", "13", "?", "8"), float(8)},
+		{"weighted", []models.Vote{{VoteValue: "1"}, {VoteValue: "2"}, {VoteValue: "13", Weight: 3}}, float(13)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Median(tt.votes)
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("Median() = %v, want %v", show(got), show(tt.want))
+			}
+		})
+	}
+}
+
+func TestVoteRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		votes []models.Vote
+		want  *Range
+	}{
+		{"empty", nil, nil},
+		{"only special cards", votes("☕", "?", deck.Abstain), nil},
+		{"middle half", votes("1", "2", "2", "3", "3", "3", "5", "5", "8", "13"), &Range{Low: 2, High: 5}},
+		{"few votes", votes("8", "1", "3"), &Range{Low: 1, High: 8}},
+		{"agreement", votes("5", "5", "5", "13"), &Range{Low: 5, High: 5}},
+		{"special cards are skipped", votes("?", "3", "☕", "5"), &Range{Low: 3, High: 5}},
+		{"weighted", []models.Vote{{VoteValue: "2", Weight: 3}, {VoteValue: "8"}}, &Range{Low: 2, High: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VoteRange(tt.votes)
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("VoteRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTicket(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		got := testDeck.Ticket(nil)
+		if got.HasValues || got.Mode != "N/A" {
+			t.Errorf("Ticket(nil) = %+v, want no values and mode N/A", got)
+		}
+	})
+
+	t.Run("numeric", func(t *testing.T) {
+		got := testDeck.Ticket(votes("3", "5", "5", "13"))
+		want := TicketStats{
+			Median:      5,
+			Mean:        6.5,
+			Mode:        "5",
+			Percentiles: []Percentile{{Percent: 70, Value: 5}, {Percent: 90, Value: 13}},
+			Range:       &Range{Low: 3, High: 5},
+			Basis:       BasisMedian,
+			Suggested:   5,
+			HasValues:   true,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Ticket() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("suggestion snaps to a card", func(t *testing.T) {
+		got := testDeck.Ticket([]models.Vote{{VoteValue: "3"}, {VoteValue: "13", Weight: 1.5}})
+		if got.Median != 13 || !got.Weighted {
+			t.Errorf("Ticket() = %+v, want weighted median 13", got)
+		}
+		// 4 is not a card; halfway between 3 and 5 rounds up
+		got = testDeck.Ticket(votes("4", "4"))
+		if got.Median != 4 || got.Suggested != 5 {
+			t.Errorf("Ticket() = %+v, want median 4 suggesting 5", got)
+		}
+	})
+
+	t.Run("special cards", func(t *testing.T) {
+		// ☕ and ? do not count toward consensus, ✂ does
+		got := testDeck.Ticket(votes("☕", "☕", "☕", "8", "✂", "✂", deck.Abstain))
+		if got.Mode != "✂" || got.Median != 8 || got.Abstentions != 1 {
+			t.Errorf("Ticket() = %+v, want mode ✂, median 8 and one abstention", got)
+		}
+	})
+
+	t.Run("only special cards", func(t *testing.T) {
+		// ∞ counts toward consensus unless the session says otherwise
+		got := testDeck.Ticket(votes("?", deck.Infinity, deck.Infinity))
+		if got.HasValues || got.Infinite != 2 || got.Mode != deck.Infinity {
+			t.Errorf("Ticket() = %+v, want no values and two ∞ as the mode", got)
+		}
+	})
+
+	t.Run("ties", func(t *testing.T) {
+		if got := testDeck.Ticket(votes("3", "5")).Mode; got != "None" {
+			t.Errorf("Mode = %q, want None", got)
+		}
+		if got := testDeck.Ticket(votes("8", "3", "3", "8", "5")).Mode; got != "Multiple: [3 8]" {
+			t.Errorf("Mode = %q, want Multiple: [3 8]", got)
+		}
+	})
+}
+
+func TestSuggestionBasis(t *testing.T) {
+	tenVotes := votes("1", "2", "2", "3", "3", "3", "5", "5", "8", "13")
+	tests := []struct {
+		basis Basis
+		want  float64
+	}{
+		{"", 3},
+		{BasisMedian, 3},
+		{BasisMean, 5}, // 4.5 rounds up to 5
+		{"p50", 3},     // the median
+		{"p70", 5},     // 7th of 10 votes
+		{"p90", 8},     // 9th of 10 votes
+		{"p99", 13},    // the highest vote
+		{"p10", 1},     // the lowest vote
+		{"p20", 2},     // 2nd of 10 votes
+		{"p95", 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.basis), func(t *testing.T) {
+			cards := testDeck
+			cards.Basis = tt.basis
+			got := cards.Suggested(tenVotes)
+			if got == nil || *got != tt.want {
+				t.Errorf("Suggested() = %v, want %v", show(got), tt.want)
+			}
+			if stats := cards.Ticket(tenVotes); stats.Suggested != tt.want {
+				t.Errorf("Ticket().Suggested = %v, want %v", stats.Suggested, tt.want)
+			}
+		})
+	}
+
+	t.Run("weighted", func(t *testing.T) {
+		cards := testDeck
+		cards.Basis = "p70"
+		weighted := []models.Vote{{VoteValue: "2", Weight: 3}, {VoteValue: "8"}}
+		if got := cards.Suggested(weighted); got == nil || *got != 2 {
+			t.Errorf("Suggested() = %v, want 2 with three quarters of the weight on it", show(got))
+		}
+	})
+
+	t.Run("no numeric votes", func(t *testing.T) {
+		cards := testDeck
+		cards.Basis = "p90"
+		if got := cards.Suggested(votes("?", "☕")); got != nil {
+			t.Errorf("Suggested() = %v, want nil", *got)
+		}
+	})
+}
+
+func TestParseBasis(t *testing.T) {
+	for _, value := range []string{"median", "mean", "p1", "p90", "P70", " p99 "} {
+		if _, ok := ParseBasis(value); !ok {
+			t.Errorf("ParseBasis(%q) failed", value)
+		}
+	}
+	for _, value := range []string{"", "mode", "p0", "p100", "p090", "p", "90", "p-5"} {
+		if basis, ok := ParseBasis(value); ok || basis != DefaultBasis {
+			t.Errorf("ParseBasis(%q) = %q, %v, want the default and false", value, basis, ok)
+		}
+	}
+	if got := Basis("p90").Label(); got != "P90" {
+		t.Errorf("Label() = %q, want P90", got)
+	}
+}
+
+func TestParsePercentiles(t *testing.T) {
+	got, err := ParsePercentiles(" 90, p70 ,,90")
+	if err != nil || !reflect.DeepEqual(got, []int{70, 90}) {
+		t.Errorf("ParsePercentiles() = %v, %v, want [70 90]", got, err)
+	}
+	for _, value := range []string{"0", "100", "seventy", "70.5"} {
+		if _, err := ParsePercentiles(value); err == nil {
+			t.Errorf("ParsePercentiles(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	if got := testDeck.Histogram(nil); len(got) != 0 {
+		t.Errorf("Histogram(nil) = %v, want no bars", got)
+	}
+
+	got := testDeck.Histogram(votes("?", "8", "zzz", "2", "8", deck.Infinity))
+	// Values the deck does not have, like ∞ here, sort last by value.
+	// Percentages add up to 100, the bars in deck order getting the points
+	// rounding leaves over
+	want := []VoteCount{
+		{Value: "2", Count: 1, Percentage: 17},
+		{Value: "8", Count: 2, Percentage: 33},
+		{Value: "?", Count: 1, Percentage: 17},
+		{Value: "zzz", Count: 1, Percentage: 17},
+		{Value: deck.Infinity, Count: 1, Percentage: 16, Infinite: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Histogram() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPercentages(t *testing.T) {
+	tests := []struct {
+		counts []int
+		total  int
+		want   []int
+	}{
+		{nil, 0, []int{}},
+		{[]int{0}, 0, []int{0}},
+		{[]int{1, 1, 1}, 3, []int{34, 33, 33}},
+		{[]int{1, 2}, 3, []int{33, 67}},
+		{[]int{7, 13, 11}, 31, []int{23, 42, 35}},
+		{[]int{1, 1, 1, 1, 1, 1, 1}, 7, []int{15, 15, 14, 14, 14, 14, 14}},
+		{[]int{5, 5}, 10, []int{50, 50}},
+	}
+
+	for _, tt := range tests {
+		got := percentages(tt.counts, tt.total)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("percentages(%v, %d) = %v, want %v", tt.counts, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestRollup(t *testing.T) {
+	// The points deck's twelve numeric cards make bands of four
+	got := testDeck.Rollup(votes("0", "3", "5", "8", "21", "34", "4", "?", deck.Abstain))
+	want := []VoteGroup{
+		{Group: GroupLow, From: "0", To: "3", Count: 2, Percentage: 22},
+		{Group: GroupMedium, From: "5", To: "21", Count: 4, Percentage: 45}, // 4 goes up to 5
+		{Group: GroupHigh, From: "34", To: "144", Count: 1, Percentage: 11},
+		{Group: GroupOther, Count: 2, Percentage: 22},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Rollup() = %+v, want %+v", got, want)
+	}
+
+	// Every group is listed even without votes, and values above the deck
+	// are high
+	got = testDeck.Rollup(votes("1000"))
+	if len(got) != 4 || got[2].Count != 1 || got[2].Percentage != 100 || got[0].Count != 0 {
+		t.Errorf("Rollup() = %+v, want one high vote", got)
+	}
+}
+
+func TestConsensus(t *testing.T) {
+	empty := testDeck.Consensus(nil)
+	if empty.Votes != 0 || empty.Median != nil || empty.Unanimous {
+		t.Errorf("Consensus(nil) = %+v, want no votes and no median", empty)
+	}
+
+	got := testDeck.Consensus(votes("5", "5", "☕", deck.Abstain))
+	if !got.Unanimous || got.Agreement != 1 || *got.Spread != 0 || *got.StdDev != 0 {
+		t.Errorf("Consensus() = %+v, want unanimous with no spread", got)
+	}
+
+	got = testDeck.Consensus(votes("1", "3", "5", "5"))
+	if got.Unanimous || got.Agreement != 0.5 || *got.Spread != 4 || *got.Mean != 3.5 {
+		t.Errorf("Consensus() = %+v, want agreement 0.5, spread 4 and mean 3.5", got)
+	}
+}
+
+func TestAdvisory(t *testing.T) {
+	all := votes("3", "3", "13", "21")
+	all[2].Advisory = true
+	all[3].Advisory = true
+
+	if got := testDeck.Consensus(all); !got.Unanimous || got.Votes != 2 || *got.Median != 3 {
+		t.Errorf("Consensus() = %+v, want the two committed votes only", got)
+	}
+	if got := testDeck.Histogram(all); len(got) != 1 || got[0].Value != "3" || got[0].Percentage != 100 {
+		t.Errorf("Histogram() = %+v, want only the committed card", got)
+	}
+	if got := Median(all); got == nil || *got != 3 {
+		t.Errorf("Median() = %v, want 3", show(got))
+	}
+	if got := Advisory(all); len(got) != 2 || got[0].VoteValue != "13" || got[1].VoteValue != "21" {
+		t.Errorf("Advisory() = %+v, want the stakeholders' votes", got)
+	}
+
+	only := votes("8")
+	only[0].Advisory = true
+	if got := testDeck.Ticket(only); got.HasValues || got.Mode != "N/A" {
+		t.Errorf("Ticket() = %+v, want no values from advisory votes alone", got)
+	}
+}
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func show(value *float64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -0,0 +1,75 @@
+package stats
+
+import "poker-planning/internal/models"
+
+// Summary is what the summary of a session computes from its votes.
+// Calibration stories get their own results but stay out of the totals and
+// the participants' statistics. Advisory votes stay out of everything but
+// the statistics of the stakeholders who cast them.
+type Summary struct {
+	TotalVotes       int                         // votes on tickets that are not calibration stories
+	EstimatedTickets int                         // tickets, not calibration stories, with numeric votes
+	Overall          TicketStats                 // all those votes together
+	Tickets          map[int]TicketStats         // ticket ID -> statistics of its votes
+	Histograms       map[int][]VoteCount         // ticket ID -> votes per card
+	Participants     map[string]*ParticipantStat // user ID -> how they voted
+}
+
+// Session summarises the votes on a session's tickets. The session must be
+// loaded with its tickets and their votes.
+func Session(session *models.Session) Summary {
+	summary := Summary{
+		Tickets:      make(map[int]TicketStats),
+		Histograms:   make(map[int][]VoteCount),
+		Participants: make(map[string]*ParticipantStat),
+	}
+
+	cards := ForSession(session)
+	var allVotes []models.Vote
+	for _, ticket := range session.Tickets {
+		if len(ticket.Votes) == 0 {
+			continue
+		}
+		if !ticket.IsCalibration {
+			counted := committed(ticket.Votes)
+			summary.TotalVotes += len(counted)
+			allVotes = append(allVotes, counted...)
+		}
+
+		stats := cards.Ticket(ticket.Votes)
+		summary.Tickets[ticket.ID] = stats
+		if stats.HasValues && !ticket.IsCalibration {
+			summary.EstimatedTickets++
+		}
+
+		summary.Histograms[ticket.ID] = cards.Histogram(ticket.Votes)
+	}
+
+	if len(allVotes) > 0 {
+		summary.Overall = cards.Ticket(allVotes)
+	}
+
+	for _, participant := range session.Participants {
+		var participantVotes []models.Vote
+		for _, ticket := range session.Tickets {
+			if ticket.IsCalibration {
+				continue
+			}
+			for _, vote := range ticket.Votes {
+				if vote.UserID == participant.ID {
+					// A stakeholder's own statistics use their advisory votes
+					vote.Advisory = false
+					participantVotes = append(participantVotes, vote)
+				}
+			}
+		}
+
+		stat := &ParticipantStat{VoteCount: len(participantVotes)}
+		if median := Median(participantVotes); median != nil {
+			stat.MedianVote = *median
+		}
+		summary.Participants[participant.ID] = stat
+	}
+
+	return summary
+}
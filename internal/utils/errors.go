@@ -2,8 +2,11 @@ package utils
 
 import (
 	"encoding/json"
+	"fmt"
+	"html"
 	"log"
 	"net/http"
+	"strings"
 )
 
 type ErrorResponse struct {
@@ -46,6 +49,51 @@ func WriteValidationError(w http.ResponseWriter, errors ValidationErrors) {
 	}
 }
 
+// FieldErrorID is the id of the element a form reserves for a field's
+// server-side validation message.
+func FieldErrorID(field string) string {
+	return field + "-field-error"
+}
+
+// WriteFormValidationError reports validation errors from a form submission
+// in the shape the client can use. API clients asking for JSON get the
+// WriteValidationError response. HTMX requests get one out-of-band fragment
+// per field, swapped into the element with id FieldErrorID(field), while the
+// form itself stays in place. Anything else gets the plain error box.
+func WriteFormValidationError(w http.ResponseWriter, r *http.Request, errors ValidationErrors) {
+	if r.Header.Get("HX-Request") == "" && strings.Contains(r.Header.Get("Accept"), "application/json") {
+		WriteValidationError(w, errors)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "true" {
+		WriteHTMLError(w, http.StatusBadRequest, html.EscapeString(errors.Error()))
+		return
+	}
+
+	// A field can fail more than one check; show its messages together
+	var fields []string
+	messages := make(map[string][]string)
+	for _, err := range errors {
+		if _, ok := messages[err.Field]; !ok {
+			fields = append(fields, err.Field)
+		}
+		messages[err.Field] = append(messages[err.Field], err.Message)
+	}
+
+	var fragments strings.Builder
+	for _, field := range fields {
+		fragments.WriteString(`<div id="` + html.EscapeString(FieldErrorID(field)) + `" hx-swap-oob="innerHTML">`)
+		fragments.WriteString(html.EscapeString(strings.Join(messages[field], ". ")))
+		fragments.WriteString("</div>\n")
+	}
+
+	w.Header().Set("Content-Type", "text/html")
+	w.Header().Set("HX-Reswap", "none")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write([]byte(fragments.String()))
+}
+
 func WriteHTMLError(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "text/html")
 	w.WriteHeader(statusCode)
@@ -65,8 +113,19 @@ func WriteHTMLError(w http.ResponseWriter, statusCode int, message string) {
 	w.Write([]byte(html))
 }
 
-func LogError(operation string, err error) {
+// LogError logs an error and reports it, if error reporting is configured,
+// with whatever context the caller can add.
+func LogError(operation string, err error, context ...ReportContext) {
 	log.Printf("Error in %s: %v", operation, err)
+
+	if reporter != nil {
+		var rc ReportContext
+		if len(context) > 0 {
+			rc = context[0]
+		}
+		rc.Operation = operation
+		reporter.enqueue(newSentryEvent("error", fmt.Sprintf("%T", err), err.Error(), rc, 1))
+	}
 }
 
 func RecoverFromPanic(next http.Handler) http.Handler {
@@ -74,6 +133,10 @@ func RecoverFromPanic(next http.Handler) http.Handler {
 		defer func() {
 			if err := recover(); err != nil {
 				log.Printf("Panic recovered: %v", err)
+				if reporter != nil {
+					// Skip runtime.gopanic; the frames that panicked are still on the stack
+					reporter.enqueue(newSentryEvent("fatal", "panic", fmt.Sprint(err), ReportContext{Request: r}, 2))
+				}
 				WriteError(w, http.StatusInternalServerError, "An unexpected error occurred")
 			}
 		}()
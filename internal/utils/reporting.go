@@ -0,0 +1,273 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"poker-planning/internal/models"
+)
+
+// Errors are reported to Sentry when a DSN is configured. Events are sent in
+// the background and dropped if Sentry falls behind, so reporting never
+// slows down or breaks a request.
+
+const reportQueueSize = 100
+
+type sentryReporter struct {
+	dsn         string
+	endpoint    string
+	auth        string
+	environment string
+	release     string
+	client      *http.Client
+	queue       chan sentryEvent
+	pending     sync.WaitGroup
+}
+
+var reporter *sentryReporter
+
+// ReportContext is what is known about where an error happened. Every field
+// is optional.
+type ReportContext struct {
+	Operation string
+	Request   *http.Request
+	SessionID string
+	UserID    string
+}
+
+// InitErrorReporting starts sending reported errors to the Sentry project
+// identified by dsn. Call it once at startup, before serving requests.
+func InitErrorReporting(dsn, environment, release string) error {
+	parsed, err := url.Parse(dsn)
+	if err != nil || parsed.User == nil || parsed.Host == "" {
+		return fmt.Errorf("invalid Sentry DSN")
+	}
+
+	projectID := strings.TrimPrefix(parsed.Path, "/")
+	prefix := ""
+	if i := strings.LastIndex(projectID, "/"); i >= 0 {
+		prefix, projectID = "/"+projectID[:i], projectID[i+1:]
+	}
+	if projectID == "" {
+		return fmt.Errorf("invalid Sentry DSN: missing project ID")
+	}
+
+	reporter = &sentryReporter{
+		dsn:         dsn,
+		endpoint:    fmt.Sprintf("%s://%s%s/api/%s/envelope/", parsed.Scheme, parsed.Host, prefix, projectID),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=poker-planning/1.0, sentry_key=%s", parsed.User.Username()),
+		environment: environment,
+		release:     release,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		queue:       make(chan sentryEvent, reportQueueSize),
+	}
+	go reporter.run()
+
+	return nil
+}
+
+// FlushErrorReports waits up to timeout for queued reports to be sent. Call
+// it before the process exits.
+func FlushErrorReports(timeout time.Duration) {
+	if reporter == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		reporter.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// ReportError sends an error to Sentry, if configured. It does not log;
+// LogError and friends do both.
+func ReportError(err error, rc ReportContext) {
+	if reporter == nil || err == nil {
+		return
+	}
+	reporter.enqueue(newSentryEvent("error", fmt.Sprintf("%T", err), err.Error(), rc, 1))
+}
+
+// LogRequestError logs an error that happened while handling a request and
+// reports it with the request attached.
+func LogRequestError(r *http.Request, operation string, err error) {
+	LogError(operation, err, ReportContext{Request: r})
+}
+
+type sentryEvent struct {
+	EventID     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	Transaction string                 `json:"transaction,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	User        map[string]string      `json:"user,omitempty"`
+	Request     map[string]interface{} `json:"request,omitempty"`
+	Exception   map[string]interface{} `json:"exception"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+// newSentryEvent builds an event with the current stack, starting skip
+// callers above the caller of newSentryEvent.
+func newSentryEvent(level, errorType, message string, rc ReportContext, skip int) sentryEvent {
+	id := make([]byte, 16)
+	rand.Read(id)
+
+	event := sentryEvent{
+		EventID:   hex.EncodeToString(id),
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Level:     level,
+		Platform:  "go",
+		Tags:      make(map[string]string),
+		Exception: map[string]interface{}{
+			"values": []map[string]interface{}{{
+				"type":       errorType,
+				"value":      message,
+				"stacktrace": map[string]interface{}{"frames": stackFrames(skip + 3)},
+			}},
+		},
+	}
+
+	if rc.Operation != "" {
+		event.Transaction = rc.Operation
+		event.Tags["operation"] = rc.Operation
+	}
+
+	if r := rc.Request; r != nil {
+		event.Request = map[string]interface{}{
+			"method": r.Method,
+			"url":    r.URL.Path,
+			"headers": map[string]string{
+				"User-Agent": r.UserAgent(),
+				"Referer":    r.Referer(),
+			},
+		}
+		if rc.SessionID == "" && strings.HasPrefix(r.URL.Path, "/session/") {
+			rc.SessionID = strings.SplitN(strings.TrimPrefix(r.URL.Path, "/session/"), "/", 2)[0]
+		}
+		// The session middleware stores the user under "user"
+		if user, ok := r.Context().Value("user").(*models.User); ok && rc.UserID == "" {
+			rc.UserID = user.ID
+		}
+	}
+
+	if rc.SessionID != "" {
+		event.Tags["session_id"] = rc.SessionID
+	}
+	if rc.UserID != "" {
+		event.User = map[string]string{"id": rc.UserID}
+	}
+
+	return event
+}
+
+// stackFrames returns the calling stack, oldest call first as Sentry expects.
+func stackFrames(skip int) []sentryFrame {
+	pcs := make([]uintptr, 50)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []sentryFrame
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, sentryFrame{
+			Function: frame.Function,
+			Filename: frame.File,
+			Lineno:   frame.Line,
+			InApp:    strings.HasPrefix(frame.Function, "poker-planning/"),
+		})
+		if !more {
+			break
+		}
+	}
+
+	for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+		stack[i], stack[j] = stack[j], stack[i]
+	}
+	return stack
+}
+
+func (s *sentryReporter) enqueue(event sentryEvent) {
+	event.Environment = s.environment
+	event.Release = s.release
+
+	s.pending.Add(1)
+	select {
+	case s.queue <- event:
+	default:
+		s.pending.Done()
+		log.Printf("Error report dropped, queue full: %s", event.EventID)
+	}
+}
+
+func (s *sentryReporter) run() {
+	for event := range s.queue {
+		if err := s.send(event); err != nil {
+			log.Printf("Failed to send error report: %v", err)
+		}
+		s.pending.Done()
+	}
+}
+
+// send posts one event as a Sentry envelope: a header line, an item header
+// line and the event itself.
+func (s *sentryReporter) send(event sentryEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	json.NewEncoder(&body).Encode(map[string]string{
+		"event_id": event.EventID,
+		"dsn":      s.dsn,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	json.NewEncoder(&body).Encode(map[string]interface{}{
+		"type":   "event",
+		"length": len(payload),
+	})
+	body.Write(payload)
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Sentry responded %s", resp.Status)
+	}
+	return nil
+}
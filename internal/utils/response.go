@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+func WriteJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Printf("Failed to encode JSON response: %v", err)
+	}
+}
+
+// WriteJSONError writes an error for JSON API clients as {"error": message}.
+func WriteJSONError(w http.ResponseWriter, statusCode int, message string) {
+	WriteJSON(w, statusCode, map[string]string{"error": message})
+}
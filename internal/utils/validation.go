@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
+
+	"poker-planning/internal/deck"
 )
 
 var (
@@ -15,6 +19,9 @@ var (
 	
 	// Ticket title validation: 1-200 characters
 	ticketTitleRegex = regexp.MustCompile(`^.{1,200}$`)
+
+	// Ticket key validation: 1-100 characters without spaces, e.g. PROJ-123
+	ticketKeyRegex = regexp.MustCompile(`^\S{1,100}$`)
 )
 
 type ValidationError struct {
@@ -91,6 +98,75 @@ func ValidateSessionName(name string) ValidationErrors {
 	return errors
 }
 
+func ValidateProjectName(name string) ValidationErrors {
+	var errors ValidationErrors
+	
+	name = strings.TrimSpace(name)
+	
+	if name == "" {
+		errors = append(errors, ValidationError{
+			Field:   "name",
+			Message: "Project name is required",
+		})
+		return errors
+	}
+	
+	if !sessionNameRegex.MatchString(name) {
+		errors = append(errors, ValidationError{
+			Field:   "name",
+			Message: "Project name must be 1-100 characters",
+		})
+	}
+	
+	return errors
+}
+
+func ValidateOrganizationName(name string) ValidationErrors {
+	var errors ValidationErrors
+	
+	name = strings.TrimSpace(name)
+	
+	if name == "" {
+		errors = append(errors, ValidationError{
+			Field:   "name",
+			Message: "Organization name is required",
+		})
+		return errors
+	}
+	
+	if !sessionNameRegex.MatchString(name) {
+		errors = append(errors, ValidationError{
+			Field:   "name",
+			Message: "Organization name must be 1-100 characters",
+		})
+	}
+	
+	return errors
+}
+
+func ValidateTeamName(name string) ValidationErrors {
+	var errors ValidationErrors
+	
+	name = strings.TrimSpace(name)
+	
+	if name == "" {
+		errors = append(errors, ValidationError{
+			Field:   "name",
+			Message: "Team name is required",
+		})
+		return errors
+	}
+	
+	if !sessionNameRegex.MatchString(name) {
+		errors = append(errors, ValidationError{
+			Field:   "name",
+			Message: "Team name must be 1-100 characters",
+		})
+	}
+	
+	return errors
+}
+
 func ValidateTicketTitle(title string) ValidationErrors {
 	var errors ValidationErrors
 	
@@ -128,21 +204,144 @@ func ValidateTicketDescription(description string) ValidationErrors {
 	return errors
 }
 
+// ValidateDecision checks the optional rationale and key assumptions
+// recorded with a final estimate. The rationale is a single line.
+func ValidateDecision(rationale, assumptions string) ValidationErrors {
+	var errors ValidationErrors
+
+	if strings.ContainsAny(rationale, "\r\n") || utf8.RuneCountInString(rationale) > 200 {
+		errors = append(errors, ValidationError{
+			Field:   "rationale",
+			Message: "The rationale must be a single line of no more than 200 characters",
+		})
+	}
+	if utf8.RuneCountInString(assumptions) > 1000 {
+		errors = append(errors, ValidationError{
+			Field:   "assumptions",
+			Message: "Key assumptions must be no more than 1000 characters",
+		})
+	}
+
+	return errors
+}
+
+// ValidateTicketKey checks a ticket's optional issue key from the tracker.
+func ValidateTicketKey(key string) ValidationErrors {
+	if key == "" || ticketKeyRegex.MatchString(key) {
+		return nil
+	}
+
+	return ValidationErrors{{
+		Field:   "external_key",
+		Message: "Ticket key must be 1-100 characters without spaces",
+	}}
+}
+
+// ValidateAgendaItemTitle checks the optional title of an agenda item.
+func ValidateAgendaItemTitle(title string) ValidationErrors {
+	if utf8.RuneCountInString(title) <= 100 {
+		return nil
+	}
+
+	return ValidationErrors{{
+		Field:   "title",
+		Message: "Agenda item titles must be no more than 100 characters",
+	}}
+}
+
+// ValidateParkingLotText checks the question or risk of a parking lot item.
+func ValidateParkingLotText(text string) ValidationErrors {
+	if length := utf8.RuneCountInString(text); length >= 1 && length <= 500 {
+		return nil
+	}
+
+	return ValidationErrors{{
+		Field:   "text",
+		Message: "Parking lot items must be 1-500 characters",
+	}}
+}
+
+// ValidateActionItemText checks the task of an action item.
+func ValidateActionItemText(text string) ValidationErrors {
+	if length := utf8.RuneCountInString(text); length >= 1 && length <= 500 {
+		return nil
+	}
+
+	return ValidationErrors{{
+		Field:   "text",
+		Message: "Action items must be 1-500 characters",
+	}}
+}
+
+// ValidateSnapshotName checks the name of a session restore point.
+func ValidateSnapshotName(name string) ValidationErrors {
+	if length := utf8.RuneCountInString(name); length >= 1 && length <= 100 {
+		return nil
+	}
+
+	return ValidationErrors{{
+		Field:   "name",
+		Message: "Restore point names must be 1-100 characters",
+	}}
+}
+
+// ValidateTemplateName checks the name of a ticket description template.
+func ValidateTemplateName(name string) ValidationErrors {
+	if length := utf8.RuneCountInString(name); length >= 1 && length <= 100 {
+		return nil
+	}
+
+	return ValidationErrors{{
+		Field:   "template_name",
+		Message: "Template names must be 1-100 characters",
+	}}
+}
+
+// ValidateFacilitatorNote checks the owner's private note on a session or
+// ticket. An empty note removes it.
+func ValidateFacilitatorNote(text string) ValidationErrors {
+	if utf8.RuneCountInString(text) <= 5000 {
+		return nil
+	}
+
+	return ValidationErrors{{
+		Field:   "text",
+		Message: "Notes must be no more than 5000 characters",
+	}}
+}
+
+// ValidateFeedback checks a participant's rating of a session and their
+// optional comment.
+func ValidateFeedback(rating int, comment string) ValidationErrors {
+	var errors ValidationErrors
+
+	if rating < 1 || rating > 5 {
+		errors = append(errors, ValidationError{
+			Field:   "rating",
+			Message: "Rate the session from 1 to 5",
+		})
+	}
+	if utf8.RuneCountInString(comment) > 500 {
+		errors = append(errors, ValidationError{
+			Field:   "comment",
+			Message: "Comments must be no more than 500 characters",
+		})
+	}
+
+	return errors
+}
+
 func SanitizeInput(input string) string {
 	// Only trim whitespace for most inputs to preserve special characters like emojis
 	// HTML escaping will be done in templates using the html/template package
 	return strings.TrimSpace(input)
 }
 
-func ValidateVoteValue(voteValue string) ValidationErrors {
+func ValidateVoteValue(voteValue string, cards deck.Deck) ValidationErrors {
 	var errors ValidationErrors
 	
-	validVotes := []string{"0", "1", "2", "3", "5", "8", "13", "21", "34", "55", "89", "144", "☕", "?"}
-	
-	for _, valid := range validVotes {
-		if voteValue == valid {
-			return errors // No errors if valid
-		}
+	if cards.IsValid(voteValue) {
+		return errors // No errors if valid
 	}
 	
 	errors = append(errors, ValidationError{
@@ -153,6 +352,55 @@ func ValidateVoteValue(voteValue string) ValidationErrors {
 	return errors
 }
 
+// ValidateEstimate checks a final estimate against the cards of the session
+// deck. Special cards are allowed so a ticket can be recorded as deferred.
+func ValidateEstimate(estimate string, cards deck.Deck) ValidationErrors {
+	var errors ValidationErrors
+	
+	if cards.IsValid(estimate) {
+		return errors
+	}
+	
+	errors = append(errors, ValidationError{
+		Field:   "final_estimate",
+		Message: "Estimate must be one of the session's cards",
+	})
+	
+	return errors
+}
+
+// ValidateEstimateRange checks a range around a final estimate: both ends
+// numeric cards of the deck, the low one no higher than the high one.
+func ValidateEstimateRange(low, high string, cards deck.Deck) ValidationErrors {
+	lowValue, lowOK := deck.NumericValue(low)
+	highValue, highOK := deck.NumericValue(high)
+	if !lowOK || !highOK || !cards.IsValid(low) || !cards.IsValid(high) {
+		return ValidationErrors{{Field: "estimate_range", Message: "Both ends of the range must be numeric cards of the session"}}
+	}
+	if lowValue > highValue {
+		return ValidationErrors{{Field: "estimate_range", Message: "The low end of the range must not be above the high end"}}
+	}
+	return nil
+}
+
+func ValidateTimezone(timezone string) ValidationErrors {
+	var errors ValidationErrors
+	
+	// Empty means "use the browser's timezone"
+	if timezone == "" {
+		return errors
+	}
+	
+	if _, err := time.LoadLocation(timezone); err != nil {
+		errors = append(errors, ValidationError{
+			Field:   "timezone",
+			Message: "Unknown timezone",
+		})
+	}
+	
+	return errors
+}
+
 func ValidateEmoji(emoji string) ValidationErrors {
 	var errors ValidationErrors
 	
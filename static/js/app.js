@@ -51,4 +51,47 @@ document.addEventListener('keydown', function(e) {
             revealBtn.click();
         }
     }
-});
\ No newline at end of file
+});
+// Server-side validation errors come back as a 400 whose body only holds
+// out-of-band fragments for each form's .field-error slots
+function isValidationResponse(xhr) {
+    return xhr.status === 400 && xhr.getResponseHeader('HX-Reswap') === 'none';
+}
+
+document.addEventListener('htmx:beforeRequest', function(e) {
+    e.detail.elt.querySelectorAll('.field-error').forEach(slot => slot.textContent = '');
+});
+
+document.addEventListener('htmx:beforeSwap', function(e) {
+    if (isValidationResponse(e.detail.xhr)) {
+        e.detail.shouldSwap = true;
+    }
+});
+
+// Countdowns, e.g. until a timed voting round is revealed. The server ends the
+// round or locks the votes itself; this only shows how long is left.
+setInterval(function() {
+    document.querySelectorAll('[data-voting-deadline]').forEach(el => {
+        const remaining = Math.max(0, Math.ceil((parseInt(el.dataset.votingDeadline) - Date.now()) / 1000));
+        const minutes = Math.floor(remaining / 60);
+        const seconds = String(remaining % 60).padStart(2, '0');
+        el.textContent = `${minutes}:${seconds}`;
+    });
+
+    // Revealed votes that may only change for a while lock by themselves
+    document.querySelectorAll('[data-vote-change-until]').forEach(el => {
+        if (Date.now() >= parseInt(el.dataset.voteChangeUntil)) {
+            el.querySelectorAll('.voting-card').forEach(card => card.disabled = true);
+        }
+    });
+}, 500);
+
+// Tell the server the browser's timezone so times render in it for users
+// who have not picked one in their preferences
+(function() {
+    const zone = Intl.DateTimeFormat().resolvedOptions().timeZone;
+    const cookie = 'poker_tz=' + encodeURIComponent(zone || '');
+    if (zone && !document.cookie.split('; ').includes(cookie)) {
+        document.cookie = cookie + '; path=/; max-age=31536000; SameSite=Strict';
+    }
+})();
@@ -0,0 +1,61 @@
+// Decodes MessagePack WebSocket messages, which pages can ask for with the
+// "msgpack" subprotocol to save bandwidth on slow connections. Handles what
+// the server sends: nil, booleans, numbers, strings, arrays and maps.
+function decodeMsgpack(buffer) {
+    const view = new DataView(buffer);
+    const text = new TextDecoder();
+    let offset = 0;
+
+    function str(length) {
+        const value = text.decode(new Uint8Array(buffer, offset, length));
+        offset += length;
+        return value;
+    }
+    function array(length) {
+        const value = [];
+        for (let i = 0; i < length; i++) value.push(read());
+        return value;
+    }
+    function map(length) {
+        const value = {};
+        for (let i = 0; i < length; i++) {
+            const key = read();
+            value[key] = read();
+        }
+        return value;
+    }
+    function read() {
+        const code = view.getUint8(offset++);
+        let value;
+        if (code <= 0x7f) return code;
+        if (code >= 0xe0) return code - 0x100;
+        if ((code & 0xe0) === 0xa0) return str(code & 0x1f);
+        if ((code & 0xf0) === 0x90) return array(code & 0x0f);
+        if ((code & 0xf0) === 0x80) return map(code & 0x0f);
+        switch (code) {
+            case 0xc0: return null;
+            case 0xc2: return false;
+            case 0xc3: return true;
+            case 0xcc: value = view.getUint8(offset); offset += 1; return value;
+            case 0xcd: value = view.getUint16(offset); offset += 2; return value;
+            case 0xce: value = view.getUint32(offset); offset += 4; return value;
+            case 0xcf: value = Number(view.getBigUint64(offset)); offset += 8; return value;
+            case 0xd0: value = view.getInt8(offset); offset += 1; return value;
+            case 0xd1: value = view.getInt16(offset); offset += 2; return value;
+            case 0xd2: value = view.getInt32(offset); offset += 4; return value;
+            case 0xd3: value = Number(view.getBigInt64(offset)); offset += 8; return value;
+            case 0xca: value = view.getFloat32(offset); offset += 4; return value;
+            case 0xcb: value = view.getFloat64(offset); offset += 8; return value;
+            case 0xd9: value = view.getUint8(offset); offset += 1; return str(value);
+            case 0xda: value = view.getUint16(offset); offset += 2; return str(value);
+            case 0xdb: value = view.getUint32(offset); offset += 4; return str(value);
+            case 0xdc: value = view.getUint16(offset); offset += 2; return array(value);
+            case 0xdd: value = view.getUint32(offset); offset += 4; return array(value);
+            case 0xde: value = view.getUint16(offset); offset += 2; return map(value);
+            case 0xdf: value = view.getUint32(offset); offset += 4; return map(value);
+        }
+        throw new Error('Unsupported MessagePack type 0x' + code.toString(16));
+    }
+
+    return read();
+}
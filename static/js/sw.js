@@ -0,0 +1,24 @@
+// Service worker for Web Push: shows the notifications the server pushes and
+// opens the session when one is clicked.
+
+self.addEventListener('push', function(event) {
+    const data = event.data ? event.data.json() : {};
+    event.waitUntil(self.registration.showNotification(data.title || 'Sprint Planning Poker', {
+        body: data.body || '',
+        tag: data.tag,
+        renotify: !!data.tag,
+        data: { url: data.url || '/' }
+    }));
+});
+
+self.addEventListener('notificationclick', function(event) {
+    event.notification.close();
+    const url = new URL(event.notification.data.url, self.location.origin).href;
+    event.waitUntil(clients.matchAll({ type: 'window', includeUncontrolled: true }).then(function(windows) {
+        // Bring an open tab of the session forward rather than opening another
+        for (const client of windows) {
+            if (client.url === url && 'focus' in client) return client.focus();
+        }
+        return clients.openWindow(url);
+    }));
+});